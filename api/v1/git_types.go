@@ -34,26 +34,36 @@ const (
 
 	GiteaDefaultAPIUrl = "https://gitea.com"
 	GiteaDefaultHost   = "https://gitea.com"
+
+	AzureDevOpsDefaultAPIUrl = "https://dev.azure.com"
+	AzureDevOpsDefaultHost   = "https://dev.azure.com"
 )
 
 // GitConfig is a git repository where the IntegrationConfig to be configured
 type GitConfig struct {
 	// Type for git remote server
-	// +kubebuilder:validation:Enum=github;gitlab;gitea
+	// +kubebuilder:validation:Enum=github;gitlab;gitea;bitbucket;azuredevops;generic
 	Type GitType `json:"type"`
 
 	// Repository name of git repository (in <org>/<repo> form, e.g., tmax-cloud/cicd-operator)
+	// For azuredevops type, it should be in <organization>/<project>/<repo> form
+	// For generic type, it is the path of the repository in the git server (e.g., mirrors/cicd-operator.git)
 	// +kubebuilder:validation:Pattern=.+/.+
 	Repository string `json:"repository"`
 
 	// APIUrl for api server (e.g., https://api.github.com for github type),
 	// for the case where the git repository is self-hosted (should contain specific protocol otherwise webhook server returns error)
 	// Also, it should *NOT* contain repository path (e.g., tmax-cloud/cicd-operator)
+	// For generic type, it is required and should be the url of the git server (e.g., https://git.example.com)
 	APIUrl string `json:"apiUrl,omitempty"`
 
 	// Token is a token for accessing the remote git server. It can be empty, if you don't want to register a webhook
 	// to the git server
 	Token *GitToken `json:"token,omitempty"`
+
+	// UseChecks reports the results of jobs as check runs, instead of commit statuses.
+	// It's only available for github type, and the token should be issued by a GitHub App (i.e., token.githubApp)
+	UseChecks bool `json:"useChecks,omitempty"`
 }
 
 // GetGitHost gets git host
@@ -65,6 +75,8 @@ func (config *GitConfig) GetGitHost() (string, error) {
 		gitURL = GitlabDefaultHost
 	} else if gitURL == GiteaDefaultAPIUrl {
 		gitURL = GiteaDefaultHost
+	} else if gitURL == AzureDevOpsDefaultAPIUrl {
+		gitURL = AzureDevOpsDefaultHost
 	}
 	gitU, err := url.Parse(gitURL)
 	if err != nil {
@@ -82,6 +94,8 @@ func (config *GitConfig) GetAPIUrl() string {
 		return GitlabDefaultAPIUrl
 	} else if config.Type == GitTypeGitea && config.APIUrl == "" {
 		return GiteaDefaultAPIUrl
+	} else if config.Type == GitTypeAzureDevOps && config.APIUrl == "" {
+		return AzureDevOpsDefaultAPIUrl
 	}
 	return config.APIUrl
 }
@@ -93,6 +107,10 @@ type GitToken struct {
 
 	// ValueFrom refers secret. Recommended
 	ValueFrom *GitTokenFrom `json:"valueFrom,omitempty"`
+
+	// GitHubApp issues installation access tokens of a GitHub App, instead of using a static token.
+	// It's only available for github type
+	GitHubApp *GitHubAppToken `json:"githubApp,omitempty"`
 }
 
 // GitTokenFrom refers to the secret for the access token
@@ -100,15 +118,30 @@ type GitTokenFrom struct {
 	SecretKeyRef corev1.SecretKeySelector `json:"secretKeyRef"`
 }
 
+// GitHubAppToken is an installation of a GitHub App, whose installation access tokens are used for the git server
+type GitHubAppToken struct {
+	// AppID is an ID of the GitHub App
+	AppID int64 `json:"appId"`
+
+	// InstallationID is an ID of the GitHub App's installation for the repository
+	InstallationID int64 `json:"installationId"`
+
+	// PrivateKey refers the secret containing the private key (PEM-encoded) of the GitHub App
+	PrivateKey corev1.SecretKeySelector `json:"privateKey"`
+}
+
 // GitType is a type of remote git server
 type GitType string
 
 // Git Types
 const (
-	GitTypeGitHub = GitType("github")
-	GitTypeGitLab = GitType("gitlab")
-	GitTypeGitea  = GitType("gitea")
-	GitTypeFake   = GitType("fake")
+	GitTypeGitHub      = GitType("github")
+	GitTypeGitLab      = GitType("gitlab")
+	GitTypeGitea       = GitType("gitea")
+	GitTypeBitbucket   = GitType("bitbucket")
+	GitTypeAzureDevOps = GitType("azuredevops")
+	GitTypeGeneric     = GitType("generic")
+	GitTypeFake        = GitType("fake")
 )
 
 // GitRef is a git reference type
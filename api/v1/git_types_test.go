@@ -38,6 +38,10 @@ func TestGitConfig_GetGitHost(t *testing.T) {
 			cfg:          &GitConfig{Type: GitTypeGitLab},
 			expectedHost: "https://gitlab.com",
 		},
+		"azuredevops": {
+			cfg:          &GitConfig{Type: GitTypeAzureDevOps},
+			expectedHost: "https://dev.azure.com",
+		},
 		"private": {
 			cfg:          &GitConfig{Type: GitTypeGitLab, APIUrl: "https://gitlab.my.com/path"},
 			expectedHost: "https://gitlab.my.com",
@@ -77,6 +81,10 @@ func TestGitConfig_GetAPIUrl(t *testing.T) {
 			cfg:         &GitConfig{Type: GitTypeGitLab},
 			expectedURL: "https://gitlab.com",
 		},
+		"azuredevops": {
+			cfg:         &GitConfig{Type: GitTypeAzureDevOps},
+			expectedURL: "https://dev.azure.com",
+		},
 		"private": {
 			cfg:         &GitConfig{Type: GitTypeGitLab, APIUrl: "https://gitlab.my.com/path"},
 			expectedURL: "https://gitlab.my.com/path",
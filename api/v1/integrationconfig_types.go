@@ -20,13 +20,17 @@ import (
 	"context"
 	"crypto/tls"
 	"fmt"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/tektoncd/pipeline/pkg/apis/pipeline/pod"
 	tektonv1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
 	"github.com/tmax-cloud/cicd-operator/internal/configs"
+	"github.com/tmax-cloud/cicd-operator/pkg/git/githubapp"
 
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -41,11 +45,15 @@ const (
 const (
 	IntegrationConfigConditionWebhookRegistered = "webhook-registered"
 	IntegrationConfigConditionReady             = "ready"
+	IntegrationConfigConditionWhenValid         = "when-valid"
+	IntegrationConfigConditionSecretRotated     = "secret-rotated"
 )
 
-// IntegrationConfigConditionReasonNoGitToken is a Reason key
+// Reason keys for IntegrationConfig's conditions
 const (
 	IntegrationConfigConditionReasonNoGitToken = "noGitToken"
+	IntegrationConfigConditionReasonPolling    = "polling"
+	IntegrationConfigConditionReasonRotating   = "secretRotating"
 )
 
 // IntegrationConfigSpec defines the desired state of IntegrationConfig
@@ -62,6 +70,9 @@ type IntegrationConfigSpec struct {
 	// Jobs specify the tasks to be executed
 	Jobs IntegrationConfigJobs `json:"jobs"`
 
+	// JobsFrom loads the pre-submit/post-submit jobs from a file in the repository, at the commit being tested
+	JobsFrom *JobsFrom `json:"jobsFrom,omitempty"`
+
 	// MergeConfig specifies how to automate the PR merge
 	MergeConfig *MergeConfig `json:"mergeConfig,omitempty"`
 
@@ -74,6 +85,13 @@ type IntegrationConfigSpec struct {
 	// IJManageSpec defines variables to manage created integration jobs
 	IJManageSpec IntegrationJobManageSpec `json:"ijManageSpec,omitempty"`
 
+	// JobTimeout is the timeout of the whole jobs (i.e., the PipelineRun) of an IntegrationJob, once it starts running
+	JobTimeout *metav1.Duration `json:"jobTimeout,omitempty"`
+
+	// MaxConcurrentJobs is the max number of IntegrationJobs of this config, running simultaneously (0 means no limit)
+	// +kubebuilder:validation:Minimum=0
+	MaxConcurrentJobs int `json:"maxConcurrentJobs,omitempty"`
+
 	// ParamConfig specifies parameter
 	ParamConfig *ParameterConfig `json:"paramConfig,omitempty"`
 
@@ -166,6 +184,52 @@ func ConvertToTektonParams(params []ParameterValue) []tektonv1beta1.Param {
 type IntegrationJobManageSpec struct {
 	// Timeout for pending integration job gc
 	Timeout *metav1.Duration `json:"timeout,omitempty"`
+
+	// CancelSuperseded cancels the pending/running pre-submit jobs of a pull request, when a new commit is pushed to it
+	CancelSuperseded bool `json:"cancelSuperseded,omitempty"`
+
+	// Priority overrides the default priorities of the integration jobs, for each job type
+	Priority *IntegrationJobPriority `json:"priority,omitempty"`
+}
+
+// Default priorities of the integration jobs
+const (
+	DefaultPriorityPeriodic   = 0
+	DefaultPriorityPreSubmit  = 10
+	DefaultPriorityPostSubmit = 20
+)
+
+// IntegrationJobPriority specifies the priorities of the integration jobs, for each job type
+type IntegrationJobPriority struct {
+	// PreSubmit is a priority of the pre-submit jobs
+	PreSubmit *int `json:"preSubmit,omitempty"`
+
+	// PostSubmit is a priority of the post-submit jobs
+	PostSubmit *int `json:"postSubmit,omitempty"`
+
+	// Periodic is a priority of the periodic jobs
+	Periodic *int `json:"periodic,omitempty"`
+}
+
+// JobsFromMode is a mode of combining the jobs loaded from the file with the jobs of the IntegrationConfig
+type JobsFromMode string
+
+// JobsFromMode types
+const (
+	JobsFromModeMerge   = JobsFromMode("merge")
+	JobsFromModeReplace = JobsFromMode("replace")
+)
+
+// JobsFrom specifies the file in the repository, from which the jobs are loaded
+type JobsFrom struct {
+	// Path is a path of the jobs file in the repository (e.g., .cicd/jobs.yaml).
+	// The file has the same structure as the jobs field (preSubmit and postSubmit). Periodic jobs are ignored
+	Path string `json:"path"`
+
+	// Mode is how the loaded jobs are combined with the jobs of the IntegrationConfig.
+	// merge (default) overrides the jobs with the same name and appends the others, while replace replaces all the jobs
+	// +kubebuilder:validation:Enum=merge;replace
+	Mode JobsFromMode `json:"mode,omitempty"`
 }
 
 // IntegrationConfigJobs categorizes jobs into three types (pre-submit, post-submit and periodic jobs)
@@ -185,6 +249,20 @@ type IntegrationConfigStatus struct {
 	// Conditions of IntegrationConfig
 	Conditions []metav1.Condition `json:"conditions"`
 	Secrets    string             `json:"secrets,omitempty"`
+
+	// PreviousSecrets is the webhook secret before it's rotated. Webhooks signed with it are still accepted until the
+	// grace period (webhookSecretGracePeriod) after the rotation
+	PreviousSecrets string `json:"previousSecrets,omitempty"`
+
+	// SecretRotationTime is the time the webhook secret is rotated
+	SecretRotationTime *metav1.Time `json:"secretRotationTime,omitempty"`
+
+	// LastSeenRefs are the refs (ref name -> commit SHA) of the repository, last seen by the poller.
+	// It's only used for the generic git type. It is empty (not omitted) if the polled repository has no refs, so that
+	// it's distinguished from the one never polled
+	// +optional
+	// +nullable
+	LastSeenRefs map[string]string `json:"lastSeenRefs"`
 }
 
 // +kubebuilder:object:root=true
@@ -225,6 +303,11 @@ func (i *IntegrationConfig) GetToken(c client.Client) (string, error) {
 		return "", nil
 	}
 
+	// Get from GitHub App
+	if tokenStruct.GitHubApp != nil {
+		return i.getGitHubAppToken(c)
+	}
+
 	// Get from value
 	if tokenStruct.ValueFrom == nil {
 		if tokenStruct.Value != "" {
@@ -247,6 +330,27 @@ func (i *IntegrationConfig) GetToken(c client.Client) (string, error) {
 	return string(token), nil
 }
 
+// getGitHubAppToken issues an installation access token of the GitHub App
+func (i *IntegrationConfig) getGitHubAppToken(c client.Client) (string, error) {
+	app := i.Spec.Git.Token.GitHubApp
+	if i.Spec.Git.Type != GitTypeGitHub {
+		return "", fmt.Errorf("githubApp token is only available for github type")
+	}
+
+	secretName := app.PrivateKey.Name
+	secretKey := app.PrivateKey.Key
+	secret := &corev1.Secret{}
+	if err := c.Get(context.Background(), types.NamespacedName{Name: secretName, Namespace: i.Namespace}, secret); err != nil {
+		return "", err
+	}
+	privateKey, ok := secret.Data[secretKey]
+	if !ok {
+		return "", fmt.Errorf("private key secret/key %s/%s not valid", secretName, secretKey)
+	}
+
+	return githubapp.GetInstallationToken(i.Spec.Git.GetAPIUrl(), app.AppID, app.InstallationID, privateKey, i.GetTLSConfig())
+}
+
 // GetServiceAccountName returns the name of the related ServiceAccount
 func GetServiceAccountName(configName string) string {
 	return fmt.Sprintf("%s-sa", configName)
@@ -262,6 +366,36 @@ func (i *IntegrationConfig) GetWebhookServerAddress() string {
 	return fmt.Sprintf("http://%s/webhook/%s/%s", configs.CurrentExternalHostName, i.Namespace, i.Name)
 }
 
+// GetWebhookSecrets returns the webhook secrets to be accepted, i.e., the current secret and the previous one while
+// the secret is being rotated
+func (i *IntegrationConfig) GetWebhookSecrets() []string {
+	secrets := []string{i.Status.Secrets}
+	if i.Status.PreviousSecrets != "" {
+		secrets = append(secrets, i.Status.PreviousSecrets)
+	}
+	return secrets
+}
+
+// RotateSecret replaces the webhook secret with the new secret, keeping the current one as the previous secret.
+// The webhook is marked as not registered, so it's registered again with the new secret
+func (i *IntegrationConfig) RotateSecret(secret string) {
+	i.Status.PreviousSecrets = i.Status.Secrets
+	i.Status.Secrets = secret
+	i.Status.SecretRotationTime = &metav1.Time{Time: time.Now()}
+
+	meta.SetStatusCondition(&i.Status.Conditions, metav1.Condition{
+		Type:    IntegrationConfigConditionSecretRotated,
+		Status:  metav1.ConditionFalse,
+		Reason:  IntegrationConfigConditionReasonRotating,
+		Message: "Webhook secret is being rotated",
+	})
+	if cond := meta.FindStatusCondition(i.Status.Conditions, IntegrationConfigConditionWebhookRegistered); cond != nil && cond.Status == metav1.ConditionTrue {
+		cond.Status = metav1.ConditionFalse
+		cond.Reason = IntegrationConfigConditionReasonRotating
+		cond.Message = "Webhook is to be registered with the new secret"
+	}
+}
+
 // GetDuration returns timeout duration. Default is TTL value
 func (i *IntegrationConfig) GetDuration() *metav1.Duration {
 	if i.Spec.IJManageSpec.Timeout != nil {
@@ -272,6 +406,58 @@ func (i *IntegrationConfig) GetDuration() *metav1.Duration {
 	}
 }
 
+// GetPriority returns the priority of the integration jobs of the job type
+func (i *IntegrationConfig) GetPriority(jobType JobType) int {
+	var override *int
+	priority := 0
+	switch jobType {
+	case JobTypePreSubmit:
+		priority = DefaultPriorityPreSubmit
+		if i.Spec.IJManageSpec.Priority != nil {
+			override = i.Spec.IJManageSpec.Priority.PreSubmit
+		}
+	case JobTypePostSubmit:
+		priority = DefaultPriorityPostSubmit
+		if i.Spec.IJManageSpec.Priority != nil {
+			override = i.Spec.IJManageSpec.Priority.PostSubmit
+		}
+	case JobTypePeriodic:
+		priority = DefaultPriorityPeriodic
+		if i.Spec.IJManageSpec.Priority != nil {
+			override = i.Spec.IJManageSpec.Priority.Periodic
+		}
+	}
+	if override != nil {
+		priority = *override
+	}
+	return priority
+}
+
+// ValidateWhen validates the branch/tag patterns of the config's when and all the jobs' when
+func (i *IntegrationConfig) ValidateWhen() error {
+	var messages []string
+	if i.Spec.When != nil {
+		if err := i.Spec.When.Validate(); err != nil {
+			messages = append(messages, fmt.Sprintf("when: %s", err.Error()))
+		}
+	}
+	for jobType, jobs := range map[JobType]Jobs{JobTypePreSubmit: i.Spec.Jobs.PreSubmit, JobTypePostSubmit: i.Spec.Jobs.PostSubmit} {
+		for _, j := range jobs {
+			if j.When == nil {
+				continue
+			}
+			if err := j.When.Validate(); err != nil {
+				messages = append(messages, fmt.Sprintf("%s job %s: %s", jobType, j.Name, err.Error()))
+			}
+		}
+	}
+	if len(messages) > 0 {
+		sort.Strings(messages)
+		return fmt.Errorf("%s", strings.Join(messages, "; "))
+	}
+	return nil
+}
+
 // GetTLSConfig returns tls config from integration configs' tlsConfig
 func (i *IntegrationConfig) GetTLSConfig() *tls.Config {
 	if i.Spec.TLSConfig != nil {
@@ -284,9 +470,11 @@ func (i *IntegrationConfig) GetTLSConfig() *tls.Config {
 
 // IntegrationConfig's API kinds
 const (
-	IntegrationConfigAPIRunPre     = "runpre"
-	IntegrationConfigAPIRunPost    = "runpost"
-	IntegrationConfigAPIWebhookURL = "webhookurl"
+	IntegrationConfigAPIRunPre       = "runpre"
+	IntegrationConfigAPIRunPost      = "runpost"
+	IntegrationConfigAPIWebhookURL   = "webhookurl"
+	IntegrationConfigAPIReplay       = "replay"
+	IntegrationConfigAPIRotateSecret = "rotatesecret"
 )
 
 // IntegrationConfigAPIReqRunPreBody is a body struct for IntegrationConfig's api request
@@ -308,3 +496,9 @@ type IntegrationConfigAPIReqWebhookURL struct {
 	URL    string `json:"url"`
 	Secret string `json:"secret"`
 }
+
+// IntegrationConfigAPIReqReplayBody is a body struct for IntegrationConfig's api request
+// +kubebuilder:object:generate=false
+type IntegrationConfigAPIReqReplayBody struct {
+	DeliveryID string `json:"delivery_id"`
+}
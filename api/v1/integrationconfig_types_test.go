@@ -17,7 +17,13 @@
 package v1
 
 import (
+	"crypto/rand"
+	"crypto/rsa"
 	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 	"time"
 
@@ -25,6 +31,7 @@ import (
 	tektonv1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
 	"github.com/tmax-cloud/cicd-operator/internal/configs"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
@@ -46,9 +53,27 @@ func TestIntegrationConfig_GetToken(t *testing.T) {
 		},
 	}
 
-	cli := fake.NewClientBuilder().WithScheme(s).WithObjects(secret1).Build()
+	privateKey, err := rsa.GenerateKey(rand.Reader, 1024)
+	require.NoError(t, err)
+	appSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "app-secret",
+			Namespace: "test-ns",
+		},
+		Data: map[string][]byte{
+			"private-key": pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(privateKey)}),
+		},
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"token": "ghs_test", "expires_at": "2099-01-01T00:00:00Z"}`))
+	}))
+	defer srv.Close()
+
+	cli := fake.NewClientBuilder().WithScheme(s).WithObjects(secret1, appSecret).Build()
 
 	tc := map[string]struct {
+		gitType  GitType
 		gitToken *GitToken
 
 		errorOccurs   bool
@@ -106,6 +131,43 @@ func TestIntegrationConfig_GetToken(t *testing.T) {
 			errorOccurs:  true,
 			errorMessage: "token secret/key secret1/token1 not valid",
 		},
+		"githubApp": {
+			gitType: GitTypeGitHub,
+			gitToken: &GitToken{
+				GitHubApp: &GitHubAppToken{
+					AppID:          1,
+					InstallationID: 2,
+					PrivateKey: corev1.SecretKeySelector{
+						LocalObjectReference: corev1.LocalObjectReference{Name: "app-secret"},
+						Key:                  "private-key",
+					},
+				},
+			},
+			expectedToken: "ghs_test",
+		},
+		"githubAppNotGitHub": {
+			gitType: GitTypeGitLab,
+			gitToken: &GitToken{
+				GitHubApp: &GitHubAppToken{AppID: 1, InstallationID: 2},
+			},
+			errorOccurs:  true,
+			errorMessage: "githubApp token is only available for github type",
+		},
+		"githubAppNoSecretKey": {
+			gitType: GitTypeGitHub,
+			gitToken: &GitToken{
+				GitHubApp: &GitHubAppToken{
+					AppID:          1,
+					InstallationID: 2,
+					PrivateKey: corev1.SecretKeySelector{
+						LocalObjectReference: corev1.LocalObjectReference{Name: "app-secret"},
+						Key:                  "key",
+					},
+				},
+			},
+			errorOccurs:  true,
+			errorMessage: "private key secret/key app-secret/key not valid",
+		},
 	}
 
 	for name, c := range tc {
@@ -116,7 +178,7 @@ func TestIntegrationConfig_GetToken(t *testing.T) {
 					Namespace: "test-ns",
 				},
 				Spec: IntegrationConfigSpec{
-					Git: GitConfig{Token: c.gitToken},
+					Git: GitConfig{Type: c.gitType, APIUrl: srv.URL, Token: c.gitToken},
 				},
 			}
 			tok, err := ic.GetToken(cli)
@@ -142,6 +204,32 @@ func TestIntegrationConfig_GetWebhookServerAddress(t *testing.T) {
 	require.Equal(t, "http://test.host.com/webhook/test-ns/test-ic", ic.GetWebhookServerAddress())
 }
 
+func TestIntegrationConfig_RotateSecret(t *testing.T) {
+	ic := &IntegrationConfig{
+		Status: IntegrationConfigStatus{
+			Secrets: "old-secret",
+			Conditions: []metav1.Condition{
+				{Type: IntegrationConfigConditionWebhookRegistered, Status: metav1.ConditionTrue, Reason: "Registered"},
+			},
+		},
+	}
+	require.Equal(t, []string{"old-secret"}, ic.GetWebhookSecrets())
+
+	ic.RotateSecret("new-secret")
+	require.Equal(t, []string{"new-secret", "old-secret"}, ic.GetWebhookSecrets())
+	require.NotNil(t, ic.Status.SecretRotationTime)
+
+	rotated := meta.FindStatusCondition(ic.Status.Conditions, IntegrationConfigConditionSecretRotated)
+	require.NotNil(t, rotated)
+	require.Equal(t, metav1.ConditionFalse, rotated.Status)
+	require.Equal(t, IntegrationConfigConditionReasonRotating, rotated.Reason)
+
+	// Webhook is to be registered again
+	registered := meta.FindStatusCondition(ic.Status.Conditions, IntegrationConfigConditionWebhookRegistered)
+	require.Equal(t, metav1.ConditionFalse, registered.Status)
+	require.Equal(t, IntegrationConfigConditionReasonRotating, registered.Reason)
+}
+
 func TestGetServiceAccountName(t *testing.T) {
 	require.Equal(t, "test-cfg-sa", GetServiceAccountName("test-cfg"))
 }
@@ -319,3 +407,63 @@ func TestIntegrationConfig_GetTLSConfig(t *testing.T) {
 		})
 	}
 }
+
+func TestIntegrationConfig_ValidateWhen(t *testing.T) {
+	tc := map[string]struct {
+		when       *JobWhen
+		preSubmit  Jobs
+		postSubmit Jobs
+
+		errorOccurs  bool
+		errorMessage string
+	}{
+		"valid": {
+			when:       &JobWhen{Branch: []string{"master", "release-.*"}},
+			preSubmit:  Jobs{{Container: corev1.Container{Name: "test"}, When: &JobWhen{SkipBranch: []string{"feat/.*"}}}},
+			postSubmit: Jobs{{Container: corev1.Container{Name: "release"}, When: &JobWhen{Tag: []string{`v[0-9]+\..*`}}}},
+		},
+		"noWhen": {
+			preSubmit: Jobs{{Container: corev1.Container{Name: "test"}}},
+		},
+		"invalid": {
+			when:         &JobWhen{Branch: []string{"release-["}},
+			preSubmit:    Jobs{{Container: corev1.Container{Name: "test"}, When: &JobWhen{SkipBranch: []string{"(feat", "fix"}}}},
+			postSubmit:   Jobs{{Container: corev1.Container{Name: "release"}, When: &JobWhen{Tag: []string{"v*+"}}}},
+			errorOccurs:  true,
+			errorMessage: "postSubmit job release: invalid regular expressions \"v*+\"; preSubmit job test: invalid regular expressions \"(feat\"; when: invalid regular expressions \"release-[\"",
+		},
+	}
+
+	for name, c := range tc {
+		t.Run(name, func(t *testing.T) {
+			ic := &IntegrationConfig{
+				Spec: IntegrationConfigSpec{
+					When: c.when,
+					Jobs: IntegrationConfigJobs{PreSubmit: c.preSubmit, PostSubmit: c.postSubmit},
+				},
+			}
+			err := ic.ValidateWhen()
+			if c.errorOccurs {
+				require.Error(t, err)
+				require.Equal(t, c.errorMessage, err.Error())
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestIntegrationConfig_GetPriority(t *testing.T) {
+	preSubmit := 100
+	periodic := -10
+
+	ic := &IntegrationConfig{}
+	require.Equal(t, DefaultPriorityPreSubmit, ic.GetPriority(JobTypePreSubmit))
+	require.Equal(t, DefaultPriorityPostSubmit, ic.GetPriority(JobTypePostSubmit))
+	require.Equal(t, DefaultPriorityPeriodic, ic.GetPriority(JobTypePeriodic))
+
+	ic.Spec.IJManageSpec.Priority = &IntegrationJobPriority{PreSubmit: &preSubmit, Periodic: &periodic}
+	require.Equal(t, 100, ic.GetPriority(JobTypePreSubmit))
+	require.Equal(t, DefaultPriorityPostSubmit, ic.GetPriority(JobTypePostSubmit))
+	require.Equal(t, -10, ic.GetPriority(JobTypePeriodic))
+}
@@ -17,7 +17,9 @@
 package v1
 
 import (
+	"bytes"
 	"fmt"
+	"text/template"
 
 	"github.com/tektoncd/pipeline/pkg/apis/pipeline/pod"
 	tektonv1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
@@ -34,6 +36,8 @@ const (
 	IntegrationJobStateRunning   = IntegrationJobState("Running")
 	IntegrationJobStateCompleted = IntegrationJobState("Completed")
 	IntegrationJobStateFailed    = IntegrationJobState("Failed")
+	IntegrationJobStateCanceled  = IntegrationJobState("Canceled")
+	IntegrationJobStateTimedOut  = IntegrationJobState("TimedOut")
 )
 
 // IntegrationJobSpec defines the desired state of IntegrationJob
@@ -59,8 +63,14 @@ type IntegrationJobSpec struct {
 	// Timeout for pending status garbage collection
 	Timeout *metav1.Duration `json:"timeout,omitempty"`
 
+	// JobTimeout is the timeout of the whole jobs (i.e., the PipelineRun), once it starts running
+	JobTimeout *metav1.Duration `json:"jobTimeout,omitempty"`
+
 	// ParamConfig specifies parameter
 	ParamConfig *ParameterConfig `json:"paramConfig,omitempty"`
+
+	// Priority of the IntegrationJob. Pending IntegrationJobs with higher priority are scheduled first
+	Priority int `json:"priority,omitempty"`
 }
 
 // IntegrationJobConfigRef refers to the IntegrationConfig
@@ -87,6 +97,10 @@ type IntegrationJobRefs struct {
 
 	// Pulls are array for pull request head commit
 	Pulls []IntegrationJobRefsPull `json:"pulls,omitempty"`
+
+	// MergedPull is the merged pull request, for the post-submit jobs triggered by the merge of the pull request.
+	// Base refers to the merge commit
+	MergedPull *IntegrationJobRefsPull `json:"mergedPull,omitempty"`
 }
 
 // IntegrationJobSender is a git user who triggered the IntegrationJob
@@ -180,3 +194,31 @@ func (i *IntegrationJob) GetReportServerAddress(jobName string) string {
 func (i *IntegrationJob) IsCompleted() bool {
 	return i.Status.CompletionTime != nil
 }
+
+// GetConcurrencyGroups renders the concurrency groups of the jobs, with the IntegrationJob's spec.
+// If the same group key is used by several jobs, cancel-in-progress policy takes precedence
+func (i *IntegrationJob) GetConcurrencyGroups() (map[string]ConcurrencyPolicy, error) {
+	groups := map[string]ConcurrencyPolicy{}
+	for _, j := range i.Spec.Jobs {
+		if j.ConcurrencyGroup == "" {
+			continue
+		}
+		tmpl, err := template.New("").Parse(j.ConcurrencyGroup)
+		if err != nil {
+			return nil, fmt.Errorf("job %s has an invalid concurrency group: %s", j.Name, err.Error())
+		}
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, i.Spec); err != nil {
+			return nil, fmt.Errorf("cannot render the concurrency group of job %s: %s", j.Name, err.Error())
+		}
+
+		policy := j.ConcurrencyPolicy
+		if policy == "" {
+			policy = ConcurrencyPolicyQueue
+		}
+		if groups[buf.String()] != ConcurrencyPolicyCancelInProgress {
+			groups[buf.String()] = policy
+		}
+	}
+	return groups, nil
+}
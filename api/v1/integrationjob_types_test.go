@@ -22,6 +22,7 @@ import (
 
 	"github.com/stretchr/testify/require"
 	"github.com/tmax-cloud/cicd-operator/internal/configs"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -85,3 +86,62 @@ func TestIntegrationJob_IsCompleted(t *testing.T) {
 		})
 	}
 }
+
+func TestIntegrationJob_GetConcurrencyGroups(t *testing.T) {
+	tc := map[string]struct {
+		jobs Jobs
+
+		errorOccurs    bool
+		errorMessage   string
+		expectedGroups map[string]ConcurrencyPolicy
+	}{
+		"noGroup": {
+			jobs:           Jobs{{Container: corev1.Container{Name: "test"}}},
+			expectedGroups: map[string]ConcurrencyPolicy{},
+		},
+		"template": {
+			jobs: Jobs{
+				{Container: corev1.Container{Name: "test"}},
+				{Container: corev1.Container{Name: "deploy"}, ConcurrencyGroup: "deploy-{{.Refs.Base.Ref.GetBranch}}"},
+				{Container: corev1.Container{Name: "notify"}, ConcurrencyGroup: "notify", ConcurrencyPolicy: ConcurrencyPolicyCancelInProgress},
+			},
+			expectedGroups: map[string]ConcurrencyPolicy{
+				"deploy-master": ConcurrencyPolicyQueue,
+				"notify":        ConcurrencyPolicyCancelInProgress,
+			},
+		},
+		"sameGroup": {
+			jobs: Jobs{
+				{Container: corev1.Container{Name: "deploy-1"}, ConcurrencyGroup: "deploy", ConcurrencyPolicy: ConcurrencyPolicyCancelInProgress},
+				{Container: corev1.Container{Name: "deploy-2"}, ConcurrencyGroup: "deploy"},
+			},
+			expectedGroups: map[string]ConcurrencyPolicy{"deploy": ConcurrencyPolicyCancelInProgress},
+		},
+		"renderError": {
+			jobs: Jobs{
+				{Container: corev1.Container{Name: "deploy"}, ConcurrencyGroup: "deploy-{{.Unknown}}"},
+			},
+			errorOccurs:  true,
+			errorMessage: "cannot render the concurrency group of job deploy",
+		},
+	}
+
+	for name, c := range tc {
+		t.Run(name, func(t *testing.T) {
+			ij := &IntegrationJob{
+				Spec: IntegrationJobSpec{
+					Jobs: c.jobs,
+					Refs: IntegrationJobRefs{Base: IntegrationJobRefsBase{Ref: "refs/heads/master"}},
+				},
+			}
+			groups, err := ij.GetConcurrencyGroups()
+			if c.errorOccurs {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), c.errorMessage)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, c.expectedGroups, groups)
+		})
+	}
+}
@@ -26,6 +26,7 @@ const (
 	JobLabelID          = JobLabelPrefix + "integration-id"
 	JobLabelRepository  = JobLabelPrefix + "repository"
 	JobLabelPullRequest = JobLabelPrefix + "pull-request"
+	JobLabelEvent       = JobLabelPrefix + "event"
 
 	RunLabelJob            = JobLabelPrefix + "integration-job"
 	RunLabelJobID          = JobLabelPrefix + "integration-job-id"
@@ -34,3 +35,13 @@ const (
 	RunLabelPullRequestSha = JobLabelPrefix + "pull-request-sha"
 	RunLabelSender         = JobLabelPrefix + "sender"
 )
+
+// Annotations for Namespaces
+const (
+	// NamespaceAnnotationMaxPipelineRun is the max number of PipelineRuns running simultaneously in the namespace.
+	// It overrides the maxNamespacePipelineRun operator config
+	NamespaceAnnotationMaxPipelineRun = JobLabelPrefix + "max-pipeline-run"
+
+	// NamespaceAnnotationSchedulingWeight is a weight of the namespace, for the fair-share scheduling strategy
+	NamespaceAnnotationSchedulingWeight = JobLabelPrefix + "scheduling-weight"
+)
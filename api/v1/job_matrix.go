@@ -0,0 +1,235 @@
+/*
+ Copyright 2021 The CI/CD Operator Authors
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package v1
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// MatrixEnvPrefix is a prefix of the env. variables, holding the axis values of the expanded matrix job
+const MatrixEnvPrefix = "MATRIX_"
+
+var (
+	matrixAxisPattern  = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+	matrixValueInvalid = regexp.MustCompile(`[^a-z0-9]+`)
+)
+
+// JobMatrix fans out a job over the combinations of the axis values
+type JobMatrix struct {
+	// Axes is a map of the axis name to its values. A job is expanded for each combination of the values
+	Axes map[string][]string `json:"axes,omitempty"`
+
+	// Include is a list of the extra combinations to be expanded
+	Include []map[string]string `json:"include,omitempty"`
+
+	// Exclude is a list of the combinations not to be expanded. A combination is excluded if it matches all the
+	// axis values of any entry
+	Exclude []map[string]string `json:"exclude,omitempty"`
+}
+
+// axisNames returns the sorted names of the axes, including the ones only used by the include entries
+func (m *JobMatrix) axisNames() []string {
+	names := map[string]struct{}{}
+	for axis := range m.Axes {
+		names[axis] = struct{}{}
+	}
+	for _, inc := range m.Include {
+		for axis := range inc {
+			names[axis] = struct{}{}
+		}
+	}
+	var sorted []string
+	for axis := range names {
+		sorted = append(sorted, axis)
+	}
+	sort.Strings(sorted)
+	return sorted
+}
+
+// Combinations returns the combinations of the axis values, in a deterministic order.
+// Axes are iterated in the order of their names, and the values in the order they are specified
+func (m *JobMatrix) Combinations() []map[string]string {
+	var axes []string
+	for axis := range m.Axes {
+		axes = append(axes, axis)
+	}
+	sort.Strings(axes)
+
+	var combinations []map[string]string
+	if len(axes) > 0 {
+		combinations = []map[string]string{{}}
+	}
+	for _, axis := range axes {
+		var next []map[string]string
+		for _, c := range combinations {
+			for _, v := range m.Axes[axis] {
+				n := map[string]string{axis: v}
+				for k, cv := range c {
+					n[k] = cv
+				}
+				next = append(next, n)
+			}
+		}
+		combinations = next
+	}
+
+	var result []map[string]string
+	for _, c := range combinations {
+		if !m.excluded(c) {
+			result = append(result, c)
+		}
+	}
+	for _, inc := range m.Include {
+		if !containsCombination(result, inc) {
+			result = append(result, inc)
+		}
+	}
+	return result
+}
+
+func (m *JobMatrix) excluded(c map[string]string) bool {
+	for _, ex := range m.Exclude {
+		if len(ex) > 0 && matchesCombination(c, ex) {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate checks if the axis names are valid and the matrix is expanded at least once
+func (m *JobMatrix) Validate() error {
+	for _, axis := range m.axisNames() {
+		if !matrixAxisPattern.MatchString(axis) {
+			return fmt.Errorf("invalid matrix axis name %q", axis)
+		}
+	}
+	for axis, values := range m.Axes {
+		if len(values) == 0 {
+			return fmt.Errorf("matrix axis %s has no values", axis)
+		}
+	}
+	if len(m.Combinations()) == 0 {
+		return fmt.Errorf("matrix has no combinations")
+	}
+	return nil
+}
+
+// MatrixJobName is a name of the job expanded from the matrix job, suffixed with the axis values in the order of
+// the axis names
+func MatrixJobName(name string, axes []string, combination map[string]string) string {
+	suffix := []string{name}
+	for _, axis := range axes {
+		v, ok := combination[axis]
+		if !ok {
+			continue
+		}
+		v = strings.Trim(matrixValueInvalid.ReplaceAllString(strings.ToLower(v), "-"), "-")
+		if v == "" {
+			v = "x"
+		}
+		suffix = append(suffix, v)
+	}
+	return strings.Join(suffix, "-")
+}
+
+// ExpandMatrix expands the matrix jobs into a job per combination, keeping the other jobs as they are.
+// The axis values are injected as env. variables (MATRIX_<AXIS>) and substituted for $(matrix.<axis>) in the
+// tekton task's params. Jobs running after a matrix job run after all of its expansions
+func (j *Jobs) ExpandMatrix() Jobs {
+	expandedNames := map[string][]string{}
+	var expanded Jobs
+	for _, job := range *j {
+		if job.Matrix == nil {
+			expanded = append(expanded, *job.DeepCopy())
+			continue
+		}
+		axes := job.Matrix.axisNames()
+		for _, c := range job.Matrix.Combinations() {
+			e := job.DeepCopy()
+			e.Matrix = nil
+			e.Name = MatrixJobName(job.Name, axes, c)
+			injectMatrix(e, axes, c)
+			expanded = append(expanded, *e)
+			expandedNames[job.Name] = append(expandedNames[job.Name], e.Name)
+		}
+	}
+	if len(expandedNames) == 0 {
+		return expanded
+	}
+
+	for i := range expanded {
+		var after []string
+		for _, a := range expanded[i].After {
+			if names, ok := expandedNames[a]; ok {
+				after = append(after, names...)
+			} else {
+				after = append(after, a)
+			}
+		}
+		expanded[i].After = after
+	}
+	return expanded
+}
+
+func injectMatrix(job *Job, axes []string, combination map[string]string) {
+	var envs []corev1.EnvVar
+	for _, axis := range axes {
+		v, ok := combination[axis]
+		if !ok {
+			continue
+		}
+		envs = append(envs, corev1.EnvVar{Name: MatrixEnvPrefix + strings.ToUpper(axis), Value: v})
+	}
+	job.Env = append(envs, job.Env...)
+
+	if job.TektonTask == nil {
+		return
+	}
+	for i := range job.TektonTask.Params {
+		p := &job.TektonTask.Params[i]
+		for axis, v := range combination {
+			placeholder := fmt.Sprintf("$(matrix.%s)", axis)
+			p.StringVal = strings.ReplaceAll(p.StringVal, placeholder, v)
+			for k := range p.ArrayVal {
+				p.ArrayVal[k] = strings.ReplaceAll(p.ArrayVal[k], placeholder, v)
+			}
+		}
+	}
+}
+
+func matchesCombination(c, sub map[string]string) bool {
+	for k, v := range sub {
+		if c[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+func containsCombination(combinations []map[string]string, c map[string]string) bool {
+	for _, e := range combinations {
+		if len(e) == len(c) && matchesCombination(e, c) {
+			return true
+		}
+	}
+	return false
+}
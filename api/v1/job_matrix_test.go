@@ -0,0 +1,105 @@
+/*
+ Copyright 2021 The CI/CD Operator Authors
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package v1
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestJobMatrix_Combinations(t *testing.T) {
+	tc := map[string]struct {
+		matrix *JobMatrix
+
+		expectedCombinations []map[string]string
+	}{
+		"normal": {
+			matrix: &JobMatrix{Axes: map[string][]string{"go": {"1.16", "1.17"}, "arch": {"amd64", "arm64"}}},
+			expectedCombinations: []map[string]string{
+				{"arch": "amd64", "go": "1.16"},
+				{"arch": "amd64", "go": "1.17"},
+				{"arch": "arm64", "go": "1.16"},
+				{"arch": "arm64", "go": "1.17"},
+			},
+		},
+		"includeExclude": {
+			matrix: &JobMatrix{
+				Axes:    map[string][]string{"go": {"1.16", "1.17"}, "arch": {"amd64", "arm64"}},
+				Include: []map[string]string{{"arch": "amd64", "go": "1.18"}, {"arch": "amd64", "go": "1.16"}},
+				Exclude: []map[string]string{{"arch": "arm64"}},
+			},
+			expectedCombinations: []map[string]string{
+				{"arch": "amd64", "go": "1.16"},
+				{"arch": "amd64", "go": "1.17"},
+				{"arch": "amd64", "go": "1.18"},
+			},
+		},
+		"includeOnly": {
+			matrix:               &JobMatrix{Include: []map[string]string{{"go": "1.17"}}},
+			expectedCombinations: []map[string]string{{"go": "1.17"}},
+		},
+	}
+
+	for name, c := range tc {
+		t.Run(name, func(t *testing.T) {
+			require.Equal(t, c.expectedCombinations, c.matrix.Combinations())
+		})
+	}
+}
+
+func TestJobs_ExpandMatrix(t *testing.T) {
+	jobs := Jobs{
+		{Container: corev1.Container{Name: "lint"}},
+		{
+			Container: corev1.Container{Name: "test", Env: []corev1.EnvVar{{Name: "FOO", Value: "bar"}}},
+			After:     []string{"lint"},
+			Matrix: &JobMatrix{
+				Axes:    map[string][]string{"go": {"1.16", "1.17"}, "arch": {"amd64", "arm64"}},
+				Exclude: []map[string]string{{"arch": "arm64", "go": "1.16"}},
+			},
+		},
+		{
+			Container: corev1.Container{Name: "scan"},
+			TektonTask: &TektonTask{
+				Params: []ParameterValue{{Name: "image", StringVal: "golang:$(matrix.go)"}, {Name: "args", ArrayVal: []string{"--go=$(matrix.go)"}}},
+			},
+			Matrix: &JobMatrix{Axes: map[string][]string{"go": {"1.17"}}},
+		},
+		{Container: corev1.Container{Name: "deploy"}, After: []string{"test", "scan"}},
+	}
+
+	expanded := jobs.ExpandMatrix()
+
+	var names []string
+	for _, j := range expanded {
+		names = append(names, j.Name)
+		require.Nil(t, j.Matrix)
+	}
+	require.Equal(t, []string{"lint", "test-amd64-1-16", "test-amd64-1-17", "test-arm64-1-17", "scan-1-17", "deploy"}, names)
+
+	require.Equal(t, []string{"lint"}, expanded[1].After)
+	require.Equal(t, []corev1.EnvVar{{Name: "MATRIX_ARCH", Value: "amd64"}, {Name: "MATRIX_GO", Value: "1.16"}, {Name: "FOO", Value: "bar"}}, expanded[1].Env)
+	require.Equal(t, "golang:1.17", expanded[4].TektonTask.Params[0].StringVal)
+	require.Equal(t, []string{"--go=1.17"}, expanded[4].TektonTask.Params[1].ArrayVal)
+	require.Equal(t, []string{"test-amd64-1-16", "test-amd64-1-17", "test-arm64-1-17", "scan-1-17"}, expanded[5].After)
+
+	// Original jobs are not modified
+	require.Equal(t, "golang:$(matrix.go)", jobs[2].TektonTask.Params[0].StringVal)
+	require.Len(t, jobs[1].Env, 1)
+}
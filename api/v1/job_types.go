@@ -18,6 +18,9 @@ package v1
 
 import (
 	"fmt"
+	"regexp"
+	"strings"
+	"text/template"
 
 	tektonv1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
 	"github.com/tmax-cloud/cicd-operator/pkg/structs"
@@ -48,6 +51,40 @@ const (
 	CommitStatusStatePending = CommitStatusState("pending")
 )
 
+// ConcurrencyPolicy is a policy for a job whose concurrency group is held by another IntegrationJob
+type ConcurrencyPolicy string
+
+// Concurrency policies
+const (
+	// ConcurrencyPolicyQueue waits until the other IntegrationJob is finished
+	ConcurrencyPolicyQueue = ConcurrencyPolicy("queue")
+	// ConcurrencyPolicyCancelInProgress cancels the other IntegrationJob
+	ConcurrencyPolicyCancelInProgress = ConcurrencyPolicy("cancel-in-progress")
+)
+
+// JobEvent is a kind of the git event, which triggers the job
+// +kubebuilder:validation:Enum=pull_request;draft_pull_request;push;tag;release;merged
+type JobEvent string
+
+// Job events
+const (
+	// JobEventPullRequest is a pull request event of a non-draft pull request. It triggers the pre-submit jobs
+	JobEventPullRequest = JobEvent("pull_request")
+	// JobEventDraftPullRequest is a pull request event of a draft pull request. It triggers the pre-submit jobs
+	JobEventDraftPullRequest = JobEvent("draft_pull_request")
+	// JobEventPush is a push event to a branch. It triggers the post-submit jobs
+	JobEventPush = JobEvent("push")
+	// JobEventTag is a push event of a tag. It triggers the post-submit jobs
+	JobEventTag = JobEvent("tag")
+	// JobEventRelease is a release publish event. It triggers the post-submit jobs
+	JobEventRelease = JobEvent("release")
+	// JobEventMerged is a merge event of a pull request. It triggers the post-submit jobs, for the merge commit
+	JobEventMerged = JobEvent("merged")
+)
+
+// DefaultJobEvents are the events triggering the jobs, if no events are specified for the jobs
+var DefaultJobEvents = []JobEvent{JobEventPullRequest, JobEventDraftPullRequest, JobEventPush, JobEventTag}
+
 // Job is a specification of the job to be executed for specific events
 // Same level of task of tekton
 type Job struct {
@@ -82,6 +119,48 @@ type Job struct {
 
 	// Results emitted by task, which also can be used as TektonWhen input value.
 	Results []tektonv1beta1.TaskResult `json:"results,omitempty"`
+
+	// ConcurrencyGroup is a go template of a group key, rendered with the IntegrationJob's spec
+	// (e.g., deploy-{{.Refs.Base.Ref}}). IntegrationJobs holding the same group key never run at the same time
+	ConcurrencyGroup string `json:"concurrencyGroup,omitempty"`
+
+	// ConcurrencyPolicy is a policy when the concurrency group is held by another IntegrationJob (default is queue)
+	// +kubebuilder:validation:Enum=queue;cancel-in-progress
+	ConcurrencyPolicy ConcurrencyPolicy `json:"concurrencyPolicy,omitempty"`
+
+	// Matrix fans out the job over the combinations of the axis values. Each combination runs as a separate job,
+	// named with the axis values as a suffix (e.g., test-1-17-amd64)
+	Matrix *JobMatrix `json:"matrix,omitempty"`
+
+	// Retries retries the failed job automatically. Approval and notification jobs cannot be retried
+	Retries *JobRetries `json:"retries,omitempty"`
+
+	// Timeout is the timeout of the job. Approval and notification jobs cannot have the timeout
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+}
+
+// JobRetries describes how many times the failed job is retried
+type JobRetries struct {
+	// Count is the maximum number of the retries
+	// +kubebuilder:validation:Minimum=0
+	Count int `json:"count"`
+
+	// OnExitCodes are the exit codes of the failed step, for which the job is retried.
+	// The job is retried for any exit code if it is empty
+	OnExitCodes []int32 `json:"onExitCodes,omitempty"`
+}
+
+// Retriable checks if the job can be retried for the exit code
+func (r *JobRetries) Retriable(exitCode int32) bool {
+	if len(r.OnExitCodes) == 0 {
+		return true
+	}
+	for _, c := range r.OnExitCodes {
+		if c == exitCode {
+			return true
+		}
+	}
+	return false
 }
 
 // Periodic runs on a time-basis, unrelated to git changes.
@@ -132,13 +211,43 @@ type JobApproval struct {
 }
 
 // JobWhen describes when the Job should be executed
-// All fields should be regular expressions
+// All fields (except for paths) should be regular expressions, which match the whole branch/tag name
 type JobWhen struct {
 	Branch     []string `json:"branch,omitempty"`
 	SkipBranch []string `json:"skipBranch,omitempty"`
 
 	Tag     []string `json:"tag,omitempty"`
 	SkipTag []string `json:"skipTag,omitempty"`
+
+	// Paths is a list of glob patterns of the changed files. The job runs only if any changed file matches them
+	Paths []string `json:"paths,omitempty"`
+	// SkipPaths is a list of glob patterns of the changed files. The job is skipped if all changed files match them
+	SkipPaths []string `json:"skipPaths,omitempty"`
+
+	// Events are the events triggering the job. Pre-submit jobs run for pull_request and draft_pull_request, and
+	// post-submit jobs run for push, tag, release and merged events. Default is all the events but release and merged
+	Events []JobEvent `json:"events,omitempty"`
+}
+
+// Validate checks if all the branch/tag patterns are valid regular expressions
+func (w *JobWhen) Validate() error {
+	var invalid []string
+	for _, patterns := range [][]string{w.Branch, w.SkipBranch, w.Tag, w.SkipTag} {
+		for _, p := range patterns {
+			if _, err := CompileWhenPattern(p); err != nil {
+				invalid = append(invalid, fmt.Sprintf("%q", p))
+			}
+		}
+	}
+	if len(invalid) > 0 {
+		return fmt.Errorf("invalid regular expressions %s", strings.Join(invalid, ", "))
+	}
+	return nil
+}
+
+// CompileWhenPattern compiles the branch/tag pattern of JobWhen, which should match the whole name
+func CompileWhenPattern(pattern string) (*regexp.Regexp, error) {
+	return regexp.Compile("^(?:" + pattern + ")$")
 }
 
 // JobStatus is a current status for each job
@@ -163,10 +272,22 @@ type JobStatus struct {
 	// PodName is a name of pod where the job is running
 	PodName string `json:"podName,omitempty"`
 
+	// Reason is a reason of the state. It is TimedOut if the job is stopped by the job's or IntegrationJob's timeout
+	Reason string `json:"reason,omitempty"`
+
+	// Attempt is the number of the current attempt, starting from 1. It is set only if the job is retried
+	Attempt int `json:"attempt,omitempty"`
+
+	// AttemptPodNames are the names of the pods of each attempt, in order. It is set only if the job is retried
+	AttemptPodNames []string `json:"attemptPodNames,omitempty"`
+
 	// Containers is status list for each step in the job
 	Containers []tektonv1beta1.StepState `json:"containers,omitempty"`
 }
 
+// JobReasonTimedOut is a reason of the job stopped by its timeout
+const JobReasonTimedOut = "TimedOut"
+
 // Equals checks if i is equal to j
 func (j *JobStatus) Equals(i *JobStatus) bool {
 	return j.State == i.State &&
@@ -195,5 +316,54 @@ func (j *Jobs) GetGraph() (structs.Graph, error) {
 	return graph, nil
 }
 
+// Validate checks if the jobs have unique names and form a valid dependency graph
+func (j *Jobs) Validate() error {
+	names := map[string]struct{}{}
+	for _, job := range *j {
+		if job.Name == "" {
+			return fmt.Errorf("job name is empty")
+		}
+		if _, exist := names[job.Name]; exist {
+			return fmt.Errorf("job %s is duplicated", job.Name)
+		}
+		if _, err := template.New("").Parse(job.ConcurrencyGroup); err != nil {
+			return fmt.Errorf("job %s has an invalid concurrency group: %s", job.Name, err.Error())
+		}
+		customTask := job.Approval != nil || job.Email != nil || job.Slack != nil || job.Webhook != nil
+		if job.Retries != nil && customTask {
+			return fmt.Errorf("job %s cannot be retried", job.Name)
+		}
+		if job.Timeout != nil && customTask {
+			return fmt.Errorf("job %s cannot have a timeout", job.Name)
+		}
+		if job.Matrix != nil {
+			if err := job.Matrix.Validate(); err != nil {
+				return fmt.Errorf("job %s has an invalid matrix: %s", job.Name, err.Error())
+			}
+		}
+		names[job.Name] = struct{}{}
+	}
+	for _, job := range *j {
+		for _, after := range job.After {
+			if _, exist := names[after]; !exist {
+				return fmt.Errorf("job %s runs after unknown job %s", job.Name, after)
+			}
+		}
+	}
+	if _, err := j.GetGraph(); err != nil {
+		return err
+	}
+
+	// Expanded matrix jobs should not collide with the other jobs
+	expandedNames := map[string]struct{}{}
+	for _, job := range j.ExpandMatrix() {
+		if _, exist := expandedNames[job.Name]; exist {
+			return fmt.Errorf("job %s is duplicated after the matrix is expanded", job.Name)
+		}
+		expandedNames[job.Name] = struct{}{}
+	}
+	return nil
+}
+
 // Periodics is an array of PeriodicJob
 type Periodics []Periodic
@@ -137,3 +137,115 @@ func TestJobs_GetGraph(t *testing.T) {
 		})
 	}
 }
+
+func TestJobs_Validate(t *testing.T) {
+	tc := map[string]struct {
+		jobs Jobs
+
+		errorOccurs  bool
+		errorMessage string
+	}{
+		"normal": {
+			jobs: Jobs{
+				{Container: corev1.Container{Name: "job-1"}},
+				{Container: corev1.Container{Name: "job-2"}, After: []string{"job-1"}},
+			},
+		},
+		"emptyName": {
+			jobs: Jobs{
+				{Container: corev1.Container{Name: ""}},
+			},
+			errorOccurs:  true,
+			errorMessage: "job name is empty",
+		},
+		"duplicated": {
+			jobs: Jobs{
+				{Container: corev1.Container{Name: "job-1"}},
+				{Container: corev1.Container{Name: "job-1"}},
+			},
+			errorOccurs:  true,
+			errorMessage: "job job-1 is duplicated",
+		},
+		"unknownAfter": {
+			jobs: Jobs{
+				{Container: corev1.Container{Name: "job-1"}, After: []string{"job-0"}},
+			},
+			errorOccurs:  true,
+			errorMessage: "job job-1 runs after unknown job job-0",
+		},
+		"cyclic": {
+			jobs: Jobs{
+				{Container: corev1.Container{Name: "job-1"}, After: []string{"job-2"}},
+				{Container: corev1.Container{Name: "job-2"}, After: []string{"job-1"}},
+			},
+			errorOccurs:  true,
+			errorMessage: "job graph is cyclic",
+		},
+		"invalidConcurrencyGroup": {
+			jobs: Jobs{
+				{Container: corev1.Container{Name: "job-1"}, ConcurrencyGroup: "deploy-{{.Refs.Base.Ref"},
+			},
+			errorOccurs:  true,
+			errorMessage: "job job-1 has an invalid concurrency group: template: :1: unclosed action",
+		},
+		"retries": {
+			jobs: Jobs{
+				{Container: corev1.Container{Name: "job-1"}, Retries: &JobRetries{Count: 2}},
+			},
+		},
+		"retriedApproval": {
+			jobs: Jobs{
+				{Container: corev1.Container{Name: "job-1"}, Retries: &JobRetries{Count: 2}, Approval: &JobApproval{}},
+			},
+			errorOccurs:  true,
+			errorMessage: "job job-1 cannot be retried",
+		},
+		"timeoutNotification": {
+			jobs: Jobs{
+				{Container: corev1.Container{Name: "job-1"}, Timeout: &metav1.Duration{Duration: time.Minute}, NotificationMethods: NotificationMethods{Slack: &NotiSlack{}}},
+			},
+			errorOccurs:  true,
+			errorMessage: "job job-1 cannot have a timeout",
+		},
+		"matrix": {
+			jobs: Jobs{
+				{Container: corev1.Container{Name: "job-1"}, Matrix: &JobMatrix{Axes: map[string][]string{"go": {"1.16", "1.17"}}}},
+				{Container: corev1.Container{Name: "job-2"}, After: []string{"job-1"}},
+			},
+		},
+		"invalidMatrixAxis": {
+			jobs: Jobs{
+				{Container: corev1.Container{Name: "job-1"}, Matrix: &JobMatrix{Axes: map[string][]string{"go-version": {"1.17"}}}},
+			},
+			errorOccurs:  true,
+			errorMessage: "job job-1 has an invalid matrix: invalid matrix axis name \"go-version\"",
+		},
+		"emptyMatrix": {
+			jobs: Jobs{
+				{Container: corev1.Container{Name: "job-1"}, Matrix: &JobMatrix{Axes: map[string][]string{"go": {"1.17"}}, Exclude: []map[string]string{{"go": "1.17"}}}},
+			},
+			errorOccurs:  true,
+			errorMessage: "job job-1 has an invalid matrix: matrix has no combinations",
+		},
+		"matrixDuplicated": {
+			jobs: Jobs{
+				{Container: corev1.Container{Name: "job-1"}, Matrix: &JobMatrix{Axes: map[string][]string{"go": {"1.17"}}}},
+				{Container: corev1.Container{Name: "job-1-1-17"}},
+			},
+			errorOccurs:  true,
+			errorMessage: "job job-1-1-17 is duplicated after the matrix is expanded",
+		},
+	}
+
+	for name, c := range tc {
+		t.Run(name, func(t *testing.T) {
+			err := c.jobs.Validate()
+			if c.errorOccurs {
+				require.Error(t, err)
+				require.Equal(t, c.errorMessage, err.Error())
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
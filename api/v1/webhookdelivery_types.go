@@ -0,0 +1,132 @@
+/*
+ Copyright 2021 The CI/CD Operator Authors
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package v1
+
+import (
+	"time"
+
+	"github.com/tmax-cloud/cicd-operator/internal/configs"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// WebhookDeliveryKind is a kind string
+const WebhookDeliveryKind = "webhookdeliveries"
+
+// WebhookDeliveryLabelConfig is a label for the name of the IntegrationConfig which received the webhook
+const WebhookDeliveryLabelConfig = JobLabelConfig
+
+// WebhookDeliveryState is a state of the WebhookDelivery
+type WebhookDeliveryState string
+
+// WebhookDelivery states. A delivery without a state is queued and not handled yet
+const (
+	WebhookDeliveryStateRetrying  = WebhookDeliveryState("Retrying")
+	WebhookDeliveryStateSucceeded = WebhookDeliveryState("Succeeded")
+	WebhookDeliveryStateFailed    = WebhookDeliveryState("Failed")
+
+	// WebhookDeliveryStateDuplicated is a state of a redelivered webhook, which is ignored
+	WebhookDeliveryStateDuplicated = WebhookDeliveryState("Duplicated")
+)
+
+// WebhookDeliverySpec defines a webhook request received by the webhook server
+type WebhookDeliverySpec struct {
+	// IntegrationConfig is the name of the IntegrationConfig which received the webhook
+	IntegrationConfig string `json:"integrationConfig"`
+
+	// Header is the http header of the webhook request
+	Header map[string][]string `json:"header,omitempty"`
+
+	// Body is the body of the webhook request
+	Body string `json:"body,omitempty"`
+}
+
+// WebhookDeliveryStatus defines how the webhook is handled
+type WebhookDeliveryStatus struct {
+	// State is the state of the delivery. Failed deliveries are the dead letters, which can be replayed
+	State WebhookDeliveryState `json:"state,omitempty"`
+
+	// EventType is the event type of the parsed webhook. It is empty if the webhook is not parsed or is ignored
+	EventType string `json:"eventType,omitempty"`
+
+	// DeliveryID is the delivery ID of the parsed webhook, given by the git server
+	DeliveryID string `json:"deliveryID,omitempty"`
+
+	// Error is an error occurred while parsing the webhook
+	Error string `json:"error,omitempty"`
+
+	// Plugins are the results of the plugins which handled the webhook
+	Plugins []WebhookDeliveryPluginResult `json:"plugins,omitempty"`
+
+	// HandledTime is the last time the webhook is handled
+	HandledTime *metav1.Time `json:"handledTime,omitempty"`
+
+	// Attempts is the number of times the plugins are run for the webhook, including the retries
+	Attempts int `json:"attempts,omitempty"`
+
+	// Replays is the number of times the webhook is replayed
+	Replays int `json:"replays,omitempty"`
+}
+
+// WebhookDeliveryPluginResult is a result of a plugin which handled the webhook
+type WebhookDeliveryPluginResult struct {
+	// Name is the name of the plugin
+	Name string `json:"name"`
+
+	// Error is an error returned by the plugin
+	Error string `json:"error,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+
+// WebhookDelivery is the Schema for the webhookdeliveries API
+// +kubebuilder:printcolumn:name="Config",type="string",JSONPath=".spec.integrationConfig",description="IntegrationConfig which received the webhook"
+// +kubebuilder:printcolumn:name="State",type="string",JSONPath=".status.state",description="State of the delivery"
+// +kubebuilder:printcolumn:name="Event",type="string",JSONPath=".status.eventType",description="Event type of the webhook"
+// +kubebuilder:printcolumn:name="Error",type="string",JSONPath=".status.error",description="Error occurred while parsing the webhook"
+// +kubebuilder:printcolumn:name="Created",type="date",JSONPath=".metadata.creationTimestamp",description="Received time"
+type WebhookDelivery struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   WebhookDeliverySpec   `json:"spec"`
+	Status WebhookDeliveryStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// WebhookDeliveryList contains a list of WebhookDelivery
+type WebhookDeliveryList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []WebhookDelivery `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&WebhookDelivery{}, &WebhookDeliveryList{})
+}
+
+// Expired returns if the delivery is older than the TTL (webhookDeliveryTTL)
+func (w *WebhookDelivery) Expired(now time.Time) bool {
+	return w.CreationTimestamp.Add(time.Duration(configs.WebhookDeliveryTTL) * time.Hour).Before(now)
+}
+
+// Handled returns if the delivery is handled (succeeded, dead-lettered or ignored as a duplicate), so it should not be
+// queued again
+func (w *WebhookDeliveryStatus) Handled() bool {
+	return w.State == WebhookDeliveryStateSucceeded || w.State == WebhookDeliveryStateFailed || w.State == WebhookDeliveryStateDuplicated
+}
@@ -174,6 +174,22 @@ func (in *GitConfig) DeepCopy() *GitConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GitHubAppToken) DeepCopyInto(out *GitHubAppToken) {
+	*out = *in
+	in.PrivateKey.DeepCopyInto(&out.PrivateKey)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GitHubAppToken.
+func (in *GitHubAppToken) DeepCopy() *GitHubAppToken {
+	if in == nil {
+		return nil
+	}
+	out := new(GitHubAppToken)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *GitToken) DeepCopyInto(out *GitToken) {
 	*out = *in
@@ -182,6 +198,11 @@ func (in *GitToken) DeepCopyInto(out *GitToken) {
 		*out = new(GitTokenFrom)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.GitHubApp != nil {
+		in, out := &in.GitHubApp, &out.GitHubApp
+		*out = new(GitHubAppToken)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GitToken.
@@ -322,6 +343,11 @@ func (in *IntegrationConfigSpec) DeepCopyInto(out *IntegrationConfigSpec) {
 		}
 	}
 	in.Jobs.DeepCopyInto(&out.Jobs)
+	if in.JobsFrom != nil {
+		in, out := &in.JobsFrom, &out.JobsFrom
+		*out = new(JobsFrom)
+		**out = **in
+	}
 	if in.MergeConfig != nil {
 		in, out := &in.MergeConfig, &out.MergeConfig
 		*out = new(MergeConfig)
@@ -333,6 +359,11 @@ func (in *IntegrationConfigSpec) DeepCopyInto(out *IntegrationConfigSpec) {
 		(*in).DeepCopyInto(*out)
 	}
 	in.IJManageSpec.DeepCopyInto(&out.IJManageSpec)
+	if in.JobTimeout != nil {
+		in, out := &in.JobTimeout, &out.JobTimeout
+		*out = new(metav1.Duration)
+		**out = **in
+	}
 	if in.ParamConfig != nil {
 		in, out := &in.ParamConfig, &out.ParamConfig
 		*out = new(ParameterConfig)
@@ -375,6 +406,17 @@ func (in *IntegrationConfigStatus) DeepCopyInto(out *IntegrationConfigStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.SecretRotationTime != nil {
+		in, out := &in.SecretRotationTime, &out.SecretRotationTime
+		*out = (*in).DeepCopy()
+	}
+	if in.LastSeenRefs != nil {
+		in, out := &in.LastSeenRefs, &out.LastSeenRefs
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IntegrationConfigStatus.
@@ -469,6 +511,11 @@ func (in *IntegrationJobManageSpec) DeepCopyInto(out *IntegrationJobManageSpec)
 		*out = new(metav1.Duration)
 		**out = **in
 	}
+	if in.Priority != nil {
+		in, out := &in.Priority, &out.Priority
+		*out = new(IntegrationJobPriority)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IntegrationJobManageSpec.
@@ -481,6 +528,36 @@ func (in *IntegrationJobManageSpec) DeepCopy() *IntegrationJobManageSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IntegrationJobPriority) DeepCopyInto(out *IntegrationJobPriority) {
+	*out = *in
+	if in.PreSubmit != nil {
+		in, out := &in.PreSubmit, &out.PreSubmit
+		*out = new(int)
+		**out = **in
+	}
+	if in.PostSubmit != nil {
+		in, out := &in.PostSubmit, &out.PostSubmit
+		*out = new(int)
+		**out = **in
+	}
+	if in.Periodic != nil {
+		in, out := &in.Periodic, &out.Periodic
+		*out = new(int)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IntegrationJobPriority.
+func (in *IntegrationJobPriority) DeepCopy() *IntegrationJobPriority {
+	if in == nil {
+		return nil
+	}
+	out := new(IntegrationJobPriority)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *IntegrationJobRefs) DeepCopyInto(out *IntegrationJobRefs) {
 	*out = *in
@@ -495,6 +572,11 @@ func (in *IntegrationJobRefs) DeepCopyInto(out *IntegrationJobRefs) {
 		*out = make([]IntegrationJobRefsPull, len(*in))
 		copy(*out, *in)
 	}
+	if in.MergedPull != nil {
+		in, out := &in.MergedPull, &out.MergedPull
+		*out = new(IntegrationJobRefsPull)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IntegrationJobRefs.
@@ -597,6 +679,11 @@ func (in *IntegrationJobSpec) DeepCopyInto(out *IntegrationJobSpec) {
 		*out = new(metav1.Duration)
 		**out = **in
 	}
+	if in.JobTimeout != nil {
+		in, out := &in.JobTimeout, &out.JobTimeout
+		*out = new(metav1.Duration)
+		**out = **in
+	}
 	if in.ParamConfig != nil {
 		in, out := &in.ParamConfig, &out.ParamConfig
 		*out = new(ParameterConfig)
@@ -686,6 +773,21 @@ func (in *Job) DeepCopyInto(out *Job) {
 		*out = make([]v1beta1.TaskResult, len(*in))
 		copy(*out, *in)
 	}
+	if in.Matrix != nil {
+		in, out := &in.Matrix, &out.Matrix
+		*out = new(JobMatrix)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Retries != nil {
+		in, out := &in.Retries, &out.Retries
+		*out = new(JobRetries)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Timeout != nil {
+		in, out := &in.Timeout, &out.Timeout
+		*out = new(metav1.Duration)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Job.
@@ -723,6 +825,82 @@ func (in *JobApproval) DeepCopy() *JobApproval {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *JobMatrix) DeepCopyInto(out *JobMatrix) {
+	*out = *in
+	if in.Axes != nil {
+		in, out := &in.Axes, &out.Axes
+		*out = make(map[string][]string, len(*in))
+		for key, val := range *in {
+			var outVal []string
+			if val == nil {
+				(*out)[key] = nil
+			} else {
+				in, out := &val, &outVal
+				*out = make([]string, len(*in))
+				copy(*out, *in)
+			}
+			(*out)[key] = outVal
+		}
+	}
+	if in.Include != nil {
+		in, out := &in.Include, &out.Include
+		*out = make([]map[string]string, len(*in))
+		for i := range *in {
+			if (*in)[i] != nil {
+				in, out := &(*in)[i], &(*out)[i]
+				*out = make(map[string]string, len(*in))
+				for key, val := range *in {
+					(*out)[key] = val
+				}
+			}
+		}
+	}
+	if in.Exclude != nil {
+		in, out := &in.Exclude, &out.Exclude
+		*out = make([]map[string]string, len(*in))
+		for i := range *in {
+			if (*in)[i] != nil {
+				in, out := &(*in)[i], &(*out)[i]
+				*out = make(map[string]string, len(*in))
+				for key, val := range *in {
+					(*out)[key] = val
+				}
+			}
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new JobMatrix.
+func (in *JobMatrix) DeepCopy() *JobMatrix {
+	if in == nil {
+		return nil
+	}
+	out := new(JobMatrix)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *JobRetries) DeepCopyInto(out *JobRetries) {
+	*out = *in
+	if in.OnExitCodes != nil {
+		in, out := &in.OnExitCodes, &out.OnExitCodes
+		*out = make([]int32, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new JobRetries.
+func (in *JobRetries) DeepCopy() *JobRetries {
+	if in == nil {
+		return nil
+	}
+	out := new(JobRetries)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *JobStatus) DeepCopyInto(out *JobStatus) {
 	*out = *in
@@ -734,6 +912,11 @@ func (in *JobStatus) DeepCopyInto(out *JobStatus) {
 		in, out := &in.CompletionTime, &out.CompletionTime
 		*out = (*in).DeepCopy()
 	}
+	if in.AttemptPodNames != nil {
+		in, out := &in.AttemptPodNames, &out.AttemptPodNames
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	if in.Containers != nil {
 		in, out := &in.Containers, &out.Containers
 		*out = make([]v1beta1.StepState, len(*in))
@@ -796,6 +979,21 @@ func (in *JobWhen) DeepCopyInto(out *JobWhen) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.Paths != nil {
+		in, out := &in.Paths, &out.Paths
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.SkipPaths != nil {
+		in, out := &in.SkipPaths, &out.SkipPaths
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Events != nil {
+		in, out := &in.Events, &out.Events
+		*out = make([]JobEvent, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new JobWhen.
@@ -829,6 +1027,21 @@ func (in Jobs) DeepCopy() Jobs {
 	return *out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *JobsFrom) DeepCopyInto(out *JobsFrom) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new JobsFrom.
+func (in *JobsFrom) DeepCopy() *JobsFrom {
+	if in == nil {
+		return nil
+	}
+	out := new(JobsFrom)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MergeConfig) DeepCopyInto(out *MergeConfig) {
 	*out = *in
@@ -1158,3 +1371,131 @@ func (in *TektonTask) DeepCopy() *TektonTask {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WebhookDelivery) DeepCopyInto(out *WebhookDelivery) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WebhookDelivery.
+func (in *WebhookDelivery) DeepCopy() *WebhookDelivery {
+	if in == nil {
+		return nil
+	}
+	out := new(WebhookDelivery)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *WebhookDelivery) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WebhookDeliveryList) DeepCopyInto(out *WebhookDeliveryList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]WebhookDelivery, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WebhookDeliveryList.
+func (in *WebhookDeliveryList) DeepCopy() *WebhookDeliveryList {
+	if in == nil {
+		return nil
+	}
+	out := new(WebhookDeliveryList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *WebhookDeliveryList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WebhookDeliveryPluginResult) DeepCopyInto(out *WebhookDeliveryPluginResult) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WebhookDeliveryPluginResult.
+func (in *WebhookDeliveryPluginResult) DeepCopy() *WebhookDeliveryPluginResult {
+	if in == nil {
+		return nil
+	}
+	out := new(WebhookDeliveryPluginResult)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WebhookDeliverySpec) DeepCopyInto(out *WebhookDeliverySpec) {
+	*out = *in
+	if in.Header != nil {
+		in, out := &in.Header, &out.Header
+		*out = make(map[string][]string, len(*in))
+		for key, val := range *in {
+			var outVal []string
+			if val == nil {
+				(*out)[key] = nil
+			} else {
+				in, out := &val, &outVal
+				*out = make([]string, len(*in))
+				copy(*out, *in)
+			}
+			(*out)[key] = outVal
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WebhookDeliverySpec.
+func (in *WebhookDeliverySpec) DeepCopy() *WebhookDeliverySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(WebhookDeliverySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WebhookDeliveryStatus) DeepCopyInto(out *WebhookDeliveryStatus) {
+	*out = *in
+	if in.Plugins != nil {
+		in, out := &in.Plugins, &out.Plugins
+		*out = make([]WebhookDeliveryPluginResult, len(*in))
+		copy(*out, *in)
+	}
+	if in.HandledTime != nil {
+		in, out := &in.HandledTime, &out.HandledTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WebhookDeliveryStatus.
+func (in *WebhookDeliveryStatus) DeepCopy() *WebhookDeliveryStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(WebhookDeliveryStatus)
+	in.DeepCopyInto(out)
+	return out
+}
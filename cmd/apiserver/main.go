@@ -11,9 +11,8 @@ import (
 	"github.com/tmax-cloud/cicd-operator/internal/configs"
 	"github.com/tmax-cloud/cicd-operator/internal/logrotate"
 	"github.com/tmax-cloud/cicd-operator/pkg/apiserver"
-	"github.com/tmax-cloud/cicd-operator/pkg/dispatcher"
-	"github.com/tmax-cloud/cicd-operator/pkg/git"
-	"github.com/tmax-cloud/cicd-operator/pkg/server"
+	"github.com/tmax-cloud/cicd-operator/pkg/plugins"
+	"github.com/tmax-cloud/cicd-operator/pkg/tracing"
 	rbac "k8s.io/api/rbac/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
@@ -96,13 +95,16 @@ func main() {
 	// Wait for initial config reconcile
 	<-configs.ControllerInitCh
 
+	// Init tracing
+	tracing.Init("cicd-api-server")
+
 	// Start API aggregation server
 	apiServer, err := apiserver.New(mgr.GetClient(), mgr.GetConfig(), mgr.GetCache())
 	if err != nil {
 		setupLog.Error(err, "unable to create api server")
 		os.Exit(1)
 	}
-	server.AddPlugin([]git.EventType{git.EventTypePullRequest, git.EventTypePush}, &dispatcher.Dispatcher{Client: mgr.GetClient()})
+	plugins.Register(mgr.GetClient())
 	go apiServer.Start()
 
 	setupLog.Info("starting manager")
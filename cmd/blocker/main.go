@@ -47,12 +47,14 @@ func init() {
 }
 
 func main() {
+	var metricsAddr string
 	var healthAddr string
 	opts := zap.Options{
 		Development: false,
 	}
 	opts.BindFlags(flag.CommandLine)
 
+	flag.StringVar(&metricsAddr, "metrics-addr", ":8080", "The address the metric endpoint binds to.")
 	flag.StringVar(&healthAddr, "health-addr", ":8888", "The address the health endpoint binds to.")
 	flag.Parse()
 
@@ -74,7 +76,7 @@ func main() {
 
 	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
 		Scheme:                 scheme,
-		MetricsBindAddress:     "0",
+		MetricsBindAddress:     metricsAddr,
 		HealthProbeBindAddress: healthAddr,
 		Port:                   9443,
 	})
@@ -41,6 +41,22 @@ func New(c *cli.Configs) cli.Command {
 		RunE:  cmd.RunCommand,
 	}
 
+	replayCommand := &cobra.Command{
+		Use:   "replay [IntegrationConfig] [WebhookDelivery]",
+		Short: "Replays a stored webhook delivery of an IntegrationConfig",
+		Args:  cobra.ExactArgs(2),
+		RunE:  cmd.runReplay,
+	}
+	cmd.Command.AddCommand(replayCommand)
+
+	rotateSecretCommand := &cobra.Command{
+		Use:   "rotate-secret [IntegrationConfig]",
+		Short: "Rotates the webhook secret of an IntegrationConfig",
+		Args:  cobra.ExactArgs(1),
+		RunE:  cmd.runRotateSecret,
+	}
+	cmd.Command.AddCommand(rotateSecretCommand)
+
 	return cmd
 }
 
@@ -64,6 +80,65 @@ func (command *command) RunCommand(_ *cobra.Command, args []string) error {
 		SubResource(cicdv1.IntegrationConfigAPIWebhookURL), printWebhookInfo)
 }
 
+func (command *command) runReplay(_ *cobra.Command, args []string) error {
+	ic := args[0]
+	delivery := args[1]
+
+	body, err := json.Marshal(&cicdv1.IntegrationConfigAPIReqReplayBody{
+		DeliveryID: delivery,
+	})
+	if err != nil {
+		return err
+	}
+
+	// Run!
+	client, ns, err := cli.GetClient(command.Config)
+	if err != nil {
+		return err
+	}
+
+	return cli.ExecAndHandleError(client.Post().
+		Resource(cicdv1.IntegrationConfigKind).
+		Namespace(ns).
+		Name(ic).
+		SubResource(cicdv1.IntegrationConfigAPIReplay).
+		Body(body), printReplayResult)
+}
+
+func (command *command) runRotateSecret(_ *cobra.Command, args []string) error {
+	ic := args[0]
+
+	// Run!
+	client, ns, err := cli.GetClient(command.Config)
+	if err != nil {
+		return err
+	}
+
+	return cli.ExecAndHandleError(client.Post().
+		Resource(cicdv1.IntegrationConfigKind).
+		Namespace(ns).
+		Name(ic).
+		SubResource(cicdv1.IntegrationConfigAPIRotateSecret), printWebhookInfo)
+}
+
+func printReplayResult(raw []byte) error {
+	status := &cicdv1.WebhookDeliveryStatus{}
+
+	if err := json.Unmarshal(raw, status); err != nil {
+		return err
+	}
+
+	fmt.Printf("Event\t: %s\n", status.EventType)
+	for _, p := range status.Plugins {
+		result := "Succeeded"
+		if p.Error != "" {
+			result = fmt.Sprintf("Failed (%s)", p.Error)
+		}
+		fmt.Printf("Plugin %s\t: %s\n", p.Name, result)
+	}
+	return nil
+}
+
 func printWebhookInfo(raw []byte) error {
 	obj := &cicdv1.IntegrationConfigAPIReqWebhookURL{}
 
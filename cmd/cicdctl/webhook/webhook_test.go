@@ -115,3 +115,13 @@ func Test_printWebhookInfo(t *testing.T) {
 		require.Error(t, printWebhookInfo([]byte("aaa")))
 	})
 }
+
+func Test_printReplayResult(t *testing.T) {
+	t.Run("normal", func(t *testing.T) {
+		require.NoError(t, printReplayResult([]byte(`{"eventType": "push", "plugins": [{"name": "dispatcher"}, {"name": "size", "error": "test error"}]}`)))
+	})
+
+	t.Run("unmarshalErr", func(t *testing.T) {
+		require.Error(t, printReplayResult([]byte("aaa")))
+	})
+}
@@ -29,6 +29,7 @@ import (
 	"github.com/tmax-cloud/cicd-operator/internal/logrotate"
 	"github.com/tmax-cloud/cicd-operator/pkg/collector"
 	"github.com/tmax-cloud/cicd-operator/pkg/notification/mail"
+	"github.com/tmax-cloud/cicd-operator/pkg/tracing"
 	rbac "k8s.io/api/rbac/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
@@ -135,6 +136,9 @@ func main() {
 	<-configs.ControllerInitCh
 	<-configs.BlockerInitCh
 
+	// Init tracing
+	tracing.Init("cicd-operator")
+
 	// Start garbage collector
 	gc, err := collector.New(mgr.GetClient())
 	if err != nil {
@@ -11,14 +11,10 @@ import (
 	"github.com/tmax-cloud/cicd-operator/controllers"
 	"github.com/tmax-cloud/cicd-operator/internal/configs"
 	"github.com/tmax-cloud/cicd-operator/internal/logrotate"
-	"github.com/tmax-cloud/cicd-operator/pkg/chatops"
-	"github.com/tmax-cloud/cicd-operator/pkg/chatops/plugins/approve"
-	"github.com/tmax-cloud/cicd-operator/pkg/chatops/plugins/hold"
-	"github.com/tmax-cloud/cicd-operator/pkg/chatops/plugins/trigger"
-	"github.com/tmax-cloud/cicd-operator/pkg/dispatcher"
-	"github.com/tmax-cloud/cicd-operator/pkg/git"
-	"github.com/tmax-cloud/cicd-operator/pkg/plugins/size"
+	"github.com/tmax-cloud/cicd-operator/pkg/plugins"
+	"github.com/tmax-cloud/cicd-operator/pkg/poller"
 	"github.com/tmax-cloud/cicd-operator/pkg/server"
+	"github.com/tmax-cloud/cicd-operator/pkg/tracing"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
@@ -40,12 +36,14 @@ func init() {
 }
 
 func main() {
+	var metricsAddr string
 	var healthAddr string
 	opts := zap.Options{
 		Development: false,
 	}
 	opts.BindFlags(flag.CommandLine)
 
+	flag.StringVar(&metricsAddr, "metrics-addr", ":8080", "The address the metric endpoint binds to.")
 	flag.StringVar(&healthAddr, "health-addr", ":8888", "The address the health endpoint binds to.")
 	flag.Parse()
 
@@ -67,7 +65,7 @@ func main() {
 
 	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
 		Scheme:                 scheme,
-		MetricsBindAddress:     "0",
+		MetricsBindAddress:     metricsAddr,
 		HealthProbeBindAddress: healthAddr,
 		Port:                   9443,
 	})
@@ -98,33 +96,22 @@ func main() {
 	// Wait for initial config reconcile
 	<-configs.ControllerInitCh
 
-	// Init chat-ops
-	co := chatops.New(mgr.GetClient())
-	if err != nil {
-		setupLog.Error(err, "unable to start manager")
-		os.Exit(1)
-	}
-
-	// Init chat-ops plugins
-	approveHandler := &approve.Handler{Client: mgr.GetClient()}
-	triggerHandler := &trigger.Handler{Client: mgr.GetClient()}
-	holdHandler := &hold.Handler{Client: mgr.GetClient()}
-
-	co.RegisterCommandHandler(approve.CommandTypeApprove, approveHandler.HandleChatOps)
-	co.RegisterCommandHandler(approve.CommandTypeGitLabApprove, approveHandler.HandleChatOps)
-	co.RegisterCommandHandler(trigger.CommandTypeTest, triggerHandler.HandleChatOps)
-	co.RegisterCommandHandler(trigger.CommandTypeRetest, triggerHandler.HandleChatOps)
-	co.RegisterCommandHandler(hold.CommandTypeHold, holdHandler.HandleChatOps)
+	// Init tracing
+	tracing.Init("cicd-webhook")
 
 	// Create and start webhook server
 	srv := server.New(mgr.GetClient(), mgr.GetConfig())
-	// Add plugins for webhook
-	server.AddPlugin([]git.EventType{git.EventTypePullRequest, git.EventTypePush}, &dispatcher.Dispatcher{Client: mgr.GetClient()})
-	server.AddPlugin([]git.EventType{git.EventTypeIssueComment, git.EventTypePullRequestReview, git.EventTypePullRequestReviewComment, git.EventTypeCommitComment}, co)
-	server.AddPlugin([]git.EventType{git.EventTypePullRequest, git.EventTypePullRequestReview}, approveHandler)
-	server.AddPlugin([]git.EventType{git.EventTypePullRequest}, &size.Size{Client: mgr.GetClient()})
+	plugins.Register(mgr.GetClient())
 	go srv.Start()
 
+	// Start git poller for the repositories which cannot send webhooks
+	gitPoller, err := poller.New(mgr.GetClient())
+	if err != nil {
+		setupLog.Error(err, "error initializing git poller")
+		os.Exit(1)
+	}
+	go gitPoller.Start()
+
 	setupLog.Info("starting manager")
 	if err := mgr.Start(ctrl.SetupSignalHandler()); err != nil {
 		setupLog.Error(err, "problem running manager")
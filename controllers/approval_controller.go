@@ -29,7 +29,9 @@ import (
 	cicdv1 "github.com/tmax-cloud/cicd-operator/api/v1"
 	"github.com/tmax-cloud/cicd-operator/internal/configs"
 	"github.com/tmax-cloud/cicd-operator/internal/utils"
+	"github.com/tmax-cloud/cicd-operator/pkg/events"
 	"github.com/tmax-cloud/cicd-operator/pkg/notification/mail"
+	corev1 "k8s.io/api/core/v1"
 	rbac "k8s.io/api/rbac/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
@@ -117,6 +119,9 @@ func (r *ApprovalReconciler) Reconcile(ctx context.Context, req ctrl.Request) (c
 	// Default conditions
 	if instance.Status.Result == "" {
 		instance.Status.Result = cicdv1.ApprovalResultAwaiting
+		if err := events.Emit(r.Client, instance, corev1.EventTypeNormal, string(instance.Status.Result), "Approval is requested"); err != nil {
+			log.Error(err, "")
+		}
 		return ctrl.Result{}, nil
 	}
 
@@ -18,7 +18,11 @@ package controllers
 
 import (
 	"context"
+	"fmt"
+	"time"
+
 	"github.com/go-logr/logr"
+	"github.com/tmax-cloud/cicd-operator/internal/configs"
 	"github.com/tmax-cloud/cicd-operator/internal/utils"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
@@ -31,13 +35,18 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 
 	cicdv1 "github.com/tmax-cloud/cicd-operator/api/v1"
+	"github.com/tmax-cloud/cicd-operator/pkg/git/githubapp"
 	"github.com/tmax-cloud/cicd-operator/pkg/periodictrigger"
 )
 
 const (
-	finalizer         = "cicd.tmax.io/finalizer"
-	gitSecretHostKey  = "tekton.dev/git-0"
-	gitSecretUserName = "tmax-cicd-bot"
+	finalizer                  = "cicd.tmax.io/finalizer"
+	gitSecretHostKey           = "tekton.dev/git-0"
+	gitSecretUserName          = "tmax-cicd-bot"
+	gitSecretGitHubAppUserName = "x-access-token"
+
+	// githubAppTokenResyncPeriod is a period to refresh the git secret, as installation tokens of GitHub Apps expire
+	githubAppTokenResyncPeriod = githubapp.RefreshMargin / 2
 )
 
 // IntegrationConfigReconciler reconciles a IntegrationConfig object
@@ -104,6 +113,12 @@ func (r *IntegrationConfigReconciler) Reconcile(ctx context.Context, req ctrl.Re
 	// Set webhook registered
 	r.setWebhookRegisteredCond(instance)
 
+	// Set secret rotated
+	rotationRequeue := r.setSecretRotatedCond(instance)
+
+	// Set when-valid
+	r.setWhenValidCond(instance)
+
 	// Set ready
 	r.setReadyCond(instance)
 
@@ -131,7 +146,12 @@ func (r *IntegrationConfigReconciler) Reconcile(ctx context.Context, req ctrl.Re
 		return ctrl.Result{}, nil
 	}
 
-	return ctrl.Result{}, nil
+	// Installation tokens of GitHub Apps expire, so the git secret should be refreshed periodically
+	if instance.Spec.Git.Token != nil && instance.Spec.Git.Token.GitHubApp != nil && (rotationRequeue == 0 || githubAppTokenResyncPeriod < rotationRequeue) {
+		return ctrl.Result{RequeueAfter: githubAppTokenResyncPeriod}, nil
+	}
+
+	return ctrl.Result{RequeueAfter: rotationRequeue}, nil
 }
 
 // SetupWithManager sets IntegrationConfigReconciler to the manager
@@ -237,6 +257,14 @@ func (r *IntegrationConfigReconciler) setWebhookRegisteredCond(instance *cicdv1.
 		webhookRegistered = meta.FindStatusCondition(instance.Status.Conditions, cicdv1.IntegrationConfigConditionWebhookRegistered)
 	}
 
+	// Generic git servers cannot send webhooks. Refs are polled instead
+	if instance.Spec.Git.Type == cicdv1.GitTypeGeneric {
+		webhookRegistered.Status = metav1.ConditionFalse
+		webhookRegistered.Reason = cicdv1.IntegrationConfigConditionReasonPolling
+		webhookRegistered.Message = "Skipped to register webhook, refs are polled"
+		return
+	}
+
 	// If token is empty, skip to register
 	if instance.Spec.Git.Token == nil {
 		webhookRegistered.Reason = cicdv1.IntegrationConfigConditionReasonNoGitToken
@@ -264,12 +292,23 @@ func (r *IntegrationConfigReconciler) setWebhookRegisteredCond(instance *cicdv1.
 				webhookRegistered.Message = err.Error()
 			}
 			for _, e := range entries {
-				if addr == e.URL {
-					webhookRegistered.Reason = "webhookRegisterFailed"
+				if addr != e.URL {
+					continue
+				}
+				// While the secret is being rotated, the webhook registered with the previous secret is replaced
+				if instance.Status.PreviousSecrets != "" {
+					r.Log.Info("Deleting webhook registered with the previous secret " + e.URL)
+					err := gitCli.DeleteWebhook(e.ID)
+					if err == nil {
+						continue
+					}
+					webhookRegistered.Message = err.Error()
+				} else {
 					webhookRegistered.Message = "same webhook has already registered"
-					isUnique = false
-					break
 				}
+				webhookRegistered.Reason = "webhookRegisterFailed"
+				isUnique = false
+				break
 			}
 			if isUnique {
 				if err := gitCli.RegisterWebhook(addr); err != nil {
@@ -285,18 +324,79 @@ func (r *IntegrationConfigReconciler) setWebhookRegisteredCond(instance *cicdv1.
 	}
 }
 
+// Set secret-rotated condition, dropping the previous webhook secret after the grace period.
+// It returns the remaining grace period, after which it should be checked again
+func (r *IntegrationConfigReconciler) setSecretRotatedCond(instance *cicdv1.IntegrationConfig) time.Duration {
+	if instance.Status.PreviousSecrets == "" {
+		return 0
+	}
+
+	cond := metav1.Condition{
+		Type:   cicdv1.IntegrationConfigConditionSecretRotated,
+		Status: metav1.ConditionFalse,
+		Reason: cicdv1.IntegrationConfigConditionReasonRotating,
+	}
+
+	// The previous secret is kept until the webhook is registered with the new secret, not to drop the webhooks
+	if !isWebhookReady(instance) {
+		cond.Message = "Waiting for the webhook to be registered with the new secret"
+		meta.SetStatusCondition(&instance.Status.Conditions, cond)
+		return 0
+	}
+
+	var rotatedAt time.Time
+	if instance.Status.SecretRotationTime != nil {
+		rotatedAt = instance.Status.SecretRotationTime.Time
+	}
+	expiry := rotatedAt.Add(time.Duration(configs.WebhookSecretGracePeriod) * time.Minute)
+	if remaining := time.Until(expiry); remaining > 0 {
+		cond.Message = fmt.Sprintf("Previous secret is accepted until %s", expiry.UTC().Format(time.RFC3339))
+		meta.SetStatusCondition(&instance.Status.Conditions, cond)
+		return remaining
+	}
+
+	instance.Status.PreviousSecrets = ""
+	instance.Status.SecretRotationTime = nil
+	cond.Status = metav1.ConditionTrue
+	cond.Reason = "Rotated"
+	cond.Message = "Webhook secret is rotated"
+	meta.SetStatusCondition(&instance.Status.Conditions, cond)
+	return 0
+}
+
+// Set when-valid condition, reporting invalid branch/tag patterns, which never match
+func (r *IntegrationConfigReconciler) setWhenValidCond(instance *cicdv1.IntegrationConfig) {
+	cond := metav1.Condition{
+		Type:    cicdv1.IntegrationConfigConditionWhenValid,
+		Status:  metav1.ConditionTrue,
+		Reason:  "Valid",
+		Message: "All branch/tag patterns are valid",
+	}
+	if err := instance.ValidateWhen(); err != nil {
+		cond.Status = metav1.ConditionFalse
+		cond.Reason = "InvalidPattern"
+		cond.Message = err.Error()
+	}
+	meta.SetStatusCondition(&instance.Status.Conditions, cond)
+}
+
 // Set ready condition, return if it's changed or not
 func (r *IntegrationConfigReconciler) setReadyCond(instance *cicdv1.IntegrationConfig) {
 	cond := meta.FindStatusCondition(instance.Status.Conditions, cicdv1.IntegrationConfigConditionReady)
 	// For now, only checked is if webhook-registered is true & secrets are set
-	webhookRegistered := meta.FindStatusCondition(instance.Status.Conditions, cicdv1.IntegrationConfigConditionWebhookRegistered)
-	if instance.Status.Secrets != "" && webhookRegistered != nil && (webhookRegistered.Status == metav1.ConditionTrue || webhookRegistered.Reason == cicdv1.IntegrationConfigConditionReasonNoGitToken) {
+	if instance.Status.Secrets != "" && isWebhookReady(instance) {
 		cond.Status = metav1.ConditionTrue
 		cond.Reason = "Ready"
 		cond.Message = "Ready"
 	}
 }
 
+// isWebhookReady returns if the webhook is registered, or does not need to be registered by the operator
+func isWebhookReady(instance *cicdv1.IntegrationConfig) bool {
+	webhookRegistered := meta.FindStatusCondition(instance.Status.Conditions, cicdv1.IntegrationConfigConditionWebhookRegistered)
+	return webhookRegistered != nil && (webhookRegistered.Status == metav1.ConditionTrue || webhookRegistered.Reason == cicdv1.IntegrationConfigConditionReasonNoGitToken || webhookRegistered.Reason == cicdv1.IntegrationConfigConditionReasonPolling)
+}
+
 func (r *IntegrationConfigReconciler) setPeriodicTrigger(instance *cicdv1.IntegrationConfig) {
 	// Check if periodicTrigger exists
 	nameAndNamespace := instance.Name + instance.Namespace
@@ -372,13 +472,17 @@ func (r *IntegrationConfigReconciler) updateGitSecret(instance *cicdv1.Integrati
 	if err != nil {
 		return false, err
 	}
+	userName := gitSecretUserName
+	if instance.Spec.Git.Token != nil && instance.Spec.Git.Token.GitHubApp != nil {
+		userName = gitSecretGitHubAppUserName
+	}
 	if secret.Data == nil {
 		needPatch = true
 		secret.Data = map[string][]byte{}
-	} else if string(secret.Data[corev1.BasicAuthUsernameKey]) != gitSecretUserName || string(secret.Data[corev1.BasicAuthPasswordKey]) != token {
+	} else if string(secret.Data[corev1.BasicAuthUsernameKey]) != userName || string(secret.Data[corev1.BasicAuthPasswordKey]) != token {
 		needPatch = true
 	}
-	secret.Data[corev1.BasicAuthUsernameKey] = []byte(gitSecretUserName)
+	secret.Data[corev1.BasicAuthUsernameKey] = []byte(userName)
 	secret.Data[corev1.BasicAuthPasswordKey] = []byte(token)
 
 	return needPatch, nil
@@ -18,7 +18,14 @@ package controllers
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 	cicdv1 "github.com/tmax-cloud/cicd-operator/api/v1"
@@ -551,6 +558,26 @@ func TestIntegrationConfigReconciler_setWebhookRegisteredCond(t *testing.T) {
 			expectedReason:     "noGitToken",
 			expectedMessage:    "Skipped to register webhook",
 		},
+		"generic": {
+			ic: &cicdv1.IntegrationConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-ic",
+					Namespace: "test-ns",
+				},
+				Spec: cicdv1.IntegrationConfigSpec{
+					Git: cicdv1.GitConfig{
+						Type:       cicdv1.GitTypeGeneric,
+						Repository: "test-repo",
+						APIUrl:     "https://git.example.com",
+						Token:      &cicdv1.GitToken{Value: "test-tkn"},
+					},
+				},
+			},
+			expectedWebhookURL: "",
+			expectedStatus:     metav1.ConditionFalse,
+			expectedReason:     "polling",
+			expectedMessage:    "Skipped to register webhook, refs are polled",
+		},
 		"getGitCliErr": {
 			ic: &cicdv1.IntegrationConfig{
 				ObjectMeta: metav1.ObjectMeta{
@@ -609,6 +636,35 @@ func TestIntegrationConfigReconciler_setWebhookRegisteredCond(t *testing.T) {
 			expectedReason:          "webhookRegisterFailed",
 			expectedMessage:         "same webhook has already registered",
 		},
+		"secretRotating": {
+			ic: &cicdv1.IntegrationConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-ic",
+					Namespace: "test-ns",
+				},
+				Spec: cicdv1.IntegrationConfigSpec{
+					Git: cicdv1.GitConfig{
+						Type:       cicdv1.GitTypeFake,
+						Repository: "test-repo",
+						Token:      &cicdv1.GitToken{Value: "test-tkn"},
+					},
+				},
+				Status: cicdv1.IntegrationConfigStatus{
+					Secrets:         "new-secret",
+					PreviousSecrets: "old-secret",
+					Conditions: []metav1.Condition{{
+						Type:   cicdv1.IntegrationConfigConditionWebhookRegistered,
+						Status: metav1.ConditionFalse,
+						Reason: cicdv1.IntegrationConfigConditionReasonRotating,
+					}},
+				},
+			},
+			preRegisteredWebhookURL: "http://cicd-webhook.com/webhook/test-ns/test-ic",
+			expectedWebhookURL:      "http://cicd-webhook.com/webhook/test-ns/test-ic",
+			expectedStatus:          metav1.ConditionTrue,
+			expectedReason:          "Registered",
+			expectedMessage:         "Webhook is registered",
+		},
 	}
 
 	for name, c := range tc {
@@ -626,6 +682,13 @@ func TestIntegrationConfigReconciler_setWebhookRegisteredCond(t *testing.T) {
 			reconciler := &IntegrationConfigReconciler{Log: &test.FakeLogger{}}
 			reconciler.setWebhookRegisteredCond(c.ic)
 
+			// Webhook registered with the previous secret is replaced
+			if c.ic.Status.PreviousSecrets != "" {
+				_, exist := gitfake.Repos["test-repo"].Webhooks[32]
+				require.False(t, exist)
+				require.Len(t, gitfake.Repos["test-repo"].Webhooks, 1)
+			}
+
 			if c.expectedWebhookURL != "" {
 				found := false
 				for _, w := range gitfake.Repos["test-repo"].Webhooks {
@@ -730,6 +793,27 @@ func TestIntegrationConfigReconciler_setReadyCond(t *testing.T) {
 			},
 			expectedReadyCondStatus: metav1.ConditionTrue,
 		},
+		"polling": {
+			ic: &cicdv1.IntegrationConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-ic",
+					Namespace: "test-ns",
+				},
+				Spec: cicdv1.IntegrationConfigSpec{
+					Git: cicdv1.GitConfig{
+						Type:   cicdv1.GitTypeGeneric,
+						APIUrl: "https://git.example.com",
+					},
+				},
+				Status: cicdv1.IntegrationConfigStatus{
+					Conditions: []metav1.Condition{
+						{Type: "webhook-registered", Status: metav1.ConditionFalse, Reason: "polling"},
+					},
+					Secrets: "test-secret",
+				},
+			},
+			expectedReadyCondStatus: metav1.ConditionTrue,
+		},
 	}
 
 	for name, c := range tc {
@@ -749,6 +833,119 @@ func TestIntegrationConfigReconciler_setReadyCond(t *testing.T) {
 	}
 }
 
+func TestIntegrationConfigReconciler_setSecretRotatedCond(t *testing.T) {
+	configs.WebhookSecretGracePeriod = 60
+
+	registered := metav1.Condition{Type: cicdv1.IntegrationConfigConditionWebhookRegistered, Status: metav1.ConditionTrue, Reason: "Registered"}
+	notRegistered := metav1.Condition{Type: cicdv1.IntegrationConfigConditionWebhookRegistered, Status: metav1.ConditionFalse, Reason: "webhookRegisterFailed"}
+
+	tc := map[string]struct {
+		previousSecret string
+		rotatedBefore  time.Duration
+		webhookCond    metav1.Condition
+
+		expectedCond           bool
+		expectedStatus         metav1.ConditionStatus
+		expectedReason         string
+		expectedPreviousSecret string
+		expectedRequeue        bool
+	}{
+		"notRotated": {
+			webhookCond: registered,
+		},
+		"gracePeriod": {
+			previousSecret:         "old-secret",
+			rotatedBefore:          10 * time.Minute,
+			webhookCond:            registered,
+			expectedCond:           true,
+			expectedStatus:         metav1.ConditionFalse,
+			expectedReason:         cicdv1.IntegrationConfigConditionReasonRotating,
+			expectedPreviousSecret: "old-secret",
+			expectedRequeue:        true,
+		},
+		"notRegistered": {
+			previousSecret:         "old-secret",
+			rotatedBefore:          2 * time.Hour,
+			webhookCond:            notRegistered,
+			expectedCond:           true,
+			expectedStatus:         metav1.ConditionFalse,
+			expectedReason:         cicdv1.IntegrationConfigConditionReasonRotating,
+			expectedPreviousSecret: "old-secret",
+		},
+		"rotated": {
+			previousSecret: "old-secret",
+			rotatedBefore:  2 * time.Hour,
+			webhookCond:    registered,
+			expectedCond:   true,
+			expectedStatus: metav1.ConditionTrue,
+			expectedReason: "Rotated",
+		},
+	}
+
+	for name, c := range tc {
+		t.Run(name, func(t *testing.T) {
+			ic := &cicdv1.IntegrationConfig{
+				Status: cicdv1.IntegrationConfigStatus{
+					Conditions:         []metav1.Condition{c.webhookCond},
+					Secrets:            "new-secret",
+					PreviousSecrets:    c.previousSecret,
+					SecretRotationTime: &metav1.Time{Time: time.Now().Add(-c.rotatedBefore)},
+				},
+			}
+			reconciler := &IntegrationConfigReconciler{}
+			requeue := reconciler.setSecretRotatedCond(ic)
+
+			require.Equal(t, c.expectedRequeue, requeue > 0)
+			require.Equal(t, c.expectedPreviousSecret, ic.Status.PreviousSecrets)
+			cond := meta.FindStatusCondition(ic.Status.Conditions, cicdv1.IntegrationConfigConditionSecretRotated)
+			if !c.expectedCond {
+				require.Nil(t, cond)
+				return
+			}
+			require.NotNil(t, cond)
+			require.Equal(t, c.expectedStatus, cond.Status)
+			require.Equal(t, c.expectedReason, cond.Reason)
+		})
+	}
+}
+
+func TestIntegrationConfigReconciler_setWhenValidCond(t *testing.T) {
+	tc := map[string]struct {
+		when *cicdv1.JobWhen
+
+		expectedStatus  metav1.ConditionStatus
+		expectedReason  string
+		expectedMessage string
+	}{
+		"valid": {
+			when:            &cicdv1.JobWhen{Branch: []string{"release-.*"}},
+			expectedStatus:  metav1.ConditionTrue,
+			expectedReason:  "Valid",
+			expectedMessage: "All branch/tag patterns are valid",
+		},
+		"invalid": {
+			when:            &cicdv1.JobWhen{Branch: []string{"release-["}},
+			expectedStatus:  metav1.ConditionFalse,
+			expectedReason:  "InvalidPattern",
+			expectedMessage: "when: invalid regular expressions \"release-[\"",
+		},
+	}
+
+	for name, c := range tc {
+		t.Run(name, func(t *testing.T) {
+			ic := &cicdv1.IntegrationConfig{Spec: cicdv1.IntegrationConfigSpec{When: c.when}}
+			reconciler := &IntegrationConfigReconciler{}
+			reconciler.setWhenValidCond(ic)
+
+			cond := meta.FindStatusCondition(ic.Status.Conditions, cicdv1.IntegrationConfigConditionWhenValid)
+			require.NotNil(t, cond)
+			require.Equal(t, c.expectedStatus, cond.Status)
+			require.Equal(t, c.expectedReason, cond.Reason)
+			require.Equal(t, c.expectedMessage, cond.Message)
+		})
+	}
+}
+
 func TestIntegrationConfigReconciler_createGitSecret(t *testing.T) {
 	s := runtime.NewScheme()
 	utilruntime.Must(corev1.AddToScheme(s))
@@ -880,7 +1077,20 @@ func TestIntegrationConfigReconciler_updateGitSecret(t *testing.T) {
 	utilruntime.Must(corev1.AddToScheme(s))
 	utilruntime.Must(cicdv1.AddToScheme(s))
 
-	fakeCli := fake.NewClientBuilder().WithScheme(s).Build()
+	privateKey, err := rsa.GenerateKey(rand.Reader, 1024)
+	require.NoError(t, err)
+	appSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "app-secret", Namespace: "test-ns"},
+		Data: map[string][]byte{
+			"private-key": pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(privateKey)}),
+		},
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"token": "ghs_test", "expires_at": "2099-01-01T00:00:00Z"}`))
+	}))
+	defer srv.Close()
+
+	fakeCli := fake.NewClientBuilder().WithScheme(s).WithObjects(appSecret).Build()
 
 	tc := map[string]struct {
 		ic     *cicdv1.IntegrationConfig
@@ -889,9 +1099,43 @@ func TestIntegrationConfigReconciler_updateGitSecret(t *testing.T) {
 		errorOccurs  bool
 		errorMessage string
 
-		doPatch       bool
-		expectedToken string
+		doPatch          bool
+		expectedHost     string
+		expectedUserName string
+		expectedToken    string
 	}{
+		"githubApp": {
+			ic: &cicdv1.IntegrationConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-ic",
+					Namespace: "test-ns",
+				},
+				Spec: cicdv1.IntegrationConfigSpec{
+					Git: cicdv1.GitConfig{
+						Type:   cicdv1.GitTypeGitHub,
+						APIUrl: srv.URL,
+						Token: &cicdv1.GitToken{GitHubApp: &cicdv1.GitHubAppToken{
+							AppID:          1,
+							InstallationID: 2,
+							PrivateKey: corev1.SecretKeySelector{
+								LocalObjectReference: corev1.LocalObjectReference{Name: "app-secret"},
+								Key:                  "private-key",
+							},
+						}},
+					},
+				},
+			},
+			secret: &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      cicdv1.GetSecretName("test-ic"),
+					Namespace: "test-ns",
+				},
+			},
+			doPatch:          true,
+			expectedHost:     srv.URL,
+			expectedUserName: "x-access-token",
+			expectedToken:    "ghs_test",
+		},
 		"create": {
 			ic: &cicdv1.IntegrationConfig{
 				ObjectMeta: metav1.ObjectMeta{
@@ -1022,8 +1266,14 @@ func TestIntegrationConfigReconciler_updateGitSecret(t *testing.T) {
 				require.NoError(t, err)
 				require.Equal(t, c.doPatch, doPatch)
 
-				require.Equal(t, map[string]string{"tekton.dev/git-0": "https://github.com"}, c.secret.Annotations)
-				require.Equal(t, map[string][]byte{"username": []byte("tmax-cicd-bot"), "password": []byte(c.expectedToken)}, c.secret.Data)
+				if c.expectedHost == "" {
+					c.expectedHost = "https://github.com"
+				}
+				if c.expectedUserName == "" {
+					c.expectedUserName = "tmax-cicd-bot"
+				}
+				require.Equal(t, map[string]string{"tekton.dev/git-0": c.expectedHost}, c.secret.Annotations)
+				require.Equal(t, map[string][]byte{"username": []byte(c.expectedUserName), "password": []byte(c.expectedToken)}, c.secret.Data)
 			}
 		})
 	}
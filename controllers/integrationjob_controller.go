@@ -18,6 +18,7 @@ package controllers
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/go-logr/logr"
 	tektonv1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
@@ -94,6 +95,13 @@ func (r *integrationJobReconciler) Reconcile(ctx context.Context, req ctrl.Reque
 
 	// Skip if it's ended
 	if instance.Status.CompletionTime != nil {
+		// The scheduler may create a PipelineRun after the job is canceled, so cancel it even after the completion
+		if instance.Status.State == cicdv1.IntegrationJobStateCanceled {
+			if err := r.cancelLeftoverPipelineRun(ctx, instance); err != nil {
+				log.Error(err, "")
+				return ctrl.Result{}, err
+			}
+		}
 		return ctrl.Result{}, nil
 	}
 
@@ -185,6 +193,28 @@ func (r *integrationJobReconciler) handleFinalizer(instance, original *cicdv1.In
 	return false, nil
 }
 
+// cancelLeftoverPipelineRun cancels the running PipelineRun of the canceled job
+func (r *integrationJobReconciler) cancelLeftoverPipelineRun(ctx context.Context, instance *cicdv1.IntegrationJob) error {
+	pr := &tektonv1beta1.PipelineRun{}
+	if err := r.Client.Get(ctx, types.NamespacedName{Name: pipelinemanager.Name(instance), Namespace: instance.Namespace}, pr); err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	if pr.Status.CompletionTime != nil || pr.IsCancelled() {
+		return nil
+	}
+
+	original := pr.DeepCopy()
+	pr.Spec.Status = tektonv1beta1.PipelineRunSpecStatusCancelled
+	if err := r.Client.Patch(ctx, pr, client.MergeFrom(original)); err != nil {
+		return err
+	}
+	r.Log.Info(fmt.Sprintf("Canceled leftover PipelineRun %s/%s of the canceled IntegrationJob", pr.Namespace, pr.Name))
+	return nil
+}
+
 func (r *integrationJobReconciler) patchJobFailed(instance *cicdv1.IntegrationJob, original *cicdv1.IntegrationJob, message string) {
 	instance.Status.State = cicdv1.IntegrationJobStateFailed
 	instance.Status.Message = message
@@ -196,6 +226,10 @@ func (r *integrationJobReconciler) patchJobFailed(instance *cicdv1.IntegrationJo
 
 // SetupWithManager sets integrationJobReconciler to the manager
 func (r *integrationJobReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	// Scheduler rebuilds its job pool whenever the manager starts leading
+	if err := mgr.Add(r.scheduler); err != nil {
+		return err
+	}
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&cicdv1.IntegrationJob{}).
 		Owns(&tektonv1beta1.PipelineRun{}).
@@ -68,6 +68,8 @@ func TestIntegrationJobReconciler_Reconcile(t *testing.T) {
 
 	logger := &test.FakeLogger{}
 
+	reconciler := &integrationJobReconciler{pm: &fakePipelineManager{}, Log: logger, scheduler: &fakeScheduler{}}
+
 	tc := map[string]struct {
 		ij         *cicdv1.IntegrationJob
 		ic         *cicdv1.IntegrationConfig
@@ -154,6 +156,29 @@ func TestIntegrationJobReconciler_Reconcile(t *testing.T) {
 			key:        types.NamespacedName{Name: "test-ij", Namespace: "test-ns"},
 			verifyFunc: func(t *testing.T, ij *cicdv1.IntegrationJob) {},
 		},
+		"canceledLeftoverPipelineRun": {
+			ij: &cicdv1.IntegrationJob{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-ij", Namespace: "test-ns", Finalizers: []string{finalizer}},
+				Spec: cicdv1.IntegrationJobSpec{
+					ConfigRef: cicdv1.IntegrationJobConfigRef{Name: "test-ic"},
+				},
+				Status: cicdv1.IntegrationJobStatus{
+					State:          cicdv1.IntegrationJobStateCanceled,
+					CompletionTime: &tt,
+				},
+			},
+			pr: &tektonv1beta1.PipelineRun{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-ij", Namespace: "test-ns"},
+			},
+			scheme: s,
+			key:    types.NamespacedName{Name: "test-ij", Namespace: "test-ns"},
+			verifyFunc: func(t *testing.T, ij *cicdv1.IntegrationJob) {
+				require.Empty(t, ij.Annotations["reflected"])
+				pr := &tektonv1beta1.PipelineRun{}
+				require.NoError(t, reconciler.Client.Get(context.Background(), types.NamespacedName{Name: "test-ij", Namespace: "test-ns"}, pr))
+				require.Equal(t, tektonv1beta1.PipelineRunSpecStatus(tektonv1beta1.PipelineRunSpecStatusCancelled), pr.Spec.Status)
+			},
+		},
 		"icGetError": {
 			ij: &cicdv1.IntegrationJob{
 				ObjectMeta: metav1.ObjectMeta{Name: "test-ij", Namespace: "test-ns", Finalizers: []string{finalizer}},
@@ -242,8 +267,6 @@ func TestIntegrationJobReconciler_Reconcile(t *testing.T) {
 		},
 	}
 
-	reconciler := &integrationJobReconciler{pm: &fakePipelineManager{}, Log: logger, scheduler: &fakeScheduler{}}
-
 	for name, c := range tc {
 		t.Run(name, func(t *testing.T) {
 			if c.ij == nil {
@@ -392,6 +415,10 @@ type fakeScheduler struct{}
 
 func (f *fakeScheduler) Notify(_ *cicdv1.IntegrationJob) {}
 
+func (f *fakeScheduler) Start(_ context.Context) error { return nil }
+
+func (f *fakeScheduler) NeedLeaderElection() bool { return true }
+
 func TestIntegrationJobReconciler_patchJobFailed(t *testing.T) {
 	s := runtime.NewScheme()
 	utilruntime.Must(corev1.AddToScheme(s))
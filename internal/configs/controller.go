@@ -40,6 +40,7 @@ func RegisterControllerConfigUpdateChan(ch chan struct{}) {
 func ApplyControllerConfigChange(cm *corev1.ConfigMap) error {
 	getVars(cm.Data, map[string]operatorConfig{
 		"maxPipelineRun":            {Type: cfgTypeInt, IntVal: &MaxPipelineRun, IntDefault: 5},                                // Max PipelineRun count
+		"maxNamespacePipelineRun":   {Type: cfgTypeInt, IntVal: &MaxNamespacePipelineRun, IntDefault: 0},                       // Max PipelineRun count per namespace
 		"enableMail":                {Type: cfgTypeBool, BoolVal: &EnableMail, BoolDefault: false},                             // Enable Mail
 		"externalHostName":          {Type: cfgTypeString, StringVal: &ExternalHostName},                                       // External Hostname
 		"exposeMode":                {Type: cfgTypeString, StringVal: &ExposeMode, StringDefault: "Ingress"},                   // Expose mode
@@ -53,6 +54,19 @@ func ApplyControllerConfigChange(cm *corev1.ConfigMap) error {
 		"gitImage":                  {Type: cfgTypeString, StringVal: &GitImage, StringDefault: "docker.io/alpine/git:1.0.30"}, // Git image
 		"gitCheckoutStepCPURequest": {Type: cfgTypeString, StringVal: &GitCheckoutStepCPURequest, StringDefault: "30m"},        // Git checkout step CPU request
 		"gitCheckoutStepMemRequest": {Type: cfgTypeString, StringVal: &GitCheckoutStepMemRequest, StringDefault: "100Mi"},      // Git checkout step Memory request
+		"gitPollingPeriod":          {Type: cfgTypeInt, IntVal: &GitPollingPeriod, IntDefault: 60},                             // Polling period for generic git repositories
+		"priorityAgingPeriod":       {Type: cfgTypeInt, IntVal: &PriorityAgingPeriod, IntDefault: 10},                          // Aging period for pending jobs' priorities
+		"schedulingStrategy":        {Type: cfgTypeString, StringVal: &SchedulingStrategy, StringDefault: "priority"},          // Scheduling strategy
+		"cloudEventsSink":           {Type: cfgTypeString, StringVal: &CloudEventsSink},                                        // Cloud events sink
+		"cloudEventsMode":           {Type: cfgTypeString, StringVal: &CloudEventsMode, StringDefault: "binary"},               // Cloud events content mode
+		"tracingEndpoint":           {Type: cfgTypeString, StringVal: &TracingEndpoint},                                        // OTLP endpoint for traces
+		"tracingInsecure":           {Type: cfgTypeBool, BoolVal: &TracingInsecure, BoolDefault: false},                        // Disable TLS for the OTLP endpoint
+		"webhookDeliveryLimit":      {Type: cfgTypeInt, IntVal: &WebhookDeliveryLimit, IntDefault: 20},                         // Max stored webhook deliveries per config
+		"webhookDeliveryTTL":        {Type: cfgTypeInt, IntVal: &WebhookDeliveryTTL, IntDefault: 24},                           // TTL of stored webhook deliveries
+		"webhookWorkers":            {Type: cfgTypeInt, IntVal: &WebhookWorkers, IntDefault: 4},                                // Number of webhook workers
+		"webhookRetries":            {Type: cfgTypeInt, IntVal: &WebhookRetries, IntDefault: 3},                                // Max retries of webhook plugins
+		"webhookDedupWindow":        {Type: cfgTypeInt, IntVal: &WebhookDedupWindow, IntDefault: 60},                           // Window for deduplicating webhooks
+		"webhookSecretGracePeriod":  {Type: cfgTypeInt, IntVal: &WebhookSecretGracePeriod, IntDefault: 60},                     // Grace period of the rotated webhook secrets
 	})
 
 	// Check SMTP config.s
@@ -83,6 +97,10 @@ var (
 	// MaxPipelineRun is the number of PipelineRuns that can run simultaneously
 	MaxPipelineRun int
 
+	// MaxNamespacePipelineRun is the number of PipelineRuns that can run simultaneously in a namespace.
+	// It is not limited if it is not positive
+	MaxNamespacePipelineRun int
+
 	// ExternalHostName to be used for webhook server (default is ingress host name)
 	ExternalHostName string
 
@@ -126,4 +144,48 @@ var (
 
 	// GitCheckoutStepMemRequest is a memory request of a git checkout step
 	GitCheckoutStepMemRequest string
+
+	// GitPollingPeriod is a period (in seconds) of polling refs of generic git repositories
+	GitPollingPeriod int
+
+	// PriorityAgingPeriod is a period (in minutes) for a pending IntegrationJob's priority to be increased by one.
+	// Aging is disabled if it is not positive
+	PriorityAgingPeriod int
+
+	// SchedulingStrategy is a strategy for ordering pending IntegrationJobs (priority/fairShare)
+	SchedulingStrategy string
+
+	// CloudEventsSink is an url of the sink, to which cloud events are sent. Cloud events are disabled if it is empty
+	CloudEventsSink string
+
+	// CloudEventsMode is a content mode of the cloud events sent to the sink (binary/structured)
+	CloudEventsMode string
+
+	// TracingEndpoint is an endpoint (host:port) of the OTLP/HTTP trace collector. Tracing is disabled if it is empty
+	TracingEndpoint string
+
+	// TracingInsecure is whether to disable TLS for the OTLP/HTTP trace collector
+	TracingInsecure bool
+
+	// WebhookDeliveryLimit is the max number of WebhookDeliveries stored for each IntegrationConfig.
+	// Webhook deliveries are not stored if it is not positive
+	WebhookDeliveryLimit int
+
+	// WebhookDeliveryTTL is a garbage collection threshold of WebhookDeliveries (in hour).
+	// If WebhookDelivery's .metadata.creationTimestamp + TTL < now, it's collected
+	WebhookDeliveryTTL int
+
+	// WebhookWorkers is the number of workers handling the queued webhooks. It is applied when the webhook server starts
+	WebhookWorkers int
+
+	// WebhookRetries is the max number of retries of the failed webhook plugins, before the webhook is dead-lettered
+	WebhookRetries int
+
+	// WebhookDedupWindow is a window (in minute) for deduplicating the webhooks. Redelivered webhooks and
+	// IntegrationJobs for the same commits are ignored within the window. Deduplication is disabled if it is not positive
+	WebhookDedupWindow int
+
+	// WebhookSecretGracePeriod is a period (in minute) for which the previous webhook secret is still accepted, after
+	// the webhook secret of an IntegrationConfig is rotated
+	WebhookSecretGracePeriod int
 )
@@ -47,6 +47,7 @@ func TestApplyControllerConfigChange(t *testing.T) {
 			require.Equal(t, 120, IntegrationJobTTL)
 			require.Equal(t, "", IngressClass)
 			require.Equal(t, "", IngressHost)
+			require.Equal(t, 60, GitPollingPeriod)
 		}},
 		"noError": {ConfigMap: &corev1.ConfigMap{
 			Data: map[string]string{
@@ -60,6 +61,7 @@ func TestApplyControllerConfigChange(t *testing.T) {
 				"integrationJobTTL":         "11",
 				"ingressClass":              "test-cls",
 				"ingressHost":               "test.host",
+				"gitPollingPeriod":          "30",
 			},
 		}, AssertFunc: func(t *testing.T, err error) {
 			require.NoError(t, err)
@@ -74,6 +76,7 @@ func TestApplyControllerConfigChange(t *testing.T) {
 			require.Equal(t, 11, IntegrationJobTTL)
 			require.Equal(t, "test-cls", IngressClass)
 			require.Equal(t, "test.host", IngressHost)
+			require.Equal(t, 30, GitPollingPeriod)
 		}},
 		"errorOccur": {ConfigMap: &corev1.ConfigMap{
 			Data: map[string]string{
@@ -103,6 +106,7 @@ func TestApplyControllerConfigChange(t *testing.T) {
 			IntegrationJobTTL = 0
 			IngressClass = ""
 			IngressHost = ""
+			GitPollingPeriod = 0
 
 			ch := make(chan struct{}, 1)
 			controllerConfigUpdateChan = append(controllerConfigUpdateChan, ch)
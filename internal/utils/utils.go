@@ -17,6 +17,7 @@
 package utils
 
 import (
+	"context"
 	"fmt"
 	"github.com/tmax-cloud/cicd-operator/pkg/git/gitea"
 	"regexp"
@@ -27,22 +28,36 @@ import (
 
 	cicdv1 "github.com/tmax-cloud/cicd-operator/api/v1"
 	"github.com/tmax-cloud/cicd-operator/pkg/git"
+	"github.com/tmax-cloud/cicd-operator/pkg/git/azuredevops"
+	"github.com/tmax-cloud/cicd-operator/pkg/git/bitbucket"
+	"github.com/tmax-cloud/cicd-operator/pkg/git/generic"
 	"github.com/tmax-cloud/cicd-operator/pkg/git/github"
 	"github.com/tmax-cloud/cicd-operator/pkg/git/gitlab"
 )
 
 // GetGitCli generates git client, depending on the git type in the cfg
 func GetGitCli(cfg *cicdv1.IntegrationConfig, cli client.Client) (git.Client, error) {
+	return GetGitCliWithContext(context.Background(), cfg, cli)
+}
+
+// GetGitCliWithContext gets git client for the IntegrationConfig, whose API calls are traced as the children of ctx
+func GetGitCliWithContext(ctx context.Context, cfg *cicdv1.IntegrationConfig, cli client.Client) (git.Client, error) {
 	var c git.Client
 	switch cfg.Spec.Git.Type {
 	case cicdv1.GitTypeGitHub:
-		c = &github.Client{IntegrationConfig: cfg, K8sClient: cli}
+		c = &github.Client{IntegrationConfig: cfg, K8sClient: cli, Context: ctx}
 	case cicdv1.GitTypeGitLab:
-		c = &gitlab.Client{IntegrationConfig: cfg, K8sClient: cli}
+		c = &gitlab.Client{IntegrationConfig: cfg, K8sClient: cli, Context: ctx}
 	case cicdv1.GitTypeFake:
 		c = &fake.Client{IntegrationConfig: cfg, K8sClient: cli}
 	case cicdv1.GitTypeGitea:
-		c = &gitea.Client{IntegrationConfig: cfg, K8sClient: cli}
+		c = &gitea.Client{IntegrationConfig: cfg, K8sClient: cli, Context: ctx}
+	case cicdv1.GitTypeBitbucket:
+		c = &bitbucket.Client{IntegrationConfig: cfg, K8sClient: cli, Context: ctx}
+	case cicdv1.GitTypeAzureDevOps:
+		c = &azuredevops.Client{IntegrationConfig: cfg, K8sClient: cli, Context: ctx}
+	case cicdv1.GitTypeGeneric:
+		c = &generic.Client{IntegrationConfig: cfg, K8sClient: cli, Context: ctx}
 	default:
 		return nil, fmt.Errorf("git type %s is not supported", cfg.Spec.Git.Type)
 	}
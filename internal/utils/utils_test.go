@@ -50,6 +50,34 @@ func TestGetGitCli(t *testing.T) {
 				},
 			},
 		},
+		"bitbucket": {
+			ic: &cicdv1.IntegrationConfig{
+				Spec: cicdv1.IntegrationConfigSpec{
+					Git: cicdv1.GitConfig{
+						Type: cicdv1.GitTypeBitbucket,
+					},
+				},
+			},
+		},
+		"azuredevops": {
+			ic: &cicdv1.IntegrationConfig{
+				Spec: cicdv1.IntegrationConfigSpec{
+					Git: cicdv1.GitConfig{
+						Type: cicdv1.GitTypeAzureDevOps,
+					},
+				},
+			},
+		},
+		"generic": {
+			ic: &cicdv1.IntegrationConfig{
+				Spec: cicdv1.IntegrationConfigSpec{
+					Git: cicdv1.GitConfig{
+						Type:   cicdv1.GitTypeGeneric,
+						APIUrl: "https://git.example.com",
+					},
+				},
+			},
+		},
 		"fake": {
 			ic: &cicdv1.IntegrationConfig{
 				Spec: cicdv1.IntegrationConfigSpec{
@@ -74,5 +74,17 @@ func NewHandler(parent wrapper.RouterWrapper, cli client.Client, authCli authori
 		return nil, err
 	}
 
+	// /integrationconfigs/<integrationconfig>/replay
+	replayWrapper := wrapper.New("/"+cicdv1.IntegrationConfigAPIReplay, []string{http.MethodPost}, handler.replayHandler)
+	if err := icWrapper.Add(replayWrapper); err != nil {
+		return nil, err
+	}
+
+	// /integrationconfigs/<integrationconfig>/rotatesecret
+	rotateSecretWrapper := wrapper.New("/"+cicdv1.IntegrationConfigAPIRotateSecret, []string{http.MethodPost}, handler.rotateSecretHandler)
+	if err := icWrapper.Add(rotateSecretWrapper); err != nil {
+		return nil, err
+	}
+
 	return handler, nil
 }
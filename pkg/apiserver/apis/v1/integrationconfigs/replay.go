@@ -0,0 +1,84 @@
+/*
+ Copyright 2021 The CI/CD Operator Authors
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package integrationconfigs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	cicdv1 "github.com/tmax-cloud/cicd-operator/api/v1"
+	"github.com/tmax-cloud/cicd-operator/internal/apiserver"
+	"github.com/tmax-cloud/cicd-operator/internal/utils"
+	"github.com/tmax-cloud/cicd-operator/pkg/server"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func (h *handler) replayHandler(w http.ResponseWriter, req *http.Request) {
+	reqID := utils.RandomString(10)
+	log := h.log.WithValues("request", reqID)
+
+	// Get ns/resource name
+	vars := mux.Vars(req)
+
+	ns, nsExist := vars[apiserver.NamespaceParamKey]
+	resName, nameExist := vars[icParamKey]
+	if !nsExist || !nameExist {
+		log.Info("url is malformed")
+		_ = utils.RespondError(w, http.StatusBadRequest, "url is malformed")
+		return
+	}
+
+	userReq := &cicdv1.IntegrationConfigAPIReqReplayBody{}
+	if err := json.NewDecoder(req.Body).Decode(userReq); err != nil || userReq.DeliveryID == "" {
+		log.Info("delivery_id is not given")
+		_ = utils.RespondError(w, http.StatusBadRequest, fmt.Sprintf("req: %s, delivery_id must be set", reqID))
+		return
+	}
+
+	// Get IntegrationConfig
+	ic := &cicdv1.IntegrationConfig{}
+	if err := h.k8sClient.Get(context.Background(), types.NamespacedName{Name: resName, Namespace: ns}, ic); err != nil {
+		log.Info(err.Error())
+		_ = utils.RespondError(w, http.StatusInternalServerError, fmt.Sprintf("req: %s, cannot get IntegrationConfig %s/%s", reqID, ns, resName))
+		return
+	}
+
+	// Get WebhookDelivery
+	delivery := &cicdv1.WebhookDelivery{}
+	if err := h.k8sClient.Get(context.Background(), types.NamespacedName{Name: userReq.DeliveryID, Namespace: ns}, delivery); err != nil {
+		log.Info(err.Error())
+		_ = utils.RespondError(w, http.StatusNotFound, fmt.Sprintf("req: %s, cannot get WebhookDelivery %s/%s", reqID, ns, userReq.DeliveryID))
+		return
+	}
+	if delivery.Spec.IntegrationConfig != ic.Name {
+		log.Info("delivery is not for the IntegrationConfig")
+		_ = utils.RespondError(w, http.StatusBadRequest, fmt.Sprintf("req: %s, WebhookDelivery %s is not for IntegrationConfig %s", reqID, userReq.DeliveryID, resName))
+		return
+	}
+
+	// Replay!
+	if err := server.ReplayDelivery(req.Context(), h.k8sClient, delivery, ic); err != nil {
+		log.Info(err.Error())
+		_ = utils.RespondError(w, http.StatusInternalServerError, fmt.Sprintf("req: %s, cannot replay webhook delivery, err : %s", reqID, err.Error()))
+		return
+	}
+
+	_ = utils.RespondJSON(w, delivery.Status)
+}
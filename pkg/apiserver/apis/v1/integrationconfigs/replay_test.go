@@ -0,0 +1,119 @@
+/*
+ Copyright 2021 The CI/CD Operator Authors
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package integrationconfigs
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/require"
+	cicdv1 "github.com/tmax-cloud/cicd-operator/api/v1"
+	"github.com/tmax-cloud/cicd-operator/internal/test"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func Test_handler_replayHandler(t *testing.T) {
+	s := runtime.NewScheme()
+	require.NoError(t, cicdv1.AddToScheme(s))
+
+	ic := &cicdv1.IntegrationConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-ic", Namespace: "test-ns"},
+		Spec: cicdv1.IntegrationConfigSpec{
+			Git: cicdv1.GitConfig{Type: "fake", APIUrl: "https://test.git.com", Repository: "test/test"},
+		},
+	}
+	delivery := &cicdv1.WebhookDelivery{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-ic-delivery", Namespace: "test-ns"},
+		Spec:       cicdv1.WebhookDeliverySpec{IntegrationConfig: "test-ic", Body: "{}"},
+	}
+	otherDelivery := &cicdv1.WebhookDelivery{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-ic2-delivery", Namespace: "test-ns"},
+		Spec:       cicdv1.WebhookDeliverySpec{IntegrationConfig: "test-ic2", Body: "{}"},
+	}
+
+	tc := map[string]struct {
+		body string
+		vars map[string]string
+
+		expectedCode    int
+		expectedMessage string
+	}{
+		"normal": {
+			body:         `{"delivery_id": "test-ic-delivery"}`,
+			vars:         map[string]string{"namespace": "test-ns", "icName": "test-ic"},
+			expectedCode: http.StatusOK,
+		},
+		"malformedURL": {
+			body:            `{"delivery_id": "test-ic-delivery"}`,
+			vars:            map[string]string{"namespace": "test-ns"},
+			expectedCode:    http.StatusBadRequest,
+			expectedMessage: "url is malformed",
+		},
+		"noDeliveryID": {
+			body:            `{}`,
+			vars:            map[string]string{"namespace": "test-ns", "icName": "test-ic"},
+			expectedCode:    http.StatusBadRequest,
+			expectedMessage: "delivery_id must be set",
+		},
+		"noConfig": {
+			body:            `{"delivery_id": "test-ic-delivery"}`,
+			vars:            map[string]string{"namespace": "test-ns", "icName": "test-ic3"},
+			expectedCode:    http.StatusInternalServerError,
+			expectedMessage: "cannot get IntegrationConfig test-ns/test-ic3",
+		},
+		"noDelivery": {
+			body:            `{"delivery_id": "test-ic-delivery2"}`,
+			vars:            map[string]string{"namespace": "test-ns", "icName": "test-ic"},
+			expectedCode:    http.StatusNotFound,
+			expectedMessage: "cannot get WebhookDelivery test-ns/test-ic-delivery2",
+		},
+		"otherConfig": {
+			body:            `{"delivery_id": "test-ic2-delivery"}`,
+			vars:            map[string]string{"namespace": "test-ns", "icName": "test-ic"},
+			expectedCode:    http.StatusBadRequest,
+			expectedMessage: "WebhookDelivery test-ic2-delivery is not for IntegrationConfig test-ic",
+		},
+	}
+
+	for name, c := range tc {
+		t.Run(name, func(t *testing.T) {
+			fakeCli := fake.NewClientBuilder().WithScheme(s).WithObjects(ic, delivery.DeepCopy(), otherDelivery).Build()
+			h := &handler{log: &test.FakeLogger{}, k8sClient: fakeCli}
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, "/", bytes.NewBuffer([]byte(c.body)))
+			req = mux.SetURLVars(req, c.vars)
+			h.replayHandler(w, req)
+
+			require.Equal(t, c.expectedCode, w.Result().StatusCode)
+			if c.expectedCode != http.StatusOK {
+				require.Contains(t, w.Body.String(), c.expectedMessage)
+				return
+			}
+			status := &cicdv1.WebhookDeliveryStatus{}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), status))
+			require.Equal(t, 1, status.Replays)
+			require.NotNil(t, status.HandledTime)
+		})
+	}
+}
@@ -0,0 +1,98 @@
+/*
+ Copyright 2021 The CI/CD Operator Authors
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package integrationconfigs
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/require"
+	cicdv1 "github.com/tmax-cloud/cicd-operator/api/v1"
+	"github.com/tmax-cloud/cicd-operator/internal/test"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func Test_handler_rotateSecretHandler(t *testing.T) {
+	s := runtime.NewScheme()
+	require.NoError(t, cicdv1.AddToScheme(s))
+
+	tc := map[string]struct {
+		previousSecret string
+		vars           map[string]string
+
+		expectedCode    int
+		expectedMessage string
+	}{
+		"normal": {
+			vars:         map[string]string{"namespace": "test-ns", "icName": "test-ic"},
+			expectedCode: http.StatusOK,
+		},
+		"malformedURL": {
+			vars:            map[string]string{"namespace": "test-ns"},
+			expectedCode:    http.StatusBadRequest,
+			expectedMessage: "url is malformed",
+		},
+		"noConfig": {
+			vars:            map[string]string{"namespace": "test-ns", "icName": "test-ic2"},
+			expectedCode:    http.StatusInternalServerError,
+			expectedMessage: "cannot get IntegrationConfig test-ns/test-ic2",
+		},
+		"alreadyRotating": {
+			previousSecret:  "older-secret",
+			vars:            map[string]string{"namespace": "test-ns", "icName": "test-ic"},
+			expectedCode:    http.StatusConflict,
+			expectedMessage: "secret of IntegrationConfig test-ns/test-ic is already being rotated",
+		},
+	}
+
+	for name, c := range tc {
+		t.Run(name, func(t *testing.T) {
+			ic := &cicdv1.IntegrationConfig{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-ic", Namespace: "test-ns"},
+				Status:     cicdv1.IntegrationConfigStatus{Secrets: "old-secret", PreviousSecrets: c.previousSecret},
+			}
+			fakeCli := fake.NewClientBuilder().WithScheme(s).WithObjects(ic).Build()
+			h := &handler{log: &test.FakeLogger{}, k8sClient: fakeCli}
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, "/", nil)
+			req = mux.SetURLVars(req, c.vars)
+			h.rotateSecretHandler(w, req)
+
+			require.Equal(t, c.expectedCode, w.Result().StatusCode)
+			if c.expectedCode != http.StatusOK {
+				require.Contains(t, w.Body.String(), c.expectedMessage)
+				return
+			}
+			resp := &cicdv1.IntegrationConfigAPIReqWebhookURL{}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), resp))
+			require.NotEqual(t, "old-secret", resp.Secret)
+
+			stored := &cicdv1.IntegrationConfig{}
+			require.NoError(t, fakeCli.Get(context.Background(), types.NamespacedName{Name: "test-ic", Namespace: "test-ns"}, stored))
+			require.Equal(t, []string{resp.Secret, "old-secret"}, stored.GetWebhookSecrets())
+			require.NotNil(t, stored.Status.SecretRotationTime)
+		})
+	}
+}
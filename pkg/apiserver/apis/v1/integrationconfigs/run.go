@@ -116,7 +116,7 @@ func (h *handler) runHandler(w http.ResponseWriter, req *http.Request, et git.Ev
 	}
 
 	// Trigger Run!
-	if err := server.HandleEvent(wh, ic, "dispatcher"); err != nil {
+	if err := server.HandleEvent(req.Context(), wh, ic, "dispatcher"); err != nil {
 		log.Info(err.Error())
 		_ = utils.RespondError(w, http.StatusInternalServerError, fmt.Sprintf("req: %s, cannot handle event, err : %s", reqID, err.Error()))
 		return
@@ -356,7 +356,7 @@ func (t *testPlugin) Name() string {
 	return "dispatcher"
 }
 
-func (t *testPlugin) Handle(_ *git.Webhook, config *cicdv1.IntegrationConfig) error {
+func (t *testPlugin) Handle(_ context.Context, _ *git.Webhook, config *cicdv1.IntegrationConfig) error {
 	fmt.Println(config.Name)
 	if config.Name == "test-err" {
 		return fmt.Errorf("test-err returns error")
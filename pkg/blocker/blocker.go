@@ -194,7 +194,7 @@ type PullRequest struct {
 	// blockerCacheDirty specifies if the commit status should be updated
 	blockerCacheDirty bool
 
-	// Statuses stores whole commit statuses of the PR
+	// Statuses stores whole commit statuses (including check runs) of the PR
 	Statuses map[string]git.CommitStatus
 
 	// Commits are the list of commits in the PR
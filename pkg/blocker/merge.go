@@ -29,6 +29,7 @@ import (
 	"github.com/tmax-cloud/cicd-operator/internal/utils"
 	"github.com/tmax-cloud/cicd-operator/pkg/dispatcher"
 	"github.com/tmax-cloud/cicd-operator/pkg/git"
+	"github.com/tmax-cloud/cicd-operator/pkg/metrics"
 	"github.com/tmax-cloud/cicd-operator/pkg/pipelinemanager"
 	"k8s.io/apimachinery/pkg/types"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
@@ -133,8 +134,9 @@ func (b *blocker) retestAndMergeOnePool(pool *PRPool) {
 		// Retest it (create IJ)
 		log.Info(fmt.Sprintf("Batched tests - %+v", prIDs))
 		gitPRs := getGitPRsFromPRs(pool.CurrentBatch.PRs)
-		if err := b.createIntegrationJobForBatch(gitPRs, ic, &pool.CurrentBatch.Job); err != nil {
+		if err := b.createIntegrationJobForBatch(gitPRs, ic, gitCli, &pool.CurrentBatch.Job); err != nil {
 			log.Error(err, "Fail to create integrationJob for batch.")
+			pool.CurrentBatch = nil
 			return
 		}
 	}
@@ -170,7 +172,7 @@ func (b *blocker) handleBatch(pool *PRPool, ic *cicdv1.IntegrationConfig, gitCli
 			}
 		}
 		pool.CurrentBatch = nil
-	case cicdv1.IntegrationJobStateFailed:
+	case cicdv1.IntegrationJobStateFailed, cicdv1.IntegrationJobStateTimedOut:
 		// If batch test fails, test again with one less PR in the batch
 		// But if the length is 1 and fails...? Kick it out from the merge pool
 		if pool.CurrentBatch.Len() <= 1 {
@@ -178,8 +180,9 @@ func (b *blocker) handleBatch(pool *PRPool, ic *cicdv1.IntegrationConfig, gitCli
 		} else {
 			pool.CurrentBatch.PRs = pool.CurrentBatch.PRs[:len(pool.CurrentBatch.PRs)-1]
 			gitPRs := getGitPRsFromPRs(pool.CurrentBatch.PRs)
-			if err := b.createIntegrationJobForBatch(gitPRs, ic, &pool.CurrentBatch.Job); err != nil {
+			if err := b.createIntegrationJobForBatch(gitPRs, ic, gitCli, &pool.CurrentBatch.Job); err != nil {
 				log.Error(err, "Fail to create integrationJob for batch.")
+				pool.CurrentBatch = nil
 				return err
 			}
 		}
@@ -228,10 +231,19 @@ func getGitPRsFromPRs(prs []*PullRequest) []git.PullRequest {
 	return gitPRs
 }
 
-func (b *blocker) createIntegrationJobForBatch(prs []git.PullRequest, ic *cicdv1.IntegrationConfig, batchJob *types.NamespacedName) error {
+func (b *blocker) createIntegrationJobForBatch(prs []git.PullRequest, ic *cicdv1.IntegrationConfig, gitCli git.Client, batchJob *types.NamespacedName) error {
+	// The jobs are loaded for the oldest PR, which is also checked by checkBaseSHA
+	cfg, err := dispatcher.ReloadPullRequestJobs(ic, gitCli, &prs[0])
+	if err != nil {
+		return err
+	}
+
 	// The PRs in batch are assumed to have the same 'repo'.
 	dummy := git.User{Name: "tmax-cicd-bot", Email: "bot@cicd.tmax.io"}
-	ij := dispatcher.GeneratePreSubmit(prs, &git.Repository{Name: ic.Spec.Git.Repository, URL: prs[0].URL}, &dummy, ic)
+	ij := dispatcher.GeneratePreSubmit(prs, &git.Repository{Name: ic.Spec.Git.Repository, URL: prs[0].URL}, &dummy, cfg, nil)
+	if ij == nil {
+		return fmt.Errorf("there is no job to test the batch")
+	}
 	*batchJob = types.NamespacedName{Name: ij.Name, Namespace: ij.Namespace}
 	if err := b.client.Create(context.Background(), ij); err != nil {
 		log.Error(err, "")
@@ -266,8 +278,10 @@ func (b *blocker) mergePullRequest(pr *PullRequest, ic *cicdv1.IntegrationConfig
 		commitMsg = buf.String()
 	}
 	if err := gitCli.MergePullRequest(pr.ID, pr.Head.Sha, getMergeMethod(pr, ic), commitMsg); err != nil {
+		metrics.BlockerMerges.WithLabelValues(metrics.ResultFailure).Inc()
 		return err
 	}
+	metrics.BlockerMerges.WithLabelValues(metrics.ResultSuccess).Inc()
 	return nil
 }
 
@@ -298,8 +312,14 @@ func checkBaseSHA(baseBranch string, ic *cicdv1.IntegrationConfig, pr *PullReque
 	}
 	latest := branch.CommitID
 
-	jobs := dispatcher.FilterJobs(ic.Spec.Jobs.PreSubmit, git.EventTypePullRequest, pr.Base.Ref, ic.Spec.When)
-	for _, j := range jobs {
+	cfg, err := dispatcher.ReloadPullRequestJobs(ic, gitCli, &pr.PullRequest)
+	if err != nil {
+		return false, err
+	}
+
+	// Commit statuses are set for each job expanded from the matrix jobs
+	jobs := cicdv1.Jobs(dispatcher.FilterJobs(cfg.Spec.Jobs.PreSubmit, dispatcher.PullRequestEvent(&pr.PullRequest), pr.Base.Ref, cfg.Spec.When, nil))
+	for _, j := range jobs.ExpandMatrix() {
 		status, exist := pr.Statuses[j.Name]
 		// The status will be there... but if not, it should've been filtered from sync_status
 		if !exist {
@@ -65,8 +65,11 @@ func TestBlocker_retestAndMergeOnePool(t *testing.T) {
 		baseSHA       string
 		existingBatch *Batch
 		existingJob   *cicdv1.IntegrationJob
+		jobsFrom      *cicdv1.JobsFrom
+		fileContents  map[string][]byte
 
 		expectedIJRefPulls   []cicdv1.IntegrationJobRefsPull
+		expectedIJJobs       []string
 		expectedBatchCreated bool
 		expectedPRMerged     bool
 	}{
@@ -215,6 +218,58 @@ func TestBlocker_retestAndMergeOnePool(t *testing.T) {
 			},
 			expectedPRMerged: true,
 		},
+		"jobsFromReplace": {
+			baseSHA: "32cd89e8d07e37ab26d8c735090ae763884283db",
+			prs: []*PullRequest{
+				{
+					PullRequest: git.PullRequest{
+						ID:        12,
+						Author:    git.User{Name: "maintainer"},
+						Base:      git.Base{Ref: "master", Sha: "22ccae53032027186ba739dfaa473ee61a82b298"},
+						Head:      git.Head{Ref: "newnew", Sha: "3196ccc37bcae94852079b04fcbfaf928341d6e9"},
+						Mergeable: true,
+						State:     git.PullRequestStateOpen,
+					},
+					BlockerStatus: git.CommitStatusStateSuccess,
+					Statuses: map[string]git.CommitStatus{
+						"lint": {Context: "lint", State: git.CommitStatusStateSuccess, Description: "Job is successful    BaseSHA:22ccae53032027186ba739dfaa473ee61a82b298"},
+					},
+				},
+			},
+			jobsFrom: &cicdv1.JobsFrom{Path: ".cicd/jobs.yaml", Mode: cicdv1.JobsFromModeReplace},
+			fileContents: map[string][]byte{
+				"3196ccc37bcae94852079b04fcbfaf928341d6e9:.cicd/jobs.yaml": []byte("preSubmit:\n- name: lint\n  image: alpine\n"),
+			},
+			expectedIJRefPulls: []cicdv1.IntegrationJobRefsPull{
+				{ID: 12, Ref: "newnew", Sha: "3196ccc37bcae94852079b04fcbfaf928341d6e9", Author: cicdv1.IntegrationJobRefsPullAuthor{Name: "maintainer"}},
+			},
+			expectedIJJobs:       []string{"lint"},
+			expectedBatchCreated: true,
+		},
+		"jobsFromNoJobs": {
+			baseSHA: "32cd89e8d07e37ab26d8c735090ae763884283db",
+			prs: []*PullRequest{
+				{
+					PullRequest: git.PullRequest{
+						ID:        12,
+						Author:    git.User{Name: "maintainer"},
+						Base:      git.Base{Ref: "master", Sha: "22ccae53032027186ba739dfaa473ee61a82b298"},
+						Head:      git.Head{Ref: "newnew", Sha: "3196ccc37bcae94852079b04fcbfaf928341d6e9"},
+						Mergeable: true,
+						State:     git.PullRequestStateOpen,
+					},
+					BlockerStatus: git.CommitStatusStateSuccess,
+					Statuses: map[string]git.CommitStatus{
+						"lint": {Context: "lint", State: git.CommitStatusStateSuccess, Description: "Job is successful    BaseSHA:22ccae53032027186ba739dfaa473ee61a82b298"},
+					},
+				},
+			},
+			jobsFrom: &cicdv1.JobsFrom{Path: ".cicd/jobs.yaml", Mode: cicdv1.JobsFromModeReplace},
+			fileContents: map[string][]byte{
+				"3196ccc37bcae94852079b04fcbfaf928341d6e9:.cicd/jobs.yaml": []byte("preSubmit:\n- name: lint\n  image: alpine\n  when:\n    branch:\n    - release\n"),
+			},
+			expectedPRMerged: true,
+		},
 	}
 
 	for name, c := range tc {
@@ -223,7 +278,16 @@ func TestBlocker_retestAndMergeOnePool(t *testing.T) {
 			ic, cli := mergeTestConfig()
 			b := New(cli)
 			gitfake.Repos = map[string]*gitfake.Repo{
-				ic.Spec.Git.Repository: {PullRequests: map[int]*git.PullRequest{}, Commits: map[string][]git.Commit{}},
+				ic.Spec.Git.Repository: {
+					PullRequests: map[int]*git.PullRequest{},
+					Commits:      map[string][]git.Commit{},
+					FileContents: c.fileContents,
+					UserCanWrite: map[string]bool{"maintainer": true},
+				},
+			}
+			if c.jobsFrom != nil {
+				ic.Spec.JobsFrom = c.jobsFrom
+				require.NoError(t, cli.Update(context.Background(), ic))
 			}
 			gitfake.Branches = map[string]*git.Branch{
 				"master": {CommitID: c.baseSHA},
@@ -256,6 +320,13 @@ func TestBlocker_retestAndMergeOnePool(t *testing.T) {
 			} else {
 				require.Len(t, ijList.Items, 1)
 				require.Equal(t, c.expectedIJRefPulls, ijList.Items[0].Spec.Refs.Pulls, "IntegrationJobs")
+				if c.expectedIJJobs != nil {
+					var names []string
+					for _, j := range ijList.Items[0].Spec.Jobs {
+						names = append(names, j.Name)
+					}
+					require.Equal(t, c.expectedIJJobs, names)
+				}
 			}
 
 			if c.expectedBatchCreated {
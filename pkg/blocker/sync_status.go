@@ -22,6 +22,7 @@ import (
 	cicdv1 "github.com/tmax-cloud/cicd-operator/api/v1"
 	"github.com/tmax-cloud/cicd-operator/internal/utils"
 	"github.com/tmax-cloud/cicd-operator/pkg/git"
+	"github.com/tmax-cloud/cicd-operator/pkg/metrics"
 )
 
 // sync_pool.go includes methods for synchronizing PR's commit status/merge conflicts status
@@ -64,6 +65,9 @@ func (b *blocker) syncMergePoolStatus() {
 		b.reportCommitStatus(pool, ic, gitCli)
 	}
 
+	// Report pool sizes
+	b.reportPoolMetrics()
+
 	// Notify that a sync is done
 	if len(b.statusSynced) < cap(b.statusSynced) {
 		b.statusSynced <- struct{}{}
@@ -81,7 +85,7 @@ func (b *blocker) syncOneMergePoolStatus(pool *PRPool, ic *cicdv1.IntegrationCon
 		// For each PR
 		for prID, pr := range pool.MergePool[oldStatus] {
 			// Fetch PR's status, commit statuses
-			if err := b.reflectPRStatus(pr, gitCli); err != nil {
+			if err := b.reflectPRStatus(pr, ic, gitCli); err != nil {
 				log.Error(err, "")
 				continue
 			}
@@ -122,7 +126,7 @@ func (b *blocker) syncOneMergePoolStatus(pool *PRPool, ic *cicdv1.IntegrationCon
 	}
 }
 
-func (b *blocker) reflectPRStatus(pull *PullRequest, gitCli git.Client) error {
+func (b *blocker) reflectPRStatus(pull *PullRequest, ic *cicdv1.IntegrationConfig, gitCli git.Client) error {
 	// GET PullRequest
 	pr, err := gitCli.GetPullRequest(pull.ID)
 	if err != nil {
@@ -139,6 +143,29 @@ func (b *blocker) reflectPRStatus(pull *PullRequest, gitCli git.Client) error {
 	for _, c := range checksSlice {
 		pull.Statuses[c.Context] = c
 	}
+
+	// GET PR check runs, if the git server supports them
+	checkRunCli, ok := gitCli.(git.CheckRunClient)
+	if !ok {
+		return nil
+	}
+	checkRuns, err := checkRunCli.ListCheckRuns(pr.Head.Sha)
+	if err != nil {
+		// Check runs are required only if the jobs are reported as check runs. Otherwise, the token may not have the
+		// permission to read them
+		if ic.Spec.Git.UseChecks {
+			return err
+		}
+		b.log.WithName("status").Info("cannot list check runs", "repo", genPoolKey(ic), "id", pr.ID, "error", err.Error())
+		return nil
+	}
+	for _, c := range checkRuns {
+		// Check runs do not replace the commit statuses of the same name
+		if _, exist := pull.Statuses[c.Name]; exist {
+			continue
+		}
+		pull.Statuses[c.Name] = c.ToCommitStatus()
+	}
 	return nil
 }
 
@@ -162,3 +189,17 @@ func (b *blocker) reportCommitStatus(pool *PRPool, ic *cicdv1.IntegrationConfig,
 		}
 	}
 }
+
+// reportPoolMetrics sets the number of pull requests of each pool and its merge pool to the metrics
+func (b *blocker) reportPoolMetrics() {
+	metrics.BlockerPullRequests.Reset()
+	metrics.BlockerMergePoolPullRequests.Reset()
+	for key, pool := range b.Pools {
+		pool.lock.Lock()
+		metrics.BlockerPullRequests.WithLabelValues(string(key)).Set(float64(len(pool.PullRequests)))
+		for state, prs := range pool.MergePool {
+			metrics.BlockerMergePoolPullRequests.WithLabelValues(string(key), string(state)).Set(float64(len(prs)))
+		}
+		pool.lock.Unlock()
+	}
+}
@@ -90,6 +90,38 @@ func TestBlocker_syncMergePoolStatus(t *testing.T) {
 	assert.Equal(t, 0, len(pool.MergePool[git.CommitStatusStatePending]), "Pending length")
 	assert.Equal(t, 1, len(pool.MergePool[git.CommitStatusStateSuccess]), "Success length")
 	assert.Equal(t, "In merge pool.", pool.PullRequests[25].BlockerDescription, "Blocker status description")
+
+	// Test 4 - check runs
+	gitfake.Repos[testRepo].CommitStatuses[testSHA] = []git.CommitStatus{}
+	gitfake.Repos[testRepo].CheckRuns = map[string][]git.CheckRun{
+		testSHA: {{Name: "test-unit", Status: git.CheckRunStatusInProgress}},
+	}
+	pool.MergePool[git.CommitStatusStatePending][testPRID] = pr
+	delete(pool.MergePool[git.CommitStatusStateSuccess], testPRID)
+	blocker.syncMergePoolStatus()
+	assert.Equal(t, 1, len(pool.MergePool[git.CommitStatusStatePending]), "Pending length")
+	assert.Equal(t, 0, len(pool.MergePool[git.CommitStatusStateSuccess]), "Success length")
+	assert.Equal(t, "Checks [test-unit] are not successful.", pool.PullRequests[25].BlockerDescription, "Blocker status description")
+
+	gitfake.Repos[testRepo].CheckRuns[testSHA] = []git.CheckRun{{Name: "test-unit", Status: git.CheckRunStatusCompleted, Conclusion: git.CheckRunConclusionSuccess}}
+	blocker.syncMergePoolStatus()
+	assert.Equal(t, 0, len(pool.MergePool[git.CommitStatusStatePending]), "Pending length")
+	assert.Equal(t, 1, len(pool.MergePool[git.CommitStatusStateSuccess]), "Success length")
+	assert.Equal(t, "In merge pool.", pool.PullRequests[25].BlockerDescription, "Blocker status description")
+
+	// Test 5 - check runs do not replace commit statuses
+	gitfake.Repos[testRepo].CommitStatuses[testSHA] = []git.CommitStatus{{Context: "test-unit", State: "success"}}
+	gitfake.Repos[testRepo].CheckRuns[testSHA] = []git.CheckRun{{Name: "test-unit", Status: git.CheckRunStatusCompleted, Conclusion: git.CheckRunConclusionFailure}}
+	blocker.syncMergePoolStatus()
+	assert.Equal(t, 0, len(pool.MergePool[git.CommitStatusStatePending]), "Pending length")
+	assert.Equal(t, 1, len(pool.MergePool[git.CommitStatusStateSuccess]), "Success length")
+	assert.Equal(t, "In merge pool.", pool.PullRequests[25].BlockerDescription, "Blocker status description")
+
+	// Test 6 - check runs cannot be listed
+	gitfake.Repos[testRepo].CheckRuns = nil
+	blocker.syncMergePoolStatus()
+	assert.Equal(t, 1, len(pool.MergePool[git.CommitStatusStateSuccess]), "Success length")
+	assert.Equal(t, "In merge pool.", pool.PullRequests[25].BlockerDescription, "Blocker status description")
 }
 
 func syncStatusTestEnv() (client.Client, *cicdv1.IntegrationConfig) {
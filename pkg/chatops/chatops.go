@@ -17,6 +17,7 @@
 package chatops
 
 import (
+	"context"
 	"strings"
 
 	cicdv1 "github.com/tmax-cloud/cicd-operator/api/v1"
@@ -46,7 +47,7 @@ func (c *chatOps) Name() string {
 }
 
 // Handle actually handles the webhook payload to create IntegrationJob
-func (c *chatOps) Handle(webhook *git.Webhook, config *cicdv1.IntegrationConfig) error {
+func (c *chatOps) Handle(_ context.Context, webhook *git.Webhook, config *cicdv1.IntegrationConfig) error {
 	issueComment := webhook.IssueComment
 	if issueComment == nil {
 		return nil
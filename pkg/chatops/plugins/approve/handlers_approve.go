@@ -17,6 +17,7 @@
 package approve
 
 import (
+	"context"
 	"fmt"
 	"sort"
 	"strings"
@@ -50,7 +51,7 @@ func (h *Handler) Name() string {
 }
 
 // Handle handles a raw webhook
-func (h *Handler) Handle(wh *git.Webhook, ic *cicdv1.IntegrationConfig) error {
+func (h *Handler) Handle(_ context.Context, wh *git.Webhook, ic *cicdv1.IntegrationConfig) error {
 	// Skip if token is empty
 	if ic.Spec.Git.Token == nil {
 		return nil
@@ -17,6 +17,7 @@
 package approve
 
 import (
+	"context"
 	"os"
 	"testing"
 	"time"
@@ -117,7 +118,7 @@ func TestHandler_Handle(t *testing.T) {
 			wh := buildTestWebhookApprove()
 			c.preFunc(wh)
 
-			err := handler.Handle(wh, ic)
+			err := handler.Handle(context.Background(), wh, ic)
 			require.NoError(t, err)
 			c.verifyFunc(t)
 		})
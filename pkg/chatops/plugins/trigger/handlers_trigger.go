@@ -69,16 +69,9 @@ func (h *Handler) HandleChatOps(command chatops.Command, webhook *git.Webhook, c
 
 // handleTestCommand handles '/test <ARGS>' command
 func (h *Handler) handleTestCommand(command chatops.Command, webhook *git.Webhook, config *cicdv1.IntegrationConfig) error {
-	var job *cicdv1.IntegrationJob
-	// Generate IntegrationJob for the PullRequest
-	if webhook.IssueComment.Issue.PullRequest != nil {
-		prs := []git.PullRequest{*webhook.IssueComment.Issue.PullRequest}
-		job = dispatcher.GeneratePreSubmit(prs, &webhook.Repo, &webhook.Sender, config)
-	} else {
-		push := &git.Push{
-			Sha: webhook.IssueComment.Issue.CommitID,
-		}
-		job = dispatcher.GeneratePostSubmit(push, &webhook.Repo, &webhook.Sender, config)
+	job, err := h.generateIntegrationJob(webhook, config)
+	if err != nil {
+		return err
 	}
 
 	if job == nil {
@@ -104,16 +97,9 @@ func (h *Handler) handleTestCommand(command chatops.Command, webhook *git.Webhoo
 
 // handleTestCommand handles '/retest' command
 func (h *Handler) handleRetestCommand(webhook *git.Webhook, config *cicdv1.IntegrationConfig) error {
-	var job *cicdv1.IntegrationJob
-	// Generate IntegrationJob for the PullRequest
-	if webhook.IssueComment.Issue.PullRequest != nil {
-		prs := []git.PullRequest{*webhook.IssueComment.Issue.PullRequest}
-		job = dispatcher.GeneratePreSubmit(prs, &webhook.Repo, &webhook.Sender, config)
-	} else {
-		push := &git.Push{
-			Sha: webhook.IssueComment.Issue.CommitID,
-		}
-		job = dispatcher.GeneratePostSubmit(push, &webhook.Repo, &webhook.Sender, config)
+	job, err := h.generateIntegrationJob(webhook, config)
+	if err != nil {
+		return err
 	}
 	if job == nil {
 		return nil
@@ -127,6 +113,27 @@ func (h *Handler) handleRetestCommand(webhook *git.Webhook, config *cicdv1.Integ
 	return nil
 }
 
+// generateIntegrationJob generates IntegrationJob for the PullRequest or the commit
+func (h *Handler) generateIntegrationJob(webhook *git.Webhook, config *cicdv1.IntegrationConfig) (*cicdv1.IntegrationJob, error) {
+	if webhook.IssueComment.Issue.PullRequest != nil {
+		pr := webhook.IssueComment.Issue.PullRequest
+		cfg, err := dispatcher.LoadPullRequestJobs(config, h.Client, pr)
+		if err != nil {
+			return nil, err
+		}
+		return dispatcher.GeneratePreSubmit([]git.PullRequest{*pr}, &webhook.Repo, &webhook.Sender, cfg, dispatcher.ListPullRequestChangedFiles(cfg, h.Client, pr)), nil
+	}
+
+	push := &git.Push{
+		Sha: webhook.IssueComment.Issue.CommitID,
+	}
+	cfg, err := dispatcher.LoadPushJobs(config, h.Client, push)
+	if err != nil {
+		return nil, err
+	}
+	return dispatcher.GeneratePostSubmit(push, &webhook.Repo, &webhook.Sender, cfg, nil), nil
+}
+
 // authorize decides if the sender is authorized to trigger the tests
 func (h *Handler) authorize(cfg *cicdv1.IntegrationConfig, sender *git.User, issueComment *git.IssueComment) error {
 	// Check if it's PR's author
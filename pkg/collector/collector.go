@@ -121,6 +121,30 @@ func (c *collector) collect() {
 			}
 		}
 	}
+
+	c.collectDeliveries(now)
+}
+
+// collectDeliveries collects the WebhookDeliveries whose ttl is over
+func (c *collector) collectDeliveries(now time.Time) {
+	deliveryList := &cicdv1.WebhookDeliveryList{}
+	if err := c.client.List(context.Background(), deliveryList); err != nil {
+		if _, ok := err.(*cache.ErrCacheNotStarted); !ok {
+			log.Error(err, "")
+		}
+		return
+	}
+
+	for i := range deliveryList.Items {
+		d := &deliveryList.Items[i]
+		if !d.Expired(now) {
+			continue
+		}
+		log.Info(fmt.Sprintf("Deleting WebhookDelivery %s/%s", d.Namespace, d.Name))
+		if err := c.client.Delete(context.Background(), d); err != nil {
+			log.Error(err, "")
+		}
+	}
 }
 
 func parseGcPeriod() string {
@@ -0,0 +1,78 @@
+/*
+ Copyright 2021 The CI/CD Operator Authors
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package dispatcher
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	cicdv1 "github.com/tmax-cloud/cicd-operator/api/v1"
+	"github.com/tmax-cloud/cicd-operator/internal/configs"
+	"github.com/tmax-cloud/cicd-operator/pkg/git"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// FindDuplicateJob returns the IntegrationJob of the same config, type and event for the same commits and base ref as
+// the job, created within the dedup window. Canceled, failed or timed out jobs are not duplicates, as the job should
+// run again for them. It returns nil if there is no such job, or the dedup is disabled.
+// Manually triggered jobs (i.e., for the fake sha) are never duplicated. Chat-ops commands (e.g., /retest) create
+// IntegrationJobs without the dispatcher, so they are not deduplicated either
+func FindDuplicateJob(k8sClient client.Client, job *cicdv1.IntegrationJob) (*cicdv1.IntegrationJob, error) {
+	window := time.Duration(configs.WebhookDedupWindow) * time.Minute
+	key := commitsKey(job)
+	if window <= 0 || key == "" {
+		return nil, nil
+	}
+
+	jobs := &cicdv1.IntegrationJobList{}
+	if err := k8sClient.List(context.Background(), jobs, client.InNamespace(job.Namespace), client.MatchingLabels{cicdv1.JobLabelConfig: job.Spec.ConfigRef.Name}); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	for i := range jobs.Items {
+		j := &jobs.Items[i]
+		if j.Spec.ConfigRef.Type != job.Spec.ConfigRef.Type || j.Labels[cicdv1.JobLabelEvent] != job.Labels[cicdv1.JobLabelEvent] || now.Sub(j.CreationTimestamp.Time) > window {
+			continue
+		}
+		if j.Status.State == cicdv1.IntegrationJobStateCanceled || j.Status.State == cicdv1.IntegrationJobStateFailed || j.Status.State == cicdv1.IntegrationJobStateTimedOut {
+			continue
+		}
+		if commitsKey(j) == key {
+			return j, nil
+		}
+	}
+	return nil, nil
+}
+
+// commitsKey is a key of the base ref and the set of the commits the job runs for. It is empty if any of the commits is
+// fake
+func commitsKey(job *cicdv1.IntegrationJob) string {
+	shas := []string{job.Spec.Refs.Base.Sha}
+	for _, p := range job.Spec.Refs.Pulls {
+		shas = append(shas, p.Sha)
+	}
+	for _, sha := range shas {
+		if sha == git.FakeSha {
+			return ""
+		}
+	}
+	sort.Strings(shas)
+	return string(job.Spec.Refs.Base.Ref) + ":" + strings.Join(shas, ",")
+}
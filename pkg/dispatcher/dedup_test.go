@@ -0,0 +1,179 @@
+/*
+ Copyright 2021 The CI/CD Operator Authors
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package dispatcher
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	cicdv1 "github.com/tmax-cloud/cicd-operator/api/v1"
+	"github.com/tmax-cloud/cicd-operator/internal/configs"
+	"github.com/tmax-cloud/cicd-operator/pkg/git"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestFindDuplicateJob(t *testing.T) {
+	s := runtime.NewScheme()
+	utilruntime.Must(cicdv1.AddToScheme(s))
+
+	newJob := func(name string, jobType cicdv1.JobType, age time.Duration, base string, pulls ...string) *cicdv1.IntegrationJob {
+		job := &cicdv1.IntegrationJob{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              name,
+				Namespace:         "default",
+				Labels:            map[string]string{cicdv1.JobLabelConfig: "test-config"},
+				CreationTimestamp: metav1.Time{Time: time.Now().Add(-age)},
+			},
+			Spec: cicdv1.IntegrationJobSpec{
+				ConfigRef: cicdv1.IntegrationJobConfigRef{Name: "test-config", Type: jobType},
+				Refs:      cicdv1.IntegrationJobRefs{Base: cicdv1.IntegrationJobRefsBase{Ref: "refs/heads/master", Sha: base}},
+			},
+		}
+		for _, p := range pulls {
+			job.Spec.Refs.Pulls = append(job.Spec.Refs.Pulls, cicdv1.IntegrationJobRefsPull{Sha: p})
+		}
+		return job
+	}
+
+	tc := map[string]struct {
+		window   int
+		existing *cicdv1.IntegrationJob
+		job      *cicdv1.IntegrationJob
+
+		expectedDuplicate string
+	}{
+		"pushDuplicated": {
+			window:            60,
+			existing:          newJob("existing", cicdv1.JobTypePostSubmit, time.Minute, "sha-1"),
+			job:               newJob("new", cicdv1.JobTypePostSubmit, 0, "sha-1"),
+			expectedDuplicate: "existing",
+		},
+		"pullRequestDuplicated": {
+			window:            60,
+			existing:          newJob("existing", cicdv1.JobTypePreSubmit, time.Minute, "base", "sha-1", "sha-2"),
+			job:               newJob("new", cicdv1.JobTypePreSubmit, 0, "base", "sha-2", "sha-1"),
+			expectedDuplicate: "existing",
+		},
+		"differentCommits": {
+			window:   60,
+			existing: newJob("existing", cicdv1.JobTypePreSubmit, time.Minute, "base", "sha-1"),
+			job:      newJob("new", cicdv1.JobTypePreSubmit, 0, "base", "sha-2"),
+		},
+		"differentType": {
+			window:   60,
+			existing: newJob("existing", cicdv1.JobTypePostSubmit, time.Minute, "sha-1"),
+			job:      newJob("new", cicdv1.JobTypePreSubmit, 0, "sha-1"),
+		},
+		"differentEvent": {
+			window: 60,
+			existing: func() *cicdv1.IntegrationJob {
+				job := newJob("existing", cicdv1.JobTypePostSubmit, time.Minute, "sha-1")
+				job.Labels[cicdv1.JobLabelEvent] = string(cicdv1.JobEventTag)
+				return job
+			}(),
+			job: func() *cicdv1.IntegrationJob {
+				job := newJob("new", cicdv1.JobTypePostSubmit, 0, "sha-1")
+				job.Labels[cicdv1.JobLabelEvent] = string(cicdv1.JobEventRelease)
+				return job
+			}(),
+		},
+		"differentBaseRef": {
+			window:   60,
+			existing: newJob("existing", cicdv1.JobTypePostSubmit, time.Minute, "sha-1"),
+			job: func() *cicdv1.IntegrationJob {
+				job := newJob("new", cicdv1.JobTypePostSubmit, 0, "sha-1")
+				job.Spec.Refs.Base.Ref = "refs/heads/release-1.0"
+				return job
+			}(),
+		},
+		"canceled": {
+			window: 60,
+			existing: func() *cicdv1.IntegrationJob {
+				job := newJob("existing", cicdv1.JobTypePreSubmit, time.Minute, "base", "sha-1")
+				job.Status.State = cicdv1.IntegrationJobStateCanceled
+				return job
+			}(),
+			job: newJob("new", cicdv1.JobTypePreSubmit, 0, "base", "sha-1"),
+		},
+		"failed": {
+			window: 60,
+			existing: func() *cicdv1.IntegrationJob {
+				job := newJob("existing", cicdv1.JobTypePostSubmit, time.Minute, "sha-1")
+				job.Status.State = cicdv1.IntegrationJobStateFailed
+				return job
+			}(),
+			job: newJob("new", cicdv1.JobTypePostSubmit, 0, "sha-1"),
+		},
+		"timedOut": {
+			window: 60,
+			existing: func() *cicdv1.IntegrationJob {
+				job := newJob("existing", cicdv1.JobTypePostSubmit, time.Minute, "sha-1")
+				job.Status.State = cicdv1.IntegrationJobStateTimedOut
+				return job
+			}(),
+			job: newJob("new", cicdv1.JobTypePostSubmit, 0, "sha-1"),
+		},
+		"running": {
+			window: 60,
+			existing: func() *cicdv1.IntegrationJob {
+				job := newJob("existing", cicdv1.JobTypePostSubmit, time.Minute, "sha-1")
+				job.Status.State = cicdv1.IntegrationJobStateRunning
+				return job
+			}(),
+			job:               newJob("new", cicdv1.JobTypePostSubmit, 0, "sha-1"),
+			expectedDuplicate: "existing",
+		},
+		"outOfWindow": {
+			window:   60,
+			existing: newJob("existing", cicdv1.JobTypePostSubmit, 2*time.Hour, "sha-1"),
+			job:      newJob("new", cicdv1.JobTypePostSubmit, 0, "sha-1"),
+		},
+		"fakeSha": {
+			window:   60,
+			existing: newJob("existing", cicdv1.JobTypePostSubmit, time.Minute, git.FakeSha),
+			job:      newJob("new", cicdv1.JobTypePostSubmit, 0, git.FakeSha),
+		},
+		"disabled": {
+			window:   0,
+			existing: newJob("existing", cicdv1.JobTypePostSubmit, time.Minute, "sha-1"),
+			job:      newJob("new", cicdv1.JobTypePostSubmit, 0, "sha-1"),
+		},
+	}
+
+	for name, c := range tc {
+		t.Run(name, func(t *testing.T) {
+			configs.WebhookDedupWindow = c.window
+			defer func() {
+				configs.WebhookDedupWindow = 0
+			}()
+			fakeCli := fake.NewClientBuilder().WithScheme(s).WithObjects(c.existing).Build()
+
+			dup, err := FindDuplicateJob(fakeCli, c.job)
+			require.NoError(t, err)
+			if c.expectedDuplicate == "" {
+				require.Nil(t, dup)
+			} else {
+				require.NotNil(t, dup)
+				require.Equal(t, c.expectedDuplicate, dup.Name)
+			}
+		})
+	}
+}
@@ -19,15 +19,28 @@ package dispatcher
 import (
 	"context"
 	"fmt"
+	"regexp"
+	"strconv"
 	"strings"
+	"sync"
 
 	cicdv1 "github.com/tmax-cloud/cicd-operator/api/v1"
 	"github.com/tmax-cloud/cicd-operator/internal/utils"
 	"github.com/tmax-cloud/cicd-operator/pkg/git"
+	"github.com/tmax-cloud/cicd-operator/pkg/tracing"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
+// priorityLabelPrefix is a prefix of the pull request labels overriding the priority (e.g., priority/100)
+const priorityLabelPrefix = "priority/"
+
+// commitBranchKeyword is a special branch name of JobWhen, to run the job for commit comment events
+const commitBranchKeyword = "[commit]"
+
+// patternCache caches compiled regular expressions of JobWhen (*regexp.Regexp, or error if invalid)
+var patternCache sync.Map
+
 // Dispatcher dispatches IntegrationJob when webhook is called
 // A kind of 'plugin' for webhook handler
 type Dispatcher struct {
@@ -39,25 +52,72 @@ func (d *Dispatcher) Name() string {
 	return "dispatcher"
 }
 
-// Handle handles pull-request and push events
-func (d Dispatcher) Handle(webhook *git.Webhook, config *cicdv1.IntegrationConfig) error {
+// Handle handles pull-request, push, tag create and release events
+// The IntegrationJob carries the trace context of ctx in its annotations, so its trace is continued by the controller
+func (d Dispatcher) Handle(ctx context.Context, webhook *git.Webhook, config *cicdv1.IntegrationConfig) (err error) {
+	ctx, span := tracing.Start(ctx, "dispatcher.handle", tracing.AttributeNamespace.String(config.Namespace),
+		tracing.AttributeIntegrationConfig.String(config.Name), tracing.AttributeGitEvent.String(string(webhook.EventType)))
+	defer func() {
+		tracing.End(span, err)
+	}()
+
 	var job *cicdv1.IntegrationJob
 	pr := webhook.PullRequest
 	push := webhook.Push
-	if pr == nil && push == nil {
-		return fmt.Errorf("pull request and push struct is nil")
+	release := webhook.Release
+	if pr == nil && push == nil && release == nil {
+		return fmt.Errorf("pull request, push and release struct is nil")
 	}
 
 	if webhook.EventType == git.EventTypePullRequest && pr != nil {
-		if pr.Action == git.PullRequestActionOpen || pr.Action == git.PullRequestActionSynchronize || pr.Action == git.PullRequestActionReOpen {
+		switch pr.Action {
+		case git.PullRequestActionOpen, git.PullRequestActionSynchronize, git.PullRequestActionReOpen, git.PullRequestActionReadyForReview:
+			if pr.Action == git.PullRequestActionSynchronize && config.Spec.IJManageSpec.CancelSuperseded {
+				if err := CancelSupersededJobs(d.Client, config, pr); err != nil {
+					log.Error(err, "cannot cancel superseded jobs")
+				}
+			}
+			cfg, err := LoadPullRequestJobs(config, d.Client, pr)
+			if err != nil {
+				return err
+			}
 			prs := []git.PullRequest{*pr}
-			job = GeneratePreSubmit(prs, &webhook.Repo, &webhook.Sender, config)
+			job = GeneratePreSubmit(prs, &webhook.Repo, &webhook.Sender, cfg, ListPullRequestChangedFiles(cfg, d.Client, pr))
+		case git.PullRequestActionMerged:
+			cfg, err := LoadPushJobs(config, d.Client, mergedPush(pr))
+			if err != nil {
+				return err
+			}
+			job = GenerateMergedPostSubmit(pr, &webhook.Repo, &webhook.Sender, cfg, ListPullRequestChangedFiles(cfg, d.Client, pr))
+		}
+	} else if (webhook.EventType == git.EventTypePush || webhook.EventType == git.EventTypeTagCreate) && push != nil {
+		cfg, err := LoadPushJobs(config, d.Client, push)
+		if err != nil {
+			return err
 		}
-	} else if webhook.EventType == git.EventTypePush && push != nil {
-		job = GeneratePostSubmit(push, &webhook.Repo, &webhook.Sender, config)
+		job = GeneratePostSubmit(push, &webhook.Repo, &webhook.Sender, cfg, ListPushChangedFiles(cfg, d.Client, push))
+	} else if webhook.EventType == git.EventTypeRelease && release != nil {
+		cfg, err := LoadPushJobs(config, d.Client, releasePush(release))
+		if err != nil {
+			return err
+		}
+		job = GenerateReleasePostSubmit(release, &webhook.Repo, &webhook.Sender, cfg)
 	}
 
 	if job == nil {
+		span.AddEvent("No job is dispatched")
+		return nil
+	}
+	span.SetAttributes(tracing.AttributeIntegrationJob.String(job.Name))
+
+	// Git servers may deliver the same event more than once, which should not run the jobs again
+	dup, err := FindDuplicateJob(d.Client, job)
+	if err != nil {
+		return err
+	}
+	if dup != nil {
+		span.AddEvent("Duplicated job is not dispatched")
+		log.Info(fmt.Sprintf("IntegrationJob %s/%s is not dispatched, as it duplicates %s", job.Namespace, job.Name, dup.Name))
 		return nil
 	}
 
@@ -72,6 +132,8 @@ func (d Dispatcher) Handle(webhook *git.Webhook, config *cicdv1.IntegrationConfi
 		job.ObjectMeta.Annotations = ann
 	}
 
+	tracing.Inject(ctx, job)
+
 	if err := d.Client.Create(context.Background(), job); err != nil {
 		return err
 	}
@@ -80,8 +142,10 @@ func (d Dispatcher) Handle(webhook *git.Webhook, config *cicdv1.IntegrationConfi
 }
 
 // GeneratePreSubmit generates IntegrationJob for pull request event
-func GeneratePreSubmit(prs []git.PullRequest, repo *git.Repository, sender *git.User, config *cicdv1.IntegrationConfig) *cicdv1.IntegrationJob {
-	filteredJobs := FilterJobs(config.Spec.Jobs.PreSubmit, git.EventTypePullRequest, prs[0].Base.Ref, config.Spec.When)
+// changedFiles are used to filter jobs by their paths. Path filters are ignored if it is nil
+func GeneratePreSubmit(prs []git.PullRequest, repo *git.Repository, sender *git.User, config *cicdv1.IntegrationConfig, changedFiles []string) *cicdv1.IntegrationJob {
+	event := PullRequestEvent(&prs[0])
+	filteredJobs := FilterJobs(config.Spec.Jobs.PreSubmit, event, prs[0].Base.Ref, config.Spec.When, changedFiles)
 	jobs := applyNotification(filteredJobs, config.Spec.GolbalNotification)
 	if len(jobs) < 1 {
 		return nil
@@ -96,7 +160,7 @@ func GeneratePreSubmit(prs []git.PullRequest, repo *git.Repository, sender *git.
 
 	jobID := utils.RandomString(20)
 	return &cicdv1.IntegrationJob{
-		ObjectMeta: generateMeta(config.Name, config.Namespace, ijName, jobID),
+		ObjectMeta: generateMeta(config.Name, config.Namespace, ijName, jobID, event),
 		Spec: cicdv1.IntegrationJobSpec{
 			ConfigRef: cicdv1.IntegrationJobConfigRef{
 				Name: config.Name,
@@ -121,21 +185,71 @@ func GeneratePreSubmit(prs []git.PullRequest, repo *git.Repository, sender *git.
 			},
 			PodTemplate: config.Spec.PodTemplate,
 			Timeout:     config.GetDuration(),
+			JobTimeout:  config.Spec.JobTimeout,
 			ParamConfig: config.Spec.ParamConfig,
+			Priority:    getPullRequestPriority(prs, config),
 		},
 	}
 }
 
 // GeneratePostSubmit generates IntegrationJob for push event
-func GeneratePostSubmit(push *git.Push, repo *git.Repository, sender *git.User, config *cicdv1.IntegrationConfig) *cicdv1.IntegrationJob {
-	filteredJobs := FilterJobs(config.Spec.Jobs.PostSubmit, git.EventTypePush, push.Ref, config.Spec.When)
+// changedFiles are used to filter jobs by their paths. Path filters are ignored if it is nil
+func GeneratePostSubmit(push *git.Push, repo *git.Repository, sender *git.User, config *cicdv1.IntegrationConfig, changedFiles []string) *cicdv1.IntegrationJob {
+	event := cicdv1.JobEventPush
+	if strings.HasPrefix(push.Ref, "refs/tags/") {
+		event = cicdv1.JobEventTag
+	}
+	return generatePostSubmit(event, push, repo, sender, config, changedFiles)
+}
+
+// GenerateReleasePostSubmit generates IntegrationJob for release event, for the release's tag
+func GenerateReleasePostSubmit(release *git.Release, repo *git.Repository, sender *git.User, config *cicdv1.IntegrationConfig) *cicdv1.IntegrationJob {
+	return generatePostSubmit(cicdv1.JobEventRelease, releasePush(release), repo, sender, config, nil)
+}
+
+// GenerateMergedPostSubmit generates IntegrationJob for the merged pull request, for its merge commit.
+// The pull request is referred by the job's refs as the merged pull request
+func GenerateMergedPostSubmit(pr *git.PullRequest, repo *git.Repository, sender *git.User, config *cicdv1.IntegrationConfig, changedFiles []string) *cicdv1.IntegrationJob {
+	job := generatePostSubmit(cicdv1.JobEventMerged, mergedPush(pr), repo, sender, config, changedFiles)
+	if job != nil {
+		pull := generatePull(*pr)
+		job.Spec.Refs.MergedPull = &pull
+	}
+	return job
+}
+
+// PullRequestEvent returns the job event of the pull request, depending on whether it is a draft or not
+func PullRequestEvent(pr *git.PullRequest) cicdv1.JobEvent {
+	if pr.Draft {
+		return cicdv1.JobEventDraftPullRequest
+	}
+	return cicdv1.JobEventPullRequest
+}
+
+// releasePush is a push of the release's tag
+func releasePush(release *git.Release) *git.Push {
+	return &git.Push{Ref: "refs/tags/" + release.Tag, Sha: release.Sha}
+}
+
+// mergedPush is a push of the merged pull request's merge commit to the base branch.
+// The head commit is used if the merge commit is unknown (e.g., fast-forward merges)
+func mergedPush(pr *git.PullRequest) *git.Push {
+	sha := pr.MergeSha
+	if sha == "" {
+		sha = pr.Head.Sha
+	}
+	return &git.Push{Ref: "refs/heads/" + pr.Base.Ref, Sha: sha, Before: pr.Base.Sha}
+}
+
+func generatePostSubmit(event cicdv1.JobEvent, push *git.Push, repo *git.Repository, sender *git.User, config *cicdv1.IntegrationConfig, changedFiles []string) *cicdv1.IntegrationJob {
+	filteredJobs := FilterJobs(config.Spec.Jobs.PostSubmit, event, push.Ref, config.Spec.When, changedFiles)
 	jobs := applyNotification(filteredJobs, config.Spec.GolbalNotification)
 	if len(jobs) < 1 {
 		return nil
 	}
 	jobID := utils.RandomString(20)
 	return &cicdv1.IntegrationJob{
-		ObjectMeta: generateMeta(config.Name, config.Namespace, push.Sha, jobID),
+		ObjectMeta: generateMeta(config.Name, config.Namespace, push.Sha, jobID, event),
 		Spec: cicdv1.IntegrationJobSpec{
 			ConfigRef: cicdv1.IntegrationJobConfigRef{
 				Name: config.Name,
@@ -159,22 +273,51 @@ func GeneratePostSubmit(push *git.Push, repo *git.Repository, sender *git.User,
 			},
 			PodTemplate: config.Spec.PodTemplate,
 			Timeout:     config.GetDuration(),
+			JobTimeout:  config.Spec.JobTimeout,
 			ParamConfig: config.Spec.ParamConfig,
+			Priority:    config.GetPriority(cicdv1.JobTypePostSubmit),
 		},
 	}
 }
 
-func generateMeta(cfgName, cfgNamespace, sha, jobID string) metav1.ObjectMeta {
+func generateMeta(cfgName, cfgNamespace, sha, jobID string, event cicdv1.JobEvent) metav1.ObjectMeta {
 	return metav1.ObjectMeta{
 		Name:      fmt.Sprintf("%s-%s-%s", cfgName, sha[:5], jobID[:5]),
 		Namespace: cfgNamespace,
 		Labels: map[string]string{
 			cicdv1.JobLabelConfig: cfgName,
 			cicdv1.JobLabelID:     jobID,
+			cicdv1.JobLabelEvent:  string(event),
 		},
 	}
 }
 
+// getPullRequestPriority returns the highest priority among the pull requests' priority labels.
+// If there is no valid priority label, the config's pre-submit priority is returned
+func getPullRequestPriority(prs []git.PullRequest, config *cicdv1.IntegrationConfig) int {
+	priority := 0
+	found := false
+	for _, pr := range prs {
+		for _, l := range pr.Labels {
+			if !strings.HasPrefix(l.Name, priorityLabelPrefix) {
+				continue
+			}
+			p, err := strconv.Atoi(strings.TrimPrefix(l.Name, priorityLabelPrefix))
+			if err != nil {
+				continue
+			}
+			if !found || p > priority {
+				priority = p
+				found = true
+			}
+		}
+	}
+	if !found {
+		return config.GetPriority(cicdv1.JobTypePreSubmit)
+	}
+	return priority
+}
+
 func generatePulls(prs []git.PullRequest) []cicdv1.IntegrationJobRefsPull {
 	var pulls []cicdv1.IntegrationJobRefsPull
 	for _, pr := range prs {
@@ -196,16 +339,18 @@ func generatePull(pr git.PullRequest) cicdv1.IntegrationJobRefsPull {
 	}
 }
 
-// FilterJobs filters job depending on the events, and ref
-func FilterJobs(cand []cicdv1.Job, evType git.EventType, ref string, when *cicdv1.JobWhen) []cicdv1.Job {
+// FilterJobs filters job depending on the events, ref, and changed files
+// ref is a base branch name for the pull request events, and a full ref for the others.
+// Path filters are ignored if changedFiles is nil. Dropped jobs are removed from the remaining jobs' 'after' field
+func FilterJobs(cand []cicdv1.Job, event cicdv1.JobEvent, ref string, when *cicdv1.JobWhen, changedFiles []string) []cicdv1.Job {
 	var filteredJobs []cicdv1.Job
 	var incomingBranch string
 	var incomingTag string
 
-	switch evType {
-	case git.EventTypePullRequest:
+	switch event {
+	case cicdv1.JobEventPullRequest, cicdv1.JobEventDraftPullRequest:
 		incomingBranch = ref
-	case git.EventTypePush:
+	default:
 		if strings.Contains(ref, "refs/tags/") {
 			incomingTag = strings.Replace(ref, "refs/tags/", "", -1)
 		} else {
@@ -213,15 +358,36 @@ func FilterJobs(cand []cicdv1.Job, evType git.EventType, ref string, when *cicdv
 		}
 	}
 	cand = applyWhen(cand, when)
+	cand = filterEvents(cand, event)
 
 	// Commit comment events
 	if incomingBranch == "" && incomingTag == "" {
 		filteredJobs = filterCommits(cand)
-		return filteredJobs
+	} else {
+		//tag push events
+		filteredJobs = filterTags(cand, incomingTag)
+		filteredJobs = filterBranches(filteredJobs, incomingBranch)
+	}
+	filteredJobs = filterPaths(filteredJobs, changedFiles)
+	return pruneAfter(filteredJobs)
+}
+
+// filterEvents filters the jobs triggered by the event. Jobs without events are triggered by the default events
+func filterEvents(jobs []cicdv1.Job, event cicdv1.JobEvent) []cicdv1.Job {
+	var filteredJobs []cicdv1.Job
+
+	for _, job := range jobs {
+		events := cicdv1.DefaultJobEvents
+		if job.When != nil && job.When.Events != nil {
+			events = job.When.Events
+		}
+		for _, e := range events {
+			if e == event {
+				filteredJobs = append(filteredJobs, job)
+				break
+			}
+		}
 	}
-	//tag push events
-	filteredJobs = filterTags(cand, incomingTag)
-	filteredJobs = filterBranches(filteredJobs, incomingBranch)
 	return filteredJobs
 }
 
@@ -233,9 +399,9 @@ func filterCommits(jobs []cicdv1.Job) []cicdv1.Job {
 			filteredJobs = append(filteredJobs, job)
 			continue
 		}
-		branches := job.When.Branch
-		for _, branch := range branches {
-			if match := matchString("[commit]", branch); match {
+		for _, branch := range job.When.Branch {
+			// '[commit]' is a special keyword, not a regular expression
+			if branch == commitBranchKeyword {
 				filteredJobs = append(filteredJobs, job)
 				break
 			}
@@ -259,30 +425,15 @@ func filterTags(jobs []cicdv1.Job, incomingTag string) []cicdv1.Job {
 		// Always run if no tag/skipTag is specified
 		if tags == nil && skipTags == nil {
 			filteredJobs = append(filteredJobs, job)
+			continue
 		}
 
 		if incomingTag == "" {
 			continue
 		}
 
-		if tags != nil && skipTags == nil {
-			for _, tag := range tags {
-				if match := matchString(incomingTag, tag); match {
-					filteredJobs = append(filteredJobs, job)
-					break
-				}
-			}
-		} else if skipTags != nil && tags == nil {
-			isInValidJob := false
-			for _, tag := range skipTags {
-				if match := matchString(incomingTag, tag); match {
-					isInValidJob = true
-					break
-				}
-			}
-			if !isInValidJob {
-				filteredJobs = append(filteredJobs, job)
-			}
+		if matchIncoming(incomingTag, tags, skipTags) {
+			filteredJobs = append(filteredJobs, job)
 		}
 	}
 	return filteredJobs
@@ -302,35 +453,94 @@ func filterBranches(jobs []cicdv1.Job, incomingBranch string) []cicdv1.Job {
 		// Always run if no branch/skipBranch is specified
 		if branches == nil && skipBranches == nil {
 			filteredJobs = append(filteredJobs, job)
+			continue
 		}
 
 		if incomingBranch == "" {
 			continue
 		}
 
-		if branches != nil && skipBranches == nil {
-			for _, branch := range branches {
-				if match := matchString(incomingBranch, branch); match {
-					filteredJobs = append(filteredJobs, job)
-					break
-				}
-			}
-		} else if skipBranches != nil && branches == nil {
-			isInValidJob := false
-			for _, branch := range skipBranches {
-				if match := matchString(incomingBranch, branch); match {
-					isInValidJob = true
-					break
-				}
-			}
-			if !isInValidJob {
+		if matchIncoming(incomingBranch, branches, skipBranches) {
+			filteredJobs = append(filteredJobs, job)
+		}
+	}
+	return filteredJobs
+}
+
+// matchIncoming checks if the incoming branch/tag matches any of the patterns (if specified)
+// and does not match any of the skip patterns
+func matchIncoming(incoming string, patterns, skipPatterns []string) bool {
+	if patterns != nil && !matchAnyString(incoming, patterns) {
+		return false
+	}
+	return !matchAnyString(incoming, skipPatterns)
+}
+
+func matchAnyString(incoming string, targets []string) bool {
+	for _, target := range targets {
+		if matchString(incoming, target) {
+			return true
+		}
+	}
+	return false
+}
+
+func filterPaths(jobs []cicdv1.Job, changedFiles []string) []cicdv1.Job {
+	// Run all jobs if the changed files are unknown
+	if changedFiles == nil {
+		return jobs
+	}
+
+	var filteredJobs []cicdv1.Job
+	for _, job := range jobs {
+		if job.When == nil || (job.When.Paths == nil && job.When.SkipPaths == nil) {
+			filteredJobs = append(filteredJobs, job)
+			continue
+		}
+
+		// Run if any of the changed files matches paths (if specified) and does not match skipPaths
+		for _, file := range changedFiles {
+			if (job.When.Paths == nil || matchAnyPath(file, job.When.Paths)) && !matchAnyPath(file, job.When.SkipPaths) {
 				filteredJobs = append(filteredJobs, job)
+				break
 			}
 		}
 	}
 	return filteredJobs
 }
 
+func matchAnyPath(file string, patterns []string) bool {
+	for _, p := range patterns {
+		if matchPath(file, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// pruneAfter removes the dropped jobs from the remaining jobs' 'after' field, so the dropped jobs are treated as
+// satisfied dependencies
+func pruneAfter(jobs []cicdv1.Job) []cicdv1.Job {
+	names := map[string]struct{}{}
+	for _, job := range jobs {
+		names[job.Name] = struct{}{}
+	}
+
+	for i := range jobs {
+		if len(jobs[i].After) == 0 {
+			continue
+		}
+		var after []string
+		for _, a := range jobs[i].After {
+			if _, exist := names[a]; exist {
+				after = append(after, a)
+			}
+		}
+		jobs[i].After = after
+	}
+	return jobs
+}
+
 // applyWhen inserts ic.When into job.When if job.When is not specified
 func applyWhen(jobs []cicdv1.Job, when *cicdv1.JobWhen) []cicdv1.Job {
 	if when != nil {
@@ -361,6 +571,20 @@ func applyNotification(jobs []cicdv1.Job, noti *cicdv1.Notification) []cicdv1.Jo
 	return jobs
 }
 
+// matchString matches the incoming branch/tag with the target regular expression.
+// Compiled expressions are cached, and invalid expressions never match
 func matchString(incoming, target string) bool {
-	return incoming == target
+	if cached, exist := patternCache.Load(target); exist {
+		re, ok := cached.(*regexp.Regexp)
+		return ok && re.MatchString(incoming)
+	}
+
+	re, err := cicdv1.CompileWhenPattern(target)
+	if err != nil {
+		// Store the invalid pattern as well, not to compile it again
+		patternCache.Store(target, err)
+		return false
+	}
+	patternCache.Store(target, re)
+	return re.MatchString(incoming)
 }
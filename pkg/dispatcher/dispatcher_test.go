@@ -23,6 +23,7 @@ import (
 	"github.com/stretchr/testify/require"
 	cicdv1 "github.com/tmax-cloud/cicd-operator/api/v1"
 	"github.com/tmax-cloud/cicd-operator/pkg/git"
+	corev1 "k8s.io/api/core/v1"
 )
 
 func TestGeneratePreSubmit(t *testing.T) {
@@ -117,7 +118,7 @@ func TestGeneratePreSubmit(t *testing.T) {
 
 	for name, c := range tc {
 		t.Run(name, func(t *testing.T) {
-			ij := GeneratePreSubmit(c.prs, c.repo, c.sender, c.config)
+			ij := GeneratePreSubmit(c.prs, c.repo, c.sender, c.config, nil)
 			if c.expectedNil {
 				require.Nil(t, ij)
 			} else {
@@ -181,7 +182,7 @@ func TestGeneratePostSubmit(t *testing.T) {
 
 	for name, c := range tc {
 		t.Run(name, func(t *testing.T) {
-			ij := GeneratePostSubmit(c.push, c.repo, c.sender, c.config)
+			ij := GeneratePostSubmit(c.push, c.repo, c.sender, c.config, nil)
 			if c.expectedNil {
 				require.Nil(t, ij)
 			} else {
@@ -191,6 +192,77 @@ func TestGeneratePostSubmit(t *testing.T) {
 	}
 }
 
+func TestGenerateReleasePostSubmit(t *testing.T) {
+	config := &cicdv1.IntegrationConfig{
+		Spec: cicdv1.IntegrationConfigSpec{
+			Jobs: cicdv1.IntegrationConfigJobs{
+				PostSubmit: cicdv1.Jobs{
+					{Container: corev1.Container{Name: "tag"}},
+					{Container: corev1.Container{Name: "release"}, When: &cicdv1.JobWhen{Events: []cicdv1.JobEvent{cicdv1.JobEventRelease}, Tag: []string{"v.*"}}},
+				},
+			},
+		},
+	}
+
+	ij := GenerateReleasePostSubmit(&git.Release{Tag: "v1.0.0", Sha: "0kokpenadiugpowkqe0qlemaogor"}, &git.Repository{}, &git.User{}, config)
+	require.NotNil(t, ij)
+	require.Len(t, ij.Spec.Jobs, 1)
+	require.Equal(t, "release", ij.Spec.Jobs[0].Name)
+	require.Equal(t, cicdv1.JobTypePostSubmit, ij.Spec.ConfigRef.Type)
+	require.Equal(t, string(cicdv1.JobEventRelease), ij.Labels[cicdv1.JobLabelEvent])
+	require.Equal(t, "refs/tags/v1.0.0", ij.Spec.Refs.Base.Ref.String())
+	require.Equal(t, "0kokpenadiugpowkqe0qlemaogor", ij.Spec.Refs.Base.Sha)
+}
+
+func TestGenerateMergedPostSubmit(t *testing.T) {
+	config := &cicdv1.IntegrationConfig{
+		Spec: cicdv1.IntegrationConfigSpec{
+			Jobs: cicdv1.IntegrationConfigJobs{
+				PostSubmit: cicdv1.Jobs{
+					{Container: corev1.Container{Name: "push"}},
+					{Container: corev1.Container{Name: "merged"}, When: &cicdv1.JobWhen{Events: []cicdv1.JobEvent{cicdv1.JobEventMerged}}},
+				},
+			},
+		},
+	}
+
+	tc := map[string]struct {
+		mergeSha string
+
+		expectedSha string
+	}{
+		"mergeCommit": {
+			mergeSha:    "merge-sha-1",
+			expectedSha: "merge-sha-1",
+		},
+		"fastForward": {
+			expectedSha: "head-sha-1",
+		},
+	}
+
+	for name, c := range tc {
+		t.Run(name, func(t *testing.T) {
+			pr := &git.PullRequest{
+				ID:       30,
+				Action:   git.PullRequestActionMerged,
+				Base:     git.Base{Ref: "master", Sha: "base-sha-1"},
+				Head:     git.Head{Ref: "feat", Sha: "head-sha-1"},
+				MergeSha: c.mergeSha,
+			}
+			ij := GenerateMergedPostSubmit(pr, &git.Repository{}, &git.User{}, config, nil)
+			require.NotNil(t, ij)
+			require.Len(t, ij.Spec.Jobs, 1)
+			require.Equal(t, "merged", ij.Spec.Jobs[0].Name)
+			require.Equal(t, string(cicdv1.JobEventMerged), ij.Labels[cicdv1.JobLabelEvent])
+			require.Equal(t, "refs/heads/master", ij.Spec.Refs.Base.Ref.String())
+			require.Equal(t, c.expectedSha, ij.Spec.Refs.Base.Sha)
+			require.Nil(t, ij.Spec.Refs.Pulls)
+			require.NotNil(t, ij.Spec.Refs.MergedPull)
+			require.Equal(t, 30, ij.Spec.Refs.MergedPull.ID)
+		})
+	}
+}
+
 func TestGeneratePull(t *testing.T) {
 	pr := git.PullRequest{
 		ID:     30,
@@ -230,3 +302,175 @@ func TestGeneratePulls(t *testing.T) {
 	assert.Equal(t, "bugfix/first", pulls[0].Ref.String())
 	assert.Equal(t, "0kokpenadiugpowkqe0qlemaogor", pulls[0].Sha)
 }
+
+func TestFilterJobs(t *testing.T) {
+	jobs := []cicdv1.Job{
+		{Container: corev1.Container{Name: "always"}},
+		{Container: corev1.Container{Name: "release"}, When: &cicdv1.JobWhen{Branch: []string{"release-.*"}}},
+		{Container: corev1.Container{Name: "not-release-rc"}, When: &cicdv1.JobWhen{Branch: []string{"release-.*"}, SkipBranch: []string{".*-rc"}}},
+		{Container: corev1.Container{Name: "not-master"}, When: &cicdv1.JobWhen{SkipBranch: []string{"master"}}},
+		{Container: corev1.Container{Name: "version-tag"}, When: &cicdv1.JobWhen{Tag: []string{`v[0-9]+\..*`}, SkipTag: []string{`.*-alpha`}}},
+		{Container: corev1.Container{Name: "invalid"}, When: &cicdv1.JobWhen{Branch: []string{"release-[", "master"}}},
+		{Container: corev1.Container{Name: "commit"}, When: &cicdv1.JobWhen{Branch: []string{"[commit]"}}},
+	}
+
+	tc := map[string]struct {
+		event cicdv1.JobEvent
+		ref   string
+
+		expectedJobs []string
+	}{
+		"master": {
+			event:        cicdv1.JobEventPullRequest,
+			ref:          "master",
+			expectedJobs: []string{"always", "invalid"},
+		},
+		"masterPrefix": {
+			event:        cicdv1.JobEventPush,
+			ref:          "refs/heads/master-old",
+			expectedJobs: []string{"always", "not-master"},
+		},
+		"release": {
+			event:        cicdv1.JobEventPush,
+			ref:          "refs/heads/release-1.0",
+			expectedJobs: []string{"always", "release", "not-release-rc", "not-master"},
+		},
+		"releaseRC": {
+			event:        cicdv1.JobEventPush,
+			ref:          "refs/heads/release-1.0-rc",
+			expectedJobs: []string{"always", "release", "not-master"},
+		},
+		"versionTag": {
+			event:        cicdv1.JobEventTag,
+			ref:          "refs/tags/v1.2.0",
+			expectedJobs: []string{"always", "version-tag"},
+		},
+		"alphaTag": {
+			event:        cicdv1.JobEventTag,
+			ref:          "refs/tags/v1.2.0-alpha",
+			expectedJobs: []string{"always"},
+		},
+		"commitComment": {
+			event:        cicdv1.JobEventPush,
+			ref:          "",
+			expectedJobs: []string{"always", "commit"},
+		},
+	}
+
+	for name, c := range tc {
+		t.Run(name, func(t *testing.T) {
+			var names []string
+			for _, j := range FilterJobs(jobs, c.event, c.ref, nil, nil) {
+				names = append(names, j.Name)
+			}
+			require.Equal(t, c.expectedJobs, names)
+		})
+	}
+}
+
+func TestFilterJobs_events(t *testing.T) {
+	jobs := []cicdv1.Job{
+		{Container: corev1.Container{Name: "default"}},
+		{Container: corev1.Container{Name: "not-draft"}, When: &cicdv1.JobWhen{Events: []cicdv1.JobEvent{cicdv1.JobEventPullRequest}}},
+		{Container: corev1.Container{Name: "release"}, When: &cicdv1.JobWhen{Events: []cicdv1.JobEvent{cicdv1.JobEventRelease}}},
+		{Container: corev1.Container{Name: "merged"}, When: &cicdv1.JobWhen{Events: []cicdv1.JobEvent{cicdv1.JobEventMerged}, Branch: []string{"master"}}},
+	}
+
+	tc := map[string]struct {
+		event cicdv1.JobEvent
+		ref   string
+
+		expectedJobs []string
+	}{
+		"pullRequest": {
+			event:        cicdv1.JobEventPullRequest,
+			ref:          "master",
+			expectedJobs: []string{"default", "not-draft"},
+		},
+		"draftPullRequest": {
+			event:        cicdv1.JobEventDraftPullRequest,
+			ref:          "master",
+			expectedJobs: []string{"default"},
+		},
+		"push": {
+			event:        cicdv1.JobEventPush,
+			ref:          "refs/heads/master",
+			expectedJobs: []string{"default"},
+		},
+		"release": {
+			event:        cicdv1.JobEventRelease,
+			ref:          "refs/tags/v1.0.0",
+			expectedJobs: []string{"release"},
+		},
+		"merged": {
+			event:        cicdv1.JobEventMerged,
+			ref:          "refs/heads/master",
+			expectedJobs: []string{"merged"},
+		},
+		"mergedOtherBranch": {
+			event: cicdv1.JobEventMerged,
+			ref:   "refs/heads/dev",
+		},
+	}
+
+	for name, c := range tc {
+		t.Run(name, func(t *testing.T) {
+			var names []string
+			for _, j := range FilterJobs(jobs, c.event, c.ref, nil, nil) {
+				names = append(names, j.Name)
+			}
+			require.Equal(t, c.expectedJobs, names)
+		})
+	}
+}
+
+func Test_getPullRequestPriority(t *testing.T) {
+	override := 50
+	tc := map[string]struct {
+		labels   [][]string
+		override *int
+
+		expected int
+	}{
+		"default": {
+			labels:   [][]string{{"kind/bug"}},
+			expected: cicdv1.DefaultPriorityPreSubmit,
+		},
+		"configOverride": {
+			labels:   [][]string{{"kind/bug"}},
+			override: &override,
+			expected: 50,
+		},
+		"label": {
+			labels:   [][]string{{"kind/bug", "priority/100"}},
+			override: &override,
+			expected: 100,
+		},
+		"invalidLabel": {
+			labels:   [][]string{{"priority/high"}},
+			expected: cicdv1.DefaultPriorityPreSubmit,
+		},
+		"batch": {
+			labels:   [][]string{{"priority/30"}, {"priority/-10"}, {"priority/70"}},
+			expected: 70,
+		},
+	}
+
+	for name, c := range tc {
+		t.Run(name, func(t *testing.T) {
+			var prs []git.PullRequest
+			for _, labels := range c.labels {
+				pr := git.PullRequest{}
+				for _, l := range labels {
+					pr.Labels = append(pr.Labels, git.IssueLabel{Name: l})
+				}
+				prs = append(prs, pr)
+			}
+			ic := &cicdv1.IntegrationConfig{}
+			if c.override != nil {
+				ic.Spec.IJManageSpec.Priority = &cicdv1.IntegrationJobPriority{PreSubmit: c.override}
+			}
+			require.Equal(t, c.expected, getPullRequestPriority(prs, ic))
+		})
+	}
+}
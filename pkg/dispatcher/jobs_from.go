@@ -0,0 +1,174 @@
+/*
+ Copyright 2021 The CI/CD Operator Authors
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package dispatcher
+
+import (
+	"fmt"
+
+	cicdv1 "github.com/tmax-cloud/cicd-operator/api/v1"
+	"github.com/tmax-cloud/cicd-operator/internal/utils"
+	"github.com/tmax-cloud/cicd-operator/pkg/git"
+	"github.com/tmax-cloud/cicd-operator/pkg/server"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+)
+
+// LoadPullRequestJobs loads the jobs from the config's jobsFrom file of the pull request.
+// It returns the config itself if jobsFrom is not set, or a copy of the config with the loaded jobs.
+// If the file is invalid, the error is commented to the pull request and a PermanentError is returned, so the webhook
+// is not retried. If the file cannot be fetched, the error is returned without the comment, to be retried
+func LoadPullRequestJobs(config *cicdv1.IntegrationConfig, k8sClient client.Client, pr *git.PullRequest) (*cicdv1.IntegrationConfig, error) {
+	if config.Spec.JobsFrom == nil {
+		return config, nil
+	}
+
+	gitCli, err := utils.GetGitCli(config, k8sClient)
+	if err != nil {
+		return nil, err
+	}
+	newConfig, err := ReloadPullRequestJobs(config, gitCli, pr)
+	if err != nil {
+		if server.IsPermanent(err) && pr.Head.Sha != git.FakeSha {
+			commentJobsFromError(gitCli, config.Spec.JobsFrom.Path, git.IssueTypePullRequest, pr.ID, pr.Head.Sha, err)
+		}
+		return nil, err
+	}
+	return newConfig, nil
+}
+
+// ReloadPullRequestJobs loads the jobs of the pull request just as LoadPullRequestJobs does, but does not comment the
+// error, as it's already commented when the pull request is dispatched
+func ReloadPullRequestJobs(config *cicdv1.IntegrationConfig, gitCli git.Client, pr *git.PullRequest) (*cicdv1.IntegrationConfig, error) {
+	if config.Spec.JobsFrom == nil {
+		return config, nil
+	}
+	return loadJobsFrom(config, gitCli, pullRequestJobsRef(gitCli, pr))
+}
+
+// pullRequestJobsRef decides the ref to load the jobs file from.
+// The jobs run under the config's service account, so the file is loaded from the head of the pull request only if
+// its author can write to the repository. Otherwise, it is loaded from the base branch
+func pullRequestJobsRef(gitCli git.Client, pr *git.PullRequest) string {
+	// Pull requests triggered by the api server do not have the head sha, but are requested by an authorized user
+	if pr.Head.Sha == git.FakeSha {
+		return pr.Head.Ref
+	}
+
+	canWrite, err := gitCli.CanUserWriteToRepo(pr.Author)
+	if err != nil {
+		log.Info("cannot check the permission of the pull request's author, loading the jobs from the base branch", "author", pr.Author.Name, "error", err.Error())
+	}
+	if canWrite {
+		return pr.Head.Sha
+	}
+	return pr.Base.Ref
+}
+
+// LoadPushJobs loads the jobs from the config's jobsFrom file at the pushed commit.
+// It returns the config itself if jobsFrom is not set, or a copy of the config with the loaded jobs.
+// Just as LoadPullRequestJobs, only the error of an invalid file is commented to the commit
+func LoadPushJobs(config *cicdv1.IntegrationConfig, k8sClient client.Client, push *git.Push) (*cicdv1.IntegrationConfig, error) {
+	if config.Spec.JobsFrom == nil {
+		return config, nil
+	}
+
+	// Pushes triggered by the api server do not have the sha
+	ref := push.Sha
+	if isZeroSha(ref) {
+		ref = push.Ref
+	}
+
+	gitCli, err := utils.GetGitCli(config, k8sClient)
+	if err != nil {
+		return nil, err
+	}
+	newConfig, err := loadJobsFrom(config, gitCli, ref)
+	if err != nil {
+		if server.IsPermanent(err) && !isZeroSha(push.Sha) {
+			commentJobsFromError(gitCli, config.Spec.JobsFrom.Path, git.IssueTypeCommit, 0, push.Sha, err)
+		}
+		return nil, err
+	}
+	return newConfig, nil
+}
+
+// loadJobsFrom fetches the jobs file at the ref and returns a copy of the config, with the jobs merged or replaced.
+// Errors of parsing or validating the file are returned as PermanentErrors, as fetching the file again does not help
+func loadJobsFrom(config *cicdv1.IntegrationConfig, gitCli git.Client, ref string) (*cicdv1.IntegrationConfig, error) {
+	raw, err := gitCli.GetFileContent(config.Spec.JobsFrom.Path, ref)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get the file: %s", err.Error())
+	}
+
+	newConfig, err := parseJobsFrom(config, raw)
+	if err != nil {
+		return nil, server.NewPermanentError(err)
+	}
+	return newConfig, nil
+}
+
+// parseJobsFrom parses the jobs file and returns a copy of the config, with the jobs merged or replaced
+func parseJobsFrom(config *cicdv1.IntegrationConfig, raw []byte) (*cicdv1.IntegrationConfig, error) {
+	loaded := cicdv1.IntegrationConfigJobs{}
+	if err := yaml.UnmarshalStrict(raw, &loaded); err != nil {
+		return nil, fmt.Errorf("cannot parse the file: %s", err.Error())
+	}
+
+	newConfig := config.DeepCopy()
+	if config.Spec.JobsFrom.Mode == cicdv1.JobsFromModeReplace {
+		newConfig.Spec.Jobs.PreSubmit = loaded.PreSubmit
+		newConfig.Spec.Jobs.PostSubmit = loaded.PostSubmit
+	} else {
+		newConfig.Spec.Jobs.PreSubmit = mergeJobs(newConfig.Spec.Jobs.PreSubmit, loaded.PreSubmit)
+		newConfig.Spec.Jobs.PostSubmit = mergeJobs(newConfig.Spec.Jobs.PostSubmit, loaded.PostSubmit)
+	}
+
+	if err := newConfig.Spec.Jobs.PreSubmit.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid preSubmit jobs: %s", err.Error())
+	}
+	if err := newConfig.Spec.Jobs.PostSubmit.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid postSubmit jobs: %s", err.Error())
+	}
+	if err := newConfig.ValidateWhen(); err != nil {
+		return nil, err
+	}
+
+	return newConfig, nil
+}
+
+// mergeJobs overrides the jobs having the same name with the loaded ones, and appends the others
+func mergeJobs(jobs, loaded cicdv1.Jobs) cicdv1.Jobs {
+	loadedNames := map[string]struct{}{}
+	for _, l := range loaded {
+		loadedNames[l.Name] = struct{}{}
+	}
+
+	merged := cicdv1.Jobs{}
+	for _, j := range jobs {
+		if _, exist := loadedNames[j.Name]; !exist {
+			merged = append(merged, j)
+		}
+	}
+	return append(merged, loaded...)
+}
+
+func commentJobsFromError(gitCli git.Client, path string, issueType git.IssueType, issueID int, sha string, err error) {
+	body := fmt.Sprintf("Cannot load the jobs from `%s`\n\n```\n%s\n```\n", path, err.Error())
+	if err := gitCli.RegisterComment(issueType, issueID, sha, body); err != nil {
+		log.Error(err, "cannot register the comment")
+	}
+}
@@ -0,0 +1,224 @@
+/*
+ Copyright 2021 The CI/CD Operator Authors
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package dispatcher
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	cicdv1 "github.com/tmax-cloud/cicd-operator/api/v1"
+	"github.com/tmax-cloud/cicd-operator/pkg/git"
+	gitfake "github.com/tmax-cloud/cicd-operator/pkg/git/fake"
+	"github.com/tmax-cloud/cicd-operator/pkg/server"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+const testJobsFile = `
+preSubmit:
+- name: lint
+  image: golang:1.17
+  script: make lint
+- name: test
+  image: golang:1.17
+  script: make test
+  after:
+  - lint
+`
+
+func TestLoadPullRequestJobs(t *testing.T) {
+	tc := map[string]struct {
+		jobsFrom    *cicdv1.JobsFrom
+		content     string
+		baseContent string
+		sha         string
+		author      string
+
+		expectedErrMsg    string
+		expectedPermanent bool
+		expectedJobs      []string
+		expectedImage     string
+	}{
+		"noJobsFrom": {
+			expectedJobs:  []string{"test", "build"},
+			expectedImage: "alpine",
+		},
+		"merge": {
+			jobsFrom:      &cicdv1.JobsFrom{Path: ".cicd/jobs.yaml"},
+			content:       testJobsFile,
+			expectedJobs:  []string{"build", "lint", "test"},
+			expectedImage: "golang:1.17",
+		},
+		"replace": {
+			jobsFrom:      &cicdv1.JobsFrom{Path: ".cicd/jobs.yaml", Mode: cicdv1.JobsFromModeReplace},
+			content:       testJobsFile,
+			expectedJobs:  []string{"lint", "test"},
+			expectedImage: "golang:1.17",
+		},
+		"notFound": {
+			jobsFrom:       &cicdv1.JobsFrom{Path: ".cicd/not-exist.yaml"},
+			expectedErrMsg: "cannot get the file: 404 no such file",
+		},
+		"parseError": {
+			jobsFrom:          &cicdv1.JobsFrom{Path: ".cicd/jobs.yaml"},
+			content:           "preSubmit:\n- name: test\n  unknownField: true\n",
+			expectedErrMsg:    "cannot parse the file",
+			expectedPermanent: true,
+		},
+		"unknownAfter": {
+			jobsFrom:          &cicdv1.JobsFrom{Path: ".cicd/jobs.yaml", Mode: cicdv1.JobsFromModeReplace},
+			content:           "preSubmit:\n- name: test\n  after:\n  - build\n",
+			expectedErrMsg:    "invalid preSubmit jobs: job test runs after unknown job build",
+			expectedPermanent: true,
+		},
+		"invalidWhen": {
+			jobsFrom:          &cicdv1.JobsFrom{Path: ".cicd/jobs.yaml"},
+			content:           "preSubmit:\n- name: test\n  when:\n    branch:\n    - \"(\"\n",
+			expectedErrMsg:    "preSubmit job test: invalid regular expressions \"(\"",
+			expectedPermanent: true,
+		},
+		"fakeSha": {
+			jobsFrom:      &cicdv1.JobsFrom{Path: ".cicd/jobs.yaml"},
+			content:       testJobsFile,
+			sha:           git.FakeSha,
+			expectedJobs:  []string{"build", "lint", "test"},
+			expectedImage: "golang:1.17",
+		},
+		"untrustedAuthor": {
+			jobsFrom:      &cicdv1.JobsFrom{Path: ".cicd/jobs.yaml"},
+			content:       testJobsFile,
+			baseContent:   "preSubmit:\n- name: lint\n  image: alpine\n",
+			author:        "outsider",
+			expectedJobs:  []string{"test", "build", "lint"},
+			expectedImage: "alpine",
+		},
+		"unknownAuthor": {
+			jobsFrom:      &cicdv1.JobsFrom{Path: ".cicd/jobs.yaml"},
+			content:       testJobsFile,
+			baseContent:   "preSubmit:\n- name: lint\n  image: alpine\n",
+			author:        "unknown",
+			expectedJobs:  []string{"test", "build", "lint"},
+			expectedImage: "alpine",
+		},
+	}
+
+	for name, c := range tc {
+		t.Run(name, func(t *testing.T) {
+			gitfake.Repos = map[string]*gitfake.Repo{
+				testRepo: {
+					FileContents: map[string][]byte{
+						testAfter + ":.cicd/jobs.yaml": []byte(c.content),
+						"new-feature:.cicd/jobs.yaml":  []byte(c.content),
+						"master:.cicd/jobs.yaml":       []byte(c.baseContent),
+					},
+					Comments:     map[int][]git.IssueComment{},
+					UserCanWrite: map[string]bool{"maintainer": true, "outsider": false},
+				},
+			}
+			ic := &cicdv1.IntegrationConfig{
+				Spec: cicdv1.IntegrationConfigSpec{
+					Git: cicdv1.GitConfig{
+						Type:       cicdv1.GitTypeFake,
+						Repository: testRepo,
+						Token:      &cicdv1.GitToken{Value: "test-token"},
+					},
+					Jobs: cicdv1.IntegrationConfigJobs{
+						PreSubmit: cicdv1.Jobs{
+							{Container: corev1.Container{Name: "test", Image: "alpine"}},
+							{Container: corev1.Container{Name: "build", Image: "alpine"}},
+						},
+					},
+					JobsFrom: c.jobsFrom,
+				},
+			}
+			sha := c.sha
+			if sha == "" {
+				sha = testAfter
+			}
+			author := c.author
+			if author == "" {
+				author = "maintainer"
+			}
+			pr := &git.PullRequest{ID: 3, Author: git.User{Name: author}, Base: git.Base{Ref: "master"}, Head: git.Head{Ref: "new-feature", Sha: sha}}
+
+			cfg, err := LoadPullRequestJobs(ic, fake.NewClientBuilder().Build(), pr)
+			if c.expectedErrMsg != "" {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), c.expectedErrMsg)
+				require.Equal(t, c.expectedPermanent, server.IsPermanent(err))
+				// Only the invalid file is commented, and the fetch failure is retried quietly
+				if !c.expectedPermanent {
+					require.Empty(t, gitfake.Repos[testRepo].Comments[3])
+					return
+				}
+				require.Len(t, gitfake.Repos[testRepo].Comments[3], 1)
+				require.Contains(t, gitfake.Repos[testRepo].Comments[3][0].Comment.Body, c.expectedErrMsg)
+				return
+			}
+			require.NoError(t, err)
+
+			var names []string
+			for _, j := range cfg.Spec.Jobs.PreSubmit {
+				names = append(names, j.Name)
+			}
+			require.Equal(t, c.expectedJobs, names)
+			require.Equal(t, c.expectedImage, cfg.Spec.Jobs.PreSubmit[len(names)-1].Image)
+			// The original config should not be modified
+			require.Len(t, ic.Spec.Jobs.PreSubmit, 2)
+			require.Equal(t, "alpine", ic.Spec.Jobs.PreSubmit[0].Image)
+		})
+	}
+}
+
+func TestLoadPushJobs(t *testing.T) {
+	gitfake.Repos = map[string]*gitfake.Repo{
+		testRepo: {
+			FileContents: map[string][]byte{
+				testAfter + ":.cicd/jobs.yaml":  []byte("postSubmit:\n- name: deploy\n  image: alpine\n"),
+				testBefore + ":.cicd/jobs.yaml": []byte("postSubmit:\n- name: deploy\n  unknownField: true\n"),
+			},
+			Comments: map[int][]git.IssueComment{},
+		},
+	}
+	ic := &cicdv1.IntegrationConfig{
+		Spec: cicdv1.IntegrationConfigSpec{
+			Git: cicdv1.GitConfig{
+				Type:       cicdv1.GitTypeFake,
+				Repository: testRepo,
+				Token:      &cicdv1.GitToken{Value: "test-token"},
+			},
+			JobsFrom: &cicdv1.JobsFrom{Path: ".cicd/jobs.yaml"},
+		},
+	}
+
+	cfg, err := LoadPushJobs(ic, fake.NewClientBuilder().Build(), &git.Push{Ref: "refs/heads/master", Sha: testAfter})
+	require.NoError(t, err)
+	require.Len(t, cfg.Spec.Jobs.PostSubmit, 1)
+	require.Equal(t, "deploy", cfg.Spec.Jobs.PostSubmit[0].Name)
+
+	// Invalid file is commented
+	_, err = LoadPushJobs(ic, fake.NewClientBuilder().Build(), &git.Push{Ref: "refs/heads/master", Sha: testBefore})
+	require.Error(t, err)
+	require.True(t, server.IsPermanent(err))
+	require.Len(t, gitfake.Repos[testRepo].Comments[0], 1)
+
+	// Fetch failure is not commented
+	_, err = LoadPushJobs(ic, fake.NewClientBuilder().Build(), &git.Push{Ref: "refs/heads/master", Sha: "0000000000000000000000000000000000000001"})
+	require.Error(t, err)
+	require.False(t, server.IsPermanent(err))
+	require.Len(t, gitfake.Repos[testRepo].Comments[0], 1)
+}
@@ -0,0 +1,135 @@
+/*
+ Copyright 2021 The CI/CD Operator Authors
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package dispatcher
+
+import (
+	"path"
+	"strings"
+
+	cicdv1 "github.com/tmax-cloud/cicd-operator/api/v1"
+	"github.com/tmax-cloud/cicd-operator/internal/utils"
+	"github.com/tmax-cloud/cicd-operator/pkg/git"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+var log = logf.Log.WithName("dispatcher")
+
+// ListPullRequestChangedFiles lists the changed files of the pull request, only if any pre-submit job filters paths.
+// It returns nil if the changed files are not needed or cannot be determined, so that the path filters are ignored
+func ListPullRequestChangedFiles(config *cicdv1.IntegrationConfig, k8sClient client.Client, pr *git.PullRequest) []string {
+	if !hasPathFilters(config.Spec.Jobs.PreSubmit, config.Spec.When) {
+		return nil
+	}
+
+	gitCli, err := utils.GetGitCli(config, k8sClient)
+	if err != nil {
+		log.Error(err, "cannot get git client, ignoring path filters")
+		return nil
+	}
+	diff, err := gitCli.GetPullRequestDiff(pr.ID)
+	if err != nil {
+		log.Error(err, "cannot get diff of the pull request, ignoring path filters")
+		return nil
+	}
+	return changedFiles(diff)
+}
+
+// ListPushChangedFiles lists the changed files of the push, only if any post-submit job filters paths.
+// It returns nil if the changed files are not needed or cannot be determined (e.g., a new branch/tag is pushed),
+// so that the path filters are ignored
+func ListPushChangedFiles(config *cicdv1.IntegrationConfig, k8sClient client.Client, push *git.Push) []string {
+	if !hasPathFilters(config.Spec.Jobs.PostSubmit, config.Spec.When) {
+		return nil
+	}
+	if isZeroSha(push.Before) || isZeroSha(push.Sha) || push.Sha == git.FakeSha {
+		return nil
+	}
+
+	gitCli, err := utils.GetGitCli(config, k8sClient)
+	if err != nil {
+		log.Error(err, "cannot get git client, ignoring path filters")
+		return nil
+	}
+	diff, err := gitCli.GetCommitDiff(push.Before, push.Sha)
+	if err != nil {
+		log.Error(err, "cannot get diff of the push, ignoring path filters")
+		return nil
+	}
+	return changedFiles(diff)
+}
+
+// hasPathFilters checks if any of the jobs (or the default when) filters paths
+func hasPathFilters(jobs []cicdv1.Job, when *cicdv1.JobWhen) bool {
+	if when != nil && (when.Paths != nil || when.SkipPaths != nil) {
+		return true
+	}
+	for _, j := range jobs {
+		if j.When != nil && (j.When.Paths != nil || j.When.SkipPaths != nil) {
+			return true
+		}
+	}
+	return false
+}
+
+// changedFiles lists both the new and old names of the changed files
+func changedFiles(diff *git.Diff) []string {
+	files := []string{}
+	for _, c := range diff.Changes {
+		files = append(files, c.Filename)
+		if c.OldFilename != "" && c.OldFilename != c.Filename {
+			files = append(files, c.OldFilename)
+		}
+	}
+	return files
+}
+
+func isZeroSha(sha string) bool {
+	return strings.Trim(sha, "0") == ""
+}
+
+// matchPath matches the file path with the glob pattern.
+// Each path segment is matched using path.Match, and '**' matches zero or more segments.
+// A pattern ending with '/' matches all the files under the directory
+func matchPath(file, pattern string) bool {
+	if strings.HasSuffix(pattern, "/") {
+		pattern += "**"
+	}
+	return matchSegments(strings.Split(file, "/"), strings.Split(pattern, "/"))
+}
+
+func matchSegments(file, pattern []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			// Try to match the rest of the pattern, skipping zero or more segments
+			for i := 0; i <= len(file); i++ {
+				if matchSegments(file[i:], pattern[1:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(file) == 0 {
+			return false
+		}
+		if match, err := path.Match(pattern[0], file[0]); err != nil || !match {
+			return false
+		}
+		file, pattern = file[1:], pattern[1:]
+	}
+	return len(file) == 0
+}
@@ -0,0 +1,178 @@
+/*
+ Copyright 2021 The CI/CD Operator Authors
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package dispatcher
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	cicdv1 "github.com/tmax-cloud/cicd-operator/api/v1"
+	"github.com/tmax-cloud/cicd-operator/pkg/git"
+	gitfake "github.com/tmax-cloud/cicd-operator/pkg/git/fake"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+const (
+	testRepo   = "tmax-cloud/cicd-test"
+	testBefore = "3196ccc37bcae94852079b04fcbfaf928341d6e9"
+	testAfter  = "4c2d7b4e5fcf7b5d0c3f2e6a6c3b7e8d9f0a1b2c"
+)
+
+func Test_matchPath(t *testing.T) {
+	tc := map[string]struct {
+		file    string
+		pattern string
+
+		expected bool
+	}{
+		"exact":              {file: "Makefile", pattern: "Makefile", expected: true},
+		"star":               {file: "README.md", pattern: "*.md", expected: true},
+		"starNotNested":      {file: "docs/README.md", pattern: "*.md", expected: false},
+		"doubleStar":         {file: "docs/guide/README.md", pattern: "**/*.md", expected: true},
+		"doubleStarRoot":     {file: "README.md", pattern: "**/*.md", expected: true},
+		"doubleStarMiddle":   {file: "pkg/a/b/c_test.go", pattern: "pkg/**/*_test.go", expected: true},
+		"doubleStarNoMatch":  {file: "cmd/a/b.go", pattern: "pkg/**", expected: false},
+		"directory":          {file: "docs/guide/README.md", pattern: "docs/", expected: true},
+		"directoryNoMatch":   {file: "docsx/README.md", pattern: "docs/", expected: false},
+		"trailingDoubleStar": {file: "config/crd/a.yaml", pattern: "config/**", expected: true},
+		"invalidPattern":     {file: "a", pattern: "[", expected: false},
+	}
+
+	for name, c := range tc {
+		t.Run(name, func(t *testing.T) {
+			require.Equal(t, c.expected, matchPath(c.file, c.pattern))
+		})
+	}
+}
+
+func TestFilterJobs_paths(t *testing.T) {
+	jobs := []cicdv1.Job{
+		{Container: corev1.Container{Name: "always"}},
+		{Container: corev1.Container{Name: "code"}, When: &cicdv1.JobWhen{Paths: []string{"pkg/**", "cmd/**"}}},
+		{Container: corev1.Container{Name: "not-docs"}, When: &cicdv1.JobWhen{SkipPaths: []string{"docs/", "**/*.md"}}},
+		{Container: corev1.Container{Name: "go-not-test"}, When: &cicdv1.JobWhen{Paths: []string{"**/*.go"}, SkipPaths: []string{"**/*_test.go"}}},
+		{Container: corev1.Container{Name: "after-code"}, After: []string{"code", "always"}},
+	}
+
+	tc := map[string]struct {
+		changedFiles []string
+
+		expectedJobs  []string
+		expectedAfter []string
+	}{
+		"unknown": {
+			changedFiles:  nil,
+			expectedJobs:  []string{"always", "code", "not-docs", "go-not-test", "after-code"},
+			expectedAfter: []string{"code", "always"},
+		},
+		"docsOnly": {
+			changedFiles:  []string{"docs/install.md", "README.md"},
+			expectedJobs:  []string{"always", "after-code"},
+			expectedAfter: []string{"always"},
+		},
+		"testOnly": {
+			changedFiles:  []string{"pkg/dispatcher/dispatcher_test.go"},
+			expectedJobs:  []string{"always", "code", "not-docs", "after-code"},
+			expectedAfter: []string{"code", "always"},
+		},
+		"codeAndDocs": {
+			changedFiles:  []string{"pkg/dispatcher/dispatcher.go", "docs/install.md"},
+			expectedJobs:  []string{"always", "code", "not-docs", "go-not-test", "after-code"},
+			expectedAfter: []string{"code", "always"},
+		},
+		"noChanges": {
+			changedFiles:  []string{},
+			expectedJobs:  []string{"always", "after-code"},
+			expectedAfter: []string{"always"},
+		},
+	}
+
+	for name, c := range tc {
+		t.Run(name, func(t *testing.T) {
+			filtered := FilterJobs(jobs, cicdv1.JobEventPullRequest, "master", nil, c.changedFiles)
+			var names []string
+			for _, j := range filtered {
+				names = append(names, j.Name)
+			}
+			require.Equal(t, c.expectedJobs, names)
+			require.Equal(t, c.expectedAfter, filtered[len(filtered)-1].After)
+		})
+	}
+	// The candidates should not be modified
+	require.Equal(t, []string{"code", "always"}, jobs[4].After)
+}
+
+func TestListPushChangedFiles(t *testing.T) {
+	gitfake.Repos = map[string]*gitfake.Repo{
+		testRepo: {
+			CommitDiffs: map[string]*git.Diff{
+				testBefore + "..." + testAfter: {Changes: []git.Change{
+					{Filename: "docs/README.md", OldFilename: "docs/README.md"},
+					{Filename: "pkg/new.go", OldFilename: "pkg/old.go"},
+				}},
+			},
+		},
+	}
+
+	tc := map[string]struct {
+		push      *git.Push
+		noFilters bool
+
+		expectedFiles []string
+	}{
+		"normal": {
+			push:          &git.Push{Ref: "refs/heads/master", Sha: testAfter, Before: testBefore},
+			expectedFiles: []string{"docs/README.md", "pkg/new.go", "pkg/old.go"},
+		},
+		"newBranch": {
+			push: &git.Push{Ref: "refs/heads/master", Sha: testAfter, Before: "0000000000000000000000000000000000000000"},
+		},
+		"fakeSha": {
+			push: &git.Push{Ref: "refs/heads/master", Sha: git.FakeSha},
+		},
+		"noPathFilters": {
+			push:      &git.Push{Ref: "refs/heads/master", Sha: testAfter, Before: testBefore},
+			noFilters: true,
+		},
+		"diffError": {
+			push: &git.Push{Ref: "refs/heads/master", Sha: testBefore, Before: testAfter},
+		},
+	}
+
+	for name, c := range tc {
+		t.Run(name, func(t *testing.T) {
+			when := &cicdv1.JobWhen{Paths: []string{"pkg/**"}}
+			if c.noFilters {
+				when = nil
+			}
+			ic := &cicdv1.IntegrationConfig{
+				Spec: cicdv1.IntegrationConfigSpec{
+					Git: cicdv1.GitConfig{
+						Type:       cicdv1.GitTypeFake,
+						Repository: testRepo,
+						Token:      &cicdv1.GitToken{Value: "test-token"},
+					},
+					Jobs: cicdv1.IntegrationConfigJobs{
+						PostSubmit: cicdv1.Jobs{{Container: corev1.Container{Name: "test"}, When: when}},
+					},
+				},
+			}
+			require.Equal(t, c.expectedFiles, ListPushChangedFiles(ic, fake.NewClientBuilder().Build(), c.push))
+		})
+	}
+}
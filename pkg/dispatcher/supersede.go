@@ -0,0 +1,72 @@
+/*
+ Copyright 2021 The CI/CD Operator Authors
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package dispatcher
+
+import (
+	"context"
+	"fmt"
+
+	cicdv1 "github.com/tmax-cloud/cicd-operator/api/v1"
+	"github.com/tmax-cloud/cicd-operator/pkg/git"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// CancelSupersededJobs marks the pending/running pre-submit IntegrationJobs of the pull request as canceled, if they
+// are not for the pull request's current head.
+// The PipelineRuns of the canceled jobs are canceled by the IntegrationJob controller
+func CancelSupersededJobs(k8sClient client.Client, config *cicdv1.IntegrationConfig, pr *git.PullRequest) error {
+	jobs := &cicdv1.IntegrationJobList{}
+	if err := k8sClient.List(context.Background(), jobs, client.InNamespace(config.Namespace), client.MatchingLabels{cicdv1.JobLabelConfig: config.Name}); err != nil {
+		return err
+	}
+
+	for i := range jobs.Items {
+		job := &jobs.Items[i]
+		if !isSuperseded(job, pr) {
+			continue
+		}
+
+		original := job.DeepCopy()
+		job.Status.State = cicdv1.IntegrationJobStateCanceled
+		job.Status.Message = fmt.Sprintf("Superseded by %s", pr.Head.Sha)
+		if err := k8sClient.Status().Patch(context.Background(), job, client.MergeFrom(original)); err != nil {
+			return err
+		}
+		log.Info(fmt.Sprintf("Canceled superseded IntegrationJob %s/%s", job.Namespace, job.Name))
+	}
+
+	return nil
+}
+
+// isSuperseded checks if the job is an unfinished pre-submit job of the pull request, for another commit.
+// Batch jobs (having multiple pull requests) are never superseded
+func isSuperseded(job *cicdv1.IntegrationJob, pr *git.PullRequest) bool {
+	if job.Spec.ConfigRef.Type != cicdv1.JobTypePreSubmit || len(job.Spec.Refs.Pulls) != 1 {
+		return false
+	}
+	if job.Spec.Refs.Pulls[0].ID != pr.ID || job.Spec.Refs.Pulls[0].Sha == pr.Head.Sha {
+		return false
+	}
+	if job.Status.CompletionTime != nil {
+		return false
+	}
+	switch job.Status.State {
+	case "", cicdv1.IntegrationJobStatePending, cicdv1.IntegrationJobStateRunning:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,93 @@
+/*
+ Copyright 2021 The CI/CD Operator Authors
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package dispatcher
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	cicdv1 "github.com/tmax-cloud/cicd-operator/api/v1"
+	"github.com/tmax-cloud/cicd-operator/pkg/git"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestCancelSupersededJobs(t *testing.T) {
+	s := runtime.NewScheme()
+	utilruntime.Must(cicdv1.AddToScheme(s))
+
+	completed := metav1.Now()
+	newJob := func(name, config string, jobType cicdv1.JobType, state cicdv1.IntegrationJobState, pulls ...cicdv1.IntegrationJobRefsPull) *cicdv1.IntegrationJob {
+		job := &cicdv1.IntegrationJob{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "default",
+				Labels:    map[string]string{cicdv1.JobLabelConfig: config},
+			},
+			Spec: cicdv1.IntegrationJobSpec{
+				ConfigRef: cicdv1.IntegrationJobConfigRef{Name: config, Type: jobType},
+				Refs:      cicdv1.IntegrationJobRefs{Pulls: pulls},
+			},
+			Status: cicdv1.IntegrationJobStatus{State: state},
+		}
+		if state == cicdv1.IntegrationJobStateCompleted {
+			job.Status.CompletionTime = &completed
+		}
+		return job
+	}
+	oldPull := cicdv1.IntegrationJobRefsPull{ID: 3, Sha: testBefore}
+	newPull := cicdv1.IntegrationJobRefsPull{ID: 3, Sha: testAfter}
+	otherPull := cicdv1.IntegrationJobRefsPull{ID: 4, Sha: testBefore}
+
+	jobs := []client.Object{
+		newJob("pending", "test-config", cicdv1.JobTypePreSubmit, cicdv1.IntegrationJobStatePending, oldPull),
+		newJob("running", "test-config", cicdv1.JobTypePreSubmit, cicdv1.IntegrationJobStateRunning, oldPull),
+		newJob("completed", "test-config", cicdv1.JobTypePreSubmit, cicdv1.IntegrationJobStateCompleted, oldPull),
+		newJob("current", "test-config", cicdv1.JobTypePreSubmit, cicdv1.IntegrationJobStatePending, newPull),
+		newJob("other-pr", "test-config", cicdv1.JobTypePreSubmit, cicdv1.IntegrationJobStateRunning, otherPull),
+		newJob("batch", "test-config", cicdv1.JobTypePreSubmit, cicdv1.IntegrationJobStateRunning, oldPull, otherPull),
+		newJob("other-config", "other-config", cicdv1.JobTypePreSubmit, cicdv1.IntegrationJobStateRunning, oldPull),
+	}
+	fakeCli := fake.NewClientBuilder().WithScheme(s).WithObjects(jobs...).Build()
+
+	ic := &cicdv1.IntegrationConfig{ObjectMeta: metav1.ObjectMeta{Name: "test-config", Namespace: "default"}}
+	pr := &git.PullRequest{ID: 3, Action: git.PullRequestActionSynchronize, Head: git.Head{Sha: testAfter}}
+	require.NoError(t, CancelSupersededJobs(fakeCli, ic, pr))
+
+	expected := map[string]cicdv1.IntegrationJobState{
+		"pending":      cicdv1.IntegrationJobStateCanceled,
+		"running":      cicdv1.IntegrationJobStateCanceled,
+		"completed":    cicdv1.IntegrationJobStateCompleted,
+		"current":      cicdv1.IntegrationJobStatePending,
+		"other-pr":     cicdv1.IntegrationJobStateRunning,
+		"batch":        cicdv1.IntegrationJobStateRunning,
+		"other-config": cicdv1.IntegrationJobStateRunning,
+	}
+	for name, state := range expected {
+		job := &cicdv1.IntegrationJob{}
+		require.NoError(t, fakeCli.Get(context.Background(), types.NamespacedName{Name: name, Namespace: "default"}, job))
+		require.Equal(t, state, job.Status.State, name)
+		if state == cicdv1.IntegrationJobStateCanceled {
+			require.Equal(t, "Superseded by "+testAfter, job.Status.Message)
+		}
+	}
+}
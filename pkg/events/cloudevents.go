@@ -0,0 +1,188 @@
+/*
+ Copyright 2021 The CI/CD Operator Authors
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package events
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	cicdv1 "github.com/tmax-cloud/cicd-operator/api/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// CloudEventType is a type of the cloud events emitted by the operator
+type CloudEventType string
+
+// Cloud event types
+const (
+	CloudEventTypeIntegrationJobQueued      = CloudEventType("cicd.tmax.io.integrationjob.queued")
+	CloudEventTypeIntegrationJobStarted     = CloudEventType("cicd.tmax.io.integrationjob.started")
+	CloudEventTypeIntegrationJobJobStarted  = CloudEventType("cicd.tmax.io.integrationjob.job.started")
+	CloudEventTypeIntegrationJobJobFinished = CloudEventType("cicd.tmax.io.integrationjob.job.finished")
+	CloudEventTypeIntegrationJobSucceeded   = CloudEventType("cicd.tmax.io.integrationjob.succeeded")
+	CloudEventTypeIntegrationJobFailed      = CloudEventType("cicd.tmax.io.integrationjob.failed")
+	CloudEventTypeIntegrationJobTimedOut    = CloudEventType("cicd.tmax.io.integrationjob.timedout")
+	CloudEventTypeIntegrationJobCanceled    = CloudEventType("cicd.tmax.io.integrationjob.canceled")
+	CloudEventTypeApprovalRequested         = CloudEventType("cicd.tmax.io.approval.requested")
+	CloudEventTypeApprovalDecided           = CloudEventType("cicd.tmax.io.approval.decided")
+)
+
+// ReasonTimedOut is an event reason for the IntegrationJobs failed by their timeouts
+const ReasonTimedOut = "TimedOut"
+
+// integrationJobCloudEventTypes maps the event reasons of IntegrationJobs to the cloud event types
+var integrationJobCloudEventTypes = map[string]CloudEventType{
+	string(cicdv1.IntegrationJobStatePending):   CloudEventTypeIntegrationJobQueued,
+	string(cicdv1.IntegrationJobStateRunning):   CloudEventTypeIntegrationJobStarted,
+	string(cicdv1.IntegrationJobStateCompleted): CloudEventTypeIntegrationJobSucceeded,
+	string(cicdv1.IntegrationJobStateFailed):    CloudEventTypeIntegrationJobFailed,
+	string(cicdv1.IntegrationJobStateCanceled):  CloudEventTypeIntegrationJobCanceled,
+	ReasonTimedOut: CloudEventTypeIntegrationJobTimedOut,
+}
+
+// approvalCloudEventTypes maps the event reasons of Approvals to the cloud event types
+var approvalCloudEventTypes = map[string]CloudEventType{
+	string(cicdv1.ApprovalResultAwaiting): CloudEventTypeApprovalRequested,
+	string(cicdv1.ApprovalResultApproved): CloudEventTypeApprovalDecided,
+	string(cicdv1.ApprovalResultRejected): CloudEventTypeApprovalDecided,
+}
+
+// CloudEvent is a cloud event (spec v1.0) to be sent to the sink
+type CloudEvent struct {
+	ID      string
+	Source  string
+	Type    CloudEventType
+	Subject string
+	Time    time.Time
+	Data    interface{}
+}
+
+// IntegrationJobEventData is a data of the IntegrationJob's cloud events
+type IntegrationJobEventData struct {
+	Name              string                     `json:"name"`
+	Namespace         string                     `json:"namespace"`
+	IntegrationConfig string                     `json:"integrationConfig"`
+	Refs              cicdv1.IntegrationJobRefs  `json:"refs"`
+	State             cicdv1.IntegrationJobState `json:"state"`
+	Message           string                     `json:"message,omitempty"`
+	ReportURL         string                     `json:"reportUrl,omitempty"`
+
+	// Job is set only for the job-started/job-finished events
+	Job *cicdv1.JobStatus `json:"job,omitempty"`
+}
+
+// ApprovalEventData is a data of the Approval's cloud events
+type ApprovalEventData struct {
+	Name              string                     `json:"name"`
+	Namespace         string                     `json:"namespace"`
+	IntegrationJob    string                     `json:"integrationJob,omitempty"`
+	JobName           string                     `json:"jobName,omitempty"`
+	IntegrationConfig string                     `json:"integrationConfig,omitempty"`
+	Refs              *cicdv1.IntegrationJobRefs `json:"refs,omitempty"`
+	Result            cicdv1.ApprovalResult      `json:"result"`
+	Approver          string                     `json:"approver,omitempty"`
+	Reason            string                     `json:"reason,omitempty"`
+	ReportURL         string                     `json:"reportUrl,omitempty"`
+}
+
+// NewIntegrationJobCloudEvent creates a cloud event for the IntegrationJob.
+// If jobStatus is not nil, the event is for the specific job of the IntegrationJob
+func NewIntegrationJobCloudEvent(evType CloudEventType, job *cicdv1.IntegrationJob, jobStatus *cicdv1.JobStatus) *CloudEvent {
+	data := &IntegrationJobEventData{
+		Name:              job.Name,
+		Namespace:         job.Namespace,
+		IntegrationConfig: job.Spec.ConfigRef.Name,
+		Refs:              job.Spec.Refs,
+		State:             job.Status.State,
+		Message:           job.Status.Message,
+	}
+	if jobStatus != nil {
+		data.Job = jobStatus.DeepCopy()
+		data.ReportURL = job.GetReportServerAddress(jobStatus.Name)
+	} else if jobs := job.Spec.Jobs.ExpandMatrix(); len(jobs) > 0 {
+		data.ReportURL = job.GetReportServerAddress(jobs[0].Name)
+	}
+
+	return &CloudEvent{
+		ID:      uuid.New().String(),
+		Source:  fmt.Sprintf("/namespaces/%s/integrationconfigs/%s", job.Namespace, job.Spec.ConfigRef.Name),
+		Type:    evType,
+		Subject: job.Name,
+		Time:    time.Now(),
+		Data:    data,
+	}
+}
+
+// NewApprovalCloudEvent creates a cloud event for the Approval.
+// The IntegrationJob of the Approval can be nil, if it is not found
+func NewApprovalCloudEvent(evType CloudEventType, approval *cicdv1.Approval, job *cicdv1.IntegrationJob) *CloudEvent {
+	data := &ApprovalEventData{
+		Name:           approval.Name,
+		Namespace:      approval.Namespace,
+		IntegrationJob: approval.Spec.IntegrationJob,
+		JobName:        approval.Spec.JobName,
+		Result:         approval.Status.Result,
+		Approver:       approval.Status.Approver,
+		Reason:         approval.Status.Reason,
+	}
+	if job != nil {
+		refs := job.Spec.Refs
+		data.Refs = &refs
+		data.IntegrationConfig = job.Spec.ConfigRef.Name
+		data.ReportURL = job.GetReportServerAddress(approval.Spec.JobName)
+	}
+
+	return &CloudEvent{
+		ID:      uuid.New().String(),
+		Source:  fmt.Sprintf("/namespaces/%s/approvals", approval.Namespace),
+		Type:    evType,
+		Subject: approval.Name,
+		Time:    time.Now(),
+		Data:    data,
+	}
+}
+
+// newCloudEvent creates a cloud event for the object, from the reason of the k8s event.
+// It returns nil if the object and the reason do not match any cloud event type
+func newCloudEvent(c client.Client, obj runtime.Object, reason string) *CloudEvent {
+	switch o := obj.(type) {
+	case *cicdv1.IntegrationJob:
+		evType, exist := integrationJobCloudEventTypes[reason]
+		if !exist {
+			return nil
+		}
+		return NewIntegrationJobCloudEvent(evType, o, nil)
+	case *cicdv1.Approval:
+		evType, exist := approvalCloudEventTypes[reason]
+		if !exist {
+			return nil
+		}
+		var job *cicdv1.IntegrationJob
+		if o.Spec.IntegrationJob != "" {
+			job = &cicdv1.IntegrationJob{}
+			if err := c.Get(context.Background(), types.NamespacedName{Name: o.Spec.IntegrationJob, Namespace: o.Namespace}, job); err != nil {
+				job = nil
+			}
+		}
+		return NewApprovalCloudEvent(evType, o, job)
+	}
+	return nil
+}
@@ -30,21 +30,27 @@ import (
 
 // Emit emits event. Both k8s event and cloud event
 func Emit(c client.Client, obj runtime.Object, evType, reason, message string) error {
-	ref, err := getObjectReference(obj)
-	if err != nil {
-		return err
-	}
-
 	// Emit K8s event
-	if err := emitK8sEvent(c, ref, evType, reason, message); err != nil {
+	if err := EmitK8sEvent(c, obj, evType, reason, message); err != nil {
 		return err
 	}
 
-	// TODO - Emit cloud event
+	// Emit cloud event
+	EmitCloudEvent(newCloudEvent(c, obj, reason))
 
 	return nil
 }
 
+// EmitK8sEvent emits only the k8s event
+func EmitK8sEvent(c client.Client, obj runtime.Object, evType, reason, message string) error {
+	ref, err := getObjectReference(obj)
+	if err != nil {
+		return err
+	}
+
+	return emitK8sEvent(c, ref, evType, reason, message)
+}
+
 func emitK8sEvent(c client.Client, ref *corev1.ObjectReference, evType, reason, message string) error {
 	t := metav1.Time{Time: time.Now()}
 	ev := &corev1.Event{
@@ -0,0 +1,164 @@
+/*
+ Copyright 2021 The CI/CD Operator Authors
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package events
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/tmax-cloud/cicd-operator/internal/configs"
+	"k8s.io/apimachinery/pkg/util/wait"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// Content modes of the cloud events
+const (
+	CloudEventsModeBinary     = "binary"
+	CloudEventsModeStructured = "structured"
+)
+
+const (
+	cloudEventsSpecVersion = "1.0"
+	cloudEventsQueueSize   = 1000
+)
+
+var log = logf.Log.WithName("events")
+
+// cloudEventBackoff is a backoff for retrying to send cloud events
+var cloudEventBackoff = wait.Backoff{
+	Duration: 1 * time.Second,
+	Factor:   2,
+	Steps:    5,
+}
+
+var (
+	cloudEventQueue     chan *CloudEvent
+	cloudEventQueueOnce sync.Once
+)
+
+// EmitCloudEvent queues the cloud event to be sent to the sink. Events are sent one by one in the queued order.
+// It is a no-op if the sink is not configured
+func EmitCloudEvent(ev *CloudEvent) {
+	if ev == nil || configs.CloudEventsSink == "" {
+		return
+	}
+
+	cloudEventQueueOnce.Do(func() {
+		cloudEventQueue = make(chan *CloudEvent, cloudEventsQueueSize)
+		go sendCloudEvents()
+	})
+
+	select {
+	case cloudEventQueue <- ev:
+	default:
+		log.Info(fmt.Sprintf("cloud event queue is full, dropping event %s (%s)", ev.ID, ev.Type))
+	}
+}
+
+func sendCloudEvents() {
+	for ev := range cloudEventQueue {
+		if err := sendCloudEvent(configs.CloudEventsSink, configs.CloudEventsMode, ev); err != nil {
+			log.Error(err, fmt.Sprintf("cannot send cloud event %s (%s)", ev.ID, ev.Type))
+		}
+	}
+}
+
+// sendCloudEvent sends the cloud event to the sink, retrying with a backoff.
+// It retries only for the network errors and the server errors (5xx, 429)
+func sendCloudEvent(sink, mode string, ev *CloudEvent) error {
+	req, err := newCloudEventRequest(sink, mode, ev)
+	if err != nil {
+		return err
+	}
+
+	var lastErr error
+	if err := wait.ExponentialBackoff(cloudEventBackoff, func() (bool, error) {
+		req.Body, _ = req.GetBody()
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			lastErr = err
+			return false, nil
+		}
+		_ = resp.Body.Close()
+
+		switch {
+		case resp.StatusCode >= 200 && resp.StatusCode < 300:
+			return true, nil
+		case resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests:
+			lastErr = fmt.Errorf("sink responded %d", resp.StatusCode)
+			return false, nil
+		default:
+			return false, fmt.Errorf("sink responded %d", resp.StatusCode)
+		}
+	}); err != nil {
+		if err == wait.ErrWaitTimeout && lastErr != nil {
+			return lastErr
+		}
+		return err
+	}
+	return nil
+}
+
+// newCloudEventRequest generates a http request for the cloud event, following the cloud events' http protocol binding
+func newCloudEventRequest(sink, mode string, ev *CloudEvent) (*http.Request, error) {
+	data, err := json.Marshal(ev.Data)
+	if err != nil {
+		return nil, err
+	}
+
+	var body []byte
+	header := http.Header{}
+	switch mode {
+	case CloudEventsModeStructured:
+		body, err = json.Marshal(map[string]interface{}{
+			"specversion":     cloudEventsSpecVersion,
+			"id":              ev.ID,
+			"source":          ev.Source,
+			"type":            ev.Type,
+			"subject":         ev.Subject,
+			"time":            ev.Time.UTC().Format(time.RFC3339Nano),
+			"datacontenttype": "application/json",
+			"data":            json.RawMessage(data),
+		})
+		if err != nil {
+			return nil, err
+		}
+		header.Set("Content-Type", "application/cloudevents+json")
+	case CloudEventsModeBinary, "":
+		body = data
+		header.Set("Content-Type", "application/json")
+		header.Set("ce-specversion", cloudEventsSpecVersion)
+		header.Set("ce-id", ev.ID)
+		header.Set("ce-source", ev.Source)
+		header.Set("ce-type", string(ev.Type))
+		header.Set("ce-subject", ev.Subject)
+		header.Set("ce-time", ev.Time.UTC().Format(time.RFC3339Nano))
+	default:
+		return nil, fmt.Errorf("cloud events mode %s is not supported", mode)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, sink, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header = header
+	return req, nil
+}
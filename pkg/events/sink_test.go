@@ -0,0 +1,210 @@
+/*
+ Copyright 2021 The CI/CD Operator Authors
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package events
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	cicdv1 "github.com/tmax-cloud/cicd-operator/api/v1"
+	"github.com/tmax-cloud/cicd-operator/internal/configs"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+type receivedEvent struct {
+	header http.Header
+	body   []byte
+}
+
+// newTestReceiver runs a local cloud event receiver, which responds with the given status codes in order
+func newTestReceiver(codes ...int) (*httptest.Server, chan receivedEvent) {
+	received := make(chan receivedEvent, 10)
+	idx := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		code := http.StatusOK
+		if idx < len(codes) {
+			code = codes[idx]
+		}
+		idx++
+		w.WriteHeader(code)
+		if code == http.StatusOK {
+			received <- receivedEvent{header: r.Header, body: body}
+		}
+	}))
+	return srv, received
+}
+
+func testJob() *cicdv1.IntegrationJob {
+	return &cicdv1.IntegrationJob{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-job", Namespace: "test-ns"},
+		Spec: cicdv1.IntegrationJobSpec{
+			ConfigRef: cicdv1.IntegrationJobConfigRef{Name: "test-config"},
+			Jobs:      cicdv1.Jobs{{Container: corev1.Container{Name: "test-1"}}},
+			Refs: cicdv1.IntegrationJobRefs{
+				Repository: "tmax-cloud/cicd-operator",
+				Base:       cicdv1.IntegrationJobRefsBase{Ref: "refs/heads/master", Sha: "sha-1"},
+			},
+		},
+		Status: cicdv1.IntegrationJobStatus{State: cicdv1.IntegrationJobStateCompleted},
+	}
+}
+
+func TestSendCloudEvent(t *testing.T) {
+	cloudEventBackoff = wait.Backoff{Duration: time.Millisecond, Factor: 1, Steps: 3}
+
+	tc := map[string]struct {
+		mode  string
+		codes []int
+
+		errorOccurs  bool
+		errorMessage string
+	}{
+		"binary": {
+			mode: CloudEventsModeBinary,
+		},
+		"structured": {
+			mode: CloudEventsModeStructured,
+		},
+		"retry": {
+			mode:  CloudEventsModeBinary,
+			codes: []int{http.StatusInternalServerError, http.StatusTooManyRequests},
+		},
+		"retryExceeded": {
+			mode:         CloudEventsModeBinary,
+			codes:        []int{http.StatusInternalServerError, http.StatusInternalServerError, http.StatusInternalServerError},
+			errorOccurs:  true,
+			errorMessage: "sink responded 500",
+		},
+		"badRequest": {
+			mode:         CloudEventsModeBinary,
+			codes:        []int{http.StatusBadRequest},
+			errorOccurs:  true,
+			errorMessage: "sink responded 400",
+		},
+		"unknownMode": {
+			mode:         "unknown",
+			errorOccurs:  true,
+			errorMessage: "cloud events mode unknown is not supported",
+		},
+	}
+
+	for name, c := range tc {
+		t.Run(name, func(t *testing.T) {
+			srv, received := newTestReceiver(c.codes...)
+			defer srv.Close()
+
+			ev := NewIntegrationJobCloudEvent(CloudEventTypeIntegrationJobSucceeded, testJob(), nil)
+			err := sendCloudEvent(srv.URL, c.mode, ev)
+			if c.errorOccurs {
+				require.Error(t, err)
+				require.Equal(t, c.errorMessage, err.Error())
+				return
+			}
+			require.NoError(t, err)
+
+			r := <-received
+			data := &IntegrationJobEventData{}
+			switch c.mode {
+			case CloudEventsModeBinary:
+				require.Equal(t, "application/json", r.header.Get("Content-Type"))
+				require.Equal(t, "1.0", r.header.Get("ce-specversion"))
+				require.Equal(t, ev.ID, r.header.Get("ce-id"))
+				require.Equal(t, "/namespaces/test-ns/integrationconfigs/test-config", r.header.Get("ce-source"))
+				require.Equal(t, string(CloudEventTypeIntegrationJobSucceeded), r.header.Get("ce-type"))
+				require.Equal(t, "test-job", r.header.Get("ce-subject"))
+				require.NoError(t, json.Unmarshal(r.body, data))
+			case CloudEventsModeStructured:
+				require.Equal(t, "application/cloudevents+json", r.header.Get("Content-Type"))
+				structured := struct {
+					SpecVersion string                   `json:"specversion"`
+					ID          string                   `json:"id"`
+					Type        string                   `json:"type"`
+					Data        *IntegrationJobEventData `json:"data"`
+				}{Data: data}
+				require.NoError(t, json.Unmarshal(r.body, &structured))
+				require.Equal(t, "1.0", structured.SpecVersion)
+				require.Equal(t, ev.ID, structured.ID)
+				require.Equal(t, string(CloudEventTypeIntegrationJobSucceeded), structured.Type)
+			}
+			require.Equal(t, "test-config", data.IntegrationConfig)
+			require.Equal(t, "sha-1", data.Refs.Base.Sha)
+			require.Equal(t, cicdv1.IntegrationJobStateCompleted, data.State)
+			require.Equal(t, "http:///report/test-ns/test-job/test-1", data.ReportURL)
+		})
+	}
+}
+
+func TestEmit_cloudEvent(t *testing.T) {
+	s := runtime.NewScheme()
+	utilruntime.Must(corev1.AddToScheme(s))
+	utilruntime.Must(cicdv1.AddToScheme(s))
+
+	srv, received := newTestReceiver()
+	defer srv.Close()
+
+	configs.CloudEventsSink = srv.URL
+	configs.CloudEventsMode = CloudEventsModeBinary
+	defer func() {
+		configs.CloudEventsSink = ""
+	}()
+
+	job := testJob()
+	approval := &cicdv1.Approval{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-approval", Namespace: "test-ns"},
+		Spec:       cicdv1.ApprovalSpec{IntegrationJob: "test-job", JobName: "test-1"},
+		Status:     cicdv1.ApprovalStatus{Result: cicdv1.ApprovalResultApproved, Approver: "admin"},
+	}
+	fakeCli := fake.NewClientBuilder().WithScheme(s).WithObjects(job, approval).Build()
+
+	// IntegrationJob
+	require.NoError(t, Emit(fakeCli, job, corev1.EventTypeNormal, ReasonTimedOut, ""))
+	r := <-received
+	require.Equal(t, string(CloudEventTypeIntegrationJobTimedOut), r.header.Get("ce-type"))
+
+	// Reasons not mapped to cloud events
+	require.NoError(t, Emit(fakeCli, job, corev1.EventTypeNormal, "Unknown", ""))
+	require.NoError(t, Emit(fakeCli, approval, corev1.EventTypeWarning, "ApproveNotAllowed", ""))
+
+	// Approval
+	require.NoError(t, Emit(fakeCli, approval, corev1.EventTypeNormal, string(cicdv1.ApprovalResultApproved), ""))
+	r = <-received
+	require.Equal(t, string(CloudEventTypeApprovalDecided), r.header.Get("ce-type"))
+	data := &ApprovalEventData{}
+	require.NoError(t, json.Unmarshal(r.body, data))
+	require.Equal(t, "admin", data.Approver)
+	require.Equal(t, "test-config", data.IntegrationConfig)
+	require.NotNil(t, data.Refs)
+	require.Equal(t, "refs/heads/master", string(data.Refs.Base.Ref))
+	require.Equal(t, "http:///report/test-ns/test-job/test-1", data.ReportURL)
+
+	select {
+	case <-received:
+		t.Fatal("unexpected cloud event is sent")
+	case <-time.After(100 * time.Millisecond):
+	}
+}
@@ -0,0 +1,819 @@
+/*
+ Copyright 2021 The CI/CD Operator Authors
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package azuredevops
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"hash/crc32"
+	"net/http"
+	"net/url"
+	"strings"
+
+	cicdv1 "github.com/tmax-cloud/cicd-operator/api/v1"
+	"github.com/tmax-cloud/cicd-operator/pkg/git"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	apiVersion        = "6.0"
+	apiVersionPreview = "6.0-preview.1"
+
+	vsspsDefaultAPIUrl = "https://vssps.dev.azure.com"
+)
+
+// Client is an Azure DevOps Repos client struct
+type Client struct {
+	IntegrationConfig *cicdv1.IntegrationConfig
+	K8sClient         client.Client
+
+	// Context is a context of the API calls, which carries the caller's trace
+	Context context.Context
+
+	header map[string]string
+}
+
+// Init initiates the Client
+func (c *Client) Init() error {
+	token, err := c.IntegrationConfig.GetToken(c.K8sClient)
+	if err != nil {
+		return err
+	}
+
+	c.header = map[string]string{
+		"Accept":       "application/json",
+		"Content-Type": "application/json",
+	}
+	if token != "" {
+		// Personal access tokens are sent as a basic auth password, with an empty user name
+		c.header["Authorization"] = "Basic " + base64.StdEncoding.EncodeToString([]byte(":"+token))
+	}
+	return nil
+}
+
+// ParseWebhook parses a service hook body for Azure DevOps
+func (c *Client) ParseWebhook(header http.Header, jsonString []byte) (*git.Webhook, error) {
+	if err := Validate(c.IntegrationConfig.GetWebhookSecrets(), header.Get("Authorization")); err != nil {
+		return nil, err
+	}
+
+	event := &Event{}
+	if err := json.Unmarshal(jsonString, event); err != nil {
+		return nil, err
+	}
+
+	var wh *git.Webhook
+	var err error
+	switch event.EventType {
+	case EventTypePush:
+		wh, err = c.parsePushWebhook(jsonString)
+	case EventTypePullRequestCreated, EventTypePullRequestUpdated, EventTypePullRequestMerged:
+		wh, err = c.parsePullRequestWebhook(jsonString)
+	case EventTypePullRequestCommented:
+		wh, err = c.parsePullRequestCommentWebhook(jsonString)
+	}
+	if wh != nil {
+		wh.DeliveryID = event.ID
+	}
+	return wh, err
+}
+
+// ListWebhook lists registered service hooks
+func (c *Client) ListWebhook() ([]git.WebhookEntry, error) {
+	subscriptions, err := c.listSubscriptions()
+	if err != nil {
+		return nil, err
+	}
+
+	var result []git.WebhookEntry
+	for _, s := range subscriptions {
+		result = append(result, git.WebhookEntry{ID: subscriptionID(s.ID), URL: s.ConsumerInputs["url"]})
+	}
+
+	return result, nil
+}
+
+// RegisterWebhook registers our webhook server to the remote git server
+// Azure DevOps service hooks are subscribed per event type, so a subscription is created for each event type
+func (c *Client) RegisterWebhook(uri string) error {
+	repo, err := c.getRepository()
+	if err != nil {
+		return err
+	}
+
+	org, _, _ := c.orgProjectRepo()
+	apiURL := withAPIVersion(fmt.Sprintf("%s/%s/_apis/hooks/subscriptions", c.IntegrationConfig.Spec.Git.GetAPIUrl(), org), apiVersion)
+
+	for _, eventType := range []string{EventTypePush, EventTypePullRequestCreated, EventTypePullRequestUpdated, EventTypePullRequestMerged, EventTypePullRequestCommented} {
+		subscription := &Subscription{
+			PublisherID:      "tfs",
+			EventType:        eventType,
+			ResourceVersion:  "1.0",
+			ConsumerID:       "webHooks",
+			ConsumerActionID: "httpRequest",
+			PublisherInputs: map[string]string{
+				"projectId":  repo.Project.ID,
+				"repository": repo.ID,
+			},
+			ConsumerInputs: map[string]string{
+				"url":               uri,
+				"basicAuthUsername": webhookBasicAuthUserName,
+				"basicAuthPassword": c.IntegrationConfig.Status.Secrets,
+			},
+		}
+		if _, _, err := c.requestHTTP(http.MethodPost, apiURL, subscription); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// DeleteWebhook deletes registered service hook
+func (c *Client) DeleteWebhook(id int) error {
+	subscriptions, err := c.listSubscriptions()
+	if err != nil {
+		return err
+	}
+
+	org, _, _ := c.orgProjectRepo()
+	for _, s := range subscriptions {
+		if subscriptionID(s.ID) != id {
+			continue
+		}
+		apiURL := withAPIVersion(fmt.Sprintf("%s/%s/_apis/hooks/subscriptions/%s", c.IntegrationConfig.Spec.Git.GetAPIUrl(), org, s.ID), apiVersion)
+		if _, _, err := c.requestHTTP(http.MethodDelete, apiURL, nil); err != nil {
+			return err
+		}
+		return nil
+	}
+
+	return fmt.Errorf("webhook %d doesn't exists", id)
+}
+
+// listSubscriptions lists service hook subscriptions for the repository
+func (c *Client) listSubscriptions() ([]Subscription, error) {
+	repo, err := c.getRepository()
+	if err != nil {
+		return nil, err
+	}
+
+	org, _, _ := c.orgProjectRepo()
+	apiURL := withAPIVersion(fmt.Sprintf("%s/%s/_apis/hooks/subscriptions", c.IntegrationConfig.Spec.Git.GetAPIUrl(), org), apiVersion)
+
+	var subscriptions []Subscription
+	if err := c.getList(apiURL, &subscriptions); err != nil {
+		return nil, err
+	}
+
+	var result []Subscription
+	for _, s := range subscriptions {
+		if s.ConsumerID != "webHooks" || s.PublisherInputs["repository"] != repo.ID {
+			continue
+		}
+		result = append(result, s)
+	}
+	return result, nil
+}
+
+// ListCommitStatuses lists commit status of the specific commit
+func (c *Client) ListCommitStatuses(ref string) ([]git.CommitStatus, error) {
+	apiURL := withAPIVersion(c.repoAPIURL(fmt.Sprintf("/commits/%s/statuses", ref)), apiVersion)
+
+	var statuses []Status
+	if err := c.getList(apiURL, &statuses); err != nil {
+		return nil, err
+	}
+
+	// Temp map for filtering duplicated contexts
+	tmp := map[string]struct{}{}
+
+	var resp []git.CommitStatus
+	for _, s := range statuses {
+		_, exist := tmp[s.Context.Name]
+		if exist {
+			continue
+		}
+		tmp[s.Context.Name] = struct{}{}
+		resp = append(resp, git.CommitStatus{
+			Context:     s.Context.Name,
+			State:       convertStatusState(s.State),
+			Description: s.Description,
+			TargetURL:   s.TargetURL,
+		})
+	}
+
+	return resp, nil
+}
+
+// SetCommitStatus sets commit status for the specific commit
+// The status is also set to the active pull requests whose head is the commit, using the pull request status API
+func (c *Client) SetCommitStatus(sha string, status git.CommitStatus) error {
+	// Don't set commit status if its' sha is a fake
+	if sha == git.FakeSha {
+		return nil
+	}
+
+	body := &Status{
+		State:       convertCommitStatusState(status.State),
+		Description: status.Description,
+		TargetURL:   status.TargetURL,
+		Context: StatusContext{
+			Name:  status.Context,
+			Genre: statusGenre,
+		},
+	}
+
+	apiURL := withAPIVersion(c.repoAPIURL(fmt.Sprintf("/commits/%s/statuses", sha)), apiVersion)
+	if _, _, err := c.requestHTTP(http.MethodPost, apiURL, body); err != nil {
+		return err
+	}
+
+	prs, err := c.listPullRequests(true)
+	if err != nil {
+		return err
+	}
+	for _, pr := range prs {
+		if pr.LastMergeSourceCommit.CommitID != sha {
+			continue
+		}
+		prStatusURL := withAPIVersion(c.repoAPIURL(fmt.Sprintf("/pullRequests/%d/statuses", pr.PullRequestID)), apiVersionPreview)
+		if _, _, err := c.requestHTTP(http.MethodPost, prStatusURL, body); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// GetUserInfo gets a user's information
+func (c *Client) GetUserInfo(userName string) (*git.User, error) {
+	identity, err := c.getIdentity(userName)
+	if err != nil {
+		return nil, err
+	}
+
+	user := &git.User{Name: userName}
+	if account, ok := identity.Properties["Account"]; ok && account.Value != "" {
+		user.Name = account.Value
+	}
+	if mail, ok := identity.Properties["Mail"]; ok {
+		user.Email = mail.Value
+	}
+	return user, nil
+}
+
+func (c *Client) getIdentity(userName string) (*Identity, error) {
+	org, _, _ := c.orgProjectRepo()
+
+	// Identities are served by a separate host for the Azure DevOps Services
+	identityURL := c.IntegrationConfig.Spec.Git.GetAPIUrl()
+	if identityURL == cicdv1.AzureDevOpsDefaultAPIUrl {
+		identityURL = vsspsDefaultAPIUrl
+	}
+	apiURL := withAPIVersion(fmt.Sprintf("%s/%s/_apis/identities?searchFilter=General&filterValue=%s&queryMembership=None", identityURL, org, url.QueryEscape(userName)), apiVersion)
+
+	var identities []Identity
+	if err := c.getList(apiURL, &identities); err != nil {
+		return nil, err
+	}
+	if len(identities) == 0 {
+		return nil, fmt.Errorf("user %s doesn't exists", userName)
+	}
+	return &identities[0], nil
+}
+
+// CanUserWriteToRepo decides if the user has write permission on the repo
+// It checks if the user is allowed to contribute to the repository, in the Git Repositories security namespace
+func (c *Client) CanUserWriteToRepo(user git.User) (bool, error) {
+	repo, err := c.getRepository()
+	if err != nil {
+		return false, err
+	}
+
+	identity, err := c.getIdentity(user.Name)
+	if err != nil {
+		return false, err
+	}
+
+	org, _, _ := c.orgProjectRepo()
+	token := fmt.Sprintf("repoV2/%s/%s", repo.Project.ID, repo.ID)
+	apiURL := withAPIVersion(fmt.Sprintf("%s/%s/_apis/accesscontrollists/%s?token=%s&descriptors=%s&includeExtendedInfo=true", c.IntegrationConfig.Spec.Git.GetAPIUrl(), org, gitSecurityNamespace, url.QueryEscape(token), url.QueryEscape(identity.Descriptor)), apiVersion)
+
+	var acls []AccessControlList
+	if err := c.getList(apiURL, &acls); err != nil {
+		return false, err
+	}
+
+	for _, acl := range acls {
+		for _, ace := range acl.ACEs {
+			if ace.ExtendedInfo.EffectiveAllow&gitPermissionContribute != 0 {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+// RegisterComment registers comment to an issue
+// Comments are registered as a new thread of the pull request
+func (c *Client) RegisterComment(issueType git.IssueType, issueNo int, _, body string) error {
+	if issueType != git.IssueTypePullRequest {
+		return fmt.Errorf("issue type %s is not supported", issueType)
+	}
+
+	apiURL := withAPIVersion(c.repoAPIURL(fmt.Sprintf("/pullRequests/%d/threads", issueNo)), apiVersion)
+	thread := &CreateThreadBody{
+		Comments: []CreateThreadComment{{ParentCommentID: 0, Content: body, CommentType: 1}},
+		Status:   1,
+	}
+	if _, _, err := c.requestHTTP(http.MethodPost, apiURL, thread); err != nil {
+		return err
+	}
+	return nil
+}
+
+// ListComments lists comments of the issue id
+func (c *Client) ListComments(issueNo int) ([]git.IssueComment, error) {
+	apiURL := withAPIVersion(c.repoAPIURL(fmt.Sprintf("/pullRequests/%d/threads", issueNo)), apiVersion)
+
+	var threads []Thread
+	if err := c.getList(apiURL, &threads); err != nil {
+		return nil, err
+	}
+
+	var comments []git.IssueComment
+	for _, thread := range threads {
+		// Vote threads
+		if threadType, ok := thread.Properties["CodeReviewThreadType"]; ok && threadType.Value == "VoteUpdate" {
+			state := convertVote(thread.Properties["CodeReviewVoteResult"].Value)
+			if state == "" {
+				continue
+			}
+			comment := git.IssueComment{Comment: git.Comment{CreatedAt: thread.PublishedDate}, ReviewState: state}
+			if len(thread.Comments) > 0 {
+				comment.Author = *convertUser(&thread.Comments[0].Author)
+			}
+			comments = append(comments, comment)
+			continue
+		}
+
+		for _, comment := range thread.Comments {
+			if comment.CommentType != "text" {
+				continue
+			}
+			comments = append(comments, git.IssueComment{
+				Comment: git.Comment{
+					Body:      comment.Content,
+					CreatedAt: comment.PublishedDate,
+				},
+				Author: *convertUser(&comment.Author),
+			})
+		}
+	}
+	return comments, nil
+}
+
+// ListPullRequests gets pull request list
+func (c *Client) ListPullRequests(onlyOpen bool) ([]git.PullRequest, error) {
+	prs, err := c.listPullRequests(onlyOpen)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []git.PullRequest
+	for i := range prs {
+		if !prs[i].IsDraft {
+			result = append(result, *convertPullRequestToShared(&prs[i]))
+		}
+	}
+
+	return result, nil
+}
+
+func (c *Client) listPullRequests(onlyOpen bool) ([]PullRequest, error) {
+	status := PullRequestStatusActive
+	if !onlyOpen {
+		status = "all"
+	}
+
+	const pageSize = 100
+	var prs []PullRequest
+	for skip := 0; ; skip += pageSize {
+		apiURL := withAPIVersion(c.repoAPIURL(fmt.Sprintf("/pullrequests?searchCriteria.status=%s&$top=%d&$skip=%d", status, pageSize, skip)), apiVersion)
+		var page []PullRequest
+		if err := c.getList(apiURL, &page); err != nil {
+			return nil, err
+		}
+		prs = append(prs, page...)
+		if len(page) < pageSize {
+			return prs, nil
+		}
+	}
+}
+
+// GetPullRequest gets PR given id
+func (c *Client) GetPullRequest(id int) (*git.PullRequest, error) {
+	apiURL := withAPIVersion(c.repoAPIURL(fmt.Sprintf("/pullrequests/%d", id)), apiVersion)
+
+	data, _, err := c.requestHTTP(http.MethodGet, apiURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	pr := &PullRequest{}
+	if err := json.Unmarshal(data, pr); err != nil {
+		return nil, err
+	}
+
+	return convertPullRequestToShared(pr), nil
+}
+
+// MergePullRequest merges a pull request, by completing it
+func (c *Client) MergePullRequest(id int, sha string, method git.MergeMethod, message string) error {
+	apiURL := withAPIVersion(c.repoAPIURL(fmt.Sprintf("/pullrequests/%d", id)), apiVersion)
+
+	strategy := "noFastForward"
+	if method == git.MergeMethodSquash {
+		strategy = "squash"
+	}
+
+	body := &CompletePullRequestBody{
+		Status:                PullRequestStatusCompleted,
+		LastMergeSourceCommit: CommitRef{CommitID: sha},
+		CompletionOptions: CompletionOptions{
+			MergeStrategy:      strategy,
+			MergeCommitMessage: message,
+		},
+	}
+
+	if _, _, err := c.requestHTTP(http.MethodPatch, apiURL, body); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// GetPullRequestDiff gets diff of the pull request
+// Changed files are fetched from the latest iteration of the pull request. Azure DevOps does not provide the number
+// of changed lines, so only the file names are filled
+func (c *Client) GetPullRequestDiff(id int) (*git.Diff, error) {
+	iterationsURL := withAPIVersion(c.repoAPIURL(fmt.Sprintf("/pullRequests/%d/iterations", id)), apiVersion)
+
+	var iterations []Iteration
+	if err := c.getList(iterationsURL, &iterations); err != nil {
+		return nil, err
+	}
+	if len(iterations) == 0 {
+		return &git.Diff{}, nil
+	}
+	lastIteration := iterations[0].ID
+	for _, i := range iterations {
+		if i.ID > lastIteration {
+			lastIteration = i.ID
+		}
+	}
+
+	apiURL := withAPIVersion(c.repoAPIURL(fmt.Sprintf("/pullRequests/%d/iterations/%d/changes?$compareTo=0", id, lastIteration)), apiVersion)
+	raw, _, err := c.requestHTTP(http.MethodGet, apiURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &IterationChanges{}
+	if err := json.Unmarshal(raw, resp); err != nil {
+		return nil, err
+	}
+
+	var changes []git.Change
+	for _, entry := range resp.ChangeEntries {
+		fileName := strings.TrimPrefix(entry.Item.Path, "/")
+		oldFileName := strings.TrimPrefix(entry.OriginalPath, "/")
+		if oldFileName == "" {
+			oldFileName = fileName
+		}
+		changes = append(changes, git.Change{
+			Filename:    fileName,
+			OldFilename: oldFileName,
+		})
+	}
+
+	return &git.Diff{Changes: changes}, nil
+}
+
+// GetFileContent gets the content of the file at the ref (commit)
+func (c *Client) GetFileContent(path, ref string) ([]byte, error) {
+	query := fmt.Sprintf("/items?path=%s&versionDescriptor.version=%s&versionDescriptor.versionType=commit&$format=octetStream", url.QueryEscape("/"+strings.TrimPrefix(path, "/")), url.QueryEscape(ref))
+	raw, _, err := c.requestHTTP(http.MethodGet, withAPIVersion(c.repoAPIURL(query), apiVersion), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return raw, nil
+}
+
+// ListPullRequestCommits lists commits list of a pull request
+func (c *Client) ListPullRequestCommits(id int) ([]git.Commit, error) {
+	apiURL := withAPIVersion(c.repoAPIURL(fmt.Sprintf("/pullRequests/%d/commits", id)), apiVersion)
+
+	var resp []CommitInfo
+	if err := c.getList(apiURL, &resp); err != nil {
+		return nil, err
+	}
+
+	var commits []git.Commit
+	for _, commit := range resp {
+		commits = append(commits, git.Commit{
+			SHA:     commit.CommitID,
+			Message: commit.Comment,
+			Author: git.User{
+				Name:  commit.Author.Name,
+				Email: commit.Author.Email,
+			},
+			Committer: git.User{
+				Name:  commit.Committer.Name,
+				Email: commit.Committer.Email,
+			},
+		})
+	}
+
+	return commits, nil
+}
+
+// GetCommitDiff gets diff between the base and head commits
+func (c *Client) GetCommitDiff(base, head string) (*git.Diff, error) {
+	query := fmt.Sprintf("/diffs/commits?baseVersion=%s&baseVersionType=commit&targetVersion=%s&targetVersionType=commit", url.QueryEscape(base), url.QueryEscape(head))
+	raw, _, err := c.requestHTTP(http.MethodGet, withAPIVersion(c.repoAPIURL(query), apiVersion), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &CommitDiffs{}
+	if err := json.Unmarshal(raw, resp); err != nil {
+		return nil, err
+	}
+
+	var changes []git.Change
+	for _, entry := range resp.Changes {
+		if entry.Item.IsFolder {
+			continue
+		}
+		fileName := strings.TrimPrefix(entry.Item.Path, "/")
+		oldFileName := strings.TrimPrefix(entry.OriginalPath, "/")
+		if oldFileName == "" {
+			oldFileName = fileName
+		}
+		changes = append(changes, git.Change{
+			Filename:    fileName,
+			OldFilename: oldFileName,
+		})
+	}
+
+	return &git.Diff{Changes: changes}, nil
+}
+
+// SetLabel sets label (tag) to the issue id
+func (c *Client) SetLabel(issueType git.IssueType, id int, label string) error {
+	if issueType != git.IssueTypePullRequest {
+		return fmt.Errorf("issue type %s is not supported", issueType)
+	}
+
+	apiURL := withAPIVersion(c.repoAPIURL(fmt.Sprintf("/pullRequests/%d/labels", id)), apiVersionPreview)
+	if _, _, err := c.requestHTTP(http.MethodPost, apiURL, &WebAPITagDefinition{Name: label}); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// ListLabels lists labels (tags) of pr id
+func (c *Client) ListLabels(id int) ([]git.IssueLabel, error) {
+	apiURL := withAPIVersion(c.repoAPIURL(fmt.Sprintf("/pullRequests/%d/labels", id)), apiVersionPreview)
+
+	var labels []WebAPITagDefinition
+	if err := c.getList(apiURL, &labels); err != nil {
+		return nil, err
+	}
+
+	return convertLabel(labels), nil
+}
+
+// DeleteLabel deletes label (tag) from the issue id
+func (c *Client) DeleteLabel(issueType git.IssueType, id int, label string) error {
+	if issueType != git.IssueTypePullRequest {
+		return fmt.Errorf("issue type %s is not supported", issueType)
+	}
+
+	apiURL := withAPIVersion(c.repoAPIURL(fmt.Sprintf("/pullRequests/%d/labels/%s", id, url.PathEscape(label))), apiVersionPreview)
+	if _, _, err := c.requestHTTP(http.MethodDelete, apiURL, nil); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// GetBranch gets branch info
+func (c *Client) GetBranch(branch string) (*git.Branch, error) {
+	apiURL := withAPIVersion(c.repoAPIURL("/refs?filter="+url.QueryEscape("heads/"+branch)), apiVersion)
+
+	var refs []Ref
+	if err := c.getList(apiURL, &refs); err != nil {
+		return nil, err
+	}
+
+	for _, ref := range refs {
+		if ref.Name == "refs/heads/"+branch {
+			return &git.Branch{Name: branch, CommitID: ref.ObjectID}, nil
+		}
+	}
+
+	return nil, fmt.Errorf("branch %s doesn't exists", branch)
+}
+
+// getRepository gets the repository, including its id and project id
+func (c *Client) getRepository() (*Repository, error) {
+	raw, _, err := c.requestHTTP(http.MethodGet, withAPIVersion(c.repoAPIURL(""), apiVersion), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	repo := &Repository{}
+	if err := json.Unmarshal(raw, repo); err != nil {
+		return nil, err
+	}
+	return repo, nil
+}
+
+// repoAPIURL returns the api url of the repository, appended by the path
+func (c *Client) repoAPIURL(path string) string {
+	org, project, repo := c.orgProjectRepo()
+	return fmt.Sprintf("%s/%s/%s/_apis/git/repositories/%s%s", c.IntegrationConfig.Spec.Git.GetAPIUrl(), org, url.PathEscape(project), url.PathEscape(repo), path)
+}
+
+// orgProjectRepo splits the repository name (<organization>/<project>/<repository>)
+// If the repository is omitted (<organization>/<project>), the repository is considered to be the project's default
+// repository, which has the same name as the project
+func (c *Client) orgProjectRepo() (string, string, string) {
+	tokens := strings.SplitN(c.IntegrationConfig.Spec.Git.Repository, "/", 3)
+	switch len(tokens) {
+	case 3:
+		return tokens[0], tokens[1], tokens[2]
+	case 2:
+		return tokens[0], tokens[1], tokens[1]
+	default:
+		return tokens[0], "", ""
+	}
+}
+
+// getList calls a list api and unmarshal its values into the result
+func (c *Client) getList(apiURL string, result interface{}) error {
+	raw, _, err := c.requestHTTP(http.MethodGet, apiURL, nil)
+	if err != nil {
+		return err
+	}
+
+	resp := &ListResponse{}
+	if err := json.Unmarshal(raw, resp); err != nil {
+		return err
+	}
+	if len(resp.Value) == 0 {
+		return nil
+	}
+	return json.Unmarshal(resp.Value, result)
+}
+
+func (c *Client) requestHTTP(method, apiURL string, data interface{}) ([]byte, http.Header, error) {
+	tlsConfig := c.IntegrationConfig.GetTLSConfig()
+
+	return git.RequestHTTP(c.Context, method, apiURL, c.header, data, tlsConfig)
+}
+
+// withAPIVersion appends api-version query to the url
+func withAPIVersion(apiURL, version string) string {
+	if strings.Contains(apiURL, "?") {
+		return apiURL + "&api-version=" + version
+	}
+	return apiURL + "?api-version=" + version
+}
+
+// subscriptionID converts the subscription's uuid into an integer id
+func subscriptionID(id string) int {
+	return int(crc32.ChecksumIEEE([]byte(id)))
+}
+
+func convertPullRequestToShared(pr *PullRequest) *git.PullRequest {
+	prURL := pr.URL
+	if pr.Repository.WebURL != "" {
+		prURL = fmt.Sprintf("%s/pullrequest/%d", pr.Repository.WebURL, pr.PullRequestID)
+	}
+
+	return &git.PullRequest{
+		ID:        pr.PullRequestID,
+		Title:     pr.Title,
+		State:     convertState(pr.Status),
+		Author:    *convertUser(&pr.CreatedBy),
+		URL:       prURL,
+		Base:      git.Base{Ref: strings.TrimPrefix(pr.TargetRefName, "refs/heads/"), Sha: pr.LastMergeTargetCommit.CommitID},
+		Head:      git.Head{Ref: strings.TrimPrefix(pr.SourceRefName, "refs/heads/"), Sha: pr.LastMergeSourceCommit.CommitID},
+		Labels:    convertLabel(pr.Labels),
+		Mergeable: pr.MergeStatus == "succeeded",
+	}
+}
+
+// convertUser converts an identity into git.User. Azure DevOps identifies users with uuid, so the ID is left empty and
+// the unique name (which is an email for AAD users) is used for the name
+func convertUser(user *IdentityRef) *git.User {
+	u := &git.User{Name: user.UniqueName}
+	if strings.Contains(user.UniqueName, "@") {
+		u.Email = user.UniqueName
+	}
+	return u
+}
+
+func convertState(original string) git.PullRequestState {
+	if original == PullRequestStatusActive {
+		return git.PullRequestStateOpen
+	}
+	return git.PullRequestStateClosed
+}
+
+func convertLabel(original []WebAPITagDefinition) []git.IssueLabel {
+	var labels []git.IssueLabel
+	for _, l := range original {
+		labels = append(labels, git.IssueLabel{Name: l.Name})
+	}
+	return labels
+}
+
+func convertStatusState(original string) git.CommitStatusState {
+	switch original {
+	case StatusStateSucceeded:
+		return git.CommitStatusStateSuccess
+	case StatusStateFailed:
+		return git.CommitStatusStateFailure
+	case StatusStateError:
+		return git.CommitStatusStateError
+	default:
+		return git.CommitStatusStatePending
+	}
+}
+
+func convertCommitStatusState(state git.CommitStatusState) string {
+	switch state {
+	case git.CommitStatusStateSuccess:
+		return StatusStateSucceeded
+	case git.CommitStatusStateFailure:
+		return StatusStateFailed
+	case git.CommitStatusStateError:
+		return StatusStateError
+	default:
+		return StatusStatePending
+	}
+}
+
+// convertVote converts vote result of a vote thread to review state
+func convertVote(vote interface{}) git.PullRequestReviewState {
+	var v string
+	switch val := vote.(type) {
+	case string:
+		v = val
+	case float64:
+		v = fmt.Sprintf("%d", int(val))
+	}
+
+	switch v {
+	case "10", "5":
+		return git.PullRequestReviewStateApproved
+	case "-10", "-5":
+		return git.PullRequestReviewStateUnapproved
+	}
+	return ""
+}
+
+// Validate validates the service hook request, using its basic auth header with any of the secrets
+func Validate(secrets []string, authHeader string) error {
+	for _, secret := range secrets {
+		expected := "Basic " + base64.StdEncoding.EncodeToString([]byte(webhookBasicAuthUserName+":"+secret))
+		if subtle.ConstantTimeCompare([]byte(expected), []byte(authHeader)) == 1 {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid request : Authorization does not match secret")
+}
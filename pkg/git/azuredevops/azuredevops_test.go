@@ -0,0 +1,735 @@
+/*
+ Copyright 2021 The CI/CD Operator Authors
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package azuredevops
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/require"
+	cicdv1 "github.com/tmax-cloud/cicd-operator/api/v1"
+	"github.com/tmax-cloud/cicd-operator/pkg/git"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+const (
+	testSecret = "1xkwb4yrcogvvv5vfdhg"
+	repoPath   = "/tmax/cicd/_apis/git/repositories/cicd-test"
+	repoID     = "3411ebc1-d5aa-464f-9615-0b527bc66719"
+	projectID  = "be9b3917-87e6-42a4-a549-2bc06a7a878f"
+
+	sampleRepository = `{"id": "` + repoID + `", "name": "cicd-test", "webUrl": "https://dev.azure.com/tmax/cicd/_git/cicd-test", "remoteUrl": "https://tmax@dev.azure.com/tmax/cicd/_git/cicd-test", "project": {"id": "` + projectID + `", "name": "cicd"}}`
+
+	samplePullRequest = `{
+  "pullRequestId": 1,
+  "status": "active",
+  "title": "Test PR",
+  "createdBy": {"id": "54d125f7-69f7-4191-904f-c5b96b6261c8", "displayName": "Sunghyun Kim", "uniqueName": "cqbqdd11519@gmail.com"},
+  "sourceRefName": "refs/heads/feat/test",
+  "targetRefName": "refs/heads/master",
+  "mergeStatus": "succeeded",
+  "isDraft": false,
+  "lastMergeSourceCommit": {"commitId": "3196ccc37bcae94852079b04fcbfaf928341d6e9"},
+  "lastMergeTargetCommit": {"commitId": "22ccae53032027186ba739dfaa473ee61a82b298"},
+  "labels": [{"id": "1", "name": "approved"}, {"id": "2", "name": "size/L"}],
+  "repository": ` + sampleRepository + `,
+  "url": "https://dev.azure.com/tmax/cicd/_apis/git/repositories/` + repoID + `/pullRequests/1"
+}`
+
+	samplePRCreatedEvent = `{
+  "id": "2ab4e3d3-b7a6-425e-92b1-5a9982c1269e",
+  "eventType": "git.pullrequest.created",
+  "message": {"text": "Sunghyun Kim created a new pull request"},
+  "resource": ` + samplePullRequest + `
+}`
+
+	samplePRUpdatedSourceEvent = `{
+  "id": "af07be1b-f3ad-44c8-a7f1-c4835f2df06b",
+  "eventType": "git.pullrequest.updated",
+  "message": {"text": "Sunghyun Kim updated the source branch of pull request 1 (Test PR)"},
+  "resource": ` + samplePullRequest + `
+}`
+
+	samplePRUpdatedReviewersEvent = `{
+  "id": "af07be1b-f3ad-44c8-a7f1-c4835f2df06c",
+  "eventType": "git.pullrequest.updated",
+  "message": {"text": "Sunghyun Kim updated the reviewers of pull request 1 (Test PR)"},
+  "resource": ` + samplePullRequest + `
+}`
+
+	samplePushEvent = `{
+  "id": "03c164c2-8912-4d5e-8009-3707d5f83734",
+  "eventType": "git.push",
+  "message": {"text": "Sunghyun Kim pushed updates to cicd-test:master."},
+  "resource": {
+    "refUpdates": [{"name": "refs/heads/master", "oldObjectId": "a00945762949b7787e5e4b3f2cd5ec3a9fc9d7c1", "newObjectId": "22ccae53032027186ba739dfaa473ee61a82b298"}],
+    "pushedBy": {"id": "54d125f7-69f7-4191-904f-c5b96b6261c8", "displayName": "Sunghyun Kim", "uniqueName": "cqbqdd11519@gmail.com"},
+    "repository": ` + sampleRepository + `
+  }
+}`
+
+	samplePushDeleteEvent = `{
+  "id": "03c164c2-8912-4d5e-8009-3707d5f83735",
+  "eventType": "git.push",
+  "resource": {
+    "refUpdates": [{"name": "refs/heads/old", "oldObjectId": "a00945762949b7787e5e4b3f2cd5ec3a9fc9d7c1", "newObjectId": "0000000000000000000000000000000000000000"}],
+    "pushedBy": {"uniqueName": "cqbqdd11519@gmail.com"},
+    "repository": ` + sampleRepository + `
+  }
+}`
+
+	sampleCommentEvent = `{
+  "id": "af07be1b-f3ad-44c8-a7f1-c4835f2df06d",
+  "eventType": "ms.vss-code.git-pullrequest-comment-event",
+  "resource": {
+    "comment": {"id": 2, "parentCommentId": 1, "content": "/retest", "commentType": "text", "author": {"uniqueName": "sunghyunkim3@gmail.com"}, "publishedDate": "2021-09-13T05:20:00Z"},
+    "pullRequest": ` + samplePullRequest + `
+  }
+}`
+
+	sampleSubscriptions = `{"count": 3, "value": [
+  {"id": "00ca946b-2fe9-4f2a-ae2f-40d5c48001bc", "publisherId": "tfs", "eventType": "git.push", "consumerId": "webHooks", "consumerActionId": "httpRequest", "publisherInputs": {"projectId": "` + projectID + `", "repository": "` + repoID + `"}, "consumerInputs": {"url": "http://asdasd/webhook/default/chatops-test"}},
+  {"id": "00ca946b-2fe9-4f2a-ae2f-40d5c48001bd", "publisherId": "tfs", "eventType": "git.push", "consumerId": "webHooks", "consumerActionId": "httpRequest", "publisherInputs": {"projectId": "` + projectID + `", "repository": "other-repo"}, "consumerInputs": {"url": "http://other"}},
+  {"id": "00ca946b-2fe9-4f2a-ae2f-40d5c48001be", "publisherId": "tfs", "eventType": "git.pullrequest.created", "consumerId": "webHooks", "consumerActionId": "httpRequest", "publisherInputs": {"projectId": "` + projectID + `", "repository": "` + repoID + `"}, "consumerInputs": {"url": "http://asdasd/webhook/default/chatops-test"}}
+]}`
+
+	sampleStatuses = `{"count": 3, "value": [
+  {"state": "succeeded", "description": "Job succeeded", "targetUrl": "http://test-1", "context": {"name": "test-1", "genre": "cicd-operator"}},
+  {"state": "pending", "description": "Job is running", "targetUrl": "http://test-1", "context": {"name": "test-1", "genre": "cicd-operator"}},
+  {"state": "failed", "description": "Job failed", "targetUrl": "http://test-2", "context": {"name": "test-2", "genre": "cicd-operator"}}
+]}`
+
+	sampleIdentities = `{"count": 1, "value": [
+  {"id": "54d125f7-69f7-4191-904f-c5b96b6261c8", "descriptor": "Microsoft.IdentityModel.Claims.ClaimsIdentity;cqbqdd11519@gmail.com", "providerDisplayName": "Sunghyun Kim", "properties": {"Account": {"$type": "System.String", "$value": "cqbqdd11519@gmail.com"}, "Mail": {"$type": "System.String", "$value": "cqbqdd11519@gmail.com"}}}
+]}`
+
+	sampleACLs = `{"count": 1, "value": [
+  {"token": "repoV2/` + projectID + `/` + repoID + `", "inheritPermissions": true, "acesDictionary": {
+    "Microsoft.IdentityModel.Claims.ClaimsIdentity;cqbqdd11519@gmail.com": {"descriptor": "Microsoft.IdentityModel.Claims.ClaimsIdentity;cqbqdd11519@gmail.com", "allow": 0, "deny": 0, "extendedInfo": {"effectiveAllow": 16502, "effectiveDeny": 0}}
+  }}
+]}`
+
+	sampleThreads = `{"count": 3, "value": [
+  {"id": 1, "publishedDate": "2021-09-13T05:00:00Z", "comments": [
+    {"id": 1, "parentCommentId": 0, "content": "/retest", "commentType": "text", "author": {"uniqueName": "sunghyunkim3@gmail.com"}, "publishedDate": "2021-09-13T05:00:00Z"},
+    {"id": 2, "parentCommentId": 1, "content": "Policy status updated", "commentType": "system", "author": {"uniqueName": "sunghyunkim3@gmail.com"}, "publishedDate": "2021-09-13T05:00:00Z"}
+  ]},
+  {"id": 2, "publishedDate": "2021-09-13T06:00:00Z", "comments": [{"id": 1, "content": "Sunghyun Kim voted 10", "commentType": "system", "author": {"uniqueName": "cqbqdd11519@gmail.com"}}],
+    "properties": {"CodeReviewThreadType": {"$type": "System.String", "$value": "VoteUpdate"}, "CodeReviewVoteResult": {"$type": "System.String", "$value": "10"}}},
+  {"id": 3, "publishedDate": "2021-09-13T07:00:00Z", "comments": [{"id": 1, "content": "Sunghyun Kim voted -10", "commentType": "system", "author": {"uniqueName": "cqbqdd11519@gmail.com"}}],
+    "properties": {"CodeReviewThreadType": {"$type": "System.String", "$value": "VoteUpdate"}, "CodeReviewVoteResult": {"$type": "System.String", "$value": "-10"}}}
+]}`
+
+	sampleIterations = `{"count": 2, "value": [{"id": 1}, {"id": 2}]}`
+
+	sampleIterationChanges = `{"changeEntries": [
+  {"changeType": "edit", "item": {"path": "/Makefile"}},
+  {"changeType": "add", "item": {"path": "/docs/new.md"}},
+  {"changeType": "rename", "originalPath": "/docs/old.md", "item": {"path": "/docs/renamed.md"}}
+]}`
+
+	sampleCommits = `{"count": 1, "value": [
+  {"commitId": "3196ccc37bcae94852079b04fcbfaf928341d6e9", "comment": "Fix critical typo", "author": {"name": "Sunghyun Kim", "email": "cqbqdd11519@gmail.com"}, "committer": {"name": "Sunghyun Kim", "email": "cqbqdd11519@gmail.com"}}
+]}`
+
+	sampleLabels = `{"count": 2, "value": [{"id": "1", "name": "approved"}, {"id": "2", "name": "size/L"}]}`
+
+	sampleRefs = `{"count": 2, "value": [
+  {"name": "refs/heads/master", "objectId": "22ccae53032027186ba739dfaa473ee61a82b298"},
+  {"name": "refs/heads/master-old", "objectId": "a00945762949b7787e5e4b3f2cd5ec3a9fc9d7c1"}
+]}`
+
+	sampleCommitDiffs = `{"changes": [
+  {"changeType": "edit", "item": {"path": "/docs", "isFolder": true}},
+  {"changeType": "edit", "item": {"path": "/Makefile"}},
+  {"changeType": "rename", "originalPath": "/docs/old.md", "item": {"path": "/docs/renamed.md"}}
+]}`
+)
+
+func TestClient_ParseWebhook(t *testing.T) {
+	tc := map[string]struct {
+		authorization string
+		jsonString    []byte
+
+		expectedErr    bool
+		expectedErrMsg string
+		expectedNil    bool
+		expectedType   git.EventType
+		expectedAction git.PullRequestAction
+		expectedRef    string
+		expectedSha    string
+	}{
+		"validationErr": {
+			authorization:  "Basic " + base64.StdEncoding.EncodeToString([]byte(webhookBasicAuthUserName+":wrong")),
+			jsonString:     []byte(samplePRCreatedEvent),
+			expectedErr:    true,
+			expectedErrMsg: "invalid request : Authorization does not match secret",
+		},
+		"pullRequestCreated": {
+			jsonString:     []byte(samplePRCreatedEvent),
+			expectedType:   git.EventTypePullRequest,
+			expectedAction: git.PullRequestActionOpen,
+			expectedRef:    "feat/test",
+			expectedSha:    "3196ccc37bcae94852079b04fcbfaf928341d6e9",
+		},
+		"pullRequestSourceUpdated": {
+			jsonString:     []byte(samplePRUpdatedSourceEvent),
+			expectedType:   git.EventTypePullRequest,
+			expectedAction: git.PullRequestActionSynchronize,
+			expectedRef:    "feat/test",
+			expectedSha:    "3196ccc37bcae94852079b04fcbfaf928341d6e9",
+		},
+		"pullRequestReviewersUpdated": {
+			jsonString:  []byte(samplePRUpdatedReviewersEvent),
+			expectedNil: true,
+		},
+		"push": {
+			jsonString:   []byte(samplePushEvent),
+			expectedType: git.EventTypePush,
+			expectedRef:  "refs/heads/master",
+			expectedSha:  "22ccae53032027186ba739dfaa473ee61a82b298",
+		},
+		"pushDelete": {
+			jsonString:  []byte(samplePushDeleteEvent),
+			expectedNil: true,
+		},
+		"comment": {
+			jsonString:   []byte(sampleCommentEvent),
+			expectedType: git.EventTypeIssueComment,
+			expectedRef:  "feat/test",
+			expectedSha:  "3196ccc37bcae94852079b04fcbfaf928341d6e9",
+		},
+		"unknownEvent": {
+			jsonString:  []byte(`{"eventType": "build.complete"}`),
+			expectedNil: true,
+		},
+		"marshalErr": {
+			jsonString:     []byte(`{"eventType": "git.push", "resource": "wrong"}`),
+			expectedErr:    true,
+			expectedErrMsg: "json: cannot unmarshal",
+		},
+	}
+	for name, c := range tc {
+		t.Run(name, func(t *testing.T) {
+			cli, err := testEnv()
+			require.NoError(t, err)
+
+			authorization := c.authorization
+			if authorization == "" {
+				authorization = "Basic " + base64.StdEncoding.EncodeToString([]byte(webhookBasicAuthUserName+":"+testSecret))
+			}
+			header := http.Header{}
+			header.Add("Authorization", authorization)
+			wh, err := cli.ParseWebhook(header, c.jsonString)
+			if c.expectedErr {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), c.expectedErrMsg)
+				return
+			}
+			require.NoError(t, err)
+			if c.expectedNil {
+				require.Nil(t, wh)
+				return
+			}
+			require.Equal(t, c.expectedType, wh.EventType)
+			require.Equal(t, "tmax/cicd/_git/cicd-test", wh.Repo.Name)
+			require.Equal(t, "https://dev.azure.com/tmax/cicd/_git/cicd-test", wh.Repo.URL)
+			switch c.expectedType {
+			case git.EventTypePullRequest:
+				require.Equal(t, c.expectedAction, wh.PullRequest.Action)
+				require.Equal(t, 1, wh.PullRequest.ID)
+				require.Equal(t, c.expectedRef, wh.PullRequest.Head.Ref)
+				require.Equal(t, c.expectedSha, wh.PullRequest.Head.Sha)
+				require.Equal(t, "master", wh.PullRequest.Base.Ref)
+				require.Equal(t, "https://dev.azure.com/tmax/cicd/_git/cicd-test/pullrequest/1", wh.PullRequest.URL)
+				require.Equal(t, "cqbqdd11519@gmail.com", wh.Sender.Email)
+			case git.EventTypePush:
+				require.Equal(t, c.expectedRef, wh.Push.Ref)
+				require.Equal(t, c.expectedSha, wh.Push.Sha)
+				require.Equal(t, "cqbqdd11519@gmail.com", wh.Sender.Name)
+				require.Equal(t, "03c164c2-8912-4d5e-8009-3707d5f83734", wh.DeliveryID)
+			case git.EventTypeIssueComment:
+				require.Equal(t, "/retest", wh.IssueComment.Comment.Body)
+				require.Equal(t, "sunghyunkim3@gmail.com", wh.IssueComment.Author.Name)
+				require.Equal(t, c.expectedRef, wh.IssueComment.Issue.PullRequest.Head.Ref)
+				require.Equal(t, c.expectedSha, wh.IssueComment.Issue.PullRequest.Head.Sha)
+			}
+		})
+	}
+}
+
+func TestClient_ListWebhook(t *testing.T) {
+	c, err := testEnv()
+	require.NoError(t, err)
+
+	wh, err := c.ListWebhook()
+	require.NoError(t, err)
+	require.Len(t, wh, 2)
+	require.Equal(t, subscriptionID("00ca946b-2fe9-4f2a-ae2f-40d5c48001bc"), wh[0].ID)
+	require.Equal(t, "http://asdasd/webhook/default/chatops-test", wh[0].URL)
+	require.Equal(t, "http://asdasd/webhook/default/chatops-test", wh[1].URL)
+}
+
+func TestClient_RegisterWebhook(t *testing.T) {
+	c, err := testEnv()
+	require.NoError(t, err)
+
+	registeredSubscriptions = nil
+	require.NoError(t, c.RegisterWebhook("http://asdasd/webhook/default/chatops-test"))
+	require.Len(t, registeredSubscriptions, 5)
+	for _, s := range registeredSubscriptions {
+		require.Equal(t, repoID, s.PublisherInputs["repository"])
+		require.Equal(t, projectID, s.PublisherInputs["projectId"])
+		require.Equal(t, testSecret, s.ConsumerInputs["basicAuthPassword"])
+	}
+}
+
+func TestClient_DeleteWebhook(t *testing.T) {
+	c, err := testEnv()
+	require.NoError(t, err)
+
+	require.NoError(t, c.DeleteWebhook(subscriptionID("00ca946b-2fe9-4f2a-ae2f-40d5c48001bc")))
+
+	err = c.DeleteWebhook(subscriptionID("00ca946b-2fe9-4f2a-ae2f-40d5c48001bd"))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "doesn't exists")
+}
+
+func TestClient_ListCommitStatuses(t *testing.T) {
+	c, err := testEnv()
+	require.NoError(t, err)
+
+	statuses, err := c.ListCommitStatuses("3196ccc37bcae94852079b04fcbfaf928341d6e9")
+	require.NoError(t, err)
+	require.Equal(t, []git.CommitStatus{
+		{Context: "test-1", State: git.CommitStatusStateSuccess, Description: "Job succeeded", TargetURL: "http://test-1"},
+		{Context: "test-2", State: git.CommitStatusStateFailure, Description: "Job failed", TargetURL: "http://test-2"},
+	}, statuses)
+}
+
+func TestClient_SetCommitStatus(t *testing.T) {
+	tc := map[string]struct {
+		sha string
+
+		expectedPRStatus bool
+	}{
+		"pullRequestHead": {
+			sha:              "3196ccc37bcae94852079b04fcbfaf928341d6e9",
+			expectedPRStatus: true,
+		},
+		"notPullRequestHead": {
+			sha:              "22ccae53032027186ba739dfaa473ee61a82b298",
+			expectedPRStatus: false,
+		},
+		"fakeSha": {
+			sha: git.FakeSha,
+		},
+	}
+	for name, c := range tc {
+		t.Run(name, func(t *testing.T) {
+			cli, err := testEnv()
+			require.NoError(t, err)
+
+			prStatusSet = false
+			err = cli.SetCommitStatus(c.sha, git.CommitStatus{Context: "test-1", State: git.CommitStatusStatePending, Description: "Job is running"})
+			require.NoError(t, err)
+			require.Equal(t, c.expectedPRStatus, prStatusSet)
+		})
+	}
+}
+
+func TestClient_GetUserInfo(t *testing.T) {
+	c, err := testEnv()
+	require.NoError(t, err)
+
+	user, err := c.GetUserInfo("cqbqdd11519@gmail.com")
+	require.NoError(t, err)
+	require.Equal(t, &git.User{Name: "cqbqdd11519@gmail.com", Email: "cqbqdd11519@gmail.com"}, user)
+
+	_, err = c.GetUserInfo("notexist")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "user notexist doesn't exists")
+}
+
+func TestClient_CanUserWriteToRepo(t *testing.T) {
+	c, err := testEnv()
+	require.NoError(t, err)
+
+	permission, err := c.CanUserWriteToRepo(git.User{Name: "cqbqdd11519@gmail.com"})
+	require.NoError(t, err)
+	require.True(t, permission)
+
+	_, err = c.CanUserWriteToRepo(git.User{Name: "notexist"})
+	require.Error(t, err)
+}
+
+func TestClient_RegisterComment(t *testing.T) {
+	c, err := testEnv()
+	require.NoError(t, err)
+
+	require.NoError(t, c.RegisterComment(git.IssueTypePullRequest, 1, "", "test comment"))
+
+	err = c.RegisterComment(git.IssueTypeCommit, 0, "3196ccc37bcae94852079b04fcbfaf928341d6e9", "test comment")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "issue type commit is not supported")
+}
+
+func TestClient_ListComments(t *testing.T) {
+	c, err := testEnv()
+	require.NoError(t, err)
+
+	comments, err := c.ListComments(1)
+	require.NoError(t, err)
+	require.Len(t, comments, 3)
+	require.Equal(t, "/retest", comments[0].Comment.Body)
+	require.Equal(t, "sunghyunkim3@gmail.com", comments[0].Author.Name)
+	require.Equal(t, git.PullRequestReviewStateApproved, comments[1].ReviewState)
+	require.Equal(t, "cqbqdd11519@gmail.com", comments[1].Author.Name)
+	require.Equal(t, git.PullRequestReviewStateUnapproved, comments[2].ReviewState)
+}
+
+func TestClient_ListPullRequests(t *testing.T) {
+	c, err := testEnv()
+	require.NoError(t, err)
+
+	prs, err := c.ListPullRequests(true)
+	require.NoError(t, err)
+	require.Len(t, prs, 1)
+	require.Equal(t, 1, prs[0].ID)
+	require.Equal(t, "Test PR", prs[0].Title)
+	require.Equal(t, git.PullRequestStateOpen, prs[0].State)
+	require.True(t, prs[0].Mergeable)
+	require.Equal(t, []git.IssueLabel{{Name: "approved"}, {Name: "size/L"}}, prs[0].Labels)
+}
+
+func TestClient_GetPullRequest(t *testing.T) {
+	c, err := testEnv()
+	require.NoError(t, err)
+
+	pr, err := c.GetPullRequest(1)
+	require.NoError(t, err)
+	require.Equal(t, 1, pr.ID)
+	require.Equal(t, git.Head{Ref: "feat/test", Sha: "3196ccc37bcae94852079b04fcbfaf928341d6e9"}, pr.Head)
+	require.Equal(t, git.Base{Ref: "master", Sha: "22ccae53032027186ba739dfaa473ee61a82b298"}, pr.Base)
+}
+
+func TestClient_MergePullRequest(t *testing.T) {
+	tc := map[string]struct {
+		method           git.MergeMethod
+		expectedStrategy string
+	}{
+		"squash": {
+			method:           git.MergeMethodSquash,
+			expectedStrategy: "squash",
+		},
+		"merge": {
+			method:           git.MergeMethodMerge,
+			expectedStrategy: "noFastForward",
+		},
+	}
+	for name, c := range tc {
+		t.Run(name, func(t *testing.T) {
+			cli, err := testEnv()
+			require.NoError(t, err)
+
+			completeBody = nil
+			require.NoError(t, cli.MergePullRequest(1, "3196ccc37bcae94852079b04fcbfaf928341d6e9", c.method, "Test PR\n\nbody"))
+			require.NotNil(t, completeBody)
+			require.Equal(t, PullRequestStatusCompleted, completeBody.Status)
+			require.Equal(t, "3196ccc37bcae94852079b04fcbfaf928341d6e9", completeBody.LastMergeSourceCommit.CommitID)
+			require.Equal(t, c.expectedStrategy, completeBody.CompletionOptions.MergeStrategy)
+			require.Equal(t, "Test PR\n\nbody", completeBody.CompletionOptions.MergeCommitMessage)
+		})
+	}
+}
+
+func TestClient_GetPullRequestDiff(t *testing.T) {
+	c, err := testEnv()
+	require.NoError(t, err)
+
+	diff, err := c.GetPullRequestDiff(1)
+	require.NoError(t, err)
+	require.Equal(t, []git.Change{
+		{Filename: "Makefile", OldFilename: "Makefile"},
+		{Filename: "docs/new.md", OldFilename: "docs/new.md"},
+		{Filename: "docs/renamed.md", OldFilename: "docs/old.md"},
+	}, diff.Changes)
+}
+
+func TestClient_GetCommitDiff(t *testing.T) {
+	c, err := testEnv()
+	require.NoError(t, err)
+
+	diff, err := c.GetCommitDiff("22ccae53032027186ba739dfaa473ee61a82b298", "3196ccc37bcae94852079b04fcbfaf928341d6e9")
+	require.NoError(t, err)
+	require.Equal(t, []git.Change{
+		{Filename: "Makefile", OldFilename: "Makefile"},
+		{Filename: "docs/renamed.md", OldFilename: "docs/old.md"},
+	}, diff.Changes)
+}
+
+func TestClient_GetFileContent(t *testing.T) {
+	c, err := testEnv()
+	require.NoError(t, err)
+
+	content, err := c.GetFileContent(".cicd/jobs.yaml", "3196ccc37bcae94852079b04fcbfaf928341d6e9")
+	require.NoError(t, err)
+	require.Equal(t, "preSubmit:\n- name: test\n", string(content))
+
+	_, err = c.GetFileContent(".cicd/jobs.yaml", "22ccae53032027186ba739dfaa473ee61a82b298")
+	require.Error(t, err)
+}
+
+func TestClient_ListPullRequestCommits(t *testing.T) {
+	c, err := testEnv()
+	require.NoError(t, err)
+
+	commits, err := c.ListPullRequestCommits(1)
+	require.NoError(t, err)
+	require.Len(t, commits, 1)
+	require.Equal(t, "3196ccc37bcae94852079b04fcbfaf928341d6e9", commits[0].SHA)
+	require.Equal(t, "Fix critical typo", commits[0].Message)
+	require.Equal(t, "Sunghyun Kim", commits[0].Author.Name)
+	require.Equal(t, "cqbqdd11519@gmail.com", commits[0].Committer.Email)
+}
+
+func TestClient_Labels(t *testing.T) {
+	c, err := testEnv()
+	require.NoError(t, err)
+
+	labels, err := c.ListLabels(1)
+	require.NoError(t, err)
+	require.Equal(t, []git.IssueLabel{{Name: "approved"}, {Name: "size/L"}}, labels)
+
+	require.NoError(t, c.SetLabel(git.IssueTypePullRequest, 1, "lgtm"))
+	require.NoError(t, c.DeleteLabel(git.IssueTypePullRequest, 1, "size/L"))
+
+	err = c.DeleteLabel(git.IssueTypePullRequest, 1, "notexist")
+	require.Error(t, err)
+
+	err = c.SetLabel(git.IssueTypeIssue, 1, "lgtm")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "issue type issue is not supported")
+}
+
+func TestClient_GetBranch(t *testing.T) {
+	c, err := testEnv()
+	require.NoError(t, err)
+
+	branch, err := c.GetBranch("master")
+	require.NoError(t, err)
+	require.Equal(t, &git.Branch{Name: "master", CommitID: "22ccae53032027186ba739dfaa473ee61a82b298"}, branch)
+
+	_, err = c.GetBranch("fake")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "branch fake doesn't exists")
+}
+
+func TestClient_orgProjectRepo(t *testing.T) {
+	tc := map[string]struct {
+		repository string
+
+		expectedOrg     string
+		expectedProject string
+		expectedRepo    string
+	}{
+		"full": {
+			repository:      "tmax/cicd/cicd-test",
+			expectedOrg:     "tmax",
+			expectedProject: "cicd",
+			expectedRepo:    "cicd-test",
+		},
+		"defaultRepo": {
+			repository:      "tmax/cicd",
+			expectedOrg:     "tmax",
+			expectedProject: "cicd",
+			expectedRepo:    "cicd",
+		},
+	}
+	for name, c := range tc {
+		t.Run(name, func(t *testing.T) {
+			cli := &Client{IntegrationConfig: &cicdv1.IntegrationConfig{Spec: cicdv1.IntegrationConfigSpec{Git: cicdv1.GitConfig{Repository: c.repository}}}}
+			org, project, repo := cli.orgProjectRepo()
+			require.Equal(t, c.expectedOrg, org)
+			require.Equal(t, c.expectedProject, project)
+			require.Equal(t, c.expectedRepo, repo)
+		})
+	}
+}
+
+var (
+	registeredSubscriptions []Subscription
+	prStatusSet             bool
+	completeBody            *CompletePullRequestBody
+)
+
+func testEnv() (*Client, error) {
+	r := mux.NewRouter().UseEncodedPath()
+	setRouter(r)
+	testSrv := httptest.NewServer(r)
+
+	s := runtime.NewScheme()
+	utilruntime.Must(cicdv1.AddToScheme(s))
+	ic := &cicdv1.IntegrationConfig{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-ic",
+			Namespace: "default",
+		},
+		Spec: cicdv1.IntegrationConfigSpec{
+			Git: cicdv1.GitConfig{
+				Type:       cicdv1.GitTypeAzureDevOps,
+				Repository: "tmax/cicd/cicd-test",
+				APIUrl:     testSrv.URL,
+				Token:      &cicdv1.GitToken{Value: "dummy"},
+			},
+		},
+		Status: cicdv1.IntegrationConfigStatus{
+			Secrets: testSecret,
+		},
+	}
+	c := &Client{
+		IntegrationConfig: ic,
+		K8sClient:         fake.NewClientBuilder().WithScheme(s).WithObjects(ic).Build(),
+	}
+	if err := c.Init(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func setRouter(r *mux.Router) {
+	writeJSON := func(body string) func(http.ResponseWriter, *http.Request) {
+		return func(w http.ResponseWriter, _ *http.Request) {
+			_, _ = w.Write([]byte(body))
+		}
+	}
+	notFound := func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"message": "doesn't exists"}`))
+	}
+
+	// Check PAT authorization for every request
+	r.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			if req.Header.Get("Authorization") != "Basic "+base64.StdEncoding.EncodeToString([]byte(":dummy")) || req.URL.Query().Get("api-version") == "" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			next.ServeHTTP(w, req)
+		})
+	})
+
+	// Repository
+	r.HandleFunc(repoPath, writeJSON(sampleRepository)).Methods(http.MethodGet)
+
+	// Service hooks
+	r.HandleFunc("/tmax/_apis/hooks/subscriptions", writeJSON(sampleSubscriptions)).Methods(http.MethodGet)
+	r.HandleFunc("/tmax/_apis/hooks/subscriptions", func(w http.ResponseWriter, req *http.Request) {
+		s := Subscription{}
+		if err := json.NewDecoder(req.Body).Decode(&s); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		registeredSubscriptions = append(registeredSubscriptions, s)
+		_, _ = w.Write([]byte(`{}`))
+	}).Methods(http.MethodPost)
+	r.HandleFunc("/tmax/_apis/hooks/subscriptions/00ca946b-2fe9-4f2a-ae2f-40d5c48001bc", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}).Methods(http.MethodDelete)
+
+	// Statuses
+	r.HandleFunc(repoPath+"/commits/{sha}/statuses", writeJSON(sampleStatuses)).Methods(http.MethodGet)
+	r.HandleFunc(repoPath+"/commits/{sha}/statuses", writeJSON(`{}`)).Methods(http.MethodPost)
+	r.HandleFunc(repoPath+"/pullRequests/1/statuses", func(w http.ResponseWriter, _ *http.Request) {
+		prStatusSet = true
+		_, _ = w.Write([]byte(`{}`))
+	}).Methods(http.MethodPost)
+
+	// Users
+	r.HandleFunc("/tmax/_apis/identities", func(w http.ResponseWriter, req *http.Request) {
+		if req.URL.Query().Get("filterValue") != "cqbqdd11519@gmail.com" {
+			_, _ = w.Write([]byte(`{"count": 0, "value": []}`))
+			return
+		}
+		_, _ = w.Write([]byte(sampleIdentities))
+	}).Methods(http.MethodGet)
+	r.HandleFunc("/tmax/_apis/accesscontrollists/"+gitSecurityNamespace, func(w http.ResponseWriter, req *http.Request) {
+		if req.URL.Query().Get("token") != "repoV2/"+projectID+"/"+repoID {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		_, _ = w.Write([]byte(sampleACLs))
+	}).Methods(http.MethodGet)
+
+	// Comments
+	r.HandleFunc(repoPath+"/pullRequests/1/threads", writeJSON(sampleThreads)).Methods(http.MethodGet)
+	r.HandleFunc(repoPath+"/pullRequests/1/threads", writeJSON(`{}`)).Methods(http.MethodPost)
+
+	// Pull requests
+	r.HandleFunc(repoPath+"/pullrequests", writeJSON(`{"count": 1, "value": [`+samplePullRequest+`]}`)).Methods(http.MethodGet)
+	r.HandleFunc(repoPath+"/pullrequests/1", writeJSON(samplePullRequest)).Methods(http.MethodGet)
+	r.HandleFunc(repoPath+"/pullrequests/1", func(w http.ResponseWriter, req *http.Request) {
+		completeBody = &CompletePullRequestBody{}
+		if err := json.NewDecoder(req.Body).Decode(completeBody); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		_, _ = w.Write([]byte(samplePullRequest))
+	}).Methods(http.MethodPatch)
+	r.HandleFunc(repoPath+"/pullRequests/1/iterations", writeJSON(sampleIterations)).Methods(http.MethodGet)
+	r.HandleFunc(repoPath+"/pullRequests/1/iterations/2/changes", writeJSON(sampleIterationChanges)).Methods(http.MethodGet)
+	r.HandleFunc(repoPath+"/diffs/commits", func(w http.ResponseWriter, req *http.Request) {
+		if req.URL.Query().Get("baseVersion") != "22ccae53032027186ba739dfaa473ee61a82b298" || req.URL.Query().Get("targetVersion") != "3196ccc37bcae94852079b04fcbfaf928341d6e9" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		_, _ = w.Write([]byte(sampleCommitDiffs))
+	}).Methods(http.MethodGet)
+	r.HandleFunc(repoPath+"/items", func(w http.ResponseWriter, req *http.Request) {
+		q := req.URL.Query()
+		if q.Get("path") != "/.cicd/jobs.yaml" || q.Get("versionDescriptor.version") != "3196ccc37bcae94852079b04fcbfaf928341d6e9" || q.Get("versionDescriptor.versionType") != "commit" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte("preSubmit:\n- name: test\n"))
+	}).Methods(http.MethodGet)
+	r.HandleFunc(repoPath+"/pullRequests/1/commits", writeJSON(sampleCommits)).Methods(http.MethodGet)
+
+	// Labels
+	r.HandleFunc(repoPath+"/pullRequests/1/labels", writeJSON(sampleLabels)).Methods(http.MethodGet)
+	r.HandleFunc(repoPath+"/pullRequests/1/labels", writeJSON(`{"id": "3", "name": "lgtm"}`)).Methods(http.MethodPost)
+	r.HandleFunc(repoPath+"/pullRequests/1/labels/{label}", func(w http.ResponseWriter, req *http.Request) {
+		// Label names should be escaped
+		if mux.Vars(req)["label"] != "size%2FL" {
+			notFound(w, req)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}).Methods(http.MethodDelete)
+
+	// Branches
+	r.HandleFunc(repoPath+"/refs", writeJSON(sampleRefs)).Methods(http.MethodGet)
+
+	r.NotFoundHandler = http.HandlerFunc(notFound)
+}
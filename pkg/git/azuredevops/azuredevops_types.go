@@ -0,0 +1,322 @@
+/*
+ Copyright 2021 The CI/CD Operator Authors
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package azuredevops
+
+import (
+	"encoding/json"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Service hook event types of Azure DevOps
+const (
+	EventTypePush                 = "git.push"
+	EventTypePullRequestCreated   = "git.pullrequest.created"
+	EventTypePullRequestUpdated   = "git.pullrequest.updated"
+	EventTypePullRequestMerged    = "git.pullrequest.merged"
+	EventTypePullRequestCommented = "ms.vss-code.git-pullrequest-comment-event"
+)
+
+// Pull request statuses of Azure DevOps
+const (
+	PullRequestStatusActive    = "active"
+	PullRequestStatusAbandoned = "abandoned"
+	PullRequestStatusCompleted = "completed"
+)
+
+// Status states of Azure DevOps
+const (
+	StatusStateSucceeded = "succeeded"
+	StatusStateFailed    = "failed"
+	StatusStateError     = "error"
+	StatusStatePending   = "pending"
+)
+
+// Git Repositories security namespace & its permission bit for contributing
+const (
+	gitSecurityNamespace     = "2e9eb7ed-3c0a-47d4-87c1-0ffdcaf5a6b2"
+	gitPermissionContribute  = 4
+	statusGenre              = "cicd-operator"
+	webhookBasicAuthUserName = "cicd-operator"
+)
+
+// ListResponse is a common list response of Azure DevOps
+type ListResponse struct {
+	Count int             `json:"count"`
+	Value json.RawMessage `json:"value"`
+}
+
+// IdentityRef is a user reference of Azure DevOps
+type IdentityRef struct {
+	ID          string `json:"id"`
+	DisplayName string `json:"displayName"`
+	UniqueName  string `json:"uniqueName"`
+	Descriptor  string `json:"descriptor,omitempty"`
+}
+
+// Project is a project of Azure DevOps
+type Project struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// Repository is a git repository of Azure DevOps
+type Repository struct {
+	ID        string  `json:"id"`
+	Name      string  `json:"name"`
+	URL       string  `json:"url"`
+	RemoteURL string  `json:"remoteUrl"`
+	WebURL    string  `json:"webUrl"`
+	Project   Project `json:"project"`
+}
+
+// CommitRef is a reference to a commit
+type CommitRef struct {
+	CommitID string `json:"commitId"`
+}
+
+// WebAPITagDefinition is a label of a pull request
+type WebAPITagDefinition struct {
+	ID   string `json:"id,omitempty"`
+	Name string `json:"name"`
+}
+
+// CompletionOptions is an option for completing a pull request
+type CompletionOptions struct {
+	MergeStrategy      string `json:"mergeStrategy,omitempty"`
+	MergeCommitMessage string `json:"mergeCommitMessage,omitempty"`
+	DeleteSourceBranch bool   `json:"deleteSourceBranch"`
+}
+
+// PullRequest is a pull request of Azure DevOps
+type PullRequest struct {
+	PullRequestID         int                   `json:"pullRequestId"`
+	Status                string                `json:"status"`
+	Title                 string                `json:"title"`
+	CreatedBy             IdentityRef           `json:"createdBy"`
+	SourceRefName         string                `json:"sourceRefName"`
+	TargetRefName         string                `json:"targetRefName"`
+	MergeStatus           string                `json:"mergeStatus"`
+	IsDraft               bool                  `json:"isDraft"`
+	LastMergeSourceCommit CommitRef             `json:"lastMergeSourceCommit"`
+	LastMergeTargetCommit CommitRef             `json:"lastMergeTargetCommit"`
+	Labels                []WebAPITagDefinition `json:"labels"`
+	Repository            Repository            `json:"repository"`
+	URL                   string                `json:"url"`
+}
+
+// CompletePullRequestBody is a request body for completing (merging) a pull request
+type CompletePullRequestBody struct {
+	Status                string            `json:"status"`
+	LastMergeSourceCommit CommitRef         `json:"lastMergeSourceCommit"`
+	CompletionOptions     CompletionOptions `json:"completionOptions"`
+}
+
+// EventMessage is a message of a service hook event
+type EventMessage struct {
+	Text string `json:"text"`
+}
+
+// Event is a common service hook event body
+type Event struct {
+	ID        string       `json:"id"`
+	EventType string       `json:"eventType"`
+	Message   EventMessage `json:"message"`
+}
+
+// PushEvent is a git.push service hook event body
+type PushEvent struct {
+	Event
+	Resource PushResource `json:"resource"`
+}
+
+// PushResource is a resource of the git.push event
+type PushResource struct {
+	RefUpdates []RefUpdate  `json:"refUpdates"`
+	PushedBy   IdentityRef  `json:"pushedBy"`
+	Repository Repository   `json:"repository"`
+	Commits    []CommitInfo `json:"commits"`
+}
+
+// RefUpdate is an update of a reference
+type RefUpdate struct {
+	Name        string `json:"name"`
+	OldObjectID string `json:"oldObjectId"`
+	NewObjectID string `json:"newObjectId"`
+}
+
+// PullRequestEvent is a git.pullrequest.* service hook event body
+type PullRequestEvent struct {
+	Event
+	Resource PullRequest `json:"resource"`
+}
+
+// PullRequestCommentEvent is a pull request comment service hook event body
+type PullRequestCommentEvent struct {
+	Event
+	Resource PullRequestCommentResource `json:"resource"`
+}
+
+// PullRequestCommentResource is a resource of the pull request comment event
+type PullRequestCommentResource struct {
+	Comment     Comment     `json:"comment"`
+	PullRequest PullRequest `json:"pullRequest"`
+}
+
+// Comment is a comment of a thread
+type Comment struct {
+	ID              int          `json:"id,omitempty"`
+	ParentCommentID int          `json:"parentCommentId"`
+	Content         string       `json:"content"`
+	CommentType     string       `json:"commentType"`
+	Author          IdentityRef  `json:"author,omitempty"`
+	PublishedDate   *metav1.Time `json:"publishedDate,omitempty"`
+}
+
+// Thread is a comment thread of a pull request
+type Thread struct {
+	ID            int                       `json:"id"`
+	PublishedDate *metav1.Time              `json:"publishedDate"`
+	Comments      []Comment                 `json:"comments"`
+	Properties    map[string]ThreadProperty `json:"properties"`
+}
+
+// ThreadProperty is a property of a thread
+type ThreadProperty struct {
+	Value interface{} `json:"$value"`
+}
+
+// CreateThreadBody is a request body for creating a thread
+type CreateThreadBody struct {
+	Comments []CreateThreadComment `json:"comments"`
+	Status   int                   `json:"status"`
+}
+
+// CreateThreadComment is a comment of CreateThreadBody
+type CreateThreadComment struct {
+	ParentCommentID int    `json:"parentCommentId"`
+	Content         string `json:"content"`
+	CommentType     int    `json:"commentType"`
+}
+
+// StatusContext is a context of a status
+type StatusContext struct {
+	Name  string `json:"name"`
+	Genre string `json:"genre"`
+}
+
+// Status is a commit/pull request status
+type Status struct {
+	State       string        `json:"state"`
+	Description string        `json:"description"`
+	TargetURL   string        `json:"targetUrl,omitempty"`
+	Context     StatusContext `json:"context"`
+}
+
+// Subscription is a service hook subscription
+type Subscription struct {
+	ID               string            `json:"id,omitempty"`
+	PublisherID      string            `json:"publisherId"`
+	EventType        string            `json:"eventType"`
+	ResourceVersion  string            `json:"resourceVersion,omitempty"`
+	ConsumerID       string            `json:"consumerId"`
+	ConsumerActionID string            `json:"consumerActionId"`
+	PublisherInputs  map[string]string `json:"publisherInputs"`
+	ConsumerInputs   map[string]string `json:"consumerInputs"`
+}
+
+// Iteration is an iteration of a pull request
+type Iteration struct {
+	ID int `json:"id"`
+}
+
+// IterationChanges is the changes of an iteration
+type IterationChanges struct {
+	ChangeEntries []ChangeEntry `json:"changeEntries"`
+}
+
+// ChangeEntry is a changed file
+type ChangeEntry struct {
+	ChangeType   string   `json:"changeType"`
+	OriginalPath string   `json:"originalPath"`
+	Item         ItemPath `json:"item"`
+}
+
+// ItemPath is a path of the changed item
+type ItemPath struct {
+	Path     string `json:"path"`
+	IsFolder bool   `json:"isFolder"`
+}
+
+// CommitDiffs is the changes between two commits
+type CommitDiffs struct {
+	Changes []ChangeEntry `json:"changes"`
+}
+
+// CommitInfo is a commit of Azure DevOps
+type CommitInfo struct {
+	CommitID  string     `json:"commitId"`
+	Comment   string     `json:"comment"`
+	Author    GitUserRef `json:"author"`
+	Committer GitUserRef `json:"committer"`
+}
+
+// GitUserRef is a git user of a commit
+type GitUserRef struct {
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
+
+// Ref is a git reference
+type Ref struct {
+	Name     string `json:"name"`
+	ObjectID string `json:"objectId"`
+}
+
+// Identity is an identity of Azure DevOps
+type Identity struct {
+	ID                  string                   `json:"id"`
+	Descriptor          string                   `json:"descriptor"`
+	ProviderDisplayName string                   `json:"providerDisplayName"`
+	Properties          map[string]PropertyValue `json:"properties"`
+}
+
+// PropertyValue is a typed property value of an identity
+type PropertyValue struct {
+	Value string `json:"$value"`
+}
+
+// AccessControlList is an access control list of a security namespace
+type AccessControlList struct {
+	Token   string                      `json:"token"`
+	ACEs    map[string]AccessControlEnt `json:"acesDictionary"`
+	Inherit bool                        `json:"inheritPermissions"`
+}
+
+// AccessControlEnt is an access control entry
+type AccessControlEnt struct {
+	Descriptor   string       `json:"descriptor"`
+	Allow        int          `json:"allow"`
+	Deny         int          `json:"deny"`
+	ExtendedInfo ExtendedInfo `json:"extendedInfo"`
+}
+
+// ExtendedInfo is an extended info of the access control entry, containing effective permissions
+type ExtendedInfo struct {
+	EffectiveAllow int `json:"effectiveAllow"`
+	EffectiveDeny  int `json:"effectiveDeny"`
+}
@@ -0,0 +1,122 @@
+/*
+ Copyright 2021 The CI/CD Operator Authors
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package azuredevops
+
+import (
+	"encoding/json"
+	"net/url"
+	"strings"
+
+	"github.com/tmax-cloud/cicd-operator/pkg/git"
+)
+
+func (c *Client) parsePullRequestWebhook(jsonString []byte) (*git.Webhook, error) {
+	var data PullRequestEvent
+	if err := json.Unmarshal(jsonString, &data); err != nil {
+		return nil, err
+	}
+
+	var action git.PullRequestAction
+	switch {
+	case data.EventType == EventTypePullRequestCreated:
+		action = git.PullRequestActionOpen
+	case data.Resource.Status == PullRequestStatusCompleted || data.Resource.Status == PullRequestStatusAbandoned:
+		action = git.PullRequestActionClose
+	case data.EventType == EventTypePullRequestUpdated && strings.Contains(data.Message.Text, "updated the source branch"):
+		action = git.PullRequestActionSynchronize
+	case data.EventType == EventTypePullRequestUpdated && strings.Contains(data.Message.Text, "reactivated"):
+		action = git.PullRequestActionReOpen
+	default:
+		// Other updates (e.g., reviewers, descriptions) are not handled
+		return nil, nil
+	}
+
+	pullRequest := convertPullRequestToShared(&data.Resource)
+	pullRequest.Action = action
+
+	// Service hooks do not contain the actor, so the creator of the pull request is considered to be the sender
+	sender := convertUser(&data.Resource.CreatedBy)
+	repo := convertRepository(&data.Resource.Repository)
+	return &git.Webhook{EventType: git.EventTypePullRequest, Repo: repo, PullRequest: pullRequest, Sender: *sender, RequestBody: string(jsonString)}, nil
+}
+
+func (c *Client) parsePushWebhook(jsonString []byte) (*git.Webhook, error) {
+	var data PushEvent
+	if err := json.Unmarshal(jsonString, &data); err != nil {
+		return nil, err
+	}
+
+	// Use the first non-deleting ref update
+	var update *RefUpdate
+	for i := range data.Resource.RefUpdates {
+		newObjectID := data.Resource.RefUpdates[i].NewObjectID
+		if !(strings.HasPrefix(newObjectID, "0000") && strings.HasSuffix(newObjectID, "0000")) {
+			update = &data.Resource.RefUpdates[i]
+			break
+		}
+	}
+	if update == nil {
+		return nil, nil
+	}
+
+	sender := convertUser(&data.Resource.PushedBy)
+	repo := convertRepository(&data.Resource.Repository)
+	push := git.Push{Ref: update.Name, Sha: update.NewObjectID, Before: update.OldObjectID}
+
+	return &git.Webhook{EventType: git.EventTypePush, Repo: repo, Sender: *sender, Push: &push, RequestBody: string(jsonString)}, nil
+}
+
+func (c *Client) parsePullRequestCommentWebhook(jsonString []byte) (*git.Webhook, error) {
+	var data PullRequestCommentEvent
+	if err := json.Unmarshal(jsonString, &data); err != nil {
+		return nil, err
+	}
+
+	// Only handle text comments (not system comments)
+	if data.Resource.Comment.CommentType != "" && data.Resource.Comment.CommentType != "text" {
+		return nil, nil
+	}
+
+	author := convertUser(&data.Resource.Comment.Author)
+	repo := convertRepository(&data.Resource.PullRequest.Repository)
+
+	return &git.Webhook{EventType: git.EventTypeIssueComment, Repo: repo,
+		Sender:      *author,
+		RequestBody: string(jsonString),
+		IssueComment: &git.IssueComment{
+			Comment: git.Comment{
+				Body:      data.Resource.Comment.Content,
+				CreatedAt: data.Resource.Comment.PublishedDate,
+			},
+			Author: *author,
+			Issue: git.Issue{
+				PullRequest: convertPullRequestToShared(&data.Resource.PullRequest),
+			},
+		}}, nil
+}
+
+// convertRepository converts the repository into git.Repository
+// The name is the path of the web url (<organization>/<project>/_git/<repository>), so the checkout url
+// (server url + name) is valid
+func convertRepository(repo *Repository) git.Repository {
+	name := repo.Project.Name + "/_git/" + repo.Name
+	if u, err := url.Parse(repo.WebURL); err == nil && u.Path != "" {
+		name = strings.TrimPrefix(u.Path, "/")
+	}
+
+	return git.Repository{Name: name, URL: repo.WebURL}
+}
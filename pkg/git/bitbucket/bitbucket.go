@@ -0,0 +1,795 @@
+/*
+ Copyright 2021 The CI/CD Operator Authors
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package bitbucket
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	cicdv1 "github.com/tmax-cloud/cicd-operator/api/v1"
+	"github.com/tmax-cloud/cicd-operator/pkg/git"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Client is a Bitbucket Server (Data Center) client struct
+type Client struct {
+	IntegrationConfig *cicdv1.IntegrationConfig
+	K8sClient         client.Client
+
+	// Context is a context of the API calls, which carries the caller's trace
+	Context context.Context
+
+	header map[string]string
+}
+
+// Init initiates the Client
+func (c *Client) Init() error {
+	token, err := c.IntegrationConfig.GetToken(c.K8sClient)
+	if err != nil {
+		return err
+	}
+
+	c.header = map[string]string{
+		"Accept": "application/json",
+	}
+	if token != "" {
+		c.header["Authorization"] = "Bearer " + token
+	}
+	return nil
+}
+
+// ParseWebhook parses a webhook body for Bitbucket Server
+func (c *Client) ParseWebhook(header http.Header, jsonString []byte) (*git.Webhook, error) {
+	wh, err := c.parseWebhook(header, jsonString)
+	if wh != nil {
+		wh.DeliveryID = header.Get("X-Request-Id")
+	}
+	return wh, err
+}
+
+func (c *Client) parseWebhook(header http.Header, jsonString []byte) (*git.Webhook, error) {
+	eventKey := header.Get("x-event-key")
+	// Ping event does not contain a signature
+	if eventKey == EventKeyDiagnosticsPing {
+		return nil, nil
+	}
+
+	var signature = strings.Replace(header.Get("x-hub-signature"), "sha256=", "", 1)
+	if err := Validate(c.IntegrationConfig.GetWebhookSecrets(), signature, jsonString); err != nil {
+		return nil, err
+	}
+
+	switch eventKey {
+	case EventKeyPullRequestOpened, EventKeyPullRequestReopened, EventKeyPullRequestFromRefUpdate, EventKeyPullRequestMerged, EventKeyPullRequestDeclined:
+		return c.parsePullRequestWebhook(eventKey, jsonString)
+	case EventKeyRepoRefsChanged:
+		return c.parsePushWebhook(jsonString)
+	case EventKeyPullRequestCommentAdded:
+		return c.parseCommentWebhook(jsonString)
+	}
+	return nil, nil
+}
+
+// ListWebhook lists registered webhooks
+func (c *Client) ListWebhook() ([]git.WebhookEntry, error) {
+	var entries []WebhookEntry
+	err := c.getPaginated(c.repoAPIURL()+"/webhooks", func(raw json.RawMessage) error {
+		var page []WebhookEntry
+		if err := json.Unmarshal(raw, &page); err != nil {
+			return err
+		}
+		entries = append(entries, page...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var result []git.WebhookEntry
+	for _, e := range entries {
+		result = append(result, git.WebhookEntry{ID: e.ID, URL: e.URL})
+	}
+
+	return result, nil
+}
+
+// RegisterWebhook registers our webhook server to the remote git server
+func (c *Client) RegisterWebhook(uri string) error {
+	registrationBody := RegistrationWebhookBody{
+		Name:   "cicd-operator",
+		URL:    uri,
+		Active: true,
+		Events: []string{
+			EventKeyRepoRefsChanged,
+			EventKeyPullRequestOpened,
+			EventKeyPullRequestReopened,
+			EventKeyPullRequestFromRefUpdate,
+			EventKeyPullRequestMerged,
+			EventKeyPullRequestDeclined,
+			EventKeyPullRequestCommentAdded,
+		},
+		Configuration: RegistrationWebhookBodyConfig{
+			Secret: c.IntegrationConfig.Status.Secrets,
+		},
+	}
+
+	if _, _, err := c.requestHTTP(http.MethodPost, c.repoAPIURL()+"/webhooks", registrationBody); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// DeleteWebhook deletes registered webhook
+func (c *Client) DeleteWebhook(id int) error {
+	apiURL := fmt.Sprintf("%s/webhooks/%d", c.repoAPIURL(), id)
+	if _, _, err := c.requestHTTP(http.MethodDelete, apiURL, nil); err != nil {
+		return err
+	}
+	return nil
+}
+
+// ListCommitStatuses lists commit status of the specific commit
+func (c *Client) ListCommitStatuses(ref string) ([]git.CommitStatus, error) {
+	apiURL := fmt.Sprintf("%s/rest/build-status/1.0/commits/%s", c.IntegrationConfig.Spec.Git.GetAPIUrl(), ref)
+
+	var statuses []BuildStatus
+	err := c.getPaginated(apiURL, func(raw json.RawMessage) error {
+		var page []BuildStatus
+		if err := json.Unmarshal(raw, &page); err != nil {
+			return err
+		}
+		statuses = append(statuses, page...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Temp map for filtering duplicated contexts
+	tmp := map[string]struct{}{}
+
+	var resp []git.CommitStatus
+	for _, s := range statuses {
+		_, exist := tmp[s.Key]
+		if exist {
+			continue
+		}
+		tmp[s.Key] = struct{}{}
+		resp = append(resp, git.CommitStatus{
+			Context:     s.Key,
+			State:       convertBuildState(s.State),
+			Description: s.Description,
+			TargetURL:   s.URL,
+		})
+	}
+
+	return resp, nil
+}
+
+// SetCommitStatus sets commit status for the specific commit
+func (c *Client) SetCommitStatus(sha string, status git.CommitStatus) error {
+	// Don't set commit status if its' sha is a fake
+	if sha == git.FakeSha {
+		return nil
+	}
+
+	apiURL := fmt.Sprintf("%s/rest/build-status/1.0/commits/%s", c.IntegrationConfig.Spec.Git.GetAPIUrl(), sha)
+
+	// Bitbucket Server does not accept a build status without url
+	targetURL := status.TargetURL
+	if targetURL == "" {
+		targetURL = c.IntegrationConfig.Spec.Git.GetAPIUrl()
+	}
+
+	body := BuildStatus{
+		State:       convertCommitStatusState(status.State),
+		Key:         status.Context,
+		Name:        status.Context,
+		URL:         targetURL,
+		Description: status.Description,
+	}
+
+	if _, _, err := c.requestHTTP(http.MethodPost, apiURL, body); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// GetUserInfo gets a user's information
+func (c *Client) GetUserInfo(userName string) (*git.User, error) {
+	apiURL := fmt.Sprintf("%s/rest/api/1.0/users/%s", c.IntegrationConfig.Spec.Git.GetAPIUrl(), url.PathEscape(userName))
+
+	result, _, err := c.requestHTTP(http.MethodGet, apiURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var userInfo User
+	if err := json.Unmarshal(result, &userInfo); err != nil {
+		return nil, err
+	}
+
+	return convertUser(userInfo), nil
+}
+
+// CanUserWriteToRepo decides if the user has write permission on the repo
+func (c *Client) CanUserWriteToRepo(user git.User) (bool, error) {
+	project, _ := c.projectRepo()
+
+	// Repository permission
+	repoPerm, err := c.getUserPermission(fmt.Sprintf("%s/permissions/users?filter=%s", c.repoAPIURL(), url.QueryEscape(user.Name)), user.Name)
+	if err != nil {
+		return false, err
+	}
+	if repoPerm == "REPO_WRITE" || repoPerm == "REPO_ADMIN" {
+		return true, nil
+	}
+
+	// Project permission
+	projectPerm, err := c.getUserPermission(fmt.Sprintf("%s/rest/api/1.0/projects/%s/permissions/users?filter=%s", c.IntegrationConfig.Spec.Git.GetAPIUrl(), project, url.QueryEscape(user.Name)), user.Name)
+	if err != nil {
+		return false, err
+	}
+	return projectPerm == "PROJECT_WRITE" || projectPerm == "PROJECT_ADMIN", nil
+}
+
+func (c *Client) getUserPermission(apiURL, userName string) (string, error) {
+	var perms []UserPermission
+	err := c.getPaginated(apiURL, func(raw json.RawMessage) error {
+		var page []UserPermission
+		if err := json.Unmarshal(raw, &page); err != nil {
+			return err
+		}
+		perms = append(perms, page...)
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	for _, p := range perms {
+		if p.User.Name == userName || p.User.Slug == userName {
+			return p.Permission, nil
+		}
+	}
+	return "", nil
+}
+
+// RegisterComment registers comment to an issue
+func (c *Client) RegisterComment(issueType git.IssueType, issueNo int, sha, body string) error {
+	var apiURL string
+	switch issueType {
+	case git.IssueTypePullRequest:
+		apiURL = fmt.Sprintf("%s/pull-requests/%d/comments", c.repoAPIURL(), issueNo)
+	case git.IssueTypeCommit:
+		apiURL = fmt.Sprintf("%s/commits/%s/comments", c.repoAPIURL(), sha)
+	default:
+		return fmt.Errorf("issue type %s is not supported", issueType)
+	}
+
+	if _, _, err := c.requestHTTP(http.MethodPost, apiURL, &CommentBody{Text: body}); err != nil {
+		return err
+	}
+	return nil
+}
+
+// ListComments lists comments of the issue id
+func (c *Client) ListComments(issueNo int) ([]git.IssueComment, error) {
+	apiURL := fmt.Sprintf("%s/pull-requests/%d/activities", c.repoAPIURL(), issueNo)
+
+	var activities []Activity
+	err := c.getPaginated(apiURL, func(raw json.RawMessage) error {
+		var page []Activity
+		if err := json.Unmarshal(raw, &page); err != nil {
+			return err
+		}
+		activities = append(activities, page...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var comments []git.IssueComment
+	for _, a := range activities {
+		comment := git.IssueComment{
+			Comment: git.Comment{
+				CreatedAt: convertTime(a.CreatedDate),
+			},
+			Author: *convertUser(a.User),
+		}
+		switch a.Action {
+		case "COMMENTED":
+			if a.Comment == nil {
+				continue
+			}
+			comment.Comment.Body = a.Comment.Text
+		case "APPROVED":
+			comment.ReviewState = git.PullRequestReviewStateApproved
+		case "REVIEWED":
+			comment.ReviewState = git.PullRequestReviewStateUnapproved
+		default:
+			continue
+		}
+		comments = append(comments, comment)
+	}
+	return comments, nil
+}
+
+// ListPullRequests gets pull request list
+func (c *Client) ListPullRequests(onlyOpen bool) ([]git.PullRequest, error) {
+	apiURL := c.repoAPIURL() + "/pull-requests"
+	if !onlyOpen {
+		apiURL += "?state=ALL"
+	}
+
+	var prs []PullRequest
+	err := c.getPaginated(apiURL, func(raw json.RawMessage) error {
+		var page []PullRequest
+		if err := json.Unmarshal(raw, &page); err != nil {
+			return err
+		}
+		prs = append(prs, page...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var result []git.PullRequest
+	for i := range prs {
+		result = append(result, *convertPullRequestToShared(&prs[i]))
+	}
+
+	return result, nil
+}
+
+// GetPullRequest gets PR given id
+func (c *Client) GetPullRequest(id int) (*git.PullRequest, error) {
+	pr, err := c.getPullRequest(id)
+	if err != nil {
+		return nil, err
+	}
+	return convertPullRequestToShared(pr), nil
+}
+
+func (c *Client) getPullRequest(id int) (*PullRequest, error) {
+	apiURL := fmt.Sprintf("%s/pull-requests/%d", c.repoAPIURL(), id)
+
+	data, _, err := c.requestHTTP(http.MethodGet, apiURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	pr := &PullRequest{}
+	if err := json.Unmarshal(data, pr); err != nil {
+		return nil, err
+	}
+	return pr, nil
+}
+
+// MergePullRequest merges a pull request
+func (c *Client) MergePullRequest(id int, sha string, method git.MergeMethod, message string) error {
+	// Merge API requires the current version of the pull request
+	pr, err := c.getPullRequest(id)
+	if err != nil {
+		return err
+	}
+	if pr.FromRef.LatestCommit != sha {
+		return fmt.Errorf("head sha of pull request %d is %s, not %s", id, pr.FromRef.LatestCommit, sha)
+	}
+
+	strategy := "no-ff"
+	if method == git.MergeMethodSquash {
+		strategy = "squash"
+	}
+
+	apiURL := fmt.Sprintf("%s/pull-requests/%d/merge?version=%d", c.repoAPIURL(), id, pr.Version)
+	if _, _, err := c.requestHTTP(http.MethodPost, apiURL, &MergeRequest{Message: message, StrategyID: strategy}); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// GetPullRequestDiff gets diff of the pull request
+func (c *Client) GetPullRequestDiff(id int) (*git.Diff, error) {
+	apiURL := fmt.Sprintf("%s/pull-requests/%d/diff?contextLines=0", c.repoAPIURL(), id)
+	raw, _, err := c.requestHTTP(http.MethodGet, apiURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &DiffResponse{}
+	if err := json.Unmarshal(raw, resp); err != nil {
+		return nil, err
+	}
+
+	var changes []git.Change
+	for _, d := range resp.Diffs {
+		var fileName, oldFileName string
+		if d.Destination != nil {
+			fileName = d.Destination.ToString
+		}
+		if d.Source != nil {
+			oldFileName = d.Source.ToString
+		}
+		if fileName == "" {
+			fileName = oldFileName
+		}
+		if oldFileName == "" {
+			oldFileName = fileName
+		}
+
+		additions, deletions := 0, 0
+		for _, h := range d.Hunks {
+			for _, s := range h.Segments {
+				switch s.Type {
+				case "ADDED":
+					additions += len(s.Lines)
+				case "REMOVED":
+					deletions += len(s.Lines)
+				}
+			}
+		}
+
+		changes = append(changes, git.Change{
+			Filename:    fileName,
+			OldFilename: oldFileName,
+			Additions:   additions,
+			Deletions:   deletions,
+			Changes:     additions + deletions,
+		})
+	}
+
+	return &git.Diff{Changes: changes}, nil
+}
+
+// ListPullRequestCommits lists commits list of a pull request
+func (c *Client) ListPullRequestCommits(id int) ([]git.Commit, error) {
+	apiURL := fmt.Sprintf("%s/pull-requests/%d/commits", c.repoAPIURL(), id)
+
+	var resp []Commit
+	err := c.getPaginated(apiURL, func(raw json.RawMessage) error {
+		var page []Commit
+		if err := json.Unmarshal(raw, &page); err != nil {
+			return err
+		}
+		resp = append(resp, page...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var commits []git.Commit
+	for _, commit := range resp {
+		commits = append(commits, git.Commit{
+			SHA:     commit.ID,
+			Message: commit.Message,
+			Author: git.User{
+				Name:  commit.Author.Name,
+				Email: commit.Author.EmailAddress,
+			},
+			Committer: git.User{
+				Name:  commit.Committer.Name,
+				Email: commit.Committer.EmailAddress,
+			},
+		})
+	}
+
+	return commits, nil
+}
+
+// GetCommitDiff gets diff between the base and head commits
+// Bitbucket Server's changes api does not contain line counts, so they are not filled
+func (c *Client) GetCommitDiff(base, head string) (*git.Diff, error) {
+	apiURL := fmt.Sprintf("%s/changes?since=%s&until=%s", c.repoAPIURL(), url.QueryEscape(base), url.QueryEscape(head))
+
+	var changes []git.Change
+	err := c.getPaginated(apiURL, func(raw json.RawMessage) error {
+		var page []Change
+		if err := json.Unmarshal(raw, &page); err != nil {
+			return err
+		}
+		for _, ch := range page {
+			oldFileName := ch.Path.ToString
+			if ch.SrcPath != nil && ch.SrcPath.ToString != "" {
+				oldFileName = ch.SrcPath.ToString
+			}
+			changes = append(changes, git.Change{Filename: ch.Path.ToString, OldFilename: oldFileName})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &git.Diff{Changes: changes}, nil
+}
+
+// GetFileContent gets the content of the file at the ref
+func (c *Client) GetFileContent(path, ref string) ([]byte, error) {
+	apiURL := fmt.Sprintf("%s/raw/%s?at=%s", c.repoAPIURL(), git.EscapeFilePath(path), url.QueryEscape(ref))
+	raw, _, err := c.requestHTTP(http.MethodGet, apiURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return raw, nil
+}
+
+// SetLabel sets label to the issue id
+// Bitbucket Server does not support labels for pull requests natively, so labels are stored in the pull request's
+// properties, under the 'labels' key
+func (c *Client) SetLabel(issueType git.IssueType, id int, label string) error {
+	if issueType != git.IssueTypePullRequest {
+		return fmt.Errorf("issue type %s is not supported", issueType)
+	}
+
+	labels, err := c.ListLabels(id)
+	if err != nil {
+		return err
+	}
+
+	var newLabels []string
+	for _, l := range labels {
+		if l.Name == label {
+			return nil
+		}
+		newLabels = append(newLabels, l.Name)
+	}
+	newLabels = append(newLabels, label)
+
+	return c.putLabels(id, newLabels)
+}
+
+// ListLabels lists labels of pr id
+func (c *Client) ListLabels(id int) ([]git.IssueLabel, error) {
+	pr, err := c.getPullRequest(id)
+	if err != nil {
+		return nil, err
+	}
+	return convertLabel(pr.Properties.Labels), nil
+}
+
+// DeleteLabel deletes label from the issue id
+func (c *Client) DeleteLabel(issueType git.IssueType, id int, label string) error {
+	if issueType != git.IssueTypePullRequest {
+		return fmt.Errorf("issue type %s is not supported", issueType)
+	}
+
+	labels, err := c.ListLabels(id)
+	if err != nil {
+		return err
+	}
+
+	newLabels := []string{}
+	for _, l := range labels {
+		if l.Name == label {
+			continue
+		}
+		newLabels = append(newLabels, l.Name)
+	}
+
+	return c.putLabels(id, newLabels)
+}
+
+func (c *Client) putLabels(id int, labels []string) error {
+	apiURL := fmt.Sprintf("%s/pull-requests/%d/properties/%s", c.repoAPIURL(), id, labelPropertyKey)
+	if _, _, err := c.requestHTTP(http.MethodPut, apiURL, labels); err != nil {
+		return err
+	}
+	return nil
+}
+
+// GetBranch gets branch info
+func (c *Client) GetBranch(branch string) (*git.Branch, error) {
+	apiURL := fmt.Sprintf("%s/branches?filterText=%s", c.repoAPIURL(), url.QueryEscape(branch))
+
+	var branches []Branch
+	err := c.getPaginated(apiURL, func(raw json.RawMessage) error {
+		var page []Branch
+		if err := json.Unmarshal(raw, &page); err != nil {
+			return err
+		}
+		branches = append(branches, page...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, b := range branches {
+		if b.DisplayID == branch {
+			return &git.Branch{Name: b.DisplayID, CommitID: b.LatestCommit}, nil
+		}
+	}
+
+	return nil, fmt.Errorf("branch %s not found", branch)
+}
+
+// repoAPIURL returns the base api url of the repository
+func (c *Client) repoAPIURL() string {
+	project, repo := c.projectRepo()
+	return fmt.Sprintf("%s/rest/api/1.0/projects/%s/repos/%s", c.IntegrationConfig.Spec.Git.GetAPIUrl(), project, repo)
+}
+
+// projectRepo splits the repository name (<project key>/<repo slug>)
+func (c *Client) projectRepo() (string, string) {
+	tokens := strings.SplitN(c.IntegrationConfig.Spec.Git.Repository, "/", 2)
+	if len(tokens) < 2 {
+		return tokens[0], ""
+	}
+	return tokens[0], tokens[1]
+}
+
+// getPaginated calls the paged api until the last page, calling handleValues for each page's values
+func (c *Client) getPaginated(apiURL string, handleValues func(json.RawMessage) error) error {
+	u, err := url.Parse(apiURL)
+	if err != nil {
+		return err
+	}
+
+	start := 0
+	for {
+		q := u.Query()
+		q.Set("limit", "100")
+		q.Set("start", fmt.Sprintf("%d", start))
+		u.RawQuery = q.Encode()
+
+		raw, _, err := c.requestHTTP(http.MethodGet, u.String(), nil)
+		if err != nil {
+			return err
+		}
+
+		page := &struct {
+			Page
+			Values json.RawMessage `json:"values"`
+		}{}
+		if err := json.Unmarshal(raw, page); err != nil {
+			return err
+		}
+		if len(page.Values) > 0 {
+			if err := handleValues(page.Values); err != nil {
+				return err
+			}
+		}
+
+		if page.IsLastPage || page.NextPageStart <= start {
+			return nil
+		}
+		start = page.NextPageStart
+	}
+}
+
+func (c *Client) requestHTTP(method, apiURL string, data interface{}) ([]byte, http.Header, error) {
+	tlsConfig := c.IntegrationConfig.GetTLSConfig()
+
+	return git.RequestHTTP(c.Context, method, apiURL, c.header, data, tlsConfig)
+}
+
+func convertPullRequestToShared(pr *PullRequest) *git.PullRequest {
+	var prURL string
+	if len(pr.Links.Self) > 0 {
+		prURL = pr.Links.Self[0].Href
+	}
+
+	return &git.PullRequest{
+		ID:        pr.ID,
+		Title:     pr.Title,
+		State:     convertState(pr.State),
+		Author:    *convertUser(pr.Author.User),
+		URL:       prURL,
+		Base:      git.Base{Ref: pr.ToRef.DisplayID, Sha: pr.ToRef.LatestCommit},
+		Head:      git.Head{Ref: pr.FromRef.DisplayID, Sha: pr.FromRef.LatestCommit},
+		Labels:    convertLabel(pr.Properties.Labels),
+		Mergeable: pr.Properties.MergeResult != nil && pr.Properties.MergeResult.Outcome == "CLEAN",
+	}
+}
+
+func convertUser(user User) *git.User {
+	return &git.User{
+		ID:    user.ID,
+		Name:  user.Name,
+		Email: user.EmailAddress,
+	}
+}
+
+func convertState(original string) git.PullRequestState {
+	if original == "OPEN" {
+		return git.PullRequestStateOpen
+	}
+	return git.PullRequestStateClosed
+}
+
+func convertLabel(original []string) []git.IssueLabel {
+	var labels []git.IssueLabel
+	for _, l := range original {
+		labels = append(labels, git.IssueLabel{Name: l})
+	}
+	return labels
+}
+
+func convertBuildState(original string) git.CommitStatusState {
+	switch original {
+	case BuildStateSuccessful:
+		return git.CommitStatusStateSuccess
+	case BuildStateFailed:
+		return git.CommitStatusStateFailure
+	default:
+		return git.CommitStatusStatePending
+	}
+}
+
+func convertCommitStatusState(state git.CommitStatusState) string {
+	switch state {
+	case git.CommitStatusStateSuccess:
+		return BuildStateSuccessful
+	case git.CommitStatusStateFailure, git.CommitStatusStateError:
+		return BuildStateFailed
+	default:
+		return BuildStateInProgress
+	}
+}
+
+// convertTime converts epoch milliseconds to metav1.Time
+func convertTime(epochMillis int64) *metav1.Time {
+	if epochMillis == 0 {
+		return nil
+	}
+	t := metav1.NewTime(time.Unix(0, epochMillis*int64(time.Millisecond)))
+	return &t
+}
+
+// IsValidPayload validates the webhook payload
+func IsValidPayload(secret, headerHash string, payload []byte) bool {
+	hash := HashPayload(secret, payload)
+	return hmac.Equal(
+		[]byte(hash),
+		[]byte(headerHash),
+	)
+}
+
+// HashPayload hashes the payload
+func HashPayload(secret string, payloadBody []byte) string {
+	hm := hmac.New(sha256.New, []byte(secret))
+	_, _ = hm.Write(payloadBody)
+	sum := hm.Sum(nil)
+
+	return fmt.Sprintf("%x", sum)
+}
+
+// Validate validates the webhook payload, signed with any of the secrets
+func Validate(secrets []string, headerHash string, payload []byte) error {
+	for _, secret := range secrets {
+		if IsValidPayload(secret, headerHash, payload) {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid request : X-Hub-Signature does not match secret")
+}
@@ -0,0 +1,732 @@
+/*
+ Copyright 2021 The CI/CD Operator Authors
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package bitbucket
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/require"
+	cicdv1 "github.com/tmax-cloud/cicd-operator/api/v1"
+	"github.com/tmax-cloud/cicd-operator/pkg/git"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+const (
+	testSecret = "1xkwb4yrcogvvv5vfdhg"
+	repoPath   = "/rest/api/1.0/projects/TMAX/repos/cicd-test"
+
+	samplePullRequest = `{
+  "id": 1,
+  "version": 3,
+  "title": "Test PR",
+  "state": "OPEN",
+  "author": {"user": {"id": 101, "name": "cqbqdd11519", "emailAddress": "cqbqdd11519@gmail.com"}},
+  "fromRef": {
+    "id": "refs/heads/feat/test",
+    "displayId": "feat/test",
+    "latestCommit": "3196ccc37bcae94852079b04fcbfaf928341d6e9",
+    "repository": {"slug": "cicd-test", "project": {"key": "TMAX"}, "links": {"self": [{"href": "https://bitbucket.example.com/projects/TMAX/repos/cicd-test/browse"}]}}
+  },
+  "toRef": {
+    "id": "refs/heads/master",
+    "displayId": "master",
+    "latestCommit": "22ccae53032027186ba739dfaa473ee61a82b298",
+    "repository": {"slug": "cicd-test", "project": {"key": "TMAX"}, "links": {"self": [{"href": "https://bitbucket.example.com/projects/TMAX/repos/cicd-test/browse"}]}}
+  },
+  "links": {"self": [{"href": "https://bitbucket.example.com/projects/TMAX/repos/cicd-test/pull-requests/1"}]},
+  "properties": {"mergeResult": {"outcome": "CLEAN"}, "labels": ["approved", "size/L"]}
+}`
+
+	samplePRWebhookOpened = `{
+  "eventKey": "pr:opened",
+  "actor": {"id": 101, "name": "cqbqdd11519", "emailAddress": "cqbqdd11519@gmail.com"},
+  "pullRequest": ` + samplePullRequest + `
+}`
+
+	samplePRWebhookFromRefUpdated = `{
+  "eventKey": "pr:from_ref_updated",
+  "actor": {"id": 101, "name": "cqbqdd11519", "emailAddress": "cqbqdd11519@gmail.com"},
+  "pullRequest": ` + samplePullRequest + `,
+  "previousFromHash": "a00945762949b7787e5e4b3f2cd5ec3a9fc9d7c1"
+}`
+
+	samplePushWebhook = `{
+  "eventKey": "repo:refs_changed",
+  "actor": {"id": 101, "name": "cqbqdd11519", "emailAddress": "cqbqdd11519@gmail.com"},
+  "repository": {"slug": "cicd-test", "project": {"key": "TMAX"}, "links": {"self": [{"href": "https://bitbucket.example.com/projects/TMAX/repos/cicd-test/browse"}]}},
+  "changes": [
+    {"ref": {"id": "refs/heads/old", "displayId": "old", "type": "BRANCH"}, "refId": "refs/heads/old", "fromHash": "a00945762949b7787e5e4b3f2cd5ec3a9fc9d7c1", "toHash": "0000000000000000000000000000000000000000", "type": "DELETE"},
+    {"ref": {"id": "refs/heads/master", "displayId": "master", "type": "BRANCH"}, "refId": "refs/heads/master", "fromHash": "a00945762949b7787e5e4b3f2cd5ec3a9fc9d7c1", "toHash": "22ccae53032027186ba739dfaa473ee61a82b298", "type": "UPDATE"}
+  ]
+}`
+
+	samplePushWebhookDeleteOnly = `{
+  "eventKey": "repo:refs_changed",
+  "actor": {"id": 101, "name": "cqbqdd11519"},
+  "repository": {"slug": "cicd-test", "project": {"key": "TMAX"}},
+  "changes": [
+    {"ref": {"id": "refs/heads/old", "displayId": "old", "type": "BRANCH"}, "refId": "refs/heads/old", "fromHash": "a00945762949b7787e5e4b3f2cd5ec3a9fc9d7c1", "toHash": "0000000000000000000000000000000000000000", "type": "DELETE"}
+  ]
+}`
+
+	sampleCommentWebhook = `{
+  "eventKey": "pr:comment:added",
+  "actor": {"id": 102, "name": "sunghyunkim3", "emailAddress": "sunghyunkim3@gmail.com"},
+  "pullRequest": ` + samplePullRequest + `,
+  "comment": {"id": 62, "text": "/retest", "author": {"id": 102, "name": "sunghyunkim3", "emailAddress": "sunghyunkim3@gmail.com"}, "createdDate": 1631510400000}
+}`
+
+	sampleWebhookList = `{"size": 2, "limit": 100, "isLastPage": true, "start": 0, "values": [
+  {"id": 1, "name": "cicd-operator", "url": "http://asdasd/webhook/default/chatops-test", "events": ["repo:refs_changed"], "active": true},
+  {"id": 2, "name": "cicd-operator", "url": "http://asdasd/webhook/default/chatops-test2", "events": ["repo:refs_changed"], "active": true}
+]}`
+
+	sampleBuildStatusesPage1 = `{"size": 2, "limit": 2, "isLastPage": false, "start": 0, "nextPageStart": 2, "values": [
+  {"state": "SUCCESSFUL", "key": "test-1", "url": "http://test-1", "description": "Job succeeded"},
+  {"state": "FAILED", "key": "test-1", "url": "http://test-1", "description": "Job failed"}
+]}`
+
+	sampleBuildStatusesPage2 = `{"size": 1, "limit": 2, "isLastPage": true, "start": 2, "values": [
+  {"state": "INPROGRESS", "key": "test-2", "url": "http://test-2", "description": "Job is running"}
+]}`
+
+	sampleUser = `{"id": 101, "name": "cqbqdd11519", "emailAddress": "cqbqdd11519@gmail.com", "displayName": "Sunghyun Kim", "slug": "cqbqdd11519"}`
+
+	sampleRepoPermissions = `{"size": 1, "limit": 100, "isLastPage": true, "start": 0, "values": [
+  {"user": {"id": 101, "name": "cqbqdd11519", "slug": "cqbqdd11519"}, "permission": "REPO_WRITE"}
+]}`
+
+	sampleProjectPermissions = `{"size": 1, "limit": 100, "isLastPage": true, "start": 0, "values": [
+  {"user": {"id": 103, "name": "projectadmin", "slug": "projectadmin"}, "permission": "PROJECT_ADMIN"}
+]}`
+
+	sampleActivities = `{"size": 4, "limit": 100, "isLastPage": true, "start": 0, "values": [
+  {"id": 1, "action": "OPENED", "createdDate": 1631510400000, "user": {"id": 101, "name": "cqbqdd11519"}},
+  {"id": 2, "action": "COMMENTED", "createdDate": 1631510400000, "user": {"id": 102, "name": "sunghyunkim3"}, "comment": {"id": 62, "text": "/retest"}},
+  {"id": 3, "action": "APPROVED", "createdDate": 1631510500000, "user": {"id": 102, "name": "sunghyunkim3"}},
+  {"id": 4, "action": "REVIEWED", "createdDate": 1631510600000, "user": {"id": 103, "name": "projectadmin"}}
+]}`
+
+	samplePullRequests = `{"size": 1, "limit": 100, "isLastPage": true, "start": 0, "values": [` + samplePullRequest + `]}`
+
+	sampleDiff = `{"fromHash": "22ccae53032027186ba739dfaa473ee61a82b298", "toHash": "3196ccc37bcae94852079b04fcbfaf928341d6e9", "diffs": [
+  {"source": {"toString": "Makefile"}, "destination": {"toString": "Makefile"}, "hunks": [{"segments": [
+    {"type": "REMOVED", "lines": [{"source": 1, "destination": 1, "line": "VERSION ?= v0.5.0"}]},
+    {"type": "ADDED", "lines": [{"source": 1, "destination": 1, "line": "VERSION ?= v0.5.1"}]}
+  ]}]},
+  {"source": null, "destination": {"toString": "docs/new.md"}, "hunks": [{"segments": [
+    {"type": "ADDED", "lines": [{"source": 0, "destination": 1, "line": "# New"}, {"source": 0, "destination": 2, "line": "doc"}]}
+  ]}]},
+  {"source": {"toString": "docs/old.md"}, "destination": {"toString": "docs/renamed.md"}, "hunks": []}
+]}`
+
+	sampleCommits = `{"size": 1, "limit": 100, "isLastPage": true, "start": 0, "values": [
+  {"id": "3196ccc37bcae94852079b04fcbfaf928341d6e9", "message": "Fix critical typo", "author": {"name": "Sunghyun Kim", "emailAddress": "cqbqdd11519@gmail.com"}, "committer": {"name": "Sunghyun Kim", "emailAddress": "cqbqdd11519@gmail.com"}}
+]}`
+
+	sampleBranches = `{"size": 2, "limit": 100, "isLastPage": true, "start": 0, "values": [
+  {"id": "refs/heads/master", "displayId": "master", "latestCommit": "22ccae53032027186ba739dfaa473ee61a82b298"},
+  {"id": "refs/heads/master-old", "displayId": "master-old", "latestCommit": "a00945762949b7787e5e4b3f2cd5ec3a9fc9d7c1"}
+]}`
+
+	sampleChanges = `{"size": 2, "limit": 100, "isLastPage": true, "start": 0, "values": [
+  {"type": "MODIFY", "path": {"toString": "Makefile"}},
+  {"type": "MOVE", "path": {"toString": "docs/renamed.md"}, "srcPath": {"toString": "docs/old.md"}}
+]}`
+)
+
+func TestClient_ParseWebhook(t *testing.T) {
+	tc := map[string]struct {
+		eventKey   string
+		signature  string
+		jsonString []byte
+
+		expectedErr    bool
+		expectedErrMsg string
+		expectedNil    bool
+		expectedType   git.EventType
+		expectedAction git.PullRequestAction
+		expectedRef    string
+		expectedSha    string
+	}{
+		"ping": {
+			eventKey:    EventKeyDiagnosticsPing,
+			jsonString:  []byte(`{"test": true}`),
+			expectedNil: true,
+		},
+		"validationErr": {
+			eventKey:       EventKeyPullRequestOpened,
+			signature:      "sha256=07032fa51772234024a49a615f50eefbe644b7fb",
+			jsonString:     []byte(samplePRWebhookOpened),
+			expectedErr:    true,
+			expectedErrMsg: "invalid request : X-Hub-Signature does not match secret",
+		},
+		"pullRequestOpened": {
+			eventKey:       EventKeyPullRequestOpened,
+			jsonString:     []byte(samplePRWebhookOpened),
+			expectedType:   git.EventTypePullRequest,
+			expectedAction: git.PullRequestActionOpen,
+			expectedRef:    "feat/test",
+			expectedSha:    "3196ccc37bcae94852079b04fcbfaf928341d6e9",
+		},
+		"pullRequestFromRefUpdated": {
+			eventKey:       EventKeyPullRequestFromRefUpdate,
+			jsonString:     []byte(samplePRWebhookFromRefUpdated),
+			expectedType:   git.EventTypePullRequest,
+			expectedAction: git.PullRequestActionSynchronize,
+			expectedRef:    "feat/test",
+			expectedSha:    "3196ccc37bcae94852079b04fcbfaf928341d6e9",
+		},
+		"push": {
+			eventKey:     EventKeyRepoRefsChanged,
+			jsonString:   []byte(samplePushWebhook),
+			expectedType: git.EventTypePush,
+			expectedRef:  "refs/heads/master",
+			expectedSha:  "22ccae53032027186ba739dfaa473ee61a82b298",
+		},
+		"pushDeleteOnly": {
+			eventKey:    EventKeyRepoRefsChanged,
+			jsonString:  []byte(samplePushWebhookDeleteOnly),
+			expectedNil: true,
+		},
+		"comment": {
+			eventKey:     EventKeyPullRequestCommentAdded,
+			jsonString:   []byte(sampleCommentWebhook),
+			expectedType: git.EventTypeIssueComment,
+			expectedRef:  "feat/test",
+			expectedSha:  "3196ccc37bcae94852079b04fcbfaf928341d6e9",
+		},
+		"unknownEvent": {
+			eventKey:    "repo:modified",
+			jsonString:  []byte(`{}`),
+			expectedNil: true,
+		},
+		"marshalErr": {
+			eventKey:       EventKeyPullRequestOpened,
+			jsonString:     []byte(`{"pullRequest": "wrong"}`),
+			expectedErr:    true,
+			expectedErrMsg: "json: cannot unmarshal",
+		},
+	}
+	for name, c := range tc {
+		t.Run(name, func(t *testing.T) {
+			cli, err := testEnv()
+			require.NoError(t, err)
+
+			signature := c.signature
+			if signature == "" {
+				signature = "sha256=" + HashPayload(testSecret, c.jsonString)
+			}
+			header := http.Header{}
+			header.Add("x-hub-signature", signature)
+			header.Add("x-event-key", c.eventKey)
+			header.Add("X-Request-Id", "test-delivery")
+			wh, err := cli.ParseWebhook(header, c.jsonString)
+			if c.expectedErr {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), c.expectedErrMsg)
+				return
+			}
+			require.NoError(t, err)
+			if c.expectedNil {
+				require.Nil(t, wh)
+				return
+			}
+			require.Equal(t, c.expectedType, wh.EventType)
+			require.Equal(t, "TMAX/cicd-test", wh.Repo.Name)
+			require.Equal(t, "test-delivery", wh.DeliveryID)
+			switch c.expectedType {
+			case git.EventTypePullRequest:
+				require.Equal(t, c.expectedAction, wh.PullRequest.Action)
+				require.Equal(t, 1, wh.PullRequest.ID)
+				require.Equal(t, c.expectedRef, wh.PullRequest.Head.Ref)
+				require.Equal(t, c.expectedSha, wh.PullRequest.Head.Sha)
+				require.Equal(t, "master", wh.PullRequest.Base.Ref)
+				require.Equal(t, "cqbqdd11519", wh.Sender.Name)
+				require.Equal(t, "cqbqdd11519@gmail.com", wh.Sender.Email)
+			case git.EventTypePush:
+				require.Equal(t, c.expectedRef, wh.Push.Ref)
+				require.Equal(t, c.expectedSha, wh.Push.Sha)
+			case git.EventTypeIssueComment:
+				require.Equal(t, "/retest", wh.IssueComment.Comment.Body)
+				require.Equal(t, "sunghyunkim3", wh.IssueComment.Author.Name)
+				require.Equal(t, c.expectedRef, wh.IssueComment.Issue.PullRequest.Head.Ref)
+				require.Equal(t, c.expectedSha, wh.IssueComment.Issue.PullRequest.Head.Sha)
+			}
+		})
+	}
+}
+
+func TestClient_ListWebhook(t *testing.T) {
+	c, err := testEnv()
+	require.NoError(t, err)
+
+	wh, err := c.ListWebhook()
+	require.NoError(t, err)
+	require.Len(t, wh, 2)
+	require.Equal(t, 1, wh[0].ID)
+	require.Equal(t, "http://asdasd/webhook/default/chatops-test", wh[0].URL)
+	require.Equal(t, "http://asdasd/webhook/default/chatops-test2", wh[1].URL)
+}
+
+func TestClient_RegisterWebhook(t *testing.T) {
+	c, err := testEnv()
+	require.NoError(t, err)
+
+	require.NoError(t, c.RegisterWebhook("http://asdasd/webhook/default/chatops-test"))
+}
+
+func TestClient_DeleteWebhook(t *testing.T) {
+	c, err := testEnv()
+	require.NoError(t, err)
+
+	require.NoError(t, c.DeleteWebhook(1))
+
+	err = c.DeleteWebhook(2)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "doesn't exists")
+}
+
+func TestClient_ListCommitStatuses(t *testing.T) {
+	c, err := testEnv()
+	require.NoError(t, err)
+
+	statuses, err := c.ListCommitStatuses("3196ccc37bcae94852079b04fcbfaf928341d6e9")
+	require.NoError(t, err)
+	require.Len(t, statuses, 2)
+	require.Equal(t, "test-1", statuses[0].Context)
+	require.Equal(t, git.CommitStatusStateSuccess, statuses[0].State)
+	require.Equal(t, "test-2", statuses[1].Context)
+	require.Equal(t, git.CommitStatusStatePending, statuses[1].State)
+}
+
+func TestClient_SetCommitStatus(t *testing.T) {
+	tc := map[string]struct {
+		sha    string
+		status git.CommitStatus
+
+		expectedErr    bool
+		expectedErrMsg string
+	}{
+		"success": {
+			sha:    "3196ccc37bcae94852079b04fcbfaf928341d6e9",
+			status: git.CommitStatus{Context: "test-1", State: git.CommitStatusStateFailure, Description: "Job failed", TargetURL: "http://test-1"},
+		},
+		"fakeSha": {
+			sha: git.FakeSha,
+		},
+		"serverErr": {
+			sha:            "3196ccc37bcae94852079b04fcbfaf928341d6e9",
+			status:         git.CommitStatus{Context: "test-1", State: git.CommitStatusStateSuccess},
+			expectedErr:    true,
+			expectedErrMsg: "url is required",
+		},
+	}
+	for name, c := range tc {
+		t.Run(name, func(t *testing.T) {
+			cli, err := testEnv()
+			require.NoError(t, err)
+
+			err = cli.SetCommitStatus(c.sha, c.status)
+			if c.expectedErr {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), c.expectedErrMsg)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestClient_GetUserInfo(t *testing.T) {
+	c, err := testEnv()
+	require.NoError(t, err)
+
+	user, err := c.GetUserInfo("cqbqdd11519")
+	require.NoError(t, err)
+	require.Equal(t, &git.User{ID: 101, Name: "cqbqdd11519", Email: "cqbqdd11519@gmail.com"}, user)
+
+	_, err = c.GetUserInfo("notexist")
+	require.Error(t, err)
+}
+
+func TestClient_CanUserWriteToRepo(t *testing.T) {
+	tc := map[string]struct {
+		user               string
+		expectedPermission bool
+	}{
+		"repoWrite": {
+			user:               "cqbqdd11519",
+			expectedPermission: true,
+		},
+		"projectAdmin": {
+			user:               "projectadmin",
+			expectedPermission: true,
+		},
+		"noPermission": {
+			user:               "sunghyunkim3",
+			expectedPermission: false,
+		},
+	}
+	for name, c := range tc {
+		t.Run(name, func(t *testing.T) {
+			cli, err := testEnv()
+			require.NoError(t, err)
+
+			permission, err := cli.CanUserWriteToRepo(git.User{Name: c.user})
+			require.NoError(t, err)
+			require.Equal(t, c.expectedPermission, permission)
+		})
+	}
+}
+
+func TestClient_RegisterComment(t *testing.T) {
+	tc := map[string]struct {
+		issueType git.IssueType
+
+		expectedErr    bool
+		expectedErrMsg string
+	}{
+		"pullRequest": {
+			issueType: git.IssueTypePullRequest,
+		},
+		"commit": {
+			issueType: git.IssueTypeCommit,
+		},
+		"issue": {
+			issueType:      git.IssueTypeIssue,
+			expectedErr:    true,
+			expectedErrMsg: "issue type issue is not supported",
+		},
+	}
+	for name, c := range tc {
+		t.Run(name, func(t *testing.T) {
+			cli, err := testEnv()
+			require.NoError(t, err)
+
+			err = cli.RegisterComment(c.issueType, 1, "3196ccc37bcae94852079b04fcbfaf928341d6e9", "test comment")
+			if c.expectedErr {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), c.expectedErrMsg)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestClient_ListComments(t *testing.T) {
+	c, err := testEnv()
+	require.NoError(t, err)
+
+	comments, err := c.ListComments(1)
+	require.NoError(t, err)
+	require.Len(t, comments, 3)
+	require.Equal(t, "/retest", comments[0].Comment.Body)
+	require.Equal(t, int64(1631510400), comments[0].Comment.CreatedAt.Unix())
+	require.Equal(t, git.PullRequestReviewStateApproved, comments[1].ReviewState)
+	require.Equal(t, git.PullRequestReviewStateUnapproved, comments[2].ReviewState)
+}
+
+func TestClient_ListPullRequests(t *testing.T) {
+	c, err := testEnv()
+	require.NoError(t, err)
+
+	prs, err := c.ListPullRequests(true)
+	require.NoError(t, err)
+	require.Len(t, prs, 1)
+	require.Equal(t, 1, prs[0].ID)
+	require.Equal(t, "Test PR", prs[0].Title)
+	require.Equal(t, git.PullRequestStateOpen, prs[0].State)
+	require.Equal(t, "https://bitbucket.example.com/projects/TMAX/repos/cicd-test/pull-requests/1", prs[0].URL)
+	require.True(t, prs[0].Mergeable)
+	require.Equal(t, []git.IssueLabel{{Name: "approved"}, {Name: "size/L"}}, prs[0].Labels)
+}
+
+func TestClient_MergePullRequest(t *testing.T) {
+	tc := map[string]struct {
+		sha    string
+		method git.MergeMethod
+
+		expectedErr    bool
+		expectedErrMsg string
+	}{
+		"squash": {
+			sha:    "3196ccc37bcae94852079b04fcbfaf928341d6e9",
+			method: git.MergeMethodSquash,
+		},
+		"merge": {
+			sha:    "3196ccc37bcae94852079b04fcbfaf928341d6e9",
+			method: git.MergeMethodMerge,
+		},
+		"shaMismatch": {
+			sha:            "a00945762949b7787e5e4b3f2cd5ec3a9fc9d7c1",
+			method:         git.MergeMethodMerge,
+			expectedErr:    true,
+			expectedErrMsg: "head sha of pull request 1 is",
+		},
+	}
+	for name, c := range tc {
+		t.Run(name, func(t *testing.T) {
+			cli, err := testEnv()
+			require.NoError(t, err)
+
+			err = cli.MergePullRequest(1, c.sha, c.method, "Test PR\n\nbody")
+			if c.expectedErr {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), c.expectedErrMsg)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestClient_GetPullRequestDiff(t *testing.T) {
+	c, err := testEnv()
+	require.NoError(t, err)
+
+	diff, err := c.GetPullRequestDiff(1)
+	require.NoError(t, err)
+	require.Equal(t, []git.Change{
+		{Filename: "Makefile", OldFilename: "Makefile", Additions: 1, Deletions: 1, Changes: 2},
+		{Filename: "docs/new.md", OldFilename: "docs/new.md", Additions: 2, Deletions: 0, Changes: 2},
+		{Filename: "docs/renamed.md", OldFilename: "docs/old.md", Additions: 0, Deletions: 0, Changes: 0},
+	}, diff.Changes)
+}
+
+func TestClient_GetCommitDiff(t *testing.T) {
+	c, err := testEnv()
+	require.NoError(t, err)
+
+	diff, err := c.GetCommitDiff("22ccae53032027186ba739dfaa473ee61a82b298", "3196ccc37bcae94852079b04fcbfaf928341d6e9")
+	require.NoError(t, err)
+	require.Equal(t, []git.Change{
+		{Filename: "Makefile", OldFilename: "Makefile"},
+		{Filename: "docs/renamed.md", OldFilename: "docs/old.md"},
+	}, diff.Changes)
+}
+
+func TestClient_GetFileContent(t *testing.T) {
+	c, err := testEnv()
+	require.NoError(t, err)
+
+	content, err := c.GetFileContent(".cicd/jobs.yaml", "3196ccc37bcae94852079b04fcbfaf928341d6e9")
+	require.NoError(t, err)
+	require.Equal(t, "preSubmit:\n- name: test\n", string(content))
+
+	_, err = c.GetFileContent(".cicd/jobs.yaml", "22ccae53032027186ba739dfaa473ee61a82b298")
+	require.Error(t, err)
+}
+
+func TestClient_ListPullRequestCommits(t *testing.T) {
+	c, err := testEnv()
+	require.NoError(t, err)
+
+	commits, err := c.ListPullRequestCommits(1)
+	require.NoError(t, err)
+	require.Len(t, commits, 1)
+	require.Equal(t, "3196ccc37bcae94852079b04fcbfaf928341d6e9", commits[0].SHA)
+	require.Equal(t, "Fix critical typo", commits[0].Message)
+	require.Equal(t, "Sunghyun Kim", commits[0].Author.Name)
+	require.Equal(t, "cqbqdd11519@gmail.com", commits[0].Committer.Email)
+}
+
+func TestClient_Labels(t *testing.T) {
+	c, err := testEnv()
+	require.NoError(t, err)
+
+	labels, err := c.ListLabels(1)
+	require.NoError(t, err)
+	require.Equal(t, []git.IssueLabel{{Name: "approved"}, {Name: "size/L"}}, labels)
+
+	require.NoError(t, c.SetLabel(git.IssueTypePullRequest, 1, "lgtm"))
+	require.Equal(t, []string{"approved", "size/L", "lgtm"}, lastLabels)
+
+	require.NoError(t, c.DeleteLabel(git.IssueTypePullRequest, 1, "approved"))
+	require.Equal(t, []string{"size/L"}, lastLabels)
+
+	err = c.SetLabel(git.IssueTypeIssue, 1, "lgtm")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "issue type issue is not supported")
+}
+
+func TestClient_GetBranch(t *testing.T) {
+	c, err := testEnv()
+	require.NoError(t, err)
+
+	branch, err := c.GetBranch("master")
+	require.NoError(t, err)
+	require.Equal(t, &git.Branch{Name: "master", CommitID: "22ccae53032027186ba739dfaa473ee61a82b298"}, branch)
+
+	_, err = c.GetBranch("fake")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "branch fake not found")
+}
+
+// lastLabels stores the labels put by the last label request
+var lastLabels []string
+
+func testEnv() (*Client, error) {
+	r := mux.NewRouter()
+	setRouter(r)
+	testSrv := httptest.NewServer(r)
+
+	s := runtime.NewScheme()
+	utilruntime.Must(cicdv1.AddToScheme(s))
+	ic := &cicdv1.IntegrationConfig{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-ic",
+			Namespace: "default",
+		},
+		Spec: cicdv1.IntegrationConfigSpec{
+			Git: cicdv1.GitConfig{
+				Type:       cicdv1.GitTypeBitbucket,
+				Repository: "TMAX/cicd-test",
+				APIUrl:     testSrv.URL,
+				Token:      &cicdv1.GitToken{Value: "dummy"},
+			},
+		},
+		Status: cicdv1.IntegrationConfigStatus{
+			Secrets: testSecret,
+		},
+	}
+	c := &Client{
+		IntegrationConfig: ic,
+		K8sClient:         fake.NewClientBuilder().WithScheme(s).WithObjects(ic).Build(),
+	}
+	if err := c.Init(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func setRouter(r *mux.Router) {
+	writeJSON := func(body string) func(http.ResponseWriter, *http.Request) {
+		return func(w http.ResponseWriter, _ *http.Request) {
+			_, _ = w.Write([]byte(body))
+		}
+	}
+	notFound := func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"errors": [{"message": "doesn't exists"}]}`))
+	}
+
+	// Webhooks
+	r.HandleFunc(repoPath+"/webhooks", writeJSON(sampleWebhookList)).Methods(http.MethodGet)
+	r.HandleFunc(repoPath+"/webhooks", func(w http.ResponseWriter, req *http.Request) {
+		body := &RegistrationWebhookBody{}
+		if err := json.NewDecoder(req.Body).Decode(body); err != nil || body.Configuration.Secret != testSecret {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	}).Methods(http.MethodPost)
+	r.HandleFunc(repoPath+"/webhooks/1", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}).Methods(http.MethodDelete)
+	r.HandleFunc(repoPath+"/webhooks/2", notFound).Methods(http.MethodDelete)
+
+	// Build statuses
+	r.HandleFunc("/rest/build-status/1.0/commits/{sha}", func(w http.ResponseWriter, req *http.Request) {
+		if req.URL.Query().Get("start") == "2" {
+			_, _ = w.Write([]byte(sampleBuildStatusesPage2))
+			return
+		}
+		_, _ = w.Write([]byte(sampleBuildStatusesPage1))
+	}).Methods(http.MethodGet)
+	r.HandleFunc("/rest/build-status/1.0/commits/{sha}", func(w http.ResponseWriter, req *http.Request) {
+		body := &BuildStatus{}
+		if err := json.NewDecoder(req.Body).Decode(body); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if body.State == BuildStateSuccessful && body.URL != "http://test-1" {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"errors": [{"message": "url is required"}]}`))
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}).Methods(http.MethodPost)
+
+	// Users
+	r.HandleFunc("/rest/api/1.0/users/cqbqdd11519", writeJSON(sampleUser)).Methods(http.MethodGet)
+	r.HandleFunc("/rest/api/1.0/users/notexist", notFound).Methods(http.MethodGet)
+	r.HandleFunc(repoPath+"/permissions/users", writeJSON(sampleRepoPermissions)).Methods(http.MethodGet)
+	r.HandleFunc("/rest/api/1.0/projects/TMAX/permissions/users", writeJSON(sampleProjectPermissions)).Methods(http.MethodGet)
+
+	// Comments
+	r.HandleFunc(repoPath+"/pull-requests/1/comments", writeJSON(`{"id": 63}`)).Methods(http.MethodPost)
+	r.HandleFunc(repoPath+"/commits/{sha}/comments", writeJSON(`{"id": 64}`)).Methods(http.MethodPost)
+	r.HandleFunc(repoPath+"/pull-requests/1/activities", writeJSON(sampleActivities)).Methods(http.MethodGet)
+
+	// Pull requests
+	r.HandleFunc(repoPath+"/pull-requests", writeJSON(samplePullRequests)).Methods(http.MethodGet)
+	r.HandleFunc(repoPath+"/pull-requests/1", writeJSON(samplePullRequest)).Methods(http.MethodGet)
+	r.HandleFunc(repoPath+"/pull-requests/1/merge", func(w http.ResponseWriter, req *http.Request) {
+		body := &MergeRequest{}
+		if err := json.NewDecoder(req.Body).Decode(body); err != nil || req.URL.Query().Get("version") != "3" {
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+		if body.StrategyID != "squash" && body.StrategyID != "no-ff" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		_, _ = w.Write([]byte(samplePullRequest))
+	}).Methods(http.MethodPost)
+	r.HandleFunc(repoPath+"/pull-requests/1/diff", writeJSON(sampleDiff)).Methods(http.MethodGet)
+	r.HandleFunc(repoPath+"/pull-requests/1/commits", writeJSON(sampleCommits)).Methods(http.MethodGet)
+	r.HandleFunc(repoPath+"/changes", func(w http.ResponseWriter, req *http.Request) {
+		if req.URL.Query().Get("since") != "22ccae53032027186ba739dfaa473ee61a82b298" || req.URL.Query().Get("until") != "3196ccc37bcae94852079b04fcbfaf928341d6e9" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		_, _ = w.Write([]byte(sampleChanges))
+	}).Methods(http.MethodGet)
+	r.HandleFunc(repoPath+"/raw/{path:.+}", func(w http.ResponseWriter, req *http.Request) {
+		if mux.Vars(req)["path"] != ".cicd/jobs.yaml" || req.URL.Query().Get("at") != "3196ccc37bcae94852079b04fcbfaf928341d6e9" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte("preSubmit:\n- name: test\n"))
+	}).Methods(http.MethodGet)
+	r.HandleFunc(repoPath+"/pull-requests/1/properties/labels", func(w http.ResponseWriter, req *http.Request) {
+		b, err := ioutil.ReadAll(req.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		lastLabels = nil
+		if err := json.Unmarshal(b, &lastLabels); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}).Methods(http.MethodPut)
+
+	// Branches
+	r.HandleFunc(repoPath+"/branches", writeJSON(sampleBranches)).Methods(http.MethodGet)
+}
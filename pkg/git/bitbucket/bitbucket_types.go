@@ -0,0 +1,264 @@
+/*
+ Copyright 2021 The CI/CD Operator Authors
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package bitbucket
+
+// Bitbucket Server event keys (X-Event-Key header)
+const (
+	EventKeyPullRequestOpened        = "pr:opened"
+	EventKeyPullRequestReopened      = "pr:reopened"
+	EventKeyPullRequestFromRefUpdate = "pr:from_ref_updated"
+	EventKeyPullRequestMerged        = "pr:merged"
+	EventKeyPullRequestDeclined      = "pr:declined"
+	EventKeyPullRequestCommentAdded  = "pr:comment:added"
+	EventKeyRepoRefsChanged          = "repo:refs_changed"
+	EventKeyDiagnosticsPing          = "diagnostics:ping"
+)
+
+// Build states of Bitbucket Server
+const (
+	BuildStateSuccessful = "SUCCESSFUL"
+	BuildStateFailed     = "FAILED"
+	BuildStateInProgress = "INPROGRESS"
+)
+
+// labelPropertyKey is a pull request property key, where labels are stored
+const labelPropertyKey = "labels"
+
+// User is a user of Bitbucket Server
+type User struct {
+	ID           int    `json:"id"`
+	Name         string `json:"name"`
+	EmailAddress string `json:"emailAddress"`
+	DisplayName  string `json:"displayName"`
+	Slug         string `json:"slug"`
+}
+
+// Project is a project of Bitbucket Server
+type Project struct {
+	Key string `json:"key"`
+}
+
+// Repository is a repository of Bitbucket Server
+type Repository struct {
+	Slug    string  `json:"slug"`
+	Name    string  `json:"name"`
+	Project Project `json:"project"`
+	Links   Links   `json:"links"`
+}
+
+// Links is a links object, included in many Bitbucket Server objects
+type Links struct {
+	Self []Link `json:"self"`
+}
+
+// Link is a single link
+type Link struct {
+	Href string `json:"href"`
+}
+
+// Ref is a reference to a branch of a repository
+type Ref struct {
+	ID           string     `json:"id"`
+	DisplayID    string     `json:"displayId"`
+	LatestCommit string     `json:"latestCommit"`
+	Repository   Repository `json:"repository"`
+}
+
+// PullRequest is a pull request of Bitbucket Server
+type PullRequest struct {
+	ID         int                    `json:"id"`
+	Version    int                    `json:"version"`
+	Title      string                 `json:"title"`
+	State      string                 `json:"state"`
+	Author     PullRequestParticipant `json:"author"`
+	FromRef    Ref                    `json:"fromRef"`
+	ToRef      Ref                    `json:"toRef"`
+	Links      Links                  `json:"links"`
+	Properties PullRequestProperties  `json:"properties"`
+}
+
+// PullRequestParticipant is a participant of a pull request
+type PullRequestParticipant struct {
+	User User `json:"user"`
+}
+
+// PullRequestProperties is properties of a pull request
+type PullRequestProperties struct {
+	MergeResult *MergeResult `json:"mergeResult,omitempty"`
+	Labels      []string     `json:"labels,omitempty"`
+}
+
+// MergeResult is a merge result of a pull request
+type MergeResult struct {
+	Outcome string `json:"outcome"`
+}
+
+// PullRequestWebhook is a pull request event webhook body
+type PullRequestWebhook struct {
+	EventKey    string      `json:"eventKey"`
+	Actor       User        `json:"actor"`
+	PullRequest PullRequest `json:"pullRequest"`
+}
+
+// PushWebhook is a repo:refs_changed event webhook body
+type PushWebhook struct {
+	EventKey   string      `json:"eventKey"`
+	Actor      User        `json:"actor"`
+	Repository Repository  `json:"repository"`
+	Changes    []RefChange `json:"changes"`
+}
+
+// RefChange is a change of a reference
+type RefChange struct {
+	Ref      Ref    `json:"ref"`
+	RefID    string `json:"refId"`
+	FromHash string `json:"fromHash"`
+	ToHash   string `json:"toHash"`
+	Type     string `json:"type"`
+}
+
+// CommentWebhook is a pr:comment:added event webhook body
+type CommentWebhook struct {
+	EventKey    string      `json:"eventKey"`
+	Actor       User        `json:"actor"`
+	PullRequest PullRequest `json:"pullRequest"`
+	Comment     Comment     `json:"comment"`
+}
+
+// Comment is a comment of Bitbucket Server
+type Comment struct {
+	ID          int    `json:"id"`
+	Text        string `json:"text"`
+	Author      User   `json:"author"`
+	CreatedDate int64  `json:"createdDate"`
+}
+
+// CommentBody is a body structure for creating new comment
+type CommentBody struct {
+	Text string `json:"text"`
+}
+
+// Activity is an activity of a pull request
+type Activity struct {
+	ID          int      `json:"id"`
+	Action      string   `json:"action"`
+	CreatedDate int64    `json:"createdDate"`
+	User        User     `json:"user"`
+	Comment     *Comment `json:"comment,omitempty"`
+}
+
+// Page is a paged response of Bitbucket Server
+type Page struct {
+	Size          int  `json:"size"`
+	Limit         int  `json:"limit"`
+	IsLastPage    bool `json:"isLastPage"`
+	Start         int  `json:"start"`
+	NextPageStart int  `json:"nextPageStart"`
+}
+
+// WebhookEntry is a webhook entry
+type WebhookEntry struct {
+	ID     int      `json:"id"`
+	Name   string   `json:"name"`
+	URL    string   `json:"url"`
+	Events []string `json:"events"`
+	Active bool     `json:"active"`
+}
+
+// RegistrationWebhookBody is a request body for registering webhook
+type RegistrationWebhookBody struct {
+	Name          string                        `json:"name"`
+	URL           string                        `json:"url"`
+	Events        []string                      `json:"events"`
+	Active        bool                          `json:"active"`
+	Configuration RegistrationWebhookBodyConfig `json:"configuration"`
+}
+
+// RegistrationWebhookBodyConfig is a configuration of the webhook
+type RegistrationWebhookBodyConfig struct {
+	Secret string `json:"secret"`
+}
+
+// BuildStatus is a build status of a commit
+type BuildStatus struct {
+	State       string `json:"state"`
+	Key         string `json:"key"`
+	Name        string `json:"name,omitempty"`
+	URL         string `json:"url"`
+	Description string `json:"description"`
+}
+
+// UserPermission is a permission granted to a user
+type UserPermission struct {
+	User       User   `json:"user"`
+	Permission string `json:"permission"`
+}
+
+// MergeRequest is a request body for merging a pull request
+type MergeRequest struct {
+	Message    string `json:"message,omitempty"`
+	StrategyID string `json:"strategyId,omitempty"`
+}
+
+// DiffResponse is a diff of a pull request
+type DiffResponse struct {
+	Diffs []FileDiff `json:"diffs"`
+}
+
+// FileDiff is a diff of a single file
+type FileDiff struct {
+	Source      *DiffPath `json:"source"`
+	Destination *DiffPath `json:"destination"`
+	Hunks       []Hunk    `json:"hunks"`
+}
+
+// DiffPath is a path of a diff file
+type DiffPath struct {
+	ToString string `json:"toString"`
+}
+
+// Change is a changed file between two commits
+type Change struct {
+	Path    DiffPath  `json:"path"`
+	SrcPath *DiffPath `json:"srcPath"`
+}
+
+// Hunk is a hunk of a diff
+type Hunk struct {
+	Segments []Segment `json:"segments"`
+}
+
+// Segment is a segment of a hunk
+type Segment struct {
+	Type  string        `json:"type"`
+	Lines []interface{} `json:"lines"`
+}
+
+// Commit is a commit of Bitbucket Server
+type Commit struct {
+	ID        string `json:"id"`
+	Message   string `json:"message"`
+	Author    User   `json:"author"`
+	Committer User   `json:"committer"`
+}
+
+// Branch is a branch of Bitbucket Server
+type Branch struct {
+	ID           string `json:"id"`
+	DisplayID    string `json:"displayId"`
+	LatestCommit string `json:"latestCommit"`
+}
@@ -0,0 +1,112 @@
+/*
+ Copyright 2021 The CI/CD Operator Authors
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package bitbucket
+
+import (
+	"encoding/json"
+
+	"github.com/tmax-cloud/cicd-operator/pkg/git"
+)
+
+func (c *Client) parsePullRequestWebhook(eventKey string, jsonString []byte) (*git.Webhook, error) {
+	var data PullRequestWebhook
+	if err := json.Unmarshal(jsonString, &data); err != nil {
+		return nil, err
+	}
+
+	var action git.PullRequestAction
+	switch eventKey {
+	case EventKeyPullRequestOpened:
+		action = git.PullRequestActionOpen
+	case EventKeyPullRequestReopened:
+		action = git.PullRequestActionReOpen
+	case EventKeyPullRequestFromRefUpdate:
+		action = git.PullRequestActionSynchronize
+	case EventKeyPullRequestMerged, EventKeyPullRequestDeclined:
+		action = git.PullRequestActionClose
+	}
+
+	pullRequest := convertPullRequestToShared(&data.PullRequest)
+	pullRequest.Action = action
+
+	sender := convertUser(data.Actor)
+	repo := convertRepository(&data.PullRequest.ToRef.Repository)
+	return &git.Webhook{EventType: git.EventTypePullRequest, Repo: repo, PullRequest: pullRequest, Sender: *sender, RequestBody: string(jsonString)}, nil
+}
+
+func (c *Client) parsePushWebhook(jsonString []byte) (*git.Webhook, error) {
+	var data PushWebhook
+	if err := json.Unmarshal(jsonString, &data); err != nil {
+		return nil, err
+	}
+
+	// Use the first non-deleting change
+	var change *RefChange
+	for i := range data.Changes {
+		if data.Changes[i].Type != "DELETE" {
+			change = &data.Changes[i]
+			break
+		}
+	}
+	if change == nil {
+		return nil, nil
+	}
+
+	ref := change.Ref.ID
+	if ref == "" {
+		ref = change.RefID
+	}
+
+	sender := convertUser(data.Actor)
+	repo := convertRepository(&data.Repository)
+	push := git.Push{Ref: ref, Sha: change.ToHash, Before: change.FromHash}
+
+	return &git.Webhook{EventType: git.EventTypePush, Repo: repo, Sender: *sender, Push: &push, RequestBody: string(jsonString)}, nil
+}
+
+func (c *Client) parseCommentWebhook(jsonString []byte) (*git.Webhook, error) {
+	var data CommentWebhook
+	if err := json.Unmarshal(jsonString, &data); err != nil {
+		return nil, err
+	}
+
+	sender := convertUser(data.Actor)
+	author := convertUser(data.Comment.Author)
+	repo := convertRepository(&data.PullRequest.ToRef.Repository)
+
+	return &git.Webhook{EventType: git.EventTypeIssueComment, Repo: repo,
+		Sender:      *sender,
+		RequestBody: string(jsonString),
+		IssueComment: &git.IssueComment{
+			Comment: git.Comment{
+				Body:      data.Comment.Text,
+				CreatedAt: convertTime(data.Comment.CreatedDate),
+			},
+			Author: *author,
+			Issue: git.Issue{
+				PullRequest: convertPullRequestToShared(&data.PullRequest),
+			},
+		}}, nil
+}
+
+func convertRepository(repo *Repository) git.Repository {
+	var repoURL string
+	if len(repo.Links.Self) > 0 {
+		repoURL = repo.Links.Self[0].Href
+	}
+	return git.Repository{Name: repo.Project.Key + "/" + repo.Slug, URL: repoURL}
+}
@@ -41,8 +41,11 @@ type Repo struct {
 	PullRequests       map[int]*git.PullRequest
 	PullRequestDiffs   map[int]*git.Diff
 	PullRequestCommits map[int][]git.Commit
+	CommitDiffs        map[string]*git.Diff
+	FileContents       map[string][]byte
 	Commits            map[string][]git.Commit
 	CommitStatuses     map[string][]git.CommitStatus
+	CheckRuns          map[string][]git.CheckRun
 	Comments           map[int][]git.IssueComment
 }
 
@@ -155,6 +158,47 @@ func (c *Client) SetCommitStatus(sha string, status git.CommitStatus) error {
 	return nil
 }
 
+// ListCheckRuns lists check runs of the specific commit
+func (c *Client) ListCheckRuns(sha string) ([]git.CheckRun, error) {
+	if Repos == nil {
+		return nil, fmt.Errorf("repos not initialized")
+	}
+	repo, repoExist := Repos[c.IntegrationConfig.Spec.Git.Repository]
+	if !repoExist {
+		return nil, fmt.Errorf("404 no such repository")
+	}
+
+	if repo.CheckRuns == nil {
+		return nil, fmt.Errorf("check runs not initialized")
+	}
+
+	return repo.CheckRuns[sha], nil
+}
+
+// SetCheckRun creates or updates a check run for the specific commit
+func (c *Client) SetCheckRun(sha string, checkRun git.CheckRun) error {
+	if Repos == nil {
+		return fmt.Errorf("repos not initialized")
+	}
+	repo, repoExist := Repos[c.IntegrationConfig.Spec.Git.Repository]
+	if !repoExist {
+		return fmt.Errorf("404 no such repository")
+	}
+
+	if repo.CheckRuns == nil {
+		return fmt.Errorf("check runs not initialized")
+	}
+
+	for i, r := range repo.CheckRuns[sha] {
+		if r.Name == checkRun.Name {
+			repo.CheckRuns[sha][i] = checkRun
+			return nil
+		}
+	}
+	repo.CheckRuns[sha] = append(repo.CheckRuns[sha], checkRun)
+	return nil
+}
+
 // GetUserInfo gets a user's information
 func (c *Client) GetUserInfo(userName string) (*git.User, error) {
 	if Users == nil {
@@ -343,6 +387,50 @@ func (c *Client) ListPullRequestCommits(id int) ([]git.Commit, error) {
 	return commits, nil
 }
 
+// GetCommitDiff gets diff between the base and head commits. The diffs are stored with the key '<base>...<head>'
+func (c *Client) GetCommitDiff(base, head string) (*git.Diff, error) {
+	if Repos == nil {
+		return nil, fmt.Errorf("repos not initialized")
+	}
+	repo, repoExist := Repos[c.IntegrationConfig.Spec.Git.Repository]
+	if !repoExist {
+		return nil, fmt.Errorf("404 no such repository")
+	}
+
+	if repo.CommitDiffs == nil {
+		return nil, fmt.Errorf("commit diffs not initialized")
+	}
+
+	diff, exist := repo.CommitDiffs[base+"..."+head]
+	if !exist {
+		return nil, fmt.Errorf("404 no such commits")
+	}
+
+	return diff, nil
+}
+
+// GetFileContent gets the content of the file at the ref. The contents are stored with the key '<ref>:<path>'
+func (c *Client) GetFileContent(path, ref string) ([]byte, error) {
+	if Repos == nil {
+		return nil, fmt.Errorf("repos not initialized")
+	}
+	repo, repoExist := Repos[c.IntegrationConfig.Spec.Git.Repository]
+	if !repoExist {
+		return nil, fmt.Errorf("404 no such repository")
+	}
+
+	if repo.FileContents == nil {
+		return nil, fmt.Errorf("file contents not initialized")
+	}
+
+	content, exist := repo.FileContents[ref+":"+path]
+	if !exist {
+		return nil, fmt.Errorf("404 no such file")
+	}
+
+	return content, nil
+}
+
 // ListLabels lists labels of pr id
 func (c *Client) ListLabels(id int) ([]git.IssueLabel, error) {
 	if Repos == nil {
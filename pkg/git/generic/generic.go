@@ -0,0 +1,202 @@
+/*
+ Copyright 2021 The CI/CD Operator Authors
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package generic
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	cicdv1 "github.com/tmax-cloud/cicd-operator/api/v1"
+	"github.com/tmax-cloud/cicd-operator/pkg/git"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	basicAuthUserName = "tmax-cicd-bot"
+)
+
+// Client is a git client for generic git servers, which do not provide any webhook/API.
+// Only the refs of the repository can be read, so events are generated by polling the refs (see pkg/poller)
+type Client struct {
+	IntegrationConfig *cicdv1.IntegrationConfig
+	K8sClient         client.Client
+
+	// Context is a context of the API calls, which carries the caller's trace
+	Context context.Context
+
+	header map[string]string
+}
+
+// Init initiates the Client
+func (c *Client) Init() error {
+	if c.IntegrationConfig.Spec.Git.APIUrl == "" {
+		return fmt.Errorf("apiUrl should be set for the generic git type")
+	}
+	// Refs are listed via the http(s) protocol, not ssh
+	if u, err := url.Parse(c.IntegrationConfig.Spec.Git.APIUrl); err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return fmt.Errorf("apiUrl should be an http or https url for the generic git type")
+	}
+
+	token, err := c.IntegrationConfig.GetToken(c.K8sClient)
+	if err != nil {
+		return err
+	}
+
+	c.header = map[string]string{}
+	if token != "" {
+		c.header["Authorization"] = "Basic " + base64.StdEncoding.EncodeToString([]byte(basicAuthUserName+":"+token))
+	}
+	return nil
+}
+
+// RepositoryURL returns the url of the repository
+func (c *Client) RepositoryURL() string {
+	return strings.TrimSuffix(c.IntegrationConfig.Spec.Git.APIUrl, "/") + "/" + strings.TrimPrefix(c.IntegrationConfig.Spec.Git.Repository, "/")
+}
+
+// ListRemoteRefs lists branches and tags of the remote repository, as git ls-remote does.
+// It returns a map of ref name (e.g., refs/heads/master) to the commit SHA
+func (c *Client) ListRemoteRefs() (map[string]string, error) {
+	data, header, err := git.RequestHTTP(c.Context, http.MethodGet, c.RepositoryURL()+"/info/refs?service=git-upload-pack", c.header, nil, c.IntegrationConfig.GetTLSConfig())
+	if err != nil {
+		return nil, err
+	}
+
+	// Dumb http servers just respond with the content of info/refs file
+	if header.Get("Content-Type") != "application/x-git-upload-pack-advertisement" {
+		return parseInfoRefs(data), nil
+	}
+	return parseRefAdvertisement(data)
+}
+
+// ParseWebhook returns nil, as generic git servers do not send webhooks
+func (c *Client) ParseWebhook(_ http.Header, _ []byte) (*git.Webhook, error) {
+	return nil, nil
+}
+
+// ListWebhook returns nothing, as generic git servers do not support webhooks
+func (c *Client) ListWebhook() ([]git.WebhookEntry, error) {
+	return nil, nil
+}
+
+// RegisterWebhook is not supported for generic git servers
+func (c *Client) RegisterWebhook(_ string) error {
+	return fmt.Errorf("webhook is not supported for the generic git type")
+}
+
+// DeleteWebhook is not supported for generic git servers
+func (c *Client) DeleteWebhook(_ int) error {
+	return fmt.Errorf("webhook is not supported for the generic git type")
+}
+
+// ListCommitStatuses returns nothing, as generic git servers do not have commit statuses
+func (c *Client) ListCommitStatuses(_ string) ([]git.CommitStatus, error) {
+	return nil, nil
+}
+
+// SetCommitStatus does nothing, as generic git servers do not have commit statuses
+func (c *Client) SetCommitStatus(_ string, _ git.CommitStatus) error {
+	return nil
+}
+
+// GetUserInfo is not supported for generic git servers
+func (c *Client) GetUserInfo(_ string) (*git.User, error) {
+	return nil, fmt.Errorf("user info is not supported for the generic git type")
+}
+
+// CanUserWriteToRepo always returns false, as there is no way to check the permission
+func (c *Client) CanUserWriteToRepo(_ git.User) (bool, error) {
+	return false, nil
+}
+
+// RegisterComment is not supported for generic git servers
+func (c *Client) RegisterComment(_ git.IssueType, _ int, _, _ string) error {
+	return fmt.Errorf("comment is not supported for the generic git type")
+}
+
+// ListComments returns nothing, as generic git servers do not have comments
+func (c *Client) ListComments(_ int) ([]git.IssueComment, error) {
+	return nil, nil
+}
+
+// ListPullRequests returns nothing, as generic git servers do not have pull requests
+func (c *Client) ListPullRequests(_ bool) ([]git.PullRequest, error) {
+	return nil, nil
+}
+
+// GetPullRequest is not supported for generic git servers
+func (c *Client) GetPullRequest(_ int) (*git.PullRequest, error) {
+	return nil, fmt.Errorf("pull request is not supported for the generic git type")
+}
+
+// MergePullRequest is not supported for generic git servers
+func (c *Client) MergePullRequest(_ int, _ string, _ git.MergeMethod, _ string) error {
+	return fmt.Errorf("pull request is not supported for the generic git type")
+}
+
+// GetPullRequestDiff is not supported for generic git servers
+func (c *Client) GetPullRequestDiff(_ int) (*git.Diff, error) {
+	return nil, fmt.Errorf("pull request is not supported for the generic git type")
+}
+
+// ListPullRequestCommits is not supported for generic git servers
+func (c *Client) ListPullRequestCommits(_ int) ([]git.Commit, error) {
+	return nil, fmt.Errorf("pull request is not supported for the generic git type")
+}
+
+// GetCommitDiff is not supported for generic git servers
+func (c *Client) GetCommitDiff(_, _ string) (*git.Diff, error) {
+	return nil, fmt.Errorf("comparing commits is not supported for the generic git type")
+}
+
+// GetFileContent is not supported for generic git servers
+func (c *Client) GetFileContent(_, _ string) ([]byte, error) {
+	return nil, fmt.Errorf("getting file content is not supported for the generic git type")
+}
+
+// SetLabel is not supported for generic git servers
+func (c *Client) SetLabel(_ git.IssueType, _ int, _ string) error {
+	return fmt.Errorf("label is not supported for the generic git type")
+}
+
+// ListLabels returns nothing, as generic git servers do not have labels
+func (c *Client) ListLabels(_ int) ([]git.IssueLabel, error) {
+	return nil, nil
+}
+
+// DeleteLabel is not supported for generic git servers
+func (c *Client) DeleteLabel(_ git.IssueType, _ int, _ string) error {
+	return fmt.Errorf("label is not supported for the generic git type")
+}
+
+// GetBranch gets branch info from the remote refs
+func (c *Client) GetBranch(branch string) (*git.Branch, error) {
+	refs, err := c.ListRemoteRefs()
+	if err != nil {
+		return nil, err
+	}
+
+	sha, exist := refs["refs/heads/"+branch]
+	if !exist {
+		return nil, fmt.Errorf("branch %s not found", branch)
+	}
+	return &git.Branch{Name: branch, CommitID: sha}, nil
+}
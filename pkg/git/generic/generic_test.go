@@ -0,0 +1,276 @@
+/*
+ Copyright 2021 The CI/CD Operator Authors
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package generic
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/require"
+	cicdv1 "github.com/tmax-cloud/cicd-operator/api/v1"
+	"github.com/tmax-cloud/cicd-operator/pkg/git"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	testMasterSha  = "a2c8b1e7e4a1d0e6f4c0a9c6c9d3f0e1b2c3d4e5"
+	testDevSha     = "b3d9c2f8f5b2e1f7a5d1b0d7d0e4a1f2c3d4e5f6"
+	testTagObjSha  = "c4e0d3a9a6c3f2a8b6e2c1e8e1f5b2a3d4e5f6a7"
+	testTagCommit  = "d5f1e4b0b7d4a3b9c7f3d2f9f2a6c3b4e5f6a7b8"
+	testInfoRefs   = testMasterSha + "\trefs/heads/master\n" + testDevSha + "\trefs/heads/dev\n" + testTagObjSha + "\trefs/tags/v0.1.0\n" + testTagCommit + "\trefs/tags/v0.1.0^{}\n" + testDevSha + "\trefs/pull/1/head\n"
+	testBasicAuth  = "Basic dG1heC1jaWNkLWJvdDp0ZXN0LXRva2Vu"
+	testRepository = "mirrors/test-repo.git"
+)
+
+func pktLine(s string) string {
+	return fmt.Sprintf("%04x%s", len(s)+4, s)
+}
+
+func testAdvertisement() string {
+	return pktLine("# service=git-upload-pack\n") + "0000" +
+		pktLine(testMasterSha+" HEAD\x00multi_ack side-band-64k ofs-delta symref=HEAD:refs/heads/master\n") +
+		pktLine(testMasterSha+" refs/heads/master\n") +
+		pktLine(testDevSha+" refs/heads/dev\n") +
+		pktLine(testDevSha+" refs/merge-requests/1/head\n") +
+		pktLine(testTagObjSha+" refs/tags/v0.1.0\n") +
+		pktLine(testTagCommit+" refs/tags/v0.1.0^{}\n") +
+		"0000"
+}
+
+func testEnv(smart bool) (*Client, *httptest.Server) {
+	r := mux.NewRouter()
+	r.HandleFunc("/"+testRepository+"/info/refs", func(w http.ResponseWriter, req *http.Request) {
+		if req.Header.Get("Authorization") != testBasicAuth {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if smart {
+			w.Header().Set("Content-Type", "application/x-git-upload-pack-advertisement")
+			_, _ = w.Write([]byte(testAdvertisement()))
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain")
+		_, _ = w.Write([]byte(testInfoRefs))
+	})
+	srv := httptest.NewServer(r)
+
+	c := &Client{
+		IntegrationConfig: &cicdv1.IntegrationConfig{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-ic", Namespace: "default"},
+			Spec: cicdv1.IntegrationConfigSpec{
+				Git: cicdv1.GitConfig{
+					Type:       cicdv1.GitTypeGeneric,
+					Repository: testRepository,
+					APIUrl:     srv.URL + "/",
+					Token:      &cicdv1.GitToken{Value: "test-token"},
+				},
+			},
+		},
+	}
+	return c, srv
+}
+
+func TestClient_Init(t *testing.T) {
+	tc := map[string]struct {
+		apiURL string
+		token  *cicdv1.GitToken
+
+		errorOccurs    bool
+		errorMessage   string
+		expectedHeader map[string]string
+	}{
+		"normal": {
+			apiURL:         "https://git.example.com",
+			token:          &cicdv1.GitToken{Value: "test-token"},
+			expectedHeader: map[string]string{"Authorization": testBasicAuth},
+		},
+		"noToken": {
+			apiURL:         "https://git.example.com",
+			expectedHeader: map[string]string{},
+		},
+		"noAPIUrl": {
+			errorOccurs:  true,
+			errorMessage: "apiUrl should be set for the generic git type",
+		},
+		"sshAPIUrl": {
+			apiURL:       "ssh://git@git.example.com",
+			errorOccurs:  true,
+			errorMessage: "apiUrl should be an http or https url for the generic git type",
+		},
+		"scpLikeAPIUrl": {
+			apiURL:       "git@git.example.com:mirrors",
+			errorOccurs:  true,
+			errorMessage: "apiUrl should be an http or https url for the generic git type",
+		},
+	}
+
+	for name, c := range tc {
+		t.Run(name, func(t *testing.T) {
+			cli := &Client{IntegrationConfig: &cicdv1.IntegrationConfig{
+				Spec: cicdv1.IntegrationConfigSpec{Git: cicdv1.GitConfig{Type: cicdv1.GitTypeGeneric, APIUrl: c.apiURL, Token: c.token}},
+			}}
+			err := cli.Init()
+			if c.errorOccurs {
+				require.Error(t, err)
+				require.Equal(t, c.errorMessage, err.Error())
+			} else {
+				require.NoError(t, err)
+				require.Equal(t, c.expectedHeader, cli.header)
+			}
+		})
+	}
+}
+
+func TestClient_ListRemoteRefs(t *testing.T) {
+	expectedRefs := map[string]string{
+		"refs/heads/master": testMasterSha,
+		"refs/heads/dev":    testDevSha,
+		"refs/tags/v0.1.0":  testTagCommit,
+	}
+
+	tc := map[string]struct {
+		smart bool
+		token *cicdv1.GitToken
+
+		errorOccurs  bool
+		expectedRefs map[string]string
+	}{
+		"smart": {
+			smart:        true,
+			expectedRefs: expectedRefs,
+		},
+		"dumb": {
+			smart:        false,
+			expectedRefs: expectedRefs,
+		},
+		"unauthorized": {
+			smart:       true,
+			token:       &cicdv1.GitToken{Value: "wrong-token"},
+			errorOccurs: true,
+		},
+	}
+
+	for name, c := range tc {
+		t.Run(name, func(t *testing.T) {
+			cli, srv := testEnv(c.smart)
+			defer srv.Close()
+			if c.token != nil {
+				cli.IntegrationConfig.Spec.Git.Token = c.token
+			}
+			require.NoError(t, cli.Init())
+
+			refs, err := cli.ListRemoteRefs()
+			if c.errorOccurs {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+				require.Equal(t, c.expectedRefs, refs)
+			}
+		})
+	}
+}
+
+func Test_parseRefAdvertisement(t *testing.T) {
+	tc := map[string]struct {
+		data string
+
+		errorOccurs  bool
+		errorMessage string
+		expectedRefs map[string]string
+	}{
+		"emptyRepo": {
+			data:         pktLine("# service=git-upload-pack\n") + "0000" + pktLine(git.FakeSha+" capabilities^{}\x00multi_ack\n") + "0000",
+			expectedRefs: map[string]string{},
+		},
+		"malformedLength": {
+			data:         "zzzz",
+			errorOccurs:  true,
+			errorMessage: "malformed pkt-line length \"zzzz\"",
+		},
+		"truncated": {
+			data:         "00ff" + testMasterSha,
+			errorOccurs:  true,
+			errorMessage: "malformed pkt-line length 255",
+		},
+		"short": {
+			data:         "00",
+			errorOccurs:  true,
+			errorMessage: "malformed pkt-line",
+		},
+	}
+
+	for name, c := range tc {
+		t.Run(name, func(t *testing.T) {
+			refs, err := parseRefAdvertisement([]byte(c.data))
+			if c.errorOccurs {
+				require.Error(t, err)
+				require.Equal(t, c.errorMessage, err.Error())
+			} else {
+				require.NoError(t, err)
+				require.Equal(t, c.expectedRefs, refs)
+			}
+		})
+	}
+}
+
+func TestClient_GetBranch(t *testing.T) {
+	tc := map[string]struct {
+		branch string
+
+		errorOccurs    bool
+		errorMessage   string
+		expectedBranch *git.Branch
+	}{
+		"normal": {
+			branch:         "dev",
+			expectedBranch: &git.Branch{Name: "dev", CommitID: testDevSha},
+		},
+		"notFound": {
+			branch:       "feat",
+			errorOccurs:  true,
+			errorMessage: "branch feat not found",
+		},
+	}
+
+	for name, c := range tc {
+		t.Run(name, func(t *testing.T) {
+			cli, srv := testEnv(true)
+			defer srv.Close()
+			require.NoError(t, cli.Init())
+
+			branch, err := cli.GetBranch(c.branch)
+			if c.errorOccurs {
+				require.Error(t, err)
+				require.Equal(t, c.errorMessage, err.Error())
+			} else {
+				require.NoError(t, err)
+				require.Equal(t, c.expectedBranch, branch)
+			}
+		})
+	}
+}
+
+func TestClient_RepositoryURL(t *testing.T) {
+	cli, srv := testEnv(true)
+	defer srv.Close()
+	require.Equal(t, srv.URL+"/"+testRepository, cli.RepositoryURL())
+	require.True(t, strings.HasSuffix(cli.RepositoryURL(), ".git"))
+}
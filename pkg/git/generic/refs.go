@@ -0,0 +1,101 @@
+/*
+ Copyright 2021 The CI/CD Operator Authors
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package generic
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	peeledSuffix = "^{}"
+)
+
+// parseRefAdvertisement parses a ref advertisement of the smart http protocol, which is a list of pkt-lines
+// (ref: https://git-scm.com/docs/http-protocol#_smart_clients)
+func parseRefAdvertisement(data []byte) (map[string]string, error) {
+	var lines []string
+	for len(data) > 0 {
+		if len(data) < 4 {
+			return nil, fmt.Errorf("malformed pkt-line")
+		}
+		length, err := strconv.ParseUint(string(data[:4]), 16, 16)
+		if err != nil {
+			return nil, fmt.Errorf("malformed pkt-line length %q", string(data[:4]))
+		}
+		// Flush packet
+		if length == 0 {
+			data = data[4:]
+			continue
+		}
+		if length < 4 || int(length) > len(data) {
+			return nil, fmt.Errorf("malformed pkt-line length %d", length)
+		}
+		line := string(data[4:length])
+		data = data[length:]
+
+		// Skip service announcement
+		if strings.HasPrefix(line, "# service=") {
+			continue
+		}
+		// Strip capabilities of the first ref
+		if i := strings.IndexByte(line, 0); i >= 0 {
+			line = line[:i]
+		}
+		lines = append(lines, strings.TrimSuffix(line, "\n"))
+	}
+
+	return parseRefLines(lines, " "), nil
+}
+
+// parseInfoRefs parses info/refs file served by dumb http servers
+func parseInfoRefs(data []byte) map[string]string {
+	var lines []string
+	for _, l := range bytes.Split(data, []byte("\n")) {
+		lines = append(lines, string(l))
+	}
+	return parseRefLines(lines, "\t")
+}
+
+// parseRefLines parses lines of '<sha><sep><ref>' into a map of branches/tags.
+// Annotated tags are resolved to the commit they point to
+func parseRefLines(lines []string, sep string) map[string]string {
+	refs := map[string]string{}
+	peeled := map[string]string{}
+	for _, l := range lines {
+		tokens := strings.SplitN(l, sep, 2)
+		if len(tokens) != 2 {
+			continue
+		}
+		sha, ref := tokens[0], tokens[1]
+		if !strings.HasPrefix(ref, "refs/heads/") && !strings.HasPrefix(ref, "refs/tags/") {
+			continue
+		}
+		if strings.HasSuffix(ref, peeledSuffix) {
+			peeled[strings.TrimSuffix(ref, peeledSuffix)] = sha
+			continue
+		}
+		refs[ref] = sha
+	}
+
+	for ref, sha := range peeled {
+		refs[ref] = sha
+	}
+	return refs
+}
@@ -54,6 +54,14 @@ type Client interface {
 	GetPullRequestDiff(id int) (*Diff, error)
 	ListPullRequestCommits(id int) ([]Commit, error)
 
+	// Commit
+
+	GetCommitDiff(base, head string) (*Diff, error)
+
+	// Contents
+
+	GetFileContent(path, ref string) ([]byte, error)
+
 	// Issue Labels
 
 	SetLabel(issueType IssueType, id int, label string) error
@@ -65,6 +73,12 @@ type Client interface {
 	GetBranch(branch string) (*Branch, error)
 }
 
+// CheckRunClient is a git client which supports check runs, in addition to commit statuses
+type CheckRunClient interface {
+	ListCheckRuns(sha string) ([]CheckRun, error)
+	SetCheckRun(sha string, checkRun CheckRun) error
+}
+
 // IssueType is a type of the issue
 type IssueType string
 
@@ -93,6 +107,30 @@ const (
 	ErrSha = "0000000000000000000000000000000000000001"
 )
 
+// CheckRunStatus is a status of a check run
+type CheckRunStatus string
+
+// CheckRunStatuses
+const (
+	CheckRunStatusQueued     = CheckRunStatus("queued")
+	CheckRunStatusInProgress = CheckRunStatus("in_progress")
+	CheckRunStatusCompleted  = CheckRunStatus("completed")
+)
+
+// CheckRunConclusion is a conclusion of a completed check run
+type CheckRunConclusion string
+
+// CheckRunConclusions
+const (
+	CheckRunConclusionSuccess        = CheckRunConclusion("success")
+	CheckRunConclusionFailure        = CheckRunConclusion("failure")
+	CheckRunConclusionNeutral        = CheckRunConclusion("neutral")
+	CheckRunConclusionCancelled      = CheckRunConclusion("cancelled")
+	CheckRunConclusionSkipped        = CheckRunConclusion("skipped")
+	CheckRunConclusionTimedOut       = CheckRunConclusion("timed_out")
+	CheckRunConclusionActionRequired = CheckRunConclusion("action_required")
+)
+
 // MergeMethod is method kind
 type MergeMethod string
 
@@ -16,7 +16,11 @@
 
 package git
 
-import metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+import (
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
 
 // EventType is a type of webhook event
 type EventType string
@@ -38,6 +42,8 @@ const (
 	EventTypePullRequestReview        = EventType("pull_request_review")
 	EventTypePullRequestReviewComment = EventType("pull_request_review_comment")
 	EventTypeCommitComment            = EventType("commit_comment")
+	EventTypeRelease                  = EventType("release")
+	EventTypeTagCreate                = EventType("tag_create")
 )
 
 // Pull Request states
@@ -48,12 +54,16 @@ const (
 
 // Pull Request actions
 const (
-	PullRequestActionReOpen      = PullRequestAction("reopened")
-	PullRequestActionOpen        = PullRequestAction("opened")
-	PullRequestActionClose       = PullRequestAction("closed")
-	PullRequestActionSynchronize = PullRequestAction("synchronize")
-	PullRequestActionLabeled     = PullRequestAction("labeled")
-	PullRequestActionUnlabeled   = PullRequestAction("unlabeled")
+	PullRequestActionReOpen           = PullRequestAction("reopened")
+	PullRequestActionOpen             = PullRequestAction("opened")
+	PullRequestActionClose            = PullRequestAction("closed")
+	PullRequestActionMerged           = PullRequestAction("merged")
+	PullRequestActionSynchronize      = PullRequestAction("synchronize")
+	PullRequestActionLabeled          = PullRequestAction("labeled")
+	PullRequestActionUnlabeled        = PullRequestAction("unlabeled")
+	PullRequestActionEdited           = PullRequestAction("edited")
+	PullRequestActionReadyForReview   = PullRequestAction("ready_for_review")
+	PullRequestActionConvertedToDraft = PullRequestAction("converted_to_draft")
 )
 
 // Pull Request review state
@@ -72,13 +82,29 @@ type Webhook struct {
 	Push         *Push
 	PullRequest  *PullRequest
 	IssueComment *IssueComment
+	Release      *Release
 	RequestBody  string
+
+	// DeliveryID is an ID of the webhook delivery, given by the git server. It is the same for the redeliveries
+	DeliveryID string
 }
 
-// Push is a common structure for push events
+// Push is a common structure for push and tag create events
 type Push struct {
 	Ref string
 	Sha string
+
+	// Before is a sha of the ref before the push. It is empty or zero-filled if the ref is newly created
+	Before string
+}
+
+// EventType returns the event type of the push, i.e., tag_create if it creates a new tag, and push otherwise
+func (p *Push) EventType() EventType {
+	created := p.Before == "" || strings.Trim(p.Before, "0") == ""
+	if strings.HasPrefix(p.Ref, "refs/tags/") && created {
+		return EventTypeTagCreate
+	}
+	return EventTypePush
 }
 
 // PullRequest is a common structure for pull request events
@@ -93,11 +119,22 @@ type PullRequest struct {
 	Head      Head
 	Labels    []IssueLabel
 	Mergeable bool
+	Draft     bool
+
+	// MergeSha is a sha of the merge commit. It is set only if the pull request is merged
+	MergeSha string
 
 	// LabelChanged
 	LabelChanged []IssueLabel
 }
 
+// Release is a common structure for release events
+type Release struct {
+	Tag  string
+	Name string
+	Sha  string
+}
+
 // Diff is a diff between commits or of a pull-request
 type Diff struct {
 	Changes []Change
@@ -185,6 +222,34 @@ type CommitStatus struct {
 	TargetURL   string
 }
 
+// CheckRun is a check run body (e.g., GitHub Checks API)
+type CheckRun struct {
+	Name        string
+	Status      CheckRunStatus
+	Conclusion  CheckRunConclusion
+	DetailsURL  string
+	Title       string
+	Summary     string
+	StartedAt   *metav1.Time
+	CompletedAt *metav1.Time
+}
+
+// ToCommitStatus converts the check run to a commit status, so it can be evaluated in the same way as commit statuses
+func (c *CheckRun) ToCommitStatus() CommitStatus {
+	state := CommitStatusStatePending
+	if c.Status == CheckRunStatusCompleted {
+		switch c.Conclusion {
+		case CheckRunConclusionSuccess, CheckRunConclusionNeutral, CheckRunConclusionSkipped:
+			state = CommitStatusStateSuccess
+		case CheckRunConclusionFailure, CheckRunConclusionActionRequired:
+			state = CommitStatusStateFailure
+		default:
+			state = CommitStatusStateError
+		}
+	}
+	return CommitStatus{Context: c.Name, State: state, Description: c.Title, TargetURL: c.DetailsURL}
+}
+
 // Branch is a branch info
 type Branch struct {
 	Name     string
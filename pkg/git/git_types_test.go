@@ -0,0 +1,67 @@
+/*
+ Copyright 2021 The CI/CD Operator Authors
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package git
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckRun_ToCommitStatus(t *testing.T) {
+	tc := map[string]struct {
+		status     CheckRunStatus
+		conclusion CheckRunConclusion
+
+		expectedState CommitStatusState
+	}{
+		"queued":         {status: CheckRunStatusQueued, expectedState: CommitStatusStatePending},
+		"inProgress":     {status: CheckRunStatusInProgress, expectedState: CommitStatusStatePending},
+		"success":        {status: CheckRunStatusCompleted, conclusion: CheckRunConclusionSuccess, expectedState: CommitStatusStateSuccess},
+		"skipped":        {status: CheckRunStatusCompleted, conclusion: CheckRunConclusionSkipped, expectedState: CommitStatusStateSuccess},
+		"failure":        {status: CheckRunStatusCompleted, conclusion: CheckRunConclusionFailure, expectedState: CommitStatusStateFailure},
+		"actionRequired": {status: CheckRunStatusCompleted, conclusion: CheckRunConclusionActionRequired, expectedState: CommitStatusStateFailure},
+		"timedOut":       {status: CheckRunStatusCompleted, conclusion: CheckRunConclusionTimedOut, expectedState: CommitStatusStateError},
+	}
+
+	for name, c := range tc {
+		t.Run(name, func(t *testing.T) {
+			checkRun := &CheckRun{Name: "test", Status: c.status, Conclusion: c.conclusion, Title: "desc", DetailsURL: "https://report"}
+			require.Equal(t, CommitStatus{Context: "test", State: c.expectedState, Description: "desc", TargetURL: "https://report"}, checkRun.ToCommitStatus())
+		})
+	}
+}
+
+func TestPush_EventType(t *testing.T) {
+	tc := map[string]struct {
+		push Push
+
+		expectedType EventType
+	}{
+		"branch":       {push: Push{Ref: "refs/heads/master", Before: "1234"}, expectedType: EventTypePush},
+		"newBranch":    {push: Push{Ref: "refs/heads/master", Before: "0000000000"}, expectedType: EventTypePush},
+		"tagCreated":   {push: Push{Ref: "refs/tags/v1.0.0", Before: "0000000000"}, expectedType: EventTypeTagCreate},
+		"tagNoBefore":  {push: Push{Ref: "refs/tags/v1.0.0"}, expectedType: EventTypeTagCreate},
+		"tagForceMove": {push: Push{Ref: "refs/tags/v1.0.0", Before: "1234"}, expectedType: EventTypePush},
+	}
+
+	for name, c := range tc {
+		t.Run(name, func(t *testing.T) {
+			require.Equal(t, c.expectedType, c.push.EventType())
+		})
+	}
+}
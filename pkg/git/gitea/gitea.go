@@ -17,13 +17,14 @@
 package gitea
 
 import (
-	"crypto/hmac"
+	"context"
 	"crypto/sha1"
 	"encoding/json"
 	"fmt"
 	cicdv1 "github.com/tmax-cloud/cicd-operator/api/v1"
 	"github.com/tmax-cloud/cicd-operator/pkg/git"
 	"net/http"
+	"net/url"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"strconv"
 	"strings"
@@ -34,6 +35,9 @@ type Client struct {
 	IntegrationConfig *cicdv1.IntegrationConfig
 	K8sClient         client.Client
 
+	// Context is a context of the API calls, which carries the caller's trace
+	Context context.Context
+
 	header map[string]string
 }
 
@@ -54,8 +58,20 @@ func (c *Client) Init() error {
 
 // ParseWebhook parses a webhook body for gitea
 func (c *Client) ParseWebhook(header http.Header, jsonString []byte) (*git.Webhook, error) {
-	var signature = strings.Replace(header.Get("x-hub-signature"), "sha1=", "", 1)
-	if err := Validate(c.IntegrationConfig.Status.Secrets, signature, jsonString); err != nil {
+	wh, err := c.parseWebhook(header, jsonString)
+	if wh != nil {
+		wh.DeliveryID = header.Get("X-Gitea-Delivery")
+	}
+	return wh, err
+}
+
+func (c *Client) parseWebhook(header http.Header, jsonString []byte) (*git.Webhook, error) {
+	// Gitea signs the payload with HMAC-SHA256 in X-Gitea-Signature as well, without the algorithm prefix
+	if header.Get(git.HeaderSignature256) == "" && header.Get("X-Gitea-Signature") != "" {
+		header = header.Clone()
+		header.Set(git.HeaderSignature256, header.Get("X-Gitea-Signature"))
+	}
+	if err := git.ValidateSignature(c.IntegrationConfig.GetWebhookSecrets(), header, jsonString); err != nil {
 		return nil, err
 	}
 
@@ -65,6 +81,8 @@ func (c *Client) ParseWebhook(header http.Header, jsonString []byte) (*git.Webho
 		return c.parsePullRequestWebhook(jsonString)
 	case git.EventTypePush:
 		return c.parsePushWebhook(jsonString)
+	case git.EventTypeRelease:
+		return c.parseReleaseWebhook(jsonString)
 	case git.EventTypeIssueComment:
 		return c.parseIssueCommentWebhook(jsonString)
 	case git.EventTypePullRequestReview:
@@ -84,7 +102,7 @@ func (c *Client) ListWebhook() ([]git.WebhookEntry, error) {
 	var entries []WebhookEntry
 	tlsConfig := c.IntegrationConfig.GetTLSConfig()
 
-	err := git.GetPaginatedRequest(apiURL, tlsConfig, c.header, func() interface{} {
+	err := git.GetPaginatedRequest(c.Context, apiURL, tlsConfig, c.header, func() interface{} {
 		return &[]WebhookEntry{}
 	}, func(i interface{}) {
 		entries = append(entries, *i.(*[]WebhookEntry)...)
@@ -111,7 +129,7 @@ func (c *Client) RegisterWebhook(url string) error {
 	registrationConfig.ContentType = "json"
 	registrationConfig.Secret = c.IntegrationConfig.Status.Secrets
 	registrationBody.Config = registrationConfig
-	registrationBody.Events = []string{"push", "pull_request", "issue_comment", "pull_request_review", "pull_request_review_comment", "commit_comment"}
+	registrationBody.Events = []string{"push", "pull_request", "issue_comment", "pull_request_review", "pull_request_review_comment", "commit_comment", "release"}
 	registrationBody.Active = true
 	registrationBody.Type = "gitea"
 
@@ -138,7 +156,7 @@ func (c *Client) ListCommitStatuses(ref string) ([]git.CommitStatus, error) {
 	var statuses []CommitStatusResponse
 	tlsConfig := c.IntegrationConfig.GetTLSConfig()
 
-	err := git.GetPaginatedRequest(apiURL, tlsConfig, c.header, func() interface{} {
+	err := git.GetPaginatedRequest(c.Context, apiURL, tlsConfig, c.header, func() interface{} {
 		return &[]CommitStatusResponse{}
 	}, func(i interface{}) {
 		statuses = append(statuses, *i.(*[]CommitStatusResponse)...)
@@ -319,7 +337,7 @@ func (c *Client) ListPullRequests(onlyOpen bool) ([]git.PullRequest, error) {
 	var prs []PullRequest
 	tlsConfig := c.IntegrationConfig.GetTLSConfig()
 
-	err := git.GetPaginatedRequest(apiURL, tlsConfig, c.header, func() interface{} {
+	err := git.GetPaginatedRequest(c.Context, apiURL, tlsConfig, c.header, func() interface{} {
 		return &[]PullRequest{}
 	}, func(i interface{}) {
 		prs = append(prs, *i.(*[]PullRequest)...)
@@ -410,6 +428,17 @@ func (c *Client) GetPullRequestDiff(id int) (*git.Diff, error) {
 	return &git.Diff{Changes: changes}, nil
 }
 
+// GetFileContent gets the content of the file at the ref
+func (c *Client) GetFileContent(path, ref string) ([]byte, error) {
+	apiURL := fmt.Sprintf("%s/api/v1/repos/%s/raw/%s?ref=%s", c.IntegrationConfig.Spec.Git.GetAPIUrl(), c.IntegrationConfig.Spec.Git.Repository, git.EscapeFilePath(path), url.QueryEscape(ref))
+	raw, _, err := c.requestHTTP(http.MethodGet, apiURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return raw, nil
+}
+
 // ListPullRequestCommits lists commits list of a pull request
 func (c *Client) ListPullRequestCommits(id int) ([]git.Commit, error) {
 	apiURL := fmt.Sprintf("%s//api/v1/repos/%s/pulls/%d/commits", c.IntegrationConfig.Spec.Git.GetAPIUrl(), c.IntegrationConfig.Spec.Git.Repository, id)
@@ -443,6 +472,35 @@ func (c *Client) ListPullRequestCommits(id int) ([]git.Commit, error) {
 	return commits, nil
 }
 
+// GetCommitDiff gets diff between the base and head commits
+// Gitea's compare api only returns the changed file names of each commit, so the line counts are not filled
+func (c *Client) GetCommitDiff(base, head string) (*git.Diff, error) {
+	apiURL := fmt.Sprintf("%s/api/v1/repos/%s/compare/%s...%s", c.IntegrationConfig.Spec.Git.GetAPIUrl(), c.IntegrationConfig.Spec.Git.Repository, base, head)
+	raw, _, err := c.requestHTTP(http.MethodGet, apiURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &CompareResponse{}
+	if err := json.Unmarshal(raw, resp); err != nil {
+		return nil, err
+	}
+
+	var changes []git.Change
+	visited := map[string]struct{}{}
+	for _, commit := range resp.Commits {
+		for _, f := range commit.Files {
+			if _, exist := visited[f.Filename]; exist {
+				continue
+			}
+			visited[f.Filename] = struct{}{}
+			changes = append(changes, git.Change{Filename: f.Filename, OldFilename: f.Filename})
+		}
+	}
+
+	return &git.Diff{Changes: changes}, nil
+}
+
 // SetLabel sets label to the issue id
 func (c *Client) SetLabel(_ git.IssueType, id int, label string) error {
 	apiURL := fmt.Sprintf("%s//api/v1/repos/%s/issues/%d/labels", c.IntegrationConfig.Spec.Git.GetAPIUrl(), c.IntegrationConfig.Spec.Git.Repository, id)
@@ -500,6 +558,23 @@ func (c *Client) GetBranch(branch string) (*git.Branch, error) {
 	return &git.Branch{Name: resp.Name, CommitID: resp.Commit.Sha}, nil
 }
 
+// getTagSha gets the sha of the commit which the tag points to
+func (c *Client) getTagSha(tag string) (string, error) {
+	apiURL := fmt.Sprintf("%s/api/v1/repos/%s/tags/%s", c.IntegrationConfig.Spec.Git.GetAPIUrl(), c.IntegrationConfig.Spec.Git.Repository, tag)
+
+	raw, _, err := c.requestHTTP(http.MethodGet, apiURL, nil)
+	if err != nil {
+		return "", err
+	}
+
+	resp := &TagResponse{}
+	if err := json.Unmarshal(raw, resp); err != nil {
+		return "", err
+	}
+
+	return resp.Commit.Sha, nil
+}
+
 func convertPullRequestToShared(pr *PullRequest) *git.PullRequest {
 	var labels []git.IssueLabel
 	for _, l := range pr.Labels {
@@ -519,37 +594,17 @@ func convertPullRequestToShared(pr *PullRequest) *git.PullRequest {
 		Head:      git.Head{Ref: pr.Head.Ref, Sha: pr.Head.Sha},
 		Labels:    labels,
 		Mergeable: pr.Mergeable,
+		Draft:     pr.Draft,
 	}
 }
 
 func (c *Client) requestHTTP(method, apiURL string, data interface{}) ([]byte, http.Header, error) {
 	tlsConfig := c.IntegrationConfig.GetTLSConfig()
 
-	return git.RequestHTTP(method, apiURL, c.header, data, tlsConfig)
-}
-
-// IsValidPayload validates the webhook payload
-func IsValidPayload(secret, headerHash string, payload []byte) bool {
-	hash := HashPayload(secret, payload)
-	return hmac.Equal(
-		[]byte(hash),
-		[]byte(headerHash),
-	)
+	return git.RequestHTTP(c.Context, method, apiURL, c.header, data, tlsConfig)
 }
 
-// HashPayload hashes the payload
+// HashPayload hashes the payload with HMAC-SHA1
 func HashPayload(secret string, payloadBody []byte) string {
-	hm := hmac.New(sha1.New, []byte(secret))
-	_, _ = hm.Write(payloadBody)
-	sum := hm.Sum(nil)
-
-	return fmt.Sprintf("%x", sum)
-}
-
-// Validate validates the webhook payload
-func Validate(secret, headerHash string, payload []byte) error {
-	if !IsValidPayload(secret, headerHash, payload) {
-		return fmt.Errorf("invalid request : X-Hub-Signature does not match secret")
-	}
-	return nil
+	return git.HashPayload(sha1.New, secret, payloadBody)
 }
@@ -67,6 +67,14 @@ type BranchResponse struct {
 	} `json:"commit"`
 }
 
+// TagResponse is a response struct for getting a tag
+type TagResponse struct {
+	Name   string `json:"name"`
+	Commit struct {
+		Sha string `json:"sha"`
+	} `json:"commit"`
+}
+
 // MergeRequest is a request struct to merge a pull request
 type MergeRequest struct {
 	CommitTitle   string `json:"commit_title,omitempty"`
@@ -87,6 +95,16 @@ type DiffFile struct {
 	Changes      int    `json:"changes"`
 }
 
+// CompareResponse is a response of comparing two commits
+type CompareResponse struct {
+	Commits []struct {
+		SHA   string `json:"sha"`
+		Files []struct {
+			Filename string `json:"filename"`
+		} `json:"files"`
+	} `json:"commits"`
+}
+
 // CommitResponse is a commits list response
 type CommitResponse struct {
 	SHA    string `json:"sha"`
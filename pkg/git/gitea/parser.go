@@ -30,7 +30,11 @@ func (c *Client) parsePullRequestWebhook(jsonString []byte) (*git.Webhook, error
 		return nil, err
 	}
 
-	pullRequest := git.PullRequest{ID: data.Number, Title: data.PullRequest.Title, URL: data.Repo.URL, State: git.PullRequestState(data.PullRequest.State), Action: git.PullRequestAction(data.Action)}
+	pullRequest := git.PullRequest{ID: data.Number, Title: data.PullRequest.Title, URL: data.Repo.URL, State: git.PullRequestState(data.PullRequest.State), Action: git.PullRequestAction(data.Action), Draft: data.PullRequest.Draft}
+	if pullRequest.Action == git.PullRequestActionClose && data.PullRequest.Merged {
+		pullRequest.Action = git.PullRequestActionMerged
+		pullRequest.MergeSha = data.PullRequest.MergeSha
+	}
 
 	// Get sender & author
 	sender, author := c.getSenderAuthor(data.Sender, data.PullRequest.User)
@@ -63,7 +67,34 @@ func (c *Client) parsePushWebhook(jsonString []byte) (*git.Webhook, error) {
 		return nil, nil
 	}
 	sender := git.User{Name: data.Sender.Name, ID: data.Sender.ID}
-	push := git.Push{Ref: data.Ref, Sha: data.Sha}
+	push := git.Push{Ref: data.Ref, Sha: data.Sha, Before: data.Before}
+
+	// Get sender email
+	userInfo, err := c.GetUserInfo(data.Sender.Name)
+	if err == nil {
+		sender.Email = userInfo.Email
+	}
+
+	return &git.Webhook{EventType: push.EventType(), Repo: repo, Sender: sender, Push: &push, RequestBody: string(jsonString)}, nil
+}
+
+func (c *Client) parseReleaseWebhook(jsonString []byte) (*git.Webhook, error) {
+	var data ReleaseWebhook
+	if err := json.Unmarshal(jsonString, &data); err != nil {
+		return nil, err
+	}
+	// Only published releases are handled
+	if data.Action != "published" {
+		return nil, nil
+	}
+
+	sha, err := c.getTagSha(data.Release.TagName)
+	if err != nil {
+		return nil, err
+	}
+	repo := git.Repository{Name: data.Repo.Name, URL: data.Repo.URL}
+	sender := git.User{Name: data.Sender.Name, ID: data.Sender.ID}
+	release := git.Release{Tag: data.Release.TagName, Name: data.Release.Name, Sha: sha}
 
 	// Get sender email
 	userInfo, err := c.GetUserInfo(data.Sender.Name)
@@ -71,7 +102,7 @@ func (c *Client) parsePushWebhook(jsonString []byte) (*git.Webhook, error) {
 		sender.Email = userInfo.Email
 	}
 
-	return &git.Webhook{EventType: git.EventTypePush, Repo: repo, Sender: sender, Push: &push, RequestBody: string(jsonString)}, nil
+	return &git.Webhook{EventType: git.EventTypeRelease, Repo: repo, Sender: sender, Release: &release, RequestBody: string(jsonString)}, nil
 }
 
 func (c *Client) parseIssueCommentWebhook(jsonString []byte) (*git.Webhook, error) {
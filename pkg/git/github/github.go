@@ -17,11 +17,13 @@
 package github
 
 import (
-	"crypto/hmac"
+	"context"
 	"crypto/sha1"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"net/url"
 	"strconv"
 	"strings"
 
@@ -35,6 +37,9 @@ type Client struct {
 	IntegrationConfig *cicdv1.IntegrationConfig
 	K8sClient         client.Client
 
+	// Context is a context of the API calls, which carries the caller's trace
+	Context context.Context
+
 	header map[string]string
 }
 
@@ -56,8 +61,15 @@ func (c *Client) Init() error {
 
 // ParseWebhook parses a webhook body for github
 func (c *Client) ParseWebhook(header http.Header, jsonString []byte) (*git.Webhook, error) {
-	var signature = strings.Replace(header.Get("x-hub-signature"), "sha1=", "", 1)
-	if err := Validate(c.IntegrationConfig.Status.Secrets, signature, jsonString); err != nil {
+	wh, err := c.parseWebhook(header, jsonString)
+	if wh != nil {
+		wh.DeliveryID = header.Get("X-GitHub-Delivery")
+	}
+	return wh, err
+}
+
+func (c *Client) parseWebhook(header http.Header, jsonString []byte) (*git.Webhook, error) {
+	if err := git.ValidateSignature(c.IntegrationConfig.GetWebhookSecrets(), header, jsonString); err != nil {
 		return nil, err
 	}
 	eventType := git.EventType(header.Get("x-github-event"))
@@ -66,6 +78,8 @@ func (c *Client) ParseWebhook(header http.Header, jsonString []byte) (*git.Webho
 		return c.parsePullRequestWebhook(jsonString)
 	case git.EventTypePush:
 		return c.parsePushWebhook(jsonString)
+	case git.EventTypeRelease:
+		return c.parseReleaseWebhook(jsonString)
 	case git.EventTypeIssueComment:
 		return c.parseIssueCommentWebhook(jsonString)
 	case git.EventTypePullRequestReview:
@@ -85,7 +99,7 @@ func (c *Client) ListWebhook() ([]git.WebhookEntry, error) {
 	var entries []WebhookEntry
 	tlsConfig := c.IntegrationConfig.GetTLSConfig()
 
-	err := git.GetPaginatedRequest(apiURL, tlsConfig, c.header, func() interface{} {
+	err := git.GetPaginatedRequest(c.Context, apiURL, tlsConfig, c.header, func() interface{} {
 		return &[]WebhookEntry{}
 	}, func(i interface{}) {
 		entries = append(entries, *i.(*[]WebhookEntry)...)
@@ -141,7 +155,7 @@ func (c *Client) ListCommitStatuses(ref string) ([]git.CommitStatus, error) {
 	var statuses []CommitStatusResponse
 	tlsConfig := c.IntegrationConfig.GetTLSConfig()
 
-	err := git.GetPaginatedRequest(apiURL, tlsConfig, c.header, func() interface{} {
+	err := git.GetPaginatedRequest(c.Context, apiURL, tlsConfig, c.header, func() interface{} {
 		return &[]CommitStatusResponse{}
 	}, func(i interface{}) {
 		statuses = append(statuses, *i.(*[]CommitStatusResponse)...)
@@ -194,6 +208,74 @@ func (c *Client) SetCommitStatus(sha string, status git.CommitStatus) error {
 	return nil
 }
 
+// ListCheckRuns lists the latest check runs of the specific commit
+func (c *Client) ListCheckRuns(sha string) ([]git.CheckRun, error) {
+	apiURL := c.IntegrationConfig.Spec.Git.GetAPIUrl() + "/repos/" + c.IntegrationConfig.Spec.Git.Repository + "/commits/" + sha + "/check-runs"
+
+	var checkRuns []CheckRunResponse
+	err := git.GetPaginatedRequest(c.Context, apiURL, c.IntegrationConfig.GetTLSConfig(), c.header, func() interface{} {
+		return &CheckRunListResponse{}
+	}, func(i interface{}) {
+		checkRuns = append(checkRuns, i.(*CheckRunListResponse).CheckRuns...)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var resp []git.CheckRun
+	for _, r := range checkRuns {
+		resp = append(resp, git.CheckRun{
+			Name:        r.Name,
+			Status:      git.CheckRunStatus(r.Status),
+			Conclusion:  git.CheckRunConclusion(r.Conclusion),
+			DetailsURL:  r.DetailsURL,
+			Title:       r.Output.Title,
+			Summary:     r.Output.Summary,
+			StartedAt:   r.StartedAt,
+			CompletedAt: r.CompletedAt,
+		})
+	}
+	return resp, nil
+}
+
+// SetCheckRun creates a check run for the specific commit, or updates it if the check run with the same name exists
+func (c *Client) SetCheckRun(sha string, checkRun git.CheckRun) error {
+	// Don't set check run if its' sha is a fake
+	if sha == git.FakeSha {
+		return nil
+	}
+
+	body := CheckRunRequest{
+		Name:        checkRun.Name,
+		Status:      string(checkRun.Status),
+		Conclusion:  string(checkRun.Conclusion),
+		DetailsURL:  checkRun.DetailsURL,
+		StartedAt:   checkRun.StartedAt,
+		CompletedAt: checkRun.CompletedAt,
+		Output:      CheckRunOutput{Title: checkRun.Title, Summary: checkRun.Summary},
+	}
+
+	repoURL := c.IntegrationConfig.Spec.Git.GetAPIUrl() + "/repos/" + c.IntegrationConfig.Spec.Git.Repository
+
+	// Find existing check run
+	data, _, err := c.requestHTTP(http.MethodGet, repoURL+"/commits/"+sha+"/check-runs?check_name="+url.QueryEscape(checkRun.Name), nil)
+	if err != nil {
+		return err
+	}
+	existing := &CheckRunListResponse{}
+	if err := json.Unmarshal(data, existing); err != nil {
+		return err
+	}
+	if len(existing.CheckRuns) > 0 {
+		_, _, err := c.requestHTTP(http.MethodPatch, fmt.Sprintf("%s/check-runs/%d", repoURL, existing.CheckRuns[0].ID), body)
+		return err
+	}
+
+	body.HeadSha = sha
+	_, _, err = c.requestHTTP(http.MethodPost, repoURL+"/check-runs", body)
+	return err
+}
+
 // GetUserInfo gets a user's information
 func (c *Client) GetUserInfo(userName string) (*git.User, error) {
 	// userName is string!
@@ -322,7 +404,7 @@ func (c *Client) ListPullRequests(onlyOpen bool) ([]git.PullRequest, error) {
 	var prs []PullRequest
 	tlsConfig := c.IntegrationConfig.GetTLSConfig()
 
-	err := git.GetPaginatedRequest(apiURL, tlsConfig, c.header, func() interface{} {
+	err := git.GetPaginatedRequest(c.Context, apiURL, tlsConfig, c.header, func() interface{} {
 		return &[]PullRequest{}
 	}, func(i interface{}) {
 		prs = append(prs, *i.(*[]PullRequest)...)
@@ -395,22 +477,7 @@ func (c *Client) GetPullRequestDiff(id int) (*git.Diff, error) {
 		return nil, err
 	}
 
-	var changes []git.Change
-	for _, d := range diffs {
-		prevName := d.PrevFilename
-		if prevName == "" {
-			prevName = d.Filename
-		}
-		changes = append(changes, git.Change{
-			Filename:    d.Filename,
-			OldFilename: prevName,
-			Additions:   d.Additions,
-			Deletions:   d.Deletions,
-			Changes:     d.Changes,
-		})
-	}
-
-	return &git.Diff{Changes: changes}, nil
+	return convertDiffFilesToShared(diffs), nil
 }
 
 // ListPullRequestCommits lists commits list of a pull request
@@ -446,6 +513,45 @@ func (c *Client) ListPullRequestCommits(id int) ([]git.Commit, error) {
 	return commits, nil
 }
 
+// GetCommitDiff gets diff between the base and head commits
+func (c *Client) GetCommitDiff(base, head string) (*git.Diff, error) {
+	apiURL := fmt.Sprintf("%s/repos/%s/compare/%s...%s", c.IntegrationConfig.Spec.Git.GetAPIUrl(), c.IntegrationConfig.Spec.Git.Repository, base, head)
+	raw, _, err := c.requestHTTP(http.MethodGet, apiURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &CompareResponse{}
+	if err := json.Unmarshal(raw, resp); err != nil {
+		return nil, err
+	}
+
+	return convertDiffFilesToShared(resp.Files), nil
+}
+
+// GetFileContent gets the content of the file at the ref
+func (c *Client) GetFileContent(path, ref string) ([]byte, error) {
+	apiURL := fmt.Sprintf("%s/repos/%s/contents/%s?ref=%s", c.IntegrationConfig.Spec.Git.GetAPIUrl(), c.IntegrationConfig.Spec.Git.Repository, git.EscapeFilePath(path), url.QueryEscape(ref))
+	raw, _, err := c.requestHTTP(http.MethodGet, apiURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &ContentResponse{}
+	if err := json.Unmarshal(raw, resp); err != nil {
+		return nil, err
+	}
+	if resp.Type != "file" {
+		return nil, fmt.Errorf("%s is not a file", path)
+	}
+	if resp.Encoding != "base64" {
+		return nil, fmt.Errorf("encoding %s is not supported", resp.Encoding)
+	}
+
+	// GitHub splits the base64 content into multiple lines
+	return base64.StdEncoding.DecodeString(strings.ReplaceAll(resp.Content, "\n", ""))
+}
+
 // SetLabel sets label to the issue id
 func (c *Client) SetLabel(_ git.IssueType, id int, label string) error {
 	apiURL := fmt.Sprintf("%s/repos/%s/issues/%d/labels", c.IntegrationConfig.Spec.Git.GetAPIUrl(), c.IntegrationConfig.Spec.Git.Repository, id)
@@ -503,6 +609,23 @@ func (c *Client) GetBranch(branch string) (*git.Branch, error) {
 	return &git.Branch{Name: resp.Name, CommitID: resp.Commit.Sha}, nil
 }
 
+// getRefSha gets the sha of the commit which the ref (i.e., a branch or a tag) points to
+func (c *Client) getRefSha(ref string) (string, error) {
+	apiURL := fmt.Sprintf("%s/repos/%s/commits/%s", c.IntegrationConfig.Spec.Git.GetAPIUrl(), c.IntegrationConfig.Spec.Git.Repository, ref)
+
+	raw, _, err := c.requestHTTP(http.MethodGet, apiURL, nil)
+	if err != nil {
+		return "", err
+	}
+
+	resp := &CommitResponse{}
+	if err := json.Unmarshal(raw, resp); err != nil {
+		return "", err
+	}
+
+	return resp.SHA, nil
+}
+
 func convertPullRequestToShared(pr *PullRequest) *git.PullRequest {
 	var labels []git.IssueLabel
 	for _, l := range pr.Labels {
@@ -522,37 +645,36 @@ func convertPullRequestToShared(pr *PullRequest) *git.PullRequest {
 		Head:      git.Head{Ref: pr.Head.Ref, Sha: pr.Head.Sha},
 		Labels:    labels,
 		Mergeable: pr.Mergeable,
+		Draft:     pr.Draft,
 	}
 }
 
-func (c *Client) requestHTTP(method, apiURL string, data interface{}) ([]byte, http.Header, error) {
-	tlsConfig := c.IntegrationConfig.GetTLSConfig()
-
-	return git.RequestHTTP(method, apiURL, c.header, data, tlsConfig)
-}
+func convertDiffFilesToShared(diffs DiffFiles) *git.Diff {
+	var changes []git.Change
+	for _, d := range diffs {
+		prevName := d.PrevFilename
+		if prevName == "" {
+			prevName = d.Filename
+		}
+		changes = append(changes, git.Change{
+			Filename:    d.Filename,
+			OldFilename: prevName,
+			Additions:   d.Additions,
+			Deletions:   d.Deletions,
+			Changes:     d.Changes,
+		})
+	}
 
-// IsValidPayload validates the webhook payload
-func IsValidPayload(secret, headerHash string, payload []byte) bool {
-	hash := HashPayload(secret, payload)
-	return hmac.Equal(
-		[]byte(hash),
-		[]byte(headerHash),
-	)
+	return &git.Diff{Changes: changes}
 }
 
-// HashPayload hashes the payload
-func HashPayload(secret string, payloadBody []byte) string {
-	hm := hmac.New(sha1.New, []byte(secret))
-	_, _ = hm.Write(payloadBody)
-	sum := hm.Sum(nil)
+func (c *Client) requestHTTP(method, apiURL string, data interface{}) ([]byte, http.Header, error) {
+	tlsConfig := c.IntegrationConfig.GetTLSConfig()
 
-	return fmt.Sprintf("%x", sum)
+	return git.RequestHTTP(c.Context, method, apiURL, c.header, data, tlsConfig)
 }
 
-// Validate validates the webhook payload
-func Validate(secret, headerHash string, payload []byte) error {
-	if !IsValidPayload(secret, headerHash, payload) {
-		return fmt.Errorf("invalid request : X-Hub-Signature does not match secret")
-	}
-	return nil
+// HashPayload hashes the payload with HMAC-SHA1
+func HashPayload(secret string, payloadBody []byte) string {
+	return git.HashPayload(sha1.New, secret, payloadBody)
 }
@@ -43,6 +43,7 @@ const (
 	samplePRComments                     = "[\n  {\n    \"url\": \"https://api.github.com/repos/tmax-cloud/cicd-operator/pulls/comments/771113606\",\n    \"pull_request_review_id\": 834849190,\n    \"id\": 771113606,\n    \"node_id\": \"PRRC_kwDOEm6Tx84t9kKG\",\n    \"diff_hunk\": \"@@ -20,89 +20,10 @@ import (\\n \\t\\\"testing\\\"\\n \\n \\t\\\"github.com/stretchr/testify/require\\\"\\n-\\ttektonv1beta1 \\\"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1\\\"\\n \\t\\\"github.com/tmax-cloud/cicd-operator/internal/configs\\\"\\n \\tmetav1 \\\"k8s.io/apimachinery/pkg/apis/meta/v1\\\"\\n )\\n \\n-func TestConvertToTektonParamSpecs(t *testing.T) {\",\n    \"path\": \"api/v1/integrationjob_types_test.go\",\n    \"position\": 9,\n    \"original_position\": 9,\n    \"commit_id\": \"d3b2006b7a2ab28268b248429bc215854a497d24\",\n    \"original_commit_id\": \"654761e79f45e62ef8ca4d94c47cf7adc1756122\",\n    \"user\": {\n      \"login\": \"eddy-kor-92\",\n      \"id\": 33279734,\n      \"node_id\": \"MDQ6VXNlcjMzMjc5NzM0\",\n      \"avatar_url\": \"https://avatars.githubusercontent.com/u/33279734?v=4\",\n      \"gravatar_id\": \"\",\n      \"url\": \"https://api.github.com/users/eddy-kor-92\",\n      \"html_url\": \"https://github.com/eddy-kor-92\",\n      \"followers_url\": \"https://api.github.com/users/eddy-kor-92/followers\",\n      \"following_url\": \"https://api.github.com/users/eddy-kor-92/following{/other_user}\",\n      \"gists_url\": \"https://api.github.com/users/eddy-kor-92/gists{/gist_id}\",\n      \"starred_url\": \"https://api.github.com/users/eddy-kor-92/starred{/owner}{/repo}\",\n      \"subscriptions_url\": \"https://api.github.com/users/eddy-kor-92/subscriptions\",\n      \"organizations_url\": \"https://api.github.com/users/eddy-kor-92/orgs\",\n      \"repos_url\": \"https://api.github.com/users/eddy-kor-92/repos\",\n      \"events_url\": \"https://api.github.com/users/eddy-kor-92/events{/privacy}\",\n      \"received_events_url\": \"https://api.github.com/users/eddy-kor-92/received_events\",\n      \"type\": \"User\",\n      \"site_admin\": false\n    },\n    \"body\": \"이 Test 함수가 원래 integrationconfig_types_test에 있는게 맞는거죠? 그래서 옮기신거죠?\",\n    \"created_at\": \"2021-12-17T05:29:08Z\",\n    \"updated_at\": \"2021-12-17T05:31:38Z\",\n    \"html_url\": \"https://github.com/tmax-cloud/cicd-operator/pull/324#discussion_r771113606\",\n    \"pull_request_url\": \"https://api.github.com/repos/tmax-cloud/cicd-operator/pulls/324\",\n    \"author_association\": \"NONE\",\n    \"_links\": {\n      \"self\": {\n        \"href\": \"https://api.github.com/repos/tmax-cloud/cicd-operator/pulls/comments/771113606\"\n      },\n      \"html\": {\n        \"href\": \"https://github.com/tmax-cloud/cicd-operator/pull/324#discussion_r771113606\"\n      },\n      \"pull_request\": {\n        \"href\": \"https://api.github.com/repos/tmax-cloud/cicd-operator/pulls/324\"\n      }\n    },\n    \"reactions\": {\n      \"url\": \"https://api.github.com/repos/tmax-cloud/cicd-operator/pulls/comments/771113606/reactions\",\n      \"total_count\": 0,\n      \"+1\": 0,\n      \"-1\": 0,\n      \"laugh\": 0,\n      \"hooray\": 0,\n      \"confused\": 0,\n      \"heart\": 0,\n      \"rocket\": 0,\n      \"eyes\": 0\n    },\n    \"start_line\": null,\n    \"original_start_line\": null,\n    \"start_side\": null,\n    \"line\": 28,\n    \"original_line\": 28,\n    \"side\": \"LEFT\"\n  },\n  {\n    \"url\": \"https://api.github.com/repos/tmax-cloud/cicd-operator/pulls/comments/771114018\",\n    \"pull_request_review_id\": 834849190,\n    \"id\": 771114018,\n    \"node_id\": \"PRRC_kwDOEm6Tx84t9kQi\",\n    \"diff_hunk\": \"@@ -127,18 +130,33 @@ func (p *pipelineManager) Generate(job *cicdv1.IntegrationJob) (*tektonv1beta1.P\\n \\t\\t\\t\\tResources:  specResources,\\n \\t\\t\\t\\tTasks:      tasks,\\n \\t\\t\\t\\tWorkspaces: workspaceDefs,\\n-\\t\\t\\t\\tParams:     cicdv1.ConvertToTektonParamSpecs(job.Spec.ParamConfig.ParamDefine),\\n+\\t\\t\\t\\tParams:     paramDefine,\\n \\t\\t\\t},\\n \\t\\t\\tPodTemplate: job.Spec.PodTemplate,\\n \\t\\t\\tWorkspaces:  job.Spec.Workspaces,\\n \\t\\t\\tTimeout: &metav1.Duration{\\n \\t\\t\\t\\tDuration: job.Spec.Timeout.Duration,\\n \\t\\t\\t},\\n-\\t\\t\\tParams: cicdv1.ConvertToTektonParams(job.Spec.ParamConfig.ParamValue),\\n+\\t\\t\\tParams: paramValue,\\n \\t\\t},\\n \\t}, nil\\n }\\n \\n+func getParams(job *cicdv1.IntegrationJob) ([]tektonv1beta1.ParamSpec, []tektonv1beta1.Param) {\",\n    \"path\": \"pkg/pipelinemanager/pipelinemanager.go\",\n    \"position\": 28,\n    \"original_position\": 28,\n    \"commit_id\": \"d3b2006b7a2ab28268b248429bc215854a497d24\",\n    \"original_commit_id\": \"654761e79f45e62ef8ca4d94c47cf7adc1756122\",\n    \"user\": {\n      \"login\": \"eddy-kor-92\",\n      \"id\": 33279734,\n      \"node_id\": \"MDQ6VXNlcjMzMjc5NzM0\",\n      \"avatar_url\": \"https://avatars.githubusercontent.com/u/33279734?v=4\",\n      \"gravatar_id\": \"\",\n      \"url\": \"https://api.github.com/users/eddy-kor-92\",\n      \"html_url\": \"https://github.com/eddy-kor-92\",\n      \"followers_url\": \"https://api.github.com/users/eddy-kor-92/followers\",\n      \"following_url\": \"https://api.github.com/users/eddy-kor-92/following{/other_user}\",\n      \"gists_url\": \"https://api.github.com/users/eddy-kor-92/gists{/gist_id}\",\n      \"starred_url\": \"https://api.github.com/users/eddy-kor-92/starred{/owner}{/repo}\",\n      \"subscriptions_url\": \"https://api.github.com/users/eddy-kor-92/subscriptions\",\n      \"organizations_url\": \"https://api.github.com/users/eddy-kor-92/orgs\",\n      \"repos_url\": \"https://api.github.com/users/eddy-kor-92/repos\",\n      \"events_url\": \"https://api.github.com/users/eddy-kor-92/events{/privacy}\",\n      \"received_events_url\": \"https://api.github.com/users/eddy-kor-92/received_events\",\n      \"type\": \"User\",\n      \"site_admin\": false\n    },\n    \"body\": \"nil 체크를 하는게 이 함수의 목적인거 같은데, parameter를 직접 사용하는 함수에서 parameter validation을 하는게 더 낫지 않을까요? ConvertToTektonParamSpecs랑 ConvertToTektonParams 함수에서요.\",\n    \"created_at\": \"2021-12-17T05:30:31Z\",\n    \"updated_at\": \"2021-12-17T05:31:38Z\",\n    \"html_url\": \"https://github.com/tmax-cloud/cicd-operator/pull/324#discussion_r771114018\",\n    \"pull_request_url\": \"https://api.github.com/repos/tmax-cloud/cicd-operator/pulls/324\",\n    \"author_association\": \"NONE\",\n    \"_links\": {\n      \"self\": {\n        \"href\": \"https://api.github.com/repos/tmax-cloud/cicd-operator/pulls/comments/771114018\"\n      },\n      \"html\": {\n        \"href\": \"https://github.com/tmax-cloud/cicd-operator/pull/324#discussion_r771114018\"\n      },\n      \"pull_request\": {\n        \"href\": \"https://api.github.com/repos/tmax-cloud/cicd-operator/pulls/324\"\n      }\n    },\n    \"reactions\": {\n      \"url\": \"https://api.github.com/repos/tmax-cloud/cicd-operator/pulls/comments/771114018/reactions\",\n      \"total_count\": 0,\n      \"+1\": 0,\n      \"-1\": 0,\n      \"laugh\": 0,\n      \"hooray\": 0,\n      \"confused\": 0,\n      \"heart\": 0,\n      \"rocket\": 0,\n      \"eyes\": 0\n    },\n    \"start_line\": null,\n    \"original_start_line\": null,\n    \"start_side\": null,\n    \"line\": 145,\n    \"original_line\": 145,\n    \"side\": \"RIGHT\"\n  },\n  {\n    \"url\": \"https://api.github.com/repos/tmax-cloud/cicd-operator/pulls/comments/771115644\",\n    \"pull_request_review_id\": 834851875,\n    \"id\": 771115644,\n    \"node_id\": \"PRRC_kwDOEm6Tx84t9kp8\",\n    \"diff_hunk\": \"@@ -20,89 +20,10 @@ import (\\n \\t\\\"testing\\\"\\n \\n \\t\\\"github.com/stretchr/testify/require\\\"\\n-\\ttektonv1beta1 \\\"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1\\\"\\n \\t\\\"github.com/tmax-cloud/cicd-operator/internal/configs\\\"\\n \\tmetav1 \\\"k8s.io/apimachinery/pkg/apis/meta/v1\\\"\\n )\\n \\n-func TestConvertToTektonParamSpecs(t *testing.T) {\",\n    \"path\": \"api/v1/integrationjob_types_test.go\",\n    \"position\": 9,\n    \"original_position\": 9,\n    \"commit_id\": \"d3b2006b7a2ab28268b248429bc215854a497d24\",\n    \"original_commit_id\": \"654761e79f45e62ef8ca4d94c47cf7adc1756122\",\n    \"user\": {\n      \"login\": \"changjjjjjjj\",\n      \"id\": 56624551,\n      \"node_id\": \"MDQ6VXNlcjU2NjI0NTUx\",\n      \"avatar_url\": \"https://avatars.githubusercontent.com/u/56624551?v=4\",\n      \"gravatar_id\": \"\",\n      \"url\": \"https://api.github.com/users/changjjjjjjj\",\n      \"html_url\": \"https://github.com/changjjjjjjj\",\n      \"followers_url\": \"https://api.github.com/users/changjjjjjjj/followers\",\n      \"following_url\": \"https://api.github.com/users/changjjjjjjj/following{/other_user}\",\n      \"gists_url\": \"https://api.github.com/users/changjjjjjjj/gists{/gist_id}\",\n      \"starred_url\": \"https://api.github.com/users/changjjjjjjj/starred{/owner}{/repo}\",\n      \"subscriptions_url\": \"https://api.github.com/users/changjjjjjjj/subscriptions\",\n      \"organizations_url\": \"https://api.github.com/users/changjjjjjjj/orgs\",\n      \"repos_url\": \"https://api.github.com/users/changjjjjjjj/repos\",\n      \"events_url\": \"https://api.github.com/users/changjjjjjjj/events{/privacy}\",\n      \"received_events_url\": \"https://api.github.com/users/changjjjjjjj/received_events\",\n      \"type\": \"User\",\n      \"site_admin\": false\n    },\n    \"body\": \"네 잘못 들어가있어서 옮겼습니다\",\n    \"created_at\": \"2021-12-17T05:36:07Z\",\n    \"updated_at\": \"2021-12-17T05:36:07Z\",\n    \"html_url\": \"https://github.com/tmax-cloud/cicd-operator/pull/324#discussion_r771115644\",\n    \"pull_request_url\": \"https://api.github.com/repos/tmax-cloud/cicd-operator/pulls/324\",\n    \"author_association\": \"COLLABORATOR\",\n    \"_links\": {\n      \"self\": {\n        \"href\": \"https://api.github.com/repos/tmax-cloud/cicd-operator/pulls/comments/771115644\"\n      },\n      \"html\": {\n        \"href\": \"https://github.com/tmax-cloud/cicd-operator/pull/324#discussion_r771115644\"\n      },\n      \"pull_request\": {\n        \"href\": \"https://api.github.com/repos/tmax-cloud/cicd-operator/pulls/324\"\n      }\n    },\n    \"reactions\": {\n      \"url\": \"https://api.github.com/repos/tmax-cloud/cicd-operator/pulls/comments/771115644/reactions\",\n      \"total_count\": 0,\n      \"+1\": 0,\n      \"-1\": 0,\n      \"laugh\": 0,\n      \"hooray\": 0,\n      \"confused\": 0,\n      \"heart\": 0,\n      \"rocket\": 0,\n      \"eyes\": 0\n    },\n    \"start_line\": null,\n    \"original_start_line\": null,\n    \"start_side\": null,\n    \"line\": 28,\n    \"original_line\": 28,\n    \"side\": \"LEFT\",\n    \"in_reply_to_id\": 771113606\n  },\n  {\n    \"url\": \"https://api.github.com/repos/tmax-cloud/cicd-operator/pulls/comments/771122149\",\n    \"pull_request_review_id\": 834860063,\n    \"id\": 771122149,\n    \"node_id\": \"PRRC_kwDOEm6Tx84t9mPl\",\n    \"diff_hunk\": \"@@ -127,18 +130,33 @@ func (p *pipelineManager) Generate(job *cicdv1.IntegrationJob) (*tektonv1beta1.P\\n \\t\\t\\t\\tResources:  specResources,\\n \\t\\t\\t\\tTasks:      tasks,\\n \\t\\t\\t\\tWorkspaces: workspaceDefs,\\n-\\t\\t\\t\\tParams:     cicdv1.ConvertToTektonParamSpecs(job.Spec.ParamConfig.ParamDefine),\\n+\\t\\t\\t\\tParams:     paramDefine,\\n \\t\\t\\t},\\n \\t\\t\\tPodTemplate: job.Spec.PodTemplate,\\n \\t\\t\\tWorkspaces:  job.Spec.Workspaces,\\n \\t\\t\\tTimeout: &metav1.Duration{\\n \\t\\t\\t\\tDuration: job.Spec.Timeout.Duration,\\n \\t\\t\\t},\\n-\\t\\t\\tParams: cicdv1.ConvertToTektonParams(job.Spec.ParamConfig.ParamValue),\\n+\\t\\t\\tParams: paramValue,\\n \\t\\t},\\n \\t}, nil\\n }\\n \\n+func getParams(job *cicdv1.IntegrationJob) ([]tektonv1beta1.ParamSpec, []tektonv1beta1.Param) {\",\n    \"path\": \"pkg/pipelinemanager/pipelinemanager.go\",\n    \"position\": 28,\n    \"original_position\": 28,\n    \"commit_id\": \"d3b2006b7a2ab28268b248429bc215854a497d24\",\n    \"original_commit_id\": \"654761e79f45e62ef8ca4d94c47cf7adc1756122\",\n    \"user\": {\n      \"login\": \"changjjjjjjj\",\n      \"id\": 56624551,\n      \"node_id\": \"MDQ6VXNlcjU2NjI0NTUx\",\n      \"avatar_url\": \"https://avatars.githubusercontent.com/u/56624551?v=4\",\n      \"gravatar_id\": \"\",\n      \"url\": \"https://api.github.com/users/changjjjjjjj\",\n      \"html_url\": \"https://github.com/changjjjjjjj\",\n      \"followers_url\": \"https://api.github.com/users/changjjjjjjj/followers\",\n      \"following_url\": \"https://api.github.com/users/changjjjjjjj/following{/other_user}\",\n      \"gists_url\": \"https://api.github.com/users/changjjjjjjj/gists{/gist_id}\",\n      \"starred_url\": \"https://api.github.com/users/changjjjjjjj/starred{/owner}{/repo}\",\n      \"subscriptions_url\": \"https://api.github.com/users/changjjjjjjj/subscriptions\",\n      \"organizations_url\": \"https://api.github.com/users/changjjjjjjj/orgs\",\n      \"repos_url\": \"https://api.github.com/users/changjjjjjjj/repos\",\n      \"events_url\": \"https://api.github.com/users/changjjjjjjj/events{/privacy}\",\n      \"received_events_url\": \"https://api.github.com/users/changjjjjjjj/received_events\",\n      \"type\": \"User\",\n      \"site_admin\": false\n    },\n    \"body\": \"paramConfig nil 은 체크해야 해서 함수는 남겨뒀고 생각해보니까 paramDefine이랑 paramValue는  getParams에서 nil 체크 안해도 돼서 삭제했습니다.\",\n    \"created_at\": \"2021-12-17T05:57:08Z\",\n    \"updated_at\": \"2021-12-17T05:57:08Z\",\n    \"html_url\": \"https://github.com/tmax-cloud/cicd-operator/pull/324#discussion_r771122149\",\n    \"pull_request_url\": \"https://api.github.com/repos/tmax-cloud/cicd-operator/pulls/324\",\n    \"author_association\": \"COLLABORATOR\",\n    \"_links\": {\n      \"self\": {\n        \"href\": \"https://api.github.com/repos/tmax-cloud/cicd-operator/pulls/comments/771122149\"\n      },\n      \"html\": {\n        \"href\": \"https://github.com/tmax-cloud/cicd-operator/pull/324#discussion_r771122149\"\n      },\n      \"pull_request\": {\n        \"href\": \"https://api.github.com/repos/tmax-cloud/cicd-operator/pulls/324\"\n      }\n    },\n    \"reactions\": {\n      \"url\": \"https://api.github.com/repos/tmax-cloud/cicd-operator/pulls/comments/771122149/reactions\",\n      \"total_count\": 0,\n      \"+1\": 0,\n      \"-1\": 0,\n      \"laugh\": 0,\n      \"hooray\": 0,\n      \"confused\": 0,\n      \"heart\": 0,\n      \"rocket\": 0,\n      \"eyes\": 0\n    },\n    \"start_line\": null,\n    \"original_start_line\": null,\n    \"start_side\": null,\n    \"line\": 145,\n    \"original_line\": 145,\n    \"side\": \"RIGHT\",\n    \"in_reply_to_id\": 771114018\n  }\n]"
 	samplePRReviews                      = "[\n  {\n    \"id\": 834849190,\n    \"node_id\": \"PRR_kwDOEm6Tx84xwsmm\",\n    \"user\": {\n      \"login\": \"eddy-kor-92\",\n      \"id\": 33279734,\n      \"node_id\": \"MDQ6VXNlcjMzMjc5NzM0\",\n      \"avatar_url\": \"https://avatars.githubusercontent.com/u/33279734?u=bed3bf0df30f21a34b1d88dac4bdea053d2edafa&v=4\",\n      \"gravatar_id\": \"\",\n      \"url\": \"https://api.github.com/users/eddy-kor-92\",\n      \"html_url\": \"https://github.com/eddy-kor-92\",\n      \"followers_url\": \"https://api.github.com/users/eddy-kor-92/followers\",\n      \"following_url\": \"https://api.github.com/users/eddy-kor-92/following{/other_user}\",\n      \"gists_url\": \"https://api.github.com/users/eddy-kor-92/gists{/gist_id}\",\n      \"starred_url\": \"https://api.github.com/users/eddy-kor-92/starred{/owner}{/repo}\",\n      \"subscriptions_url\": \"https://api.github.com/users/eddy-kor-92/subscriptions\",\n      \"organizations_url\": \"https://api.github.com/users/eddy-kor-92/orgs\",\n      \"repos_url\": \"https://api.github.com/users/eddy-kor-92/repos\",\n      \"events_url\": \"https://api.github.com/users/eddy-kor-92/events{/privacy}\",\n      \"received_events_url\": \"https://api.github.com/users/eddy-kor-92/received_events\",\n      \"type\": \"User\",\n      \"site_admin\": false\n    },\n    \"body\": \"\",\n    \"state\": \"COMMENTED\",\n    \"html_url\": \"https://github.com/tmax-cloud/cicd-operator/pull/324#pullrequestreview-834849190\",\n    \"pull_request_url\": \"https://api.github.com/repos/tmax-cloud/cicd-operator/pulls/324\",\n    \"author_association\": \"NONE\",\n    \"_links\": {\n      \"html\": {\n        \"href\": \"https://github.com/tmax-cloud/cicd-operator/pull/324#pullrequestreview-834849190\"\n      },\n      \"pull_request\": {\n        \"href\": \"https://api.github.com/repos/tmax-cloud/cicd-operator/pulls/324\"\n      }\n    },\n    \"submitted_at\": \"2021-12-17T05:31:38Z\",\n    \"commit_id\": \"654761e79f45e62ef8ca4d94c47cf7adc1756122\"\n  },\n  {\n    \"id\": 834851875,\n    \"node_id\": \"PRR_kwDOEm6Tx84xwtQj\",\n    \"user\": {\n      \"login\": \"changjjjjjjj\",\n      \"id\": 56624551,\n      \"node_id\": \"MDQ6VXNlcjU2NjI0NTUx\",\n      \"avatar_url\": \"https://avatars.githubusercontent.com/u/56624551?v=4\",\n      \"gravatar_id\": \"\",\n      \"url\": \"https://api.github.com/users/changjjjjjjj\",\n      \"html_url\": \"https://github.com/changjjjjjjj\",\n      \"followers_url\": \"https://api.github.com/users/changjjjjjjj/followers\",\n      \"following_url\": \"https://api.github.com/users/changjjjjjjj/following{/other_user}\",\n      \"gists_url\": \"https://api.github.com/users/changjjjjjjj/gists{/gist_id}\",\n      \"starred_url\": \"https://api.github.com/users/changjjjjjjj/starred{/owner}{/repo}\",\n      \"subscriptions_url\": \"https://api.github.com/users/changjjjjjjj/subscriptions\",\n      \"organizations_url\": \"https://api.github.com/users/changjjjjjjj/orgs\",\n      \"repos_url\": \"https://api.github.com/users/changjjjjjjj/repos\",\n      \"events_url\": \"https://api.github.com/users/changjjjjjjj/events{/privacy}\",\n      \"received_events_url\": \"https://api.github.com/users/changjjjjjjj/received_events\",\n      \"type\": \"User\",\n      \"site_admin\": false\n    },\n    \"body\": \"\",\n    \"state\": \"COMMENTED\",\n    \"html_url\": \"https://github.com/tmax-cloud/cicd-operator/pull/324#pullrequestreview-834851875\",\n    \"pull_request_url\": \"https://api.github.com/repos/tmax-cloud/cicd-operator/pulls/324\",\n    \"author_association\": \"COLLABORATOR\",\n    \"_links\": {\n      \"html\": {\n        \"href\": \"https://github.com/tmax-cloud/cicd-operator/pull/324#pullrequestreview-834851875\"\n      },\n      \"pull_request\": {\n        \"href\": \"https://api.github.com/repos/tmax-cloud/cicd-operator/pulls/324\"\n      }\n    },\n    \"submitted_at\": \"2021-12-17T05:36:07Z\",\n    \"commit_id\": \"654761e79f45e62ef8ca4d94c47cf7adc1756122\"\n  },\n  {\n    \"id\": 834860063,\n    \"node_id\": \"PRR_kwDOEm6Tx84xwvQf\",\n    \"user\": {\n      \"login\": \"changjjjjjjj\",\n      \"id\": 56624551,\n      \"node_id\": \"MDQ6VXNlcjU2NjI0NTUx\",\n      \"avatar_url\": \"https://avatars.githubusercontent.com/u/56624551?v=4\",\n      \"gravatar_id\": \"\",\n      \"url\": \"https://api.github.com/users/changjjjjjjj\",\n      \"html_url\": \"https://github.com/changjjjjjjj\",\n      \"followers_url\": \"https://api.github.com/users/changjjjjjjj/followers\",\n      \"following_url\": \"https://api.github.com/users/changjjjjjjj/following{/other_user}\",\n      \"gists_url\": \"https://api.github.com/users/changjjjjjjj/gists{/gist_id}\",\n      \"starred_url\": \"https://api.github.com/users/changjjjjjjj/starred{/owner}{/repo}\",\n      \"subscriptions_url\": \"https://api.github.com/users/changjjjjjjj/subscriptions\",\n      \"organizations_url\": \"https://api.github.com/users/changjjjjjjj/orgs\",\n      \"repos_url\": \"https://api.github.com/users/changjjjjjjj/repos\",\n      \"events_url\": \"https://api.github.com/users/changjjjjjjj/events{/privacy}\",\n      \"received_events_url\": \"https://api.github.com/users/changjjjjjjj/received_events\",\n      \"type\": \"User\",\n      \"site_admin\": false\n    },\n    \"body\": \"\",\n    \"state\": \"COMMENTED\",\n    \"html_url\": \"https://github.com/tmax-cloud/cicd-operator/pull/324#pullrequestreview-834860063\",\n    \"pull_request_url\": \"https://api.github.com/repos/tmax-cloud/cicd-operator/pulls/324\",\n    \"author_association\": \"COLLABORATOR\",\n    \"_links\": {\n      \"html\": {\n        \"href\": \"https://github.com/tmax-cloud/cicd-operator/pull/324#pullrequestreview-834860063\"\n      },\n      \"pull_request\": {\n        \"href\": \"https://api.github.com/repos/tmax-cloud/cicd-operator/pulls/324\"\n      }\n    },\n    \"submitted_at\": \"2021-12-17T05:57:08Z\",\n    \"commit_id\": \"d3b2006b7a2ab28268b248429bc215854a497d24\"\n  },\n  {\n    \"id\": 834871251,\n    \"node_id\": \"PRR_kwDOEm6Tx84xwx_T\",\n    \"user\": {\n      \"login\": \"yxzzzxh\",\n      \"id\": 36444454,\n      \"node_id\": \"MDQ6VXNlcjM2NDQ0NDU0\",\n      \"avatar_url\": \"https://avatars.githubusercontent.com/u/36444454?u=bbc82e004d2e79434274c1fc4ac97c1d2b6f249e&v=4\",\n      \"gravatar_id\": \"\",\n      \"url\": \"https://api.github.com/users/yxzzzxh\",\n      \"html_url\": \"https://github.com/yxzzzxh\",\n      \"followers_url\": \"https://api.github.com/users/yxzzzxh/followers\",\n      \"following_url\": \"https://api.github.com/users/yxzzzxh/following{/other_user}\",\n      \"gists_url\": \"https://api.github.com/users/yxzzzxh/gists{/gist_id}\",\n      \"starred_url\": \"https://api.github.com/users/yxzzzxh/starred{/owner}{/repo}\",\n      \"subscriptions_url\": \"https://api.github.com/users/yxzzzxh/subscriptions\",\n      \"organizations_url\": \"https://api.github.com/users/yxzzzxh/orgs\",\n      \"repos_url\": \"https://api.github.com/users/yxzzzxh/repos\",\n      \"events_url\": \"https://api.github.com/users/yxzzzxh/events{/privacy}\",\n      \"received_events_url\": \"https://api.github.com/users/yxzzzxh/received_events\",\n      \"type\": \"User\",\n      \"site_admin\": false\n    },\n    \"body\": \"/approve\",\n    \"state\": \"COMMENTED\",\n    \"html_url\": \"https://github.com/tmax-cloud/cicd-operator/pull/324#pullrequestreview-834871251\",\n    \"pull_request_url\": \"https://api.github.com/repos/tmax-cloud/cicd-operator/pulls/324\",\n    \"author_association\": \"CONTRIBUTOR\",\n    \"_links\": {\n      \"html\": {\n        \"href\": \"https://github.com/tmax-cloud/cicd-operator/pull/324#pullrequestreview-834871251\"\n      },\n      \"pull_request\": {\n        \"href\": \"https://api.github.com/repos/tmax-cloud/cicd-operator/pulls/324\"\n      }\n    },\n    \"submitted_at\": \"2021-12-17T06:21:13Z\",\n    \"commit_id\": \"d3b2006b7a2ab28268b248429bc215854a497d24\"\n  }\n]"
 	sampleIssueComments                  = "[\n  {\n    \"url\": \"https://api.github.com/repos/tmax-cloud/cicd-operator/issues/comments/996468306\",\n    \"html_url\": \"https://github.com/tmax-cloud/cicd-operator/pull/324#issuecomment-996468306\",\n    \"issue_url\": \"https://api.github.com/repos/tmax-cloud/cicd-operator/issues/324\",\n    \"id\": 996468306,\n    \"node_id\": \"IC_kwDOEm6Tx847ZOZS\",\n    \"user\": {\n      \"login\": \"tmax-cloud-bot\",\n      \"id\": 76757421,\n      \"node_id\": \"MDQ6VXNlcjc2NzU3NDIx\",\n      \"avatar_url\": \"https://avatars.githubusercontent.com/u/76757421?v=4\",\n      \"gravatar_id\": \"\",\n      \"url\": \"https://api.github.com/users/tmax-cloud-bot\",\n      \"html_url\": \"https://github.com/tmax-cloud-bot\",\n      \"followers_url\": \"https://api.github.com/users/tmax-cloud-bot/followers\",\n      \"following_url\": \"https://api.github.com/users/tmax-cloud-bot/following{/other_user}\",\n      \"gists_url\": \"https://api.github.com/users/tmax-cloud-bot/gists{/gist_id}\",\n      \"starred_url\": \"https://api.github.com/users/tmax-cloud-bot/starred{/owner}{/repo}\",\n      \"subscriptions_url\": \"https://api.github.com/users/tmax-cloud-bot/subscriptions\",\n      \"organizations_url\": \"https://api.github.com/users/tmax-cloud-bot/orgs\",\n      \"repos_url\": \"https://api.github.com/users/tmax-cloud-bot/repos\",\n      \"events_url\": \"https://api.github.com/users/tmax-cloud-bot/events{/privacy}\",\n      \"received_events_url\": \"https://api.github.com/users/tmax-cloud-bot/received_events\",\n      \"type\": \"User\",\n      \"site_admin\": false\n    },\n    \"created_at\": \"2021-12-17T06:21:16Z\",\n    \"updated_at\": \"2021-12-17T06:21:16Z\",\n    \"author_association\": \"NONE\",\n    \"body\": \"[APPROVE ALERT]\\n\\nUser `yxzzzxh` approved this pull request!\",\n    \"reactions\": {\n      \"url\": \"https://api.github.com/repos/tmax-cloud/cicd-operator/issues/comments/996468306/reactions\",\n      \"total_count\": 0,\n      \"+1\": 0,\n      \"-1\": 0,\n      \"laugh\": 0,\n      \"hooray\": 0,\n      \"confused\": 0,\n      \"heart\": 0,\n      \"rocket\": 0,\n      \"eyes\": 0\n    },\n    \"performed_via_github_app\": null\n  }\n]"
+	sampleCheckRunsList                  = "{\"total_count\":1,\"check_runs\":[{\"id\":4,\"name\":\"test-1\",\"status\":\"completed\",\"conclusion\":\"success\",\"details_url\":\"https://report\",\"started_at\":\"2021-04-12T08:37:32Z\",\"completed_at\":\"2021-04-12T08:40:32Z\",\"output\":{\"title\":\"Job succeeded\",\"summary\":\"\"}}]}"
 	sampleUserInfo                       = "{\"id\":123456,\"login\":\"changjjjjjjj\",\"email\":\"sample@email.com\"}"
 	samplePermissionTrue                 = "{\"permission\":\"admin\"}"
 	samplePermissionFalse                = "{\"permission\":\"dev\"}"
@@ -74,6 +75,9 @@ const (
 // "pull_request":{"title":"test","number":1234,"state":"opened","html_url":"https://test","mergeable":true,"user":{"login":"changjjjjjjj","id":11111},"draft":false,"head":{"ref":"master","sha":"sha1=11111111111111"},"base":{"ref":"master","sha":"sha1=11111111111111"},"labels":[{"name":"size"}]}
 var serverURL string
 
+// checkRunRequestMethod stores the method of the last create/update check run request
+var checkRunRequestMethod string
+
 func TestClient_Init(t *testing.T) {
 	tc := map[string]struct {
 		expectErr      bool
@@ -257,17 +261,104 @@ func TestClient_ParseWebhook(t *testing.T) {
 			header := http.Header{}
 			header.Add("x-hub-signature", c.xHubSignature)
 			header.Add("x-github-event", string(c.event))
-			_, err = cli.ParseWebhook(header, c.jsonString)
+			header.Add("X-GitHub-Delivery", "test-delivery")
+			wh, err := cli.ParseWebhook(header, c.jsonString)
 
 			if c.expectedErr {
 				require.Contains(t, err.Error(), c.expectedErrMsg)
 			} else {
 				require.NoError(t, err)
+				if wh != nil {
+					require.Equal(t, "test-delivery", wh.DeliveryID)
+				}
 			}
 		})
 	}
 }
 
+func TestClient_parsePullRequestWebhook(t *testing.T) {
+	tc := map[string]struct {
+		action   string
+		draft    bool
+		merged   bool
+		mergeSha string
+
+		expectedAction   git.PullRequestAction
+		expectedMergeSha string
+	}{
+		"readyForReview": {
+			action:         "ready_for_review",
+			expectedAction: git.PullRequestActionReadyForReview,
+		},
+		"convertedToDraft": {
+			action:         "converted_to_draft",
+			draft:          true,
+			expectedAction: git.PullRequestActionConvertedToDraft,
+		},
+		"closed": {
+			action:         "closed",
+			expectedAction: git.PullRequestActionClose,
+		},
+		"merged": {
+			action:           "closed",
+			merged:           true,
+			mergeSha:         "merge-sha",
+			expectedAction:   git.PullRequestActionMerged,
+			expectedMergeSha: "merge-sha",
+		},
+	}
+
+	for name, c := range tc {
+		t.Run(name, func(t *testing.T) {
+			cli, err := testEnv()
+			require.NoError(t, err)
+
+			body := fmt.Sprintf(`{"action": "%s", "number": 3, "pull_request": {"draft": %t, "merged": %t, "merge_commit_sha": "%s", "base": {"ref": "master"}}, "repository": {"full_name": "tmax-cloud/cicd-test"}, "sender": {"login": "cqbqdd11519"}}`,
+				c.action, c.draft, c.merged, c.mergeSha)
+			wh, err := cli.parsePullRequestWebhook([]byte(body))
+			require.NoError(t, err)
+			require.Equal(t, git.EventTypePullRequest, wh.EventType)
+			require.Equal(t, c.expectedAction, wh.PullRequest.Action)
+			require.Equal(t, c.draft, wh.PullRequest.Draft)
+			require.Equal(t, c.expectedMergeSha, wh.PullRequest.MergeSha)
+		})
+	}
+}
+
+func TestClient_parseReleaseWebhook(t *testing.T) {
+	tc := map[string]struct {
+		action string
+
+		expectedNil bool
+	}{
+		"published": {
+			action: "published",
+		},
+		"created": {
+			action:      "created",
+			expectedNil: true,
+		},
+	}
+
+	for name, c := range tc {
+		t.Run(name, func(t *testing.T) {
+			cli, err := testEnv()
+			require.NoError(t, err)
+
+			body := fmt.Sprintf(`{"action": "%s", "release": {"tag_name": "v1.0.0", "name": "Release v1.0.0"}, "repository": {"full_name": "tmax-cloud/cicd-test"}, "sender": {"login": "cqbqdd11519"}}`, c.action)
+			wh, err := cli.parseReleaseWebhook([]byte(body))
+			require.NoError(t, err)
+			if c.expectedNil {
+				require.Nil(t, wh)
+				return
+			}
+			require.Equal(t, git.EventTypeRelease, wh.EventType)
+			require.Equal(t, &git.Release{Tag: "v1.0.0", Name: "Release v1.0.0", Sha: "3196ccc37bcae94852079b04fcbfaf928341d6e9"}, wh.Release)
+			require.Equal(t, "cqbqdd11519", wh.Sender.Name)
+		})
+	}
+}
+
 func TestClient_ListWebhook(t *testing.T) {
 	c, err := testEnv()
 	if err != nil {
@@ -346,6 +437,66 @@ func TestClient_SetCommitStatus(t *testing.T) {
 	}
 }
 
+func TestClient_ListCheckRuns(t *testing.T) {
+	c, err := testEnv()
+	require.NoError(t, err)
+
+	checkRuns, err := c.ListCheckRuns("3196ccc37bcae94852079b04fcbfaf928341d6e9")
+	require.NoError(t, err)
+	require.Len(t, checkRuns, 1)
+	require.Equal(t, "test-1", checkRuns[0].Name)
+	require.Equal(t, git.CheckRunStatusCompleted, checkRuns[0].Status)
+	require.Equal(t, git.CheckRunConclusionSuccess, checkRuns[0].Conclusion)
+	require.Equal(t, "Job succeeded", checkRuns[0].Title)
+	require.Equal(t, "https://report", checkRuns[0].DetailsURL)
+}
+
+func TestClient_SetCheckRun(t *testing.T) {
+	tc := map[string]struct {
+		sha      string
+		checkRun git.CheckRun
+
+		expectErr      bool
+		expectedErrMsg string
+		expectedMethod string
+	}{
+		"fakeSha": {
+			sha:      git.FakeSha,
+			checkRun: git.CheckRun{Name: "test-1"},
+		},
+		"create": {
+			sha:            "0000000000000000000000000000000000000002",
+			checkRun:       git.CheckRun{Name: "test-new", Status: git.CheckRunStatusInProgress},
+			expectedMethod: http.MethodPost,
+		},
+		"update": {
+			sha:            "0000000000000000000000000000000000000002",
+			checkRun:       git.CheckRun{Name: "test-1", Status: git.CheckRunStatusCompleted, Conclusion: git.CheckRunConclusionFailure},
+			expectedMethod: http.MethodPatch,
+		},
+		"errSha": {
+			sha:            git.ErrSha,
+			checkRun:       git.CheckRun{Name: "test-1"},
+			expectErr:      true,
+			expectedErrMsg: "doesn't exists",
+		},
+	}
+	for name, c := range tc {
+		t.Run(name, func(t *testing.T) {
+			cli, _ := testEnv()
+			checkRunRequestMethod = ""
+			err := cli.SetCheckRun(c.sha, c.checkRun)
+			if c.expectErr {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), c.expectedErrMsg)
+			} else {
+				require.NoError(t, err)
+				require.Equal(t, c.expectedMethod, checkRunRequestMethod)
+			}
+		})
+	}
+}
+
 func TestClient_GetUserInfo(t *testing.T) {
 	tc := map[string]struct {
 		userName string
@@ -557,6 +708,62 @@ func TestClient_GetPullRequestDiff(t *testing.T) {
 	require.Equal(t, 2, diff.Changes[2].Changes)
 }
 
+func TestClient_GetCommitDiff(t *testing.T) {
+	c, err := testEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	diff, err := c.GetCommitDiff("3196ccc37bcae94852079b04fcbfaf928341d6e9", "bfa929712952e60d5ad5d3b73376f6ba392f8b50")
+	require.NoError(t, err)
+	require.Len(t, diff.Changes, 3)
+	require.Equal(t, "Makefile", diff.Changes[0].Filename)
+	require.Equal(t, "config/release.yaml", diff.Changes[1].Filename)
+	require.Equal(t, "docs/installation.md", diff.Changes[2].Filename)
+}
+
+func TestClient_GetFileContent(t *testing.T) {
+	tc := map[string]struct {
+		path string
+		ref  string
+
+		expectedErrMsg  string
+		expectedContent string
+	}{
+		"success": {
+			path:            ".cicd/jobs.yaml",
+			ref:             "bfa929712952e60d5ad5d3b73376f6ba392f8b50",
+			expectedContent: "preSubmit:\n- name: test\n",
+		},
+		"notFound": {
+			path:           ".cicd/jobs.yaml",
+			ref:            "3196ccc37bcae94852079b04fcbfaf928341d6e9",
+			expectedErrMsg: "code 404",
+		},
+		"directory": {
+			path:           ".cicd",
+			ref:            "bfa929712952e60d5ad5d3b73376f6ba392f8b50",
+			expectedErrMsg: "cannot unmarshal array",
+		},
+	}
+
+	for name, c := range tc {
+		t.Run(name, func(t *testing.T) {
+			cli, err := testEnv()
+			require.NoError(t, err)
+
+			content, err := cli.GetFileContent(c.path, c.ref)
+			if c.expectedErrMsg != "" {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), c.expectedErrMsg)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, c.expectedContent, string(content))
+		})
+	}
+}
+
 func TestClient_ListPullRequestCommits(t *testing.T) {
 	c, err := testEnv()
 	if err != nil {
@@ -687,6 +894,7 @@ func testEnv() (*Client, error) {
 
 	setRouter(r)
 	setRouter2(r)
+	setRouterCheckRuns(r)
 	testSrv := httptest.NewServer(r)
 	serverURL = testSrv.URL
 
@@ -791,6 +999,34 @@ func setRouter(r *mux.Router) {
 	})
 }
 
+func setRouterCheckRuns(r *mux.Router) {
+	r.HandleFunc("/repos/{org}/{repo}/commits/{sha}/check-runs", func(w http.ResponseWriter, req *http.Request) {
+		if mux.Vars(req)["sha"] == git.ErrSha {
+			w.WriteHeader(http.StatusBadRequest)
+			j, _ := json.Marshal("doesn't exists")
+			_, _ = w.Write(j)
+			return
+		}
+		checkName := req.URL.Query().Get("check_name")
+		if checkName != "" && checkName != "test-1" {
+			_, _ = w.Write([]byte("{\"total_count\":0,\"check_runs\":[]}"))
+			return
+		}
+		_, _ = w.Write([]byte(sampleCheckRunsList))
+	}).Methods(http.MethodGet)
+	r.HandleFunc("/repos/{org}/{repo}/check-runs", func(w http.ResponseWriter, req *http.Request) {
+		body := &CheckRunRequest{}
+		if err := json.NewDecoder(req.Body).Decode(body); err != nil || body.HeadSha == "" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		checkRunRequestMethod = req.Method
+	}).Methods(http.MethodPost)
+	r.HandleFunc("/repos/{org}/{repo}/check-runs/4", func(w http.ResponseWriter, req *http.Request) {
+		checkRunRequestMethod = req.Method
+	}).Methods(http.MethodPatch)
+}
+
 func setRouter2(r *mux.Router) {
 	r.HandleFunc("/users/{username}", func(w http.ResponseWriter, req *http.Request) {
 		vars := mux.Vars(req)
@@ -833,6 +1069,23 @@ func setRouter2(r *mux.Router) {
 	r.HandleFunc("/repos/{org}/{repo}/pulls/{id}/files", func(w http.ResponseWriter, req *http.Request) {
 		_, _ = w.Write([]byte(samplePRFiles))
 	})
+	r.HandleFunc("/repos/{org}/{repo}/compare/3196ccc37bcae94852079b04fcbfaf928341d6e9...bfa929712952e60d5ad5d3b73376f6ba392f8b50", func(w http.ResponseWriter, req *http.Request) {
+		_, _ = w.Write([]byte("{\"files\":" + samplePRFiles + "}"))
+	})
+	r.HandleFunc("/repos/{org}/{repo}/contents/{path:.+}", func(w http.ResponseWriter, req *http.Request) {
+		switch mux.Vars(req)["path"] {
+		case ".cicd/jobs.yaml":
+			if req.URL.Query().Get("ref") != "bfa929712952e60d5ad5d3b73376f6ba392f8b50" {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			_, _ = w.Write([]byte("{\"type\":\"file\",\"encoding\":\"base64\",\"content\":\"cHJlU3VibWl0Ogot\\nIG5hbWU6IHRlc3QK\\n\"}"))
+		case ".cicd":
+			_, _ = w.Write([]byte("[{\"type\":\"file\"}]"))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
 	r.HandleFunc("/repos/{org}/{repo}/pulls/{id}/commits", func(w http.ResponseWriter, req *http.Request) {
 		_, _ = w.Write([]byte(samplePRCommits))
 	})
@@ -877,6 +1130,9 @@ func setRouter2(r *mux.Router) {
 	r.HandleFunc("/repos/{org}/{repo}/commits/{id}/comments", func(w http.ResponseWriter, req *http.Request) {
 		_, _ = w.Write([]byte(sampleIssueComments))
 	})
+	r.HandleFunc("/repos/{org}/{repo}/commits/{ref}", func(w http.ResponseWriter, req *http.Request) {
+		_, _ = w.Write([]byte(`{"sha": "3196ccc37bcae94852079b04fcbfaf928341d6e9"}`))
+	})
 	r.HandleFunc("/repos/{org}/{repo}/branches/{branch}", func(w http.ResponseWriter, req *http.Request) {
 		vars := mux.Vars(req)
 		branch := vars["branch"]
@@ -49,6 +49,42 @@ type CommitStatusResponse struct {
 	TargetURL   string `json:"target_url"`
 }
 
+// CheckRunRequest is an API body for creating/updating a check run
+type CheckRunRequest struct {
+	Name        string         `json:"name"`
+	HeadSha     string         `json:"head_sha,omitempty"`
+	Status      string         `json:"status"`
+	Conclusion  string         `json:"conclusion,omitempty"`
+	DetailsURL  string         `json:"details_url,omitempty"`
+	StartedAt   *v1.Time       `json:"started_at,omitempty"`
+	CompletedAt *v1.Time       `json:"completed_at,omitempty"`
+	Output      CheckRunOutput `json:"output"`
+}
+
+// CheckRunOutput is an output of a check run
+type CheckRunOutput struct {
+	Title   string `json:"title"`
+	Summary string `json:"summary"`
+}
+
+// CheckRunResponse is a response body of getting a check run
+type CheckRunResponse struct {
+	ID          int            `json:"id"`
+	Name        string         `json:"name"`
+	Status      string         `json:"status"`
+	Conclusion  string         `json:"conclusion"`
+	DetailsURL  string         `json:"details_url"`
+	StartedAt   *v1.Time       `json:"started_at"`
+	CompletedAt *v1.Time       `json:"completed_at"`
+	Output      CheckRunOutput `json:"output"`
+}
+
+// CheckRunListResponse is a response body of listing check runs
+type CheckRunListResponse struct {
+	TotalCount int                `json:"total_count"`
+	CheckRuns  []CheckRunResponse `json:"check_runs"`
+}
+
 // CommentBody is a body structure for creating new comment
 type CommentBody struct {
 	Body string `json:"body"`
@@ -87,6 +123,18 @@ type DiffFile struct {
 	Changes      int    `json:"changes"`
 }
 
+// CompareResponse is a response of comparing two commits
+type CompareResponse struct {
+	Files DiffFiles `json:"files"`
+}
+
+// ContentResponse is a response of getting a file content
+type ContentResponse struct {
+	Type     string `json:"type"`
+	Encoding string `json:"encoding"`
+	Content  string `json:"content"`
+}
+
 // CommitResponse is a commits list response
 type CommitResponse struct {
 	SHA    string `json:"sha"`
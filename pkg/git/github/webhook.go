@@ -38,6 +38,18 @@ type PushWebhook struct {
 	Repo   Repo   `json:"repository"`
 	Sender User   `json:"sender"`
 	Sha    string `json:"after"`
+	Before string `json:"before"`
+}
+
+// ReleaseWebhook is a github-specific release event webhook body
+type ReleaseWebhook struct {
+	Action  string `json:"action"`
+	Release struct {
+		TagName string `json:"tag_name"`
+		Name    string `json:"name"`
+	} `json:"release"`
+	Repo   Repo `json:"repository"`
+	Sender User `json:"sender"`
 }
 
 // IssueCommentWebhook is a github-specific issue_comment webhook body
@@ -103,6 +115,8 @@ type PullRequest struct {
 	Mergeable bool   `json:"mergeable"`
 	User      User   `json:"user"`
 	Draft     bool   `json:"draft"`
+	Merged    bool   `json:"merged"`
+	MergeSha  string `json:"merge_commit_sha"`
 	Head      struct {
 		Ref string `json:"ref"`
 		Sha string `json:"sha"`
@@ -0,0 +1,175 @@
+/*
+ Copyright 2021 The CI/CD Operator Authors
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package githubapp
+
+// Githubapp issues installation access tokens of GitHub Apps
+// (ref: https://docs.github.com/en/developers/apps/building-github-apps/authenticating-with-github-apps)
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tmax-cloud/cicd-operator/pkg/git"
+)
+
+const (
+	// jwtLifetime is a lifetime of the JWT for the app. GitHub allows up to 10 minutes
+	jwtLifetime = 9 * time.Minute
+	// jwtClockDrift is subtracted from the issued time of the JWT, to allow clock drift
+	jwtClockDrift = time.Minute
+
+	// RefreshMargin is a margin before the expiry of an installation token.
+	// A cached token is refreshed if it expires within the margin
+	RefreshMargin = 10 * time.Minute
+)
+
+// installationToken is an installation access token with its expiry
+type installationToken struct {
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// cachedToken is a cached token of an installation. Its lock is held while the token is refreshed, so the token of an
+// installation is issued once at a time, without blocking the other installations
+type cachedToken struct {
+	lock  sync.Mutex
+	token *installationToken
+}
+
+var (
+	tokenCache     = map[string]*cachedToken{}
+	tokenCacheLock sync.Mutex
+
+	now = time.Now
+)
+
+// GetInstallationToken returns an installation access token of a GitHub App's installation.
+// Tokens are cached until they are about to expire, and refreshed transparently after then
+func GetInstallationToken(apiURL string, appID, installationID int64, privateKey []byte, tlsConfig *tls.Config) (string, error) {
+	cached := getCachedToken(fmt.Sprintf("%s/%d/%d", apiURL, appID, installationID))
+
+	cached.lock.Lock()
+	defer cached.lock.Unlock()
+
+	if cached.token != nil && now().Add(RefreshMargin).Before(cached.token.ExpiresAt) {
+		return cached.token.Token, nil
+	}
+
+	jwt, err := generateJWT(appID, privateKey)
+	if err != nil {
+		return "", err
+	}
+
+	header := map[string]string{
+		"Accept":        "application/vnd.github.v3+json",
+		"Authorization": "Bearer " + jwt,
+	}
+	apiURL = fmt.Sprintf("%s/app/installations/%d/access_tokens", strings.TrimSuffix(apiURL, "/"), installationID)
+	data, _, err := git.RequestHTTP(context.Background(), http.MethodPost, apiURL, header, nil, tlsConfig)
+	if err != nil {
+		return "", err
+	}
+
+	tok := &installationToken{}
+	if err := json.Unmarshal(data, tok); err != nil {
+		return "", err
+	}
+	if tok.Token == "" {
+		return "", fmt.Errorf("installation token is empty")
+	}
+
+	cached.token = tok
+	return tok.Token, nil
+}
+
+// getCachedToken returns the cached token of the installation, creating an empty one if it does not exist
+func getCachedToken(key string) *cachedToken {
+	tokenCacheLock.Lock()
+	defer tokenCacheLock.Unlock()
+
+	cached, exist := tokenCache[key]
+	if !exist {
+		cached = &cachedToken{}
+		tokenCache[key] = cached
+	}
+	return cached
+}
+
+// generateJWT generates a JWT signed by the app's private key (RS256)
+func generateJWT(appID int64, privateKey []byte) (string, error) {
+	key, err := parsePrivateKey(privateKey)
+	if err != nil {
+		return "", err
+	}
+
+	header, err := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT"})
+	if err != nil {
+		return "", err
+	}
+	issuedAt := now().Add(-jwtClockDrift)
+	claims, err := json.Marshal(map[string]int64{
+		"iat": issuedAt.Unix(),
+		"exp": issuedAt.Add(jwtLifetime).Unix(),
+		"iss": appID,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	unsigned := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+	hashed := sha256.Sum256([]byte(unsigned))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, hashed[:])
+	if err != nil {
+		return "", err
+	}
+
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(sig), nil
+}
+
+// parsePrivateKey parses PEM-encoded RSA private key (PKCS#1 or PKCS#8)
+func parsePrivateKey(privateKey []byte) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode(privateKey)
+	if block == nil {
+		return nil, fmt.Errorf("private key is not PEM-encoded")
+	}
+
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("private key is not an RSA key")
+	}
+	return key, nil
+}
@@ -0,0 +1,205 @@
+/*
+ Copyright 2021 The CI/CD Operator Authors
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package githubapp
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testAppID          = 1234
+	testInstallationID = 5678
+)
+
+func testKeys(t *testing.T) (*rsa.PrivateKey, []byte, []byte) {
+	key, err := rsa.GenerateKey(rand.Reader, 1024)
+	require.NoError(t, err)
+
+	pkcs1 := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	pkcs8Bytes, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+	pkcs8 := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: pkcs8Bytes})
+	return key, pkcs1, pkcs8
+}
+
+// verifyJWT verifies the JWT and returns its claims
+func verifyJWT(t *testing.T, jwt string, key *rsa.PublicKey) map[string]int64 {
+	tokens := strings.Split(jwt, ".")
+	require.Len(t, tokens, 3)
+
+	sig, err := base64.RawURLEncoding.DecodeString(tokens[2])
+	require.NoError(t, err)
+	hashed := sha256.Sum256([]byte(tokens[0] + "." + tokens[1]))
+	require.NoError(t, rsa.VerifyPKCS1v15(key, crypto.SHA256, hashed[:], sig))
+
+	claimBytes, err := base64.RawURLEncoding.DecodeString(tokens[1])
+	require.NoError(t, err)
+	claims := map[string]int64{}
+	require.NoError(t, json.Unmarshal(claimBytes, &claims))
+	return claims
+}
+
+func TestGetInstallationToken(t *testing.T) {
+	key, pkcs1, pkcs8 := testKeys(t)
+	fixedNow := time.Date(2021, 10, 1, 12, 0, 0, 0, time.UTC)
+
+	tc := map[string]struct {
+		privateKey []byte
+		cached     *installationToken
+
+		errorOccurs    bool
+		errorMessage   string
+		expectedToken  string
+		expectedIssued bool
+	}{
+		"pkcs1": {
+			privateKey:     pkcs1,
+			expectedToken:  "ghs_issued",
+			expectedIssued: true,
+		},
+		"pkcs8": {
+			privateKey:     pkcs8,
+			expectedToken:  "ghs_issued",
+			expectedIssued: true,
+		},
+		"cached": {
+			privateKey:    pkcs1,
+			cached:        &installationToken{Token: "ghs_cached", ExpiresAt: fixedNow.Add(30 * time.Minute)},
+			expectedToken: "ghs_cached",
+		},
+		"cachedExpiring": {
+			privateKey:     pkcs1,
+			cached:         &installationToken{Token: "ghs_cached", ExpiresAt: fixedNow.Add(5 * time.Minute)},
+			expectedToken:  "ghs_issued",
+			expectedIssued: true,
+		},
+		"notPEM": {
+			privateKey:   []byte("not-a-key"),
+			errorOccurs:  true,
+			errorMessage: "private key is not PEM-encoded",
+		},
+	}
+
+	for name, c := range tc {
+		t.Run(name, func(t *testing.T) {
+			issued := false
+			r := mux.NewRouter()
+			r.HandleFunc(fmt.Sprintf("/app/installations/%d/access_tokens", testInstallationID), func(w http.ResponseWriter, req *http.Request) {
+				require.Equal(t, http.MethodPost, req.Method)
+				claims := verifyJWT(t, strings.TrimPrefix(req.Header.Get("Authorization"), "Bearer "), &key.PublicKey)
+				require.Equal(t, int64(testAppID), claims["iss"])
+				require.Equal(t, fixedNow.Add(-time.Minute).Unix(), claims["iat"])
+				require.Equal(t, fixedNow.Add(8*time.Minute).Unix(), claims["exp"])
+
+				issued = true
+				_, _ = w.Write([]byte(`{"token": "ghs_issued", "expires_at": "2021-10-01T13:00:00Z"}`))
+			})
+			srv := httptest.NewServer(r)
+			defer srv.Close()
+
+			now = func() time.Time { return fixedNow }
+			defer func() { now = time.Now }()
+
+			tokenCache = map[string]*cachedToken{}
+			if c.cached != nil {
+				tokenCache[fmt.Sprintf("%s/%d/%d", srv.URL, testAppID, testInstallationID)] = &cachedToken{token: c.cached}
+			}
+
+			tok, err := GetInstallationToken(srv.URL, testAppID, testInstallationID, c.privateKey, nil)
+			if c.errorOccurs {
+				require.Error(t, err)
+				require.Equal(t, c.errorMessage, err.Error())
+			} else {
+				require.NoError(t, err)
+				require.Equal(t, c.expectedToken, tok)
+				require.Equal(t, c.expectedIssued, issued)
+			}
+		})
+	}
+}
+
+func TestGetInstallationToken_error(t *testing.T) {
+	_, pkcs1, _ := testKeys(t)
+
+	r := mux.NewRouter()
+	r.HandleFunc(fmt.Sprintf("/app/installations/%d/access_tokens", testInstallationID), func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		_, _ = w.Write([]byte(`{"message": "Bad credentials"}`))
+	})
+	srv := httptest.NewServer(r)
+	defer srv.Close()
+
+	tokenCache = map[string]*cachedToken{}
+	_, err := GetInstallationToken(srv.URL, testAppID, testInstallationID, pkcs1, nil)
+	require.Error(t, err)
+	require.Nil(t, tokenCache[fmt.Sprintf("%s/%d/%d", srv.URL, testAppID, testInstallationID)].token)
+}
+
+func TestGetInstallationToken_notBlocking(t *testing.T) {
+	_, pkcs1, _ := testKeys(t)
+
+	// Issuing the token of the slow installation blocks until it's released
+	const slowInstallationID = 2
+	release := make(chan struct{})
+	r := mux.NewRouter()
+	r.HandleFunc(fmt.Sprintf("/app/installations/%d/access_tokens", slowInstallationID), func(w http.ResponseWriter, _ *http.Request) {
+		<-release
+		_, _ = w.Write([]byte(`{"token": "ghs_slow", "expires_at": "2099-10-01T13:00:00Z"}`))
+	})
+	r.HandleFunc(fmt.Sprintf("/app/installations/%d/access_tokens", testInstallationID), func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"token": "ghs_issued", "expires_at": "2099-10-01T13:00:00Z"}`))
+	})
+	srv := httptest.NewServer(r)
+	defer srv.Close()
+
+	tokenCache = map[string]*cachedToken{}
+	slowDone := make(chan string)
+	go func() {
+		tok, _ := GetInstallationToken(srv.URL, testAppID, slowInstallationID, pkcs1, nil)
+		slowDone <- tok
+	}()
+
+	// Wait until the slow installation's token is being issued
+	require.Eventually(t, func() bool {
+		tokenCacheLock.Lock()
+		defer tokenCacheLock.Unlock()
+		return len(tokenCache) == 1
+	}, time.Second, 10*time.Millisecond)
+
+	tok, err := GetInstallationToken(srv.URL, testAppID, testInstallationID, pkcs1, nil)
+	require.NoError(t, err)
+	require.Equal(t, "ghs_issued", tok)
+
+	close(release)
+	require.Equal(t, "ghs_slow", <-slowDone)
+}
@@ -17,6 +17,7 @@
 package gitlab
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -34,6 +35,9 @@ type Client struct {
 	IntegrationConfig *cicdv1.IntegrationConfig
 	K8sClient         client.Client
 
+	// Context is a context of the API calls, which carries the caller's trace
+	Context context.Context
+
 	header map[string]string
 }
 
@@ -55,7 +59,15 @@ func (c *Client) Init() error {
 
 // ParseWebhook parses a webhook body for gitlab
 func (c *Client) ParseWebhook(header http.Header, jsonString []byte) (*git.Webhook, error) {
-	if err := Validate(c.IntegrationConfig.Status.Secrets, header.Get("x-gitlab-token")); err != nil {
+	wh, err := c.parseWebhook(header, jsonString)
+	if wh != nil {
+		wh.DeliveryID = header.Get("X-Gitlab-Event-UUID")
+	}
+	return wh, err
+}
+
+func (c *Client) parseWebhook(header http.Header, jsonString []byte) (*git.Webhook, error) {
+	if err := Validate(c.IntegrationConfig.GetWebhookSecrets(), header.Get("x-gitlab-token")); err != nil {
 		return nil, err
 	}
 
@@ -65,6 +77,8 @@ func (c *Client) ParseWebhook(header http.Header, jsonString []byte) (*git.Webho
 		return c.parsePullRequestWebhook(jsonString)
 	case "Push Hook", "Tag Push Hook":
 		return c.parsePushWebhook(jsonString)
+	case "Release Hook":
+		return c.parseReleaseWebhook(jsonString)
 	case "Note Hook":
 		return c.parseIssueComment(jsonString)
 	}
@@ -80,7 +94,7 @@ func (c *Client) ListWebhook() ([]git.WebhookEntry, error) {
 	var entries []WebhookEntry
 	tlsConfig := c.IntegrationConfig.GetTLSConfig()
 
-	err := git.GetPaginatedRequest(apiURL, tlsConfig, c.header, func() interface{} {
+	err := git.GetPaginatedRequest(c.Context, apiURL, tlsConfig, c.header, func() interface{} {
 		return &[]WebhookEntry{}
 	}, func(i interface{}) {
 		entries = append(entries, *i.(*[]WebhookEntry)...)
@@ -114,6 +128,7 @@ func (c *Client) RegisterWebhook(uri string) error {
 	registrationBody.NoteEvents = true
 	registrationBody.PipeLineEvents = true
 	registrationBody.PushEvents = true
+	registrationBody.ReleasesEvents = true
 	registrationBody.TagPushEvents = true
 	registrationBody.WikiPageEvents = true
 	registrationBody.URL = uri
@@ -147,7 +162,7 @@ func (c *Client) ListCommitStatuses(ref string) ([]git.CommitStatus, error) {
 	var statuses []CommitStatusResponse
 	tlsConfig := c.IntegrationConfig.GetTLSConfig()
 
-	err := git.GetPaginatedRequest(apiURL, tlsConfig, c.header, func() interface{} {
+	err := git.GetPaginatedRequest(c.Context, apiURL, tlsConfig, c.header, func() interface{} {
 		return &[]CommitStatusResponse{}
 	}, func(i interface{}) {
 		statuses = append(statuses, *i.(*[]CommitStatusResponse)...)
@@ -311,7 +326,7 @@ func (c *Client) ListPullRequests(onlyOpen bool) ([]git.PullRequest, error) {
 	var mrs []MergeRequest
 	tlsConfig := c.IntegrationConfig.GetTLSConfig()
 
-	err := git.GetPaginatedRequest(apiURL, tlsConfig, c.header, func() interface{} {
+	err := git.GetPaginatedRequest(c.Context, apiURL, tlsConfig, c.header, func() interface{} {
 		return &[]MergeRequest{}
 	}, func(i interface{}) {
 		mrs = append(mrs, *i.(*[]MergeRequest)...)
@@ -334,6 +349,7 @@ func (c *Client) ListPullRequests(onlyOpen bool) ([]git.PullRequest, error) {
 			Base:   git.Base{Ref: mr.TargetBranch},
 			Head:   git.Head{Ref: mr.SourceBranch, Sha: mr.SHA},
 			Labels: convertLabel(mr.Labels),
+			Draft:  mr.Draft || mr.WorkInProgress,
 		})
 	}
 
@@ -373,6 +389,7 @@ func (c *Client) GetPullRequest(id int) (*git.PullRequest, error) {
 		Head:      git.Head{Ref: mr.SourceBranch, Sha: mr.SHA},
 		Labels:    convertLabel(mr.Labels),
 		Mergeable: !mr.HasConflicts,
+		Draft:     mr.Draft || mr.WorkInProgress,
 	}, nil
 }
 
@@ -414,23 +431,7 @@ func (c *Client) GetPullRequestDiff(id int) (*git.Diff, error) {
 		return nil, err
 	}
 
-	var changes []git.Change
-	for _, d := range rawDiff.Changes {
-		additions, deletions, err := git.GetChangedLinesFromDiff(d.Diff)
-		if err != nil {
-			return nil, err
-		}
-
-		changes = append(changes, git.Change{
-			Filename:    d.NewPath,
-			OldFilename: d.OldPath,
-			Additions:   additions,
-			Deletions:   deletions,
-			Changes:     additions + deletions,
-		})
-	}
-
-	return &git.Diff{Changes: changes}, nil
+	return convertFileDiffsToShared(rawDiff.Changes)
 }
 
 // ListPullRequestCommits lists commits list of a pull request
@@ -466,6 +467,35 @@ func (c *Client) ListPullRequestCommits(id int) ([]git.Commit, error) {
 	return commits, nil
 }
 
+// GetCommitDiff gets diff between the base and head commits
+func (c *Client) GetCommitDiff(base, head string) (*git.Diff, error) {
+	apiURL := fmt.Sprintf("%s/api/v4/projects/%s/repository/compare?from=%s&to=%s", c.IntegrationConfig.Spec.Git.GetAPIUrl(), url.QueryEscape(c.IntegrationConfig.Spec.Git.Repository), url.QueryEscape(base), url.QueryEscape(head))
+
+	result, _, err := c.requestHTTP(http.MethodGet, apiURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	rawDiff := &CompareResponse{}
+	if err := json.Unmarshal(result, rawDiff); err != nil {
+		return nil, err
+	}
+
+	return convertFileDiffsToShared(rawDiff.Diffs)
+}
+
+// GetFileContent gets the content of the file at the ref
+func (c *Client) GetFileContent(path, ref string) ([]byte, error) {
+	apiURL := fmt.Sprintf("%s/api/v4/projects/%s/repository/files/%s/raw?ref=%s", c.IntegrationConfig.Spec.Git.GetAPIUrl(), url.QueryEscape(c.IntegrationConfig.Spec.Git.Repository), url.PathEscape(strings.TrimPrefix(path, "/")), url.QueryEscape(ref))
+
+	result, _, err := c.requestHTTP(http.MethodGet, apiURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
 // SetLabel sets label to the issue id
 func (c *Client) SetLabel(issueType git.IssueType, id int, label string) error {
 	var t string
@@ -547,10 +577,30 @@ func (c *Client) GetBranch(branch string) (*git.Branch, error) {
 	return &git.Branch{Name: resp.Name, CommitID: resp.Commit.ID}, nil
 }
 
+func convertFileDiffsToShared(diffs []FileDiff) (*git.Diff, error) {
+	var changes []git.Change
+	for _, d := range diffs {
+		additions, deletions, err := git.GetChangedLinesFromDiff(d.Diff)
+		if err != nil {
+			return nil, err
+		}
+
+		changes = append(changes, git.Change{
+			Filename:    d.NewPath,
+			OldFilename: d.OldPath,
+			Additions:   additions,
+			Deletions:   deletions,
+			Changes:     additions + deletions,
+		})
+	}
+
+	return &git.Diff{Changes: changes}, nil
+}
+
 func (c *Client) requestHTTP(method, apiURL string, data interface{}) ([]byte, http.Header, error) {
 	tlsConfig := c.IntegrationConfig.GetTLSConfig()
 
-	return git.RequestHTTP(method, apiURL, c.header, data, tlsConfig)
+	return git.RequestHTTP(c.Context, method, apiURL, c.header, data, tlsConfig)
 }
 
 func convertState(original string) git.PullRequestState {
@@ -572,10 +622,12 @@ func convertLabel(original []string) []git.IssueLabel {
 	return labels
 }
 
-// Validate validates the webhook payload
-func Validate(secret, headerToken string) error {
-	if secret != headerToken {
-		return fmt.Errorf("invalid request : X-Gitlab-Token does not match secret")
+// Validate validates the webhook token, which should be any of the secrets
+func Validate(secrets []string, headerToken string) error {
+	for _, secret := range secrets {
+		if secret == headerToken {
+			return nil
+		}
 	}
-	return nil
+	return fmt.Errorf("invalid request : X-Gitlab-Token does not match secret")
 }
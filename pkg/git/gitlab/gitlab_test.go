@@ -23,6 +23,7 @@ import (
 	"github.com/gorilla/mux"
 	"github.com/stretchr/testify/require"
 	cicdv1 "github.com/tmax-cloud/cicd-operator/api/v1"
+	"github.com/tmax-cloud/cicd-operator/pkg/git"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
@@ -40,6 +41,7 @@ const (
 	sampleMRCommits    = "[\n    {\n        \"id\":\"5f065c6de7dacb91aa5929a5c0ab71ecba5456b0\",\n        \"created_at\":\"2021-04-12T05:07:48.000Z\",\n        \"title\":\"Update index.html\",\n        \"message\":\"Update index.html\",\n        \"author_name\":\"Sunghyun Kim\",\n        \"author_email\":\"cqbqdd11519@gmail.com\",\n        \"authored_date\":\"2021-04-12T05:07:48.000Z\",\n        \"committer_name\":\"Sunghyun Kim\",\n        \"committer_email\":\"cqbqdd11519@gmail.com\",\n        \"committed_date\":\"2021-04-12T05:07:48.000Z\"\n    },\n    {\n        \"id\":\"dace98c2d0437f6ccacd8b9c8094f4dde9162214\",\n        \"created_at\":\"2021-04-12T05:04:54.000Z\",\n        \"title\":\"Update index.html\",\n        \"message\":\"Update index.html\",\n        \"author_name\":\"Sunghyun Kim\",\n        \"author_email\":\"cqbqdd11519@gmail.com\",\n        \"authored_date\":\"2021-04-12T05:04:54.000Z\",\n        \"committer_name\":\"Sunghyun Kim\",\n        \"committer_email\":\"cqbqdd11519@gmail.com\",\n        \"committed_date\":\"2021-04-12T05:04:54.000Z\"\n    },\n    {\n        \"id\":\"e703f64f722f33c4fbb1f326aed08edc81053b0b\",\n        \"created_at\":\"2021-04-12T04:50:34.000Z\",\n        \"title\":\"Update index.html\",\n        \"message\":\"Update index.html\",\n        \"author_name\":\"Sunghyun Kim\",\n        \"author_email\":\"cqbqdd11519@gmail.com\",\n        \"authored_date\":\"2021-04-12T04:50:34.000Z\",\n        \"committer_name\":\"Sunghyun Kim\",\n        \"committer_email\":\"cqbqdd11519@gmail.com\",\n        \"committed_date\":\"2021-04-12T04:50:34.000Z\"\n    },\n    {\n        \"id\":\"3196ccc37bcae94852079b04fcbfaf928341d6e9\",\n        \"created_at\":\"2021-01-22T03:25:50.000Z\",\n        \"title\":\"newnew\",\n        \"message\":\"newnew\\n\",\n        \"author_name\":\"Sunghyun Kim\",\n        \"author_email\":\"cqbqdd11519@gmail.com\",\n        \"authored_date\":\"2021-01-22T03:25:50.000Z\",\n        \"committer_name\":\"Sunghyun Kim\",\n        \"committer_email\":\"cqbqdd11519@gmail.com\",\n        \"committed_date\":\"2021-01-22T03:25:50.000Z\"\n    }\n]"
 	sampleMR           = "{\"id\":133148669,\"iid\":1,\"project_id\":31228574,\"title\":\"Child directory test\",\"description\":\"\",\"state\":\"opened\",\"created_at\":\"2021-12-30T06:58:09.077Z\",\"updated_at\":\"2021-12-30T07:18:33.391Z\",\"merged_by\":null,\"merged_at\":null,\"closed_by\":null,\"closed_at\":null,\"target_branch\":\"main\",\"source_branch\":\"child-directory-test\",\"user_notes_count\":1,\"upvotes\":0,\"downvotes\":0,\"author\":{\"id\":10192010,\"username\":\"changjjjjjjj\",\"name\":\"Changju Kim\",\"state\":\"active\",\"avatar_url\":\"https://secure.gravatar.com/avatar/c9995fef2d5a47e133b9461fea8cf3d3?s=80\\u0026d=identicon\",\"web_url\":\"https://gitlab.com/changjjjjjjj\"},\"assignees\":[],\"assignee\":null,\"reviewers\":[],\"source_project_id\":31228574,\"target_project_id\":31228574,\"labels\":[\"approved\"],\"draft\":false,\"work_in_progress\":false,\"milestone\":null,\"merge_when_pipeline_succeeds\":false,\"merge_status\":\"can_be_merged\",\"sha\":\"d84e251bf2d84b74e2e5161bcf693cdbb7130f23\",\"merge_commit_sha\":null,\"squash_commit_sha\":null,\"discussion_locked\":null,\"should_remove_source_branch\":null,\"force_remove_source_branch\":true,\"reference\":\"!1\",\"references\":{\"short\":\"!1\",\"relative\":\"!1\",\"full\":\"changjjjjjjj/cd-example-apps!1\"},\"web_url\":\"https://gitlab.com/changjjjjjjj/cd-example-apps/-/merge_requests/1\",\"time_stats\":{\"time_estimate\":0,\"total_time_spent\":0,\"human_time_estimate\":null,\"human_total_time_spent\":null},\"squash\":false,\"task_completion_status\":{\"count\":0,\"completed_count\":0},\"has_conflicts\":false,\"blocking_discussions_resolved\":true,\"approvals_before_merge\":null,\"subscribed\":true,\"changes_count\":\"2\",\"latest_build_started_at\":null,\"latest_build_finished_at\":null,\"first_deployed_to_production_at\":null,\"pipeline\":null,\"head_pipeline\":null,\"diff_refs\":{\"base_sha\":\"e1eb6f3829eee63f55e77fdf6cf2b332d3a91ae0\",\"head_sha\":\"d84e251bf2d84b74e2e5161bcf693cdbb7130f23\",\"start_sha\":\"c37271972e2bb9fe7ada89e2e7ae7045da4fffcb\"},\"merge_error\":null,\"first_contribution\":false,\"user\":{\"can_merge\":true}}"
 	sampleMRNotes      = "[{\"id\":797962489,\"type\":null,\"body\":\"test\",\"attachment\":null,\"author\":{\"id\":10192010,\"username\":\"changjjjjjjj\",\"name\":\"Changju Kim\",\"state\":\"active\",\"avatar_url\":\"https://secure.gravatar.com/avatar/c9995fef2d5a47e133b9461fea8cf3d3?s=80\\u0026d=identicon\",\"web_url\":\"https://gitlab.com/changjjjjjjj\"},\"created_at\":\"2021-12-30T06:58:52.936Z\",\"updated_at\":\"2021-12-30T06:58:52.936Z\",\"system\":false,\"noteable_id\":133148669,\"noteable_type\":\"MergeRequest\",\"resolvable\":false,\"confidential\":false,\"noteable_iid\":1,\"commands_changes\":{}}]"
+	sampleCompare      = `{"commits": [], "diffs": [{"old_path": "src/main/webapp/index.html", "new_path": "src/main/webapp/index.html", "diff": "@@ -1,3 +1,3 @@\n <html>\n-<p>old</p>\n+<p>new</p>\n </html>\n"}, {"old_path": "docs/old.md", "new_path": "docs/renamed.md", "diff": ""}]}`
 )
 
 var serverURL string
@@ -137,6 +139,34 @@ func TestClient_GetPullRequestDiff(t *testing.T) {
 	require.Equal(t, 2, diff.Changes[0].Changes)
 }
 
+func TestClient_GetCommitDiff(t *testing.T) {
+	c, err := testEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	diff, err := c.GetCommitDiff("0b4bc9a49b562e85de7cc9e834518ea6828729b9", "5f065c6de7dacb91aa5929a5c0ab71ecba5456b0")
+	require.NoError(t, err)
+	require.Equal(t, []git.Change{
+		{Filename: "src/main/webapp/index.html", OldFilename: "src/main/webapp/index.html", Additions: 1, Deletions: 1, Changes: 2},
+		{Filename: "docs/renamed.md", OldFilename: "docs/old.md"},
+	}, diff.Changes)
+}
+
+func TestClient_GetFileContent(t *testing.T) {
+	c, err := testEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	content, err := c.GetFileContent(".cicd/jobs.yaml", "5f065c6de7dacb91aa5929a5c0ab71ecba5456b0")
+	require.NoError(t, err)
+	require.Equal(t, "preSubmit:\n- name: test\n", string(content))
+
+	_, err = c.GetFileContent(".cicd/jobs.yaml", "0b4bc9a49b562e85de7cc9e834518ea6828729b9")
+	require.Error(t, err)
+}
+
 func TestClient_ListComments(t *testing.T) {
 	c, err := testEnv()
 	if err != nil {
@@ -195,6 +225,20 @@ func testEnv() (*Client, error) {
 	r.HandleFunc("/api/v4/projects/{org}/{repo}/merge_requests/{iid}/changes", func(w http.ResponseWriter, req *http.Request) {
 		_, _ = w.Write([]byte(sampleMRChange))
 	})
+	r.HandleFunc("/api/v4/projects/{org}/{repo}/repository/compare", func(w http.ResponseWriter, req *http.Request) {
+		if req.URL.Query().Get("from") != "0b4bc9a49b562e85de7cc9e834518ea6828729b9" || req.URL.Query().Get("to") != "5f065c6de7dacb91aa5929a5c0ab71ecba5456b0" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		_, _ = w.Write([]byte(sampleCompare))
+	})
+	r.HandleFunc("/api/v4/projects/{org}/{repo}/repository/files/{path:.+}/raw", func(w http.ResponseWriter, req *http.Request) {
+		if mux.Vars(req)["path"] != ".cicd/jobs.yaml" || req.URL.Query().Get("ref") != "5f065c6de7dacb91aa5929a5c0ab71ecba5456b0" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte("preSubmit:\n- name: test\n"))
+	})
 	r.HandleFunc("/api/v4/projects/{org}/{repo}/merge_requests/{iid}/commits", func(w http.ResponseWriter, req *http.Request) {
 		_, _ = w.Write([]byte(sampleMRCommits))
 	})
@@ -236,3 +280,36 @@ func testEnv() (*Client, error) {
 
 	return c, nil
 }
+
+func TestClient_parseReleaseWebhook(t *testing.T) {
+	tc := map[string]struct {
+		action string
+
+		expectedNil bool
+	}{
+		"create": {
+			action: "create",
+		},
+		"update": {
+			action:      "update",
+			expectedNil: true,
+		},
+	}
+
+	for name, c := range tc {
+		t.Run(name, func(t *testing.T) {
+			cli, err := testEnv()
+			require.NoError(t, err)
+
+			body := fmt.Sprintf(`{"object_kind": "release", "action": "%s", "tag": "v1.0.0", "name": "Release v1.0.0", "project": {"path_with_namespace": "tmax-cloud/cicd-test"}, "commit": {"id": "3196ccc37bcae94852079b04fcbfaf928341d6e9"}}`, c.action)
+			wh, err := cli.parseReleaseWebhook([]byte(body))
+			require.NoError(t, err)
+			if c.expectedNil {
+				require.Nil(t, wh)
+				return
+			}
+			require.Equal(t, git.EventTypeRelease, wh.EventType)
+			require.Equal(t, &git.Release{Tag: "v1.0.0", Name: "Release v1.0.0", Sha: "3196ccc37bcae94852079b04fcbfaf928341d6e9"}, wh.Release)
+		})
+	}
+}
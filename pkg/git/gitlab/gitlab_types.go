@@ -78,6 +78,9 @@ type MergeRequest struct {
 	SHA          string   `json:"sha"`
 	Labels       []string `json:"labels"`
 	HasConflicts bool     `json:"has_conflicts"`
+
+	Draft          bool `json:"draft"`
+	WorkInProgress bool `json:"work_in_progress"`
 }
 
 // BranchResponse is a respond struct for branch request
@@ -100,11 +103,19 @@ type MergeAcceptRequest struct {
 
 // MergeRequestChanges is a changed list of the merge request
 type MergeRequestChanges struct {
-	Changes []struct {
-		OldPath string `json:"old_path"`
-		NewPath string `json:"new_path"`
-		Diff    string `json:"diff"`
-	} `json:"changes"`
+	Changes []FileDiff `json:"changes"`
+}
+
+// CompareResponse is a response of comparing two commits
+type CompareResponse struct {
+	Diffs []FileDiff `json:"diffs"`
+}
+
+// FileDiff is a diff of a single file
+type FileDiff struct {
+	OldPath string `json:"old_path"`
+	NewPath string `json:"new_path"`
+	Diff    string `json:"diff"`
 }
 
 // CommitResponse is a commits list response
@@ -36,7 +36,7 @@ func (c *Client) parsePullRequestWebhook(jsonString []byte) (*git.Webhook, error
 		return nil, err
 	}
 
-	pullRequest := git.PullRequest{ID: data.ObjectAttribute.ID, Title: data.ObjectAttribute.Title, URL: data.Project.WebURL}
+	pullRequest := git.PullRequest{ID: data.ObjectAttribute.ID, Title: data.ObjectAttribute.Title, URL: data.Project.WebURL, Draft: data.ObjectAttribute.Draft || data.ObjectAttribute.WorkInProgress}
 	pullRequest.Author = *author
 	pullRequest.Base = git.Base{Ref: data.ObjectAttribute.BaseRef}
 	pullRequest.Head = git.Head{Ref: data.ObjectAttribute.HeadRef, Sha: data.ObjectAttribute.LastCommit.Sha}
@@ -49,6 +49,9 @@ func (c *Client) parsePullRequestWebhook(jsonString []byte) (*git.Webhook, error
 		pullRequest.Action = git.PullRequestActionOpen
 	case "reopen":
 		pullRequest.Action = git.PullRequestActionReOpen
+	case "merge":
+		pullRequest.Action = git.PullRequestActionMerged
+		pullRequest.MergeSha = data.ObjectAttribute.MergeSha
 	case "update":
 		if data.ObjectAttribute.OldRev != "" {
 			pullRequest.Action = git.PullRequestActionSynchronize
@@ -59,6 +62,13 @@ func (c *Client) parsePullRequestWebhook(jsonString []byte) (*git.Webhook, error
 			if isUnlabeled {
 				pullRequest.Action = git.PullRequestActionUnlabeled
 			}
+		} else if data.Changes.Draft != nil {
+			pullRequest.Action = git.PullRequestActionReadyForReview
+			if data.Changes.Draft.Current {
+				pullRequest.Action = git.PullRequestActionConvertedToDraft
+			}
+		} else {
+			pullRequest.Action = git.PullRequestActionEdited
 		}
 	case "approved", "unapproved":
 		return c.parsePullRequestReviewWebhook(data, jsonString)
@@ -97,7 +107,7 @@ func (c *Client) parsePushWebhook(jsonString []byte) (*git.Webhook, error) {
 		return nil, nil
 	}
 	sender := git.User{Name: data.UserName, ID: data.UserID}
-	push := git.Push{Ref: data.Ref, Sha: data.Sha}
+	push := git.Push{Ref: data.Ref, Sha: data.Sha, Before: data.Before}
 
 	// Get sender email
 	userInfo, err := c.GetUserInfo(strconv.Itoa(data.UserID))
@@ -105,7 +115,23 @@ func (c *Client) parsePushWebhook(jsonString []byte) (*git.Webhook, error) {
 		sender.Email = userInfo.Email
 	}
 
-	return &git.Webhook{EventType: git.EventTypePush, Repo: repo, Sender: sender, Push: &push, RequestBody: string(jsonString)}, nil
+	return &git.Webhook{EventType: push.EventType(), Repo: repo, Sender: sender, Push: &push, RequestBody: string(jsonString)}, nil
+}
+
+func (c *Client) parseReleaseWebhook(jsonString []byte) (*git.Webhook, error) {
+	var data ReleaseWebhook
+	if err := json.Unmarshal(jsonString, &data); err != nil {
+		return nil, err
+	}
+	// Only created (i.e., published) releases are handled
+	if data.Action != "create" {
+		return nil, nil
+	}
+
+	repo := git.Repository{Name: data.Project.Name, URL: data.Project.WebURL}
+	release := git.Release{Tag: data.Tag, Name: data.Name, Sha: data.Commit.ID}
+
+	return &git.Webhook{EventType: git.EventTypeRelease, Repo: repo, Release: &release, RequestBody: string(jsonString)}, nil
 }
 
 func (c *Client) parseIssueComment(jsonString []byte) (*git.Webhook, error) {
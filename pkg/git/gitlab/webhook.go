@@ -29,9 +29,12 @@ type MergeRequestWebhook struct {
 		LastCommit struct {
 			Sha string `json:"id"`
 		} `json:"last_commit"`
-		State  string `json:"state"`
-		Action string `json:"action"`
-		OldRev string `json:"oldrev"`
+		State          string `json:"state"`
+		Action         string `json:"action"`
+		OldRev         string `json:"oldrev"`
+		Draft          bool   `json:"draft"`
+		WorkInProgress bool   `json:"work_in_progress"`
+		MergeSha       string `json:"merge_commit_sha"`
 	} `json:"object_attributes"`
 	Project Project `json:"project"`
 	Labels  []Label `json:"labels"`
@@ -40,6 +43,10 @@ type MergeRequestWebhook struct {
 			Previous []Label `json:"previous"`
 			Current  []Label `json:"current"`
 		} `json:"labels,omitempty"`
+		Draft *struct {
+			Previous bool `json:"previous"`
+			Current  bool `json:"current"`
+		} `json:"draft,omitempty"`
 	} `json:"changes"`
 }
 
@@ -51,6 +58,19 @@ type PushWebhook struct {
 	UserName string  `json:"user_name"`
 	UserID   int     `json:"user_id"`
 	Sha      string  `json:"after"`
+	Before   string  `json:"before"`
+}
+
+// ReleaseWebhook is a gitlab-specific release event webhook body
+type ReleaseWebhook struct {
+	Kind    string  `json:"object_kind"`
+	Action  string  `json:"action"`
+	Tag     string  `json:"tag"`
+	Name    string  `json:"name"`
+	Project Project `json:"project"`
+	Commit  struct {
+		ID string `json:"id"`
+	} `json:"commit"`
 }
 
 // NoteHook is a gitlab-specific issue comment webhook body
@@ -117,6 +137,7 @@ type RegistrationWebhookBody struct {
 	NoteEvents              bool   `json:"note_events"`
 	PipeLineEvents          bool   `json:"pipeline_events"`
 	PushEvents              bool   `json:"push_events"`
+	ReleasesEvents          bool   `json:"releases_events"`
 	TagPushEvents           bool   `json:"tag_push_events"`
 	WikiPageEvents          bool   `json:"wiki_page_events"`
 	URL                     string `json:"url"`
@@ -18,16 +18,24 @@ package git
 
 import (
 	"bytes"
+	"context"
 	"crypto/tls"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"net/http"
 	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tmax-cloud/cicd-operator/pkg/metrics"
+	"github.com/tmax-cloud/cicd-operator/pkg/tracing"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 // GetPaginatedRequest gets paginated APIs and accumulates them together
-func GetPaginatedRequest(apiURL string, tlsConfig *tls.Config, header map[string]string, newObj func() interface{}, accumulate func(interface{})) error {
+func GetPaginatedRequest(ctx context.Context, apiURL string, tlsConfig *tls.Config, header map[string]string, newObj func() interface{}, accumulate func(interface{})) error {
 	u, err := url.Parse(apiURL)
 	if err != nil {
 		return err
@@ -39,7 +47,7 @@ func GetPaginatedRequest(apiURL string, tlsConfig *tls.Config, header map[string
 	}
 	uri := u.String()
 	for {
-		data, h, err := RequestHTTP(http.MethodGet, uri, header, nil, tlsConfig)
+		data, h, err := RequestHTTP(ctx, http.MethodGet, uri, header, nil, tlsConfig)
 		if err != nil {
 			return err
 		}
@@ -65,10 +73,13 @@ func GetPaginatedRequest(apiURL string, tlsConfig *tls.Config, header map[string
 	return nil
 }
 
-// RequestHTTP requests api call
-func RequestHTTP(method string, uri string, header map[string]string, data interface{}, tlsConfig *tls.Config) ([]byte, http.Header, error) {
+// RequestHTTP requests api call. The call is traced as a child span of ctx, which can be nil
+func RequestHTTP(ctx context.Context, method string, uri string, header map[string]string, data interface{}, tlsConfig *tls.Config) (_ []byte, _ http.Header, err error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
 	var jsonBytes []byte
-	var err error
 
 	if data != nil {
 		jsonBytes, err = json.Marshal(data)
@@ -82,12 +93,22 @@ func RequestHTTP(method string, uri string, header map[string]string, data inter
 		return nil, nil, err
 	}
 
+	_, span := tracing.Start(ctx, "git.request", attribute.String("http.method", method), attribute.String("http.host", req.URL.Host), attribute.String("http.path", req.URL.Path))
+	defer func() {
+		tracing.End(span, err)
+	}()
+
 	for k, v := range header {
 		req.Header.Add(k, v)
 	}
 
 	var resp *http.Response
 
+	start := time.Now()
+	defer func() {
+		metrics.GitAPIRequestDuration.WithLabelValues(method, req.URL.Host).Observe(time.Since(start).Seconds())
+	}()
+
 	if tlsConfig != nil {
 		tr := &http.Transport{
 			TLSClientConfig: tlsConfig,
@@ -95,14 +116,12 @@ func RequestHTTP(method string, uri string, header map[string]string, data inter
 		tlsClient := http.Client{Transport: tr}
 
 		resp, err = tlsClient.Do(req)
-		if err != nil {
-			return nil, nil, err
-		}
 	} else {
 		resp, err = http.DefaultClient.Do(req)
-		if err != nil {
-			return nil, nil, err
-		}
+	}
+	if err != nil {
+		metrics.GitAPIRequestErrors.WithLabelValues(method, req.URL.Host, "error").Inc()
+		return nil, nil, err
 	}
 
 	defer func() {
@@ -114,10 +133,22 @@ func RequestHTTP(method string, uri string, header map[string]string, data inter
 		return nil, nil, err
 	}
 
+	span.SetAttributes(attribute.Int("http.status_code", resp.StatusCode))
+
 	// Check additional response header
 	var newErr error
 	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		metrics.GitAPIRequestErrors.WithLabelValues(method, req.URL.Host, strconv.Itoa(resp.StatusCode)).Inc()
 		newErr = fmt.Errorf("error requesting api [%s] %s, code %d, msg %s", method, uri, resp.StatusCode, string(body))
 	}
 	return body, resp.Header, newErr
 }
+
+// EscapeFilePath escapes each segment of the file path, keeping the slashes
+func EscapeFilePath(path string) string {
+	segments := strings.Split(strings.TrimPrefix(path, "/"), "/")
+	for i, s := range segments {
+		segments[i] = url.PathEscape(s)
+	}
+	return strings.Join(segments, "/")
+}
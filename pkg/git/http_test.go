@@ -15,3 +15,59 @@
 */
 
 package git
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	"github.com/tmax-cloud/cicd-operator/pkg/metrics"
+)
+
+func TestRequestHTTP(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/not-found" {
+			w.WriteHeader(http.StatusNotFound)
+		}
+		_, _ = w.Write([]byte("{}"))
+	}))
+	defer srv.Close()
+
+	u, err := url.Parse(srv.URL)
+	require.NoError(t, err)
+
+	tc := map[string]struct {
+		path string
+
+		errorOccurs bool
+		errorCode   string
+	}{
+		"ok": {
+			path: "/ok",
+		},
+		"notFound": {
+			path:        "/not-found",
+			errorOccurs: true,
+			errorCode:   "404",
+		},
+	}
+
+	for name, c := range tc {
+		t.Run(name, func(t *testing.T) {
+			before := testutil.ToFloat64(metrics.GitAPIRequestErrors.WithLabelValues(http.MethodGet, u.Host, "404"))
+			body, _, err := RequestHTTP(context.Background(), http.MethodGet, srv.URL+c.path, nil, nil, nil)
+			if c.errorOccurs {
+				require.Error(t, err)
+				require.Equal(t, before+1, testutil.ToFloat64(metrics.GitAPIRequestErrors.WithLabelValues(http.MethodGet, u.Host, c.errorCode)))
+			} else {
+				require.NoError(t, err)
+				require.Equal(t, "{}", string(body))
+				require.Equal(t, before, testutil.ToFloat64(metrics.GitAPIRequestErrors.WithLabelValues(http.MethodGet, u.Host, "404")))
+			}
+		})
+	}
+}
@@ -0,0 +1,66 @@
+/*
+ Copyright 2021 The CI/CD Operator Authors
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package git
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"fmt"
+	"hash"
+	"net/http"
+	"strings"
+)
+
+// Headers for the HMAC signatures of the webhook payloads
+const (
+	HeaderSignature256 = "X-Hub-Signature-256"
+	HeaderSignature    = "X-Hub-Signature"
+)
+
+// HashPayload returns the hex-encoded HMAC of the payload, using the hash function
+func HashPayload(h func() hash.Hash, secret string, payload []byte) string {
+	hm := hmac.New(h, []byte(secret))
+	_, _ = hm.Write(payload)
+	return fmt.Sprintf("%x", hm.Sum(nil))
+}
+
+// ValidateSignature validates the HMAC signature of the webhook payload, signed with any of the secrets.
+// The HMAC-SHA256 signature (X-Hub-Signature-256) is validated if it's given. The HMAC-SHA1 signature (X-Hub-Signature)
+// is validated only as a fallback, for the git servers not sending the SHA-256 one
+func ValidateSignature(secrets []string, header http.Header, payload []byte) error {
+	if signature := header.Get(HeaderSignature256); signature != "" {
+		if !isValidSignature(sha256.New, secrets, strings.TrimPrefix(signature, "sha256="), payload) {
+			return fmt.Errorf("invalid request : %s does not match secret", HeaderSignature256)
+		}
+		return nil
+	}
+
+	if !isValidSignature(sha1.New, secrets, strings.TrimPrefix(header.Get(HeaderSignature), "sha1="), payload) {
+		return fmt.Errorf("invalid request : %s does not match secret", HeaderSignature)
+	}
+	return nil
+}
+
+func isValidSignature(h func() hash.Hash, secrets []string, signature string, payload []byte) bool {
+	for _, secret := range secrets {
+		if hmac.Equal([]byte(HashPayload(h, secret, payload)), []byte(signature)) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,89 @@
+/*
+ Copyright 2021 The CI/CD Operator Authors
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package git
+
+import (
+	"crypto/sha1"
+	"crypto/sha256"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateSignature(t *testing.T) {
+	payload := []byte(`{"test": "payload"}`)
+
+	tc := map[string]struct {
+		secrets      []string
+		signature256 string
+		signature    string
+
+		expectedErr    bool
+		expectedErrMsg string
+	}{
+		"sha256": {
+			secrets:      []string{"new-secret"},
+			signature256: "sha256=" + HashPayload(sha256.New, "new-secret", payload),
+		},
+		"sha256PreferredOverSha1": {
+			secrets:        []string{"new-secret"},
+			signature256:   "sha256=" + HashPayload(sha256.New, "wrong-secret", payload),
+			signature:      "sha1=" + HashPayload(sha1.New, "new-secret", payload),
+			expectedErr:    true,
+			expectedErrMsg: "invalid request : X-Hub-Signature-256 does not match secret",
+		},
+		"sha1Fallback": {
+			secrets:   []string{"new-secret"},
+			signature: "sha1=" + HashPayload(sha1.New, "new-secret", payload),
+		},
+		"sha1Mismatch": {
+			secrets:        []string{"new-secret"},
+			signature:      "sha1=" + HashPayload(sha1.New, "wrong-secret", payload),
+			expectedErr:    true,
+			expectedErrMsg: "invalid request : X-Hub-Signature does not match secret",
+		},
+		"previousSecret": {
+			secrets:      []string{"new-secret", "old-secret"},
+			signature256: "sha256=" + HashPayload(sha256.New, "old-secret", payload),
+		},
+		"noSignature": {
+			secrets:        []string{"new-secret"},
+			expectedErr:    true,
+			expectedErrMsg: "invalid request : X-Hub-Signature does not match secret",
+		},
+	}
+
+	for name, c := range tc {
+		t.Run(name, func(t *testing.T) {
+			header := http.Header{}
+			if c.signature256 != "" {
+				header.Set(HeaderSignature256, c.signature256)
+			}
+			if c.signature != "" {
+				header.Set(HeaderSignature, c.signature)
+			}
+			err := ValidateSignature(c.secrets, header, payload)
+			if c.expectedErr {
+				require.Error(t, err)
+				require.Equal(t, c.expectedErrMsg, err.Error())
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,190 @@
+/*
+ Copyright 2021 The CI/CD Operator Authors
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+// Package metrics defines the prometheus metrics of the operator.
+// All the metrics are registered to the controller-runtime's registry, which is served by the manager's metrics endpoint
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const namespace = "cicd"
+
+// Scheduler metrics
+var (
+	// SchedulerPendingJobs is the number of pending IntegrationJobs in the scheduler's pool
+	SchedulerPendingJobs = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "scheduler",
+		Name:      "pending_jobs",
+		Help:      "Number of pending IntegrationJobs in the scheduler's pool",
+	})
+
+	// SchedulerRunningJobs is the number of running IntegrationJobs in the scheduler's pool
+	SchedulerRunningJobs = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "scheduler",
+		Name:      "running_jobs",
+		Help:      "Number of running IntegrationJobs in the scheduler's pool",
+	})
+
+	// SchedulerPendingDuration is the time IntegrationJobs spent in pending state, until they are scheduled
+	SchedulerPendingDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "scheduler",
+		Name:      "pending_duration_seconds",
+		Help:      "Time IntegrationJobs spent in pending state, until they are scheduled",
+		Buckets:   prometheus.ExponentialBuckets(1, 2, 14),
+	}, []string{"namespace"})
+)
+
+// Job metrics
+var (
+	// JobDuration is the duration of each job of IntegrationJobs
+	JobDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "job",
+		Name:      "duration_seconds",
+		Help:      "Duration of the jobs of IntegrationJobs",
+		Buckets:   prometheus.ExponentialBuckets(10, 2, 12),
+	}, []string{"namespace", "config", "job", "result"})
+)
+
+// Webhook metrics
+var (
+	// WebhookEventsReceived is the number of webhook events received for the IntegrationConfigs
+	WebhookEventsReceived = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "webhook",
+		Name:      "events_received_total",
+		Help:      "Number of webhook events received",
+	}, []string{"provider"})
+
+	// WebhookEventsParsed is the number of webhook events parsed successfully
+	WebhookEventsParsed = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "webhook",
+		Name:      "events_parsed_total",
+		Help:      "Number of webhook events parsed successfully",
+	}, []string{"provider", "event"})
+
+	// WebhookEventsFailed is the number of webhook events failed to be handled
+	WebhookEventsFailed = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "webhook",
+		Name:      "events_failed_total",
+		Help:      "Number of webhook events failed to be parsed or handled",
+	}, []string{"provider", "reason"})
+
+	// WebhookQueueDepth is the number of webhook events queued, waiting to be handled
+	WebhookQueueDepth = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "webhook",
+		Name:      "queue_depth",
+		Help:      "Number of webhook events queued, waiting to be handled",
+	})
+
+	// WebhookEventRetries is the number of retries of the webhook events' failed plugins
+	WebhookEventRetries = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "webhook",
+		Name:      "event_retries_total",
+		Help:      "Number of retries of the webhook events' failed plugins",
+	}, []string{"provider"})
+
+	// WebhookEventsDuplicated is the number of redelivered webhook events, ignored by their delivery IDs
+	WebhookEventsDuplicated = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "webhook",
+		Name:      "events_duplicated_total",
+		Help:      "Number of redelivered webhook events, ignored by their delivery IDs",
+	}, []string{"provider"})
+)
+
+// Blocker metrics
+var (
+	// BlockerPullRequests is the number of open pull requests in each blocker pool
+	BlockerPullRequests = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "blocker",
+		Name:      "pull_requests",
+		Help:      "Number of open pull requests in each blocker pool",
+	}, []string{"repository"})
+
+	// BlockerMergePoolPullRequests is the number of pull requests in each blocker pool's merge pool
+	BlockerMergePoolPullRequests = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "blocker",
+		Name:      "merge_pool_pull_requests",
+		Help:      "Number of pull requests in each blocker pool's merge pool, by their commit status",
+	}, []string{"repository", "state"})
+
+	// BlockerMerges is the number of merge attempts by the blocker
+	BlockerMerges = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "blocker",
+		Name:      "merges_total",
+		Help:      "Number of pull request merges tried by the blocker",
+	}, []string{"result"})
+)
+
+// Git metrics
+var (
+	// GitAPIRequestDuration is the latency of the git API calls
+	GitAPIRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "git",
+		Name:      "api_request_duration_seconds",
+		Help:      "Latency of the git API requests",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "host"})
+
+	// GitAPIRequestErrors is the number of failed git API calls
+	GitAPIRequestErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "git",
+		Name:      "api_request_errors_total",
+		Help:      "Number of failed git API requests",
+	}, []string{"method", "host", "code"})
+)
+
+// Result labels
+const (
+	ResultSuccess = "success"
+	ResultFailure = "failure"
+)
+
+func init() {
+	metrics.Registry.MustRegister(
+		SchedulerPendingJobs,
+		SchedulerRunningJobs,
+		SchedulerPendingDuration,
+		JobDuration,
+		WebhookEventsReceived,
+		WebhookEventsParsed,
+		WebhookEventsFailed,
+		WebhookQueueDepth,
+		WebhookEventRetries,
+		WebhookEventsDuplicated,
+		BlockerPullRequests,
+		BlockerMergePoolPullRequests,
+		BlockerMerges,
+		GitAPIRequestDuration,
+		GitAPIRequestErrors,
+	)
+}
@@ -134,7 +134,9 @@ func generatePeriodic(config *cicdv1.IntegrationConfig, job cicdv1.Job) *cicdv1.
 			},
 			PodTemplate: config.Spec.PodTemplate,
 			Timeout:     config.GetDuration(),
+			JobTimeout:  config.Spec.JobTimeout,
 			ParamConfig: config.Spec.ParamConfig,
+			Priority:    config.GetPriority(cicdv1.JobTypePeriodic),
 		},
 	}
 }
@@ -0,0 +1,93 @@
+/*
+ Copyright 2021 The CI/CD Operator Authors
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package pipelinemanager
+
+import (
+	"fmt"
+	"strings"
+
+	tektonv1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	cicdv1 "github.com/tmax-cloud/cicd-operator/api/v1"
+	"github.com/tmax-cloud/cicd-operator/pkg/git"
+)
+
+// generateCheckRun generates a check run for the job status.
+// The title is the same as the commit status' description (containing the base SHA), so the merger can parse it
+func generateCheckRun(j *cicdv1.JobStatus, title, detailsURL string) git.CheckRun {
+	checkRun := git.CheckRun{
+		Name:       j.Name,
+		Status:     git.CheckRunStatusInProgress,
+		DetailsURL: detailsURL,
+		Title:      title,
+		Summary:    generateCheckRunSummary(j),
+		StartedAt:  j.StartTime,
+	}
+
+	switch j.State {
+	case cicdv1.CommitStatusStateSuccess:
+		checkRun.Status = git.CheckRunStatusCompleted
+		checkRun.Conclusion = git.CheckRunConclusionSuccess
+	case cicdv1.CommitStatusStateFailure, cicdv1.CommitStatusStateError:
+		checkRun.Status = git.CheckRunStatusCompleted
+		checkRun.Conclusion = git.CheckRunConclusionFailure
+		if j.Reason == cicdv1.JobReasonTimedOut {
+			checkRun.Conclusion = git.CheckRunConclusionTimedOut
+		}
+	default:
+		if j.StartTime == nil {
+			checkRun.Status = git.CheckRunStatusQueued
+		}
+	}
+	if checkRun.Status == git.CheckRunStatusCompleted {
+		checkRun.CompletedAt = j.CompletionTime
+	}
+
+	return checkRun
+}
+
+// generateCheckRunSummary generates a markdown summary of the job, containing the message and the states of the steps
+func generateCheckRunSummary(j *cicdv1.JobStatus) string {
+	var lines []string
+	if j.Message != "" {
+		lines = append(lines, fmt.Sprintf("**Message**: %s", j.Message), "")
+	}
+
+	if len(j.Containers) > 0 {
+		lines = append(lines, "| Step | State |", "| --- | --- |")
+		for _, c := range j.Containers {
+			lines = append(lines, fmt.Sprintf("| %s | %s |", c.Name, stepStateString(c)))
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func stepStateString(s tektonv1beta1.StepState) string {
+	switch {
+	case s.Terminated != nil:
+		if s.Terminated.Reason != "" {
+			return fmt.Sprintf("Terminated (%s, exit code %d)", s.Terminated.Reason, s.Terminated.ExitCode)
+		}
+		return fmt.Sprintf("Terminated (exit code %d)", s.Terminated.ExitCode)
+	case s.Running != nil:
+		return "Running"
+	case s.Waiting != nil && s.Waiting.Reason != "":
+		return fmt.Sprintf("Waiting (%s)", s.Waiting.Reason)
+	default:
+		return "Waiting"
+	}
+}
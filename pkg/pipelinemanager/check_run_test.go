@@ -0,0 +1,130 @@
+/*
+ Copyright 2021 The CI/CD Operator Authors
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package pipelinemanager
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	tektonv1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	cicdv1 "github.com/tmax-cloud/cicd-operator/api/v1"
+	"github.com/tmax-cloud/cicd-operator/pkg/git"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestGenerateCheckRun(t *testing.T) {
+	startTime := metav1.NewTime(time.Date(2021, 10, 1, 12, 0, 0, 0, time.UTC))
+	completionTime := metav1.NewTime(time.Date(2021, 10, 1, 12, 3, 0, 0, time.UTC))
+
+	tc := map[string]struct {
+		status *cicdv1.JobStatus
+
+		expectedCheckRun git.CheckRun
+	}{
+		"queued": {
+			status: &cicdv1.JobStatus{Name: "test-job", State: cicdv1.CommitStatusStatePending},
+			expectedCheckRun: git.CheckRun{
+				Name:       "test-job",
+				Status:     git.CheckRunStatusQueued,
+				DetailsURL: "https://report",
+				Title:      "Job is running",
+			},
+		},
+		"inProgress": {
+			status: &cicdv1.JobStatus{
+				Name:      "test-job",
+				State:     cicdv1.CommitStatusStatePending,
+				StartTime: &startTime,
+				Containers: []tektonv1beta1.StepState{
+					{Name: "git-clone", ContainerState: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{ExitCode: 0, Reason: "Completed"}}},
+					{Name: "test", ContainerState: corev1.ContainerState{Running: &corev1.ContainerStateRunning{}}},
+					{Name: "report", ContainerState: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "PodInitializing"}}},
+				},
+			},
+			expectedCheckRun: git.CheckRun{
+				Name:       "test-job",
+				Status:     git.CheckRunStatusInProgress,
+				DetailsURL: "https://report",
+				Title:      "Job is running",
+				Summary:    "| Step | State |\n| --- | --- |\n| git-clone | Terminated (Completed, exit code 0) |\n| test | Running |\n| report | Waiting (PodInitializing) |",
+				StartedAt:  &startTime,
+			},
+		},
+		"success": {
+			status: &cicdv1.JobStatus{Name: "test-job", State: cicdv1.CommitStatusStateSuccess, StartTime: &startTime, CompletionTime: &completionTime},
+			expectedCheckRun: git.CheckRun{
+				Name:        "test-job",
+				Status:      git.CheckRunStatusCompleted,
+				Conclusion:  git.CheckRunConclusionSuccess,
+				DetailsURL:  "https://report",
+				Title:       "Job is running",
+				StartedAt:   &startTime,
+				CompletedAt: &completionTime,
+			},
+		},
+		"failure": {
+			status: &cicdv1.JobStatus{
+				Name:           "test-job",
+				State:          cicdv1.CommitStatusStateFailure,
+				Message:        "\"step-test\" exited with code 1",
+				StartTime:      &startTime,
+				CompletionTime: &completionTime,
+				Containers: []tektonv1beta1.StepState{
+					{Name: "test", ContainerState: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{ExitCode: 1}}},
+				},
+			},
+			expectedCheckRun: git.CheckRun{
+				Name:        "test-job",
+				Status:      git.CheckRunStatusCompleted,
+				Conclusion:  git.CheckRunConclusionFailure,
+				DetailsURL:  "https://report",
+				Title:       "Job is running",
+				Summary:     "**Message**: \"step-test\" exited with code 1\n\n| Step | State |\n| --- | --- |\n| test | Terminated (exit code 1) |",
+				StartedAt:   &startTime,
+				CompletedAt: &completionTime,
+			},
+		},
+		"timedOut": {
+			status: &cicdv1.JobStatus{
+				Name:           "test-job",
+				State:          cicdv1.CommitStatusStateFailure,
+				Reason:         cicdv1.JobReasonTimedOut,
+				Message:        JobMessageTimedOut,
+				StartTime:      &startTime,
+				CompletionTime: &completionTime,
+			},
+			expectedCheckRun: git.CheckRun{
+				Name:        "test-job",
+				Status:      git.CheckRunStatusCompleted,
+				Conclusion:  git.CheckRunConclusionTimedOut,
+				DetailsURL:  "https://report",
+				Title:       "Job is running",
+				Summary:     "**Message**: Job timed out\n",
+				StartedAt:   &startTime,
+				CompletedAt: &completionTime,
+			},
+		},
+	}
+
+	for name, c := range tc {
+		t.Run(name, func(t *testing.T) {
+			require.Equal(t, c.expectedCheckRun, generateCheckRun(c.status, JobMessagePending, "https://report"))
+		})
+	}
+}
@@ -19,6 +19,7 @@ package pipelinemanager
 import (
 	"fmt"
 	"net/url"
+	"strconv"
 
 	tektonv1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
 	cicdv1 "github.com/tmax-cloud/cicd-operator/api/v1"
@@ -76,6 +77,9 @@ func generateDefaultEnvs(job *cicdv1.IntegrationJob) ([]corev1.EnvVar, error) {
 	}
 
 	refs := jobSpec.Refs
+	if refs.MergedPull != nil {
+		defaultEnvs = append(defaultEnvs, corev1.EnvVar{Name: "CI_MERGED_PULL_REQUEST", Value: strconv.Itoa(refs.MergedPull.ID)})
+	}
 	if refs.Pulls == nil {
 		// Push event
 		defaultEnvs = append(defaultEnvs, []corev1.EnvVar{
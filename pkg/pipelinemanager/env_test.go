@@ -147,6 +147,23 @@ func TestGenerateDefaultEnvs(t *testing.T) {
 				{Name: "CI_HEAD_REF", Value: "master"},
 			},
 		},
+		"merged": {
+			jobSpec: cicdv1.IntegrationJobSpec{
+				ConfigRef: cicdv1.IntegrationJobConfigRef{Name: "test-ic", Type: cicdv1.JobTypePostSubmit},
+				ID:        "dummy-id",
+				Refs: cicdv1.IntegrationJobRefs{
+					Repository: "yxzzzxh/test",
+					Link:       "https://hub.docker.io/test-repo",
+					Base:       cicdv1.IntegrationJobRefsBase{Ref: "refs/heads/master", Sha: "dkfpoekglfjpgarl2p4idmgisq"},
+					MergedPull: &cicdv1.IntegrationJobRefsPull{ID: 30, Ref: "bugfix/first", Sha: "0kokpenadiugpowkqe0qlemaogor"},
+				},
+			},
+			expectedVars: []corev1.EnvVar{
+				{Name: "CI_MERGED_PULL_REQUEST", Value: "30"},
+				{Name: "CI_HEAD_SHA", Value: "dkfpoekglfjpgarl2p4idmgisq"},
+				{Name: "CI_HEAD_REF", Value: "refs/heads/master"},
+			},
+		},
 	}
 
 	for name, c := range tc {
@@ -31,6 +31,11 @@ import (
 func (p *pipelineManager) handleNotification(jobStatus *cicdv1.JobStatus, ij *cicdv1.IntegrationJob, cfg *cicdv1.IntegrationConfig) error {
 	// Get jobSpec spec
 	jobSpec := getSpecFromStatus(jobStatus, ij.Spec.ConfigRef.Type, cfg)
+	if jobSpec == nil {
+		// The job may be loaded from the repository (jobsFrom) or expanded from a matrix job, so look up the
+		// IntegrationJob's jobs
+		jobSpec = findJob(ij.Spec.Jobs.ExpandMatrix(), jobStatus.Name)
+	}
 	if jobSpec == nil {
 		return fmt.Errorf("no jobSpec %s exists in the config", jobStatus.Name)
 	}
@@ -171,8 +176,12 @@ func getSpecFromStatus(jobStatus *cicdv1.JobStatus, t cicdv1.JobType, cfg *cicdv
 		return nil
 	}
 
+	return findJob(jobs, jobStatus.Name)
+}
+
+func findJob(jobs []cicdv1.Job, name string) *cicdv1.Job {
 	for _, j := range jobs {
-		if j.Name == jobStatus.Name {
+		if j.Name == name {
 			return &j
 		}
 	}
@@ -30,6 +30,9 @@ import (
 	"github.com/tmax-cloud/cicd-operator/internal/utils"
 	"github.com/tmax-cloud/cicd-operator/pkg/events"
 	"github.com/tmax-cloud/cicd-operator/pkg/git"
+	"github.com/tmax-cloud/cicd-operator/pkg/metrics"
+	"github.com/tmax-cloud/cicd-operator/pkg/tracing"
+	"go.opentelemetry.io/otel/attribute"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -51,6 +54,8 @@ const (
 	JobMessagePending    = "Job is running"
 	JobMessageSuccessful = "Job succeeded"
 	JobMessageFailure    = "Job failed"
+	JobMessageCanceled   = "Job canceled"
+	JobMessageTimedOut   = "Job timed out"
 )
 
 const (
@@ -77,7 +82,12 @@ func NewPipelineManager(c client.Client, s *runtime.Scheme) PipelineManager {
 }
 
 // Generate generates (but not creates) a PipelineRun object
-func (p *pipelineManager) Generate(job *cicdv1.IntegrationJob) (*tektonv1beta1.Pipeline, *tektonv1beta1.PipelineRun, error) {
+func (p *pipelineManager) Generate(job *cicdv1.IntegrationJob) (_ *tektonv1beta1.Pipeline, _ *tektonv1beta1.PipelineRun, err error) {
+	_, span := tracing.StartForJob(job, job.Spec.ConfigRef.Name, "pipelinemanager.generate")
+	defer func() {
+		tracing.End(span, err)
+	}()
+
 	log.Info("Generating a pipeline run")
 	// token for private repo
 	var token string
@@ -101,7 +111,7 @@ func (p *pipelineManager) Generate(job *cicdv1.IntegrationJob) (*tektonv1beta1.P
 
 	// Generate Tasks
 	var tasks []tektonv1beta1.PipelineTask
-	for _, j := range job.Spec.Jobs {
+	for _, j := range job.Spec.Jobs.ExpandMatrix() {
 		taskSpec, resources, err := generateTask(job, &j, token)
 		if err != nil {
 			return nil, nil, err
@@ -136,6 +146,11 @@ func (p *pipelineManager) Generate(job *cicdv1.IntegrationJob) (*tektonv1beta1.P
 			Params:     paramDefine,
 		},
 	}
+	// The whole jobs' timeout is the IntegrationJob's jobTimeout if it is set
+	timeout := job.Spec.Timeout
+	if job.Spec.JobTimeout != nil {
+		timeout = job.Spec.JobTimeout
+	}
 	pr := &tektonv1beta1.PipelineRun{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      pl.Name,
@@ -151,7 +166,7 @@ func (p *pipelineManager) Generate(job *cicdv1.IntegrationJob) (*tektonv1beta1.P
 			PodTemplate: job.Spec.PodTemplate,
 			Workspaces:  job.Spec.Workspaces,
 			Timeout: &metav1.Duration{
-				Duration: job.Spec.Timeout.Duration,
+				Duration: timeout.Duration,
 			},
 			Params: paramValue,
 		},
@@ -260,6 +275,17 @@ func generateTask(job *cicdv1.IntegrationJob, j *cicdv1.Job, token string) (*tek
 		task.TaskSpec.Results = append(task.TaskSpec.Results, j.Results...)
 	}
 
+	// Retries (custom tasks cannot be retried)
+	customTask := task.TaskRef != nil && task.TaskRef.APIVersion != ""
+	if j.Retries != nil && !customTask {
+		task.Retries = j.Retries.Count
+	}
+
+	// Timeout (custom tasks cannot have the timeout)
+	if j.Timeout != nil && !customTask {
+		task.Timeout = j.Timeout.DeepCopy()
+	}
+
 	return task, resources, nil
 }
 
@@ -302,9 +328,23 @@ func generateLabel(j *cicdv1.IntegrationJob) map[string]string {
 
 // ReflectStatus reflects PipelineRun's status into IntegrationJob's status
 // It also set commit status for remote git server
-func (p *pipelineManager) ReflectStatus(pr *tektonv1beta1.PipelineRun, job *cicdv1.IntegrationJob, cfg *cicdv1.IntegrationConfig) error {
-	oldState := job.Status.State
-	oldMessage := job.Status.Message
+func (p *pipelineManager) ReflectStatus(pr *tektonv1beta1.PipelineRun, job *cicdv1.IntegrationJob, cfg *cicdv1.IntegrationConfig) (err error) {
+	ctx, span := tracing.StartForJob(job, cfg.Name, "pipelinemanager.reflectStatus")
+	defer func() {
+		span.SetAttributes(attribute.String("cicd.state", string(job.Status.State)))
+		tracing.End(span, err)
+	}()
+
+	oldStatus := job.Status.DeepCopy()
+
+	// Status of the jobs is not initialized yet, only for the newly queued IntegrationJob
+	if len(oldStatus.Jobs) == 0 && oldStatus.State == cicdv1.IntegrationJobStatePending {
+		oldStatus.State = ""
+	}
+
+	if job.Status.State == cicdv1.IntegrationJobStateCanceled {
+		return p.reflectCanceled(ctx, pr, job, cfg)
+	}
 
 	// If PR is nil but IntegrationJob's status is running, set as error
 	// Also, schedule next pipelineRun
@@ -315,6 +355,8 @@ func (p *pipelineManager) ReflectStatus(pr *tektonv1beta1.PipelineRun, job *cicd
 	// Initialize status.jobs
 	stateChanged := initState(job)
 
+	reason := ""
+
 	// If PR exists, default state is running
 	if pr != nil {
 		job.Status.State = cicdv1.IntegrationJobStateRunning
@@ -331,20 +373,30 @@ func (p *pipelineManager) ReflectStatus(pr *tektonv1beta1.PipelineRun, job *cicd
 			switch tektonv1beta1.PipelineRunReason(pr.Status.Conditions[0].Reason) {
 			case tektonv1beta1.PipelineRunReasonSuccessful, tektonv1beta1.PipelineRunReasonCompleted:
 				job.Status.State = cicdv1.IntegrationJobStateCompleted
-			case tektonv1beta1.PipelineRunReasonFailed, tektonv1beta1.PipelineRunReasonCancelled, tektonv1beta1.PipelineRunReasonTimedOut:
+			case tektonv1beta1.PipelineRunReasonFailed, tektonv1beta1.PipelineRunReasonCancelled:
 				job.Status.State = cicdv1.IntegrationJobStateFailed
+			case tektonv1beta1.PipelineRunReasonTimedOut:
+				job.Status.State = cicdv1.IntegrationJobStateTimedOut
+				reason = events.ReasonTimedOut
 			}
 		}
 
 		// Reflect status of each task(job)
 		// Be sure job.Status.Jobs[i] is set sequentially
-		for i, j := range job.Spec.Jobs {
+		for i, j := range job.Spec.Jobs.ExpandMatrix() {
 			stateChanged[i] = p.reflectJobStatus(pr, &j, &job.Status.Jobs[i], job, cfg)
 		}
+
+		if job.Status.State == cicdv1.IntegrationJobStateTimedOut {
+			reflectTimedOut(job, pr, stateChanged)
+		} else if err := p.emitJobTimedOutEvents(job, oldStatus.Jobs, stateChanged); err != nil {
+			return err
+		}
 	}
 
 	// If it's start/completed but completion time is not set, set it as now
-	if job.Status.State == cicdv1.IntegrationJobStateFailed || job.Status.State == cicdv1.IntegrationJobStateCompleted {
+	if job.Status.State == cicdv1.IntegrationJobStateFailed || job.Status.State == cicdv1.IntegrationJobStateCompleted ||
+		job.Status.State == cicdv1.IntegrationJobStateTimedOut {
 		t := &metav1.Time{Time: time.Now()}
 		if job.Status.StartTime == nil {
 			job.Status.StartTime = t
@@ -354,28 +406,95 @@ func (p *pipelineManager) ReflectStatus(pr *tektonv1beta1.PipelineRun, job *cicd
 		}
 	}
 
+	observeJobDurations(job, stateChanged)
+
 	if job.Spec.ConfigRef.Type != cicdv1.JobTypePeriodic {
 		// Set remote git's commit status for each job
-		if err := p.updateGitCommitStatus(cfg, job, stateChanged); err != nil {
+		if err := p.updateGitCommitStatus(ctx, cfg, job, stateChanged); err != nil {
 			return err
 		}
 	}
 
 	// Emit events
-	if err := p.emitEvents(job, oldState, oldMessage); err != nil {
+	if reason == "" {
+		reason = string(job.Status.State)
+	}
+	if err := p.emitEvents(job, oldStatus, reason); err != nil {
 		return err
 	}
 
 	return nil
 }
 
+// reflectCanceled cancels the PipelineRun of the canceled IntegrationJob, and marks its unfinished jobs as canceled
+func (p *pipelineManager) reflectCanceled(ctx context.Context, pr *tektonv1beta1.PipelineRun, job *cicdv1.IntegrationJob, cfg *cicdv1.IntegrationConfig) error {
+	oldStatus := job.Status.DeepCopy()
+	stateChanged := initState(job)
+
+	if pr != nil {
+		// Keep the status of the already completed jobs
+		for i, j := range job.Spec.Jobs.ExpandMatrix() {
+			stateChanged[i] = p.reflectJobStatus(pr, &j, &job.Status.Jobs[i], job, cfg)
+		}
+
+		if pr.Status.CompletionTime == nil && !pr.IsCancelled() {
+			original := pr.DeepCopy()
+			pr.Spec.Status = tektonv1beta1.PipelineRunSpecStatusCancelled
+			if err := p.Client.Patch(context.Background(), pr, client.MergeFrom(original)); err != nil {
+				return err
+			}
+		}
+	}
+
+	now := &metav1.Time{Time: time.Now()}
+	for i := range job.Status.Jobs {
+		if job.Status.Jobs[i].CompletionTime != nil {
+			continue
+		}
+		job.Status.Jobs[i].State = cicdv1.CommitStatusStateError
+		job.Status.Jobs[i].Message = JobMessageCanceled
+		job.Status.Jobs[i].CompletionTime = now
+		stateChanged[i] = true
+	}
+	if job.Status.StartTime == nil {
+		job.Status.StartTime = now
+	}
+	job.Status.CompletionTime = now
+
+	observeJobDurations(job, stateChanged)
+
+	if err := p.updateGitCommitStatus(ctx, cfg, job, stateChanged); err != nil {
+		return err
+	}
+
+	// The state is set to canceled by the dispatcher, not here, so always emit the event
+	oldStatus.State = ""
+	return p.emitEvents(job, oldStatus, string(job.Status.State))
+}
+
+// observeJobDurations observes the durations of the jobs, which are just completed
+func observeJobDurations(job *cicdv1.IntegrationJob, stateChanged []bool) {
+	for i, j := range job.Status.Jobs {
+		if i >= len(stateChanged) || !stateChanged[i] || j.StartTime == nil || j.CompletionTime == nil {
+			continue
+		}
+		if j.State == cicdv1.CommitStatusStatePending {
+			continue
+		}
+		duration := j.CompletionTime.Sub(j.StartTime.Time)
+		metrics.JobDuration.WithLabelValues(job.Namespace, job.Spec.ConfigRef.Name, j.Name, string(j.State)).Observe(duration.Seconds())
+	}
+}
+
+// initState initializes the status of the jobs, each expanded from the matrix job having its own status
 func initState(job *cicdv1.IntegrationJob) []bool {
-	stateChanged := make([]bool, len(job.Spec.Jobs))
-	reset := len(job.Status.Jobs) != len(job.Spec.Jobs)
+	jobs := job.Spec.Jobs.ExpandMatrix()
+	stateChanged := make([]bool, len(jobs))
+	reset := len(job.Status.Jobs) != len(jobs)
 	if reset {
 		job.Status.Jobs = nil
 	}
-	for _, j := range job.Spec.Jobs {
+	for _, j := range jobs {
 		if reset {
 			job.Status.Jobs = append(job.Status.Jobs, cicdv1.JobStatus{
 				Name:  j.Name,
@@ -390,12 +509,17 @@ func initState(job *cicdv1.IntegrationJob) []bool {
 func (p *pipelineManager) reflectJobStatus(pr *tektonv1beta1.PipelineRun, j *cicdv1.Job, jStatus *cicdv1.JobStatus, ij *cicdv1.IntegrationJob, cfg *cicdv1.IntegrationConfig) bool {
 	changed := false
 
+	// Stop retrying the job failed with an exit code not to be retried
+	if err := p.stopUnretriableJob(pr, j); err != nil {
+		log.Error(err, fmt.Sprintf("cannot stop retrying job %s", j.Name))
+	}
+
 	runStatus := getJobRunStatus(pr.Status, j)
 
 	// Only update if taskRun's status exists
 	if runStatus != nil {
 		// If something is changed, commit status should be posted (except for message - message is decided by the state)
-		changed = jStatus.State != runStatus.State || !jStatus.StartTime.Equal(runStatus.StartTime) || !jStatus.CompletionTime.Equal(runStatus.CompletionTime)
+		changed = jStatus.State != runStatus.State || jStatus.Reason != runStatus.Reason || jStatus.Attempt != runStatus.Attempt || !jStatus.StartTime.Equal(runStatus.StartTime) || !jStatus.CompletionTime.Equal(runStatus.CompletionTime)
 		runStatus.DeepCopyInto(jStatus)
 
 		// Handle post-run notifications for the completed jobs
@@ -424,6 +548,13 @@ func reflectFromTaskRuns(prStatus tektonv1beta1.PipelineRunStatus, j *cicdv1.Job
 		if runStatus.Status != nil && runStatus.PipelineTaskName == j.Name {
 			rStatus := runStatus.Status
 			jobStatus.PodName = rStatus.PodName
+			if len(rStatus.RetriesStatus) > 0 {
+				jobStatus.Attempt = len(rStatus.RetriesStatus) + 1
+				for _, retry := range rStatus.RetriesStatus {
+					jobStatus.AttemptPodNames = append(jobStatus.AttemptPodNames, retry.PodName)
+				}
+				jobStatus.AttemptPodNames = append(jobStatus.AttemptPodNames, rStatus.PodName)
+			}
 			jobStatus.StartTime = rStatus.StartTime.DeepCopy()
 			jobStatus.CompletionTime = rStatus.CompletionTime.DeepCopy()
 			if len(rStatus.Conditions) > 0 {
@@ -434,6 +565,13 @@ func reflectFromTaskRuns(prStatus tektonv1beta1.PipelineRunStatus, j *cicdv1.Job
 				case corev1.ConditionFalse:
 					jobStatus.State = cicdv1.CommitStatusStateFailure
 				}
+				if rStatus.Conditions[0].Reason == tektonv1beta1.TaskRunReasonTimedOut.String() {
+					jobStatus.Reason = cicdv1.JobReasonTimedOut
+				}
+				// The retry stopped by stopUnretriableJob is reported with the exit code of the last failed attempt
+				if msg, stopped := notRetriedMessage(rStatus, j); stopped {
+					jobStatus.Message = msg
+				}
 			}
 			break
 		}
@@ -462,12 +600,12 @@ func reflectFromRuns(prStatus tektonv1beta1.PipelineRunStatus, j *cicdv1.Job, jo
 	}
 }
 
-func (p *pipelineManager) updateGitCommitStatus(cfg *cicdv1.IntegrationConfig, job *cicdv1.IntegrationJob, stateChanged []bool) error {
+func (p *pipelineManager) updateGitCommitStatus(ctx context.Context, cfg *cicdv1.IntegrationConfig, job *cicdv1.IntegrationJob, stateChanged []bool) error {
 	// Skip if token is nil
 	if cfg.Spec.Git.Token == nil {
 		return nil
 	}
-	gitCli, err := utils.GetGitCli(cfg, p.Client)
+	gitCli, err := utils.GetGitCliWithContext(ctx, cfg, p.Client)
 	if err != nil {
 		return err
 	}
@@ -488,6 +626,16 @@ func (p *pipelineManager) updateGitCommitStatus(cfg *cicdv1.IntegrationConfig, j
 			case cicdv1.CommitStatusStateFailure:
 				msg = JobMessageFailure
 			}
+			canceled := job.Status.State == cicdv1.IntegrationJobStateCanceled && j.State == cicdv1.CommitStatusStateError
+			if canceled {
+				msg = JobMessageCanceled
+			}
+			if j.Reason == cicdv1.JobReasonTimedOut {
+				msg = JobMessageTimedOut
+			}
+			if j.Attempt > 1 {
+				msg = fmt.Sprintf("%s (attempt %d)", msg, j.Attempt)
+			}
 			if job.Spec.Refs.Pulls != nil {
 				msg = appendBaseShaToDescription(msg, job.Spec.Refs.Base.Sha)
 			}
@@ -499,6 +647,20 @@ func (p *pipelineManager) updateGitCommitStatus(cfg *cicdv1.IntegrationConfig, j
 			} else {
 				sha = job.Spec.Refs.Pulls[0].Sha
 			}
+
+			// Report as a check run, if it's enabled and supported by the git server
+			if checkRunCli, ok := gitCli.(git.CheckRunClient); ok && cfg.Spec.Git.UseChecks {
+				log.Info(fmt.Sprintf("Setting check run %s:%s to %s's %s", j.Name, j.State, cfg.Spec.Git.Repository, sha))
+				checkRun := generateCheckRun(&job.Status.Jobs[i], msg, job.GetReportServerAddress(j.Name))
+				if canceled {
+					checkRun.Conclusion = git.CheckRunConclusionCancelled
+				}
+				if err := checkRunCli.SetCheckRun(sha, checkRun); err != nil {
+					log.Error(err, "")
+				}
+				continue
+			}
+
 			log.Info(fmt.Sprintf("Setting commit status %s:%s to %s's %s", j.Name, j.State, cfg.Spec.Git.Repository, sha))
 			if err := gitCli.SetCommitStatus(sha, git.CommitStatus{Context: j.Name, State: git.CommitStatusState(j.State), Description: msg, TargetURL: job.GetReportServerAddress(j.Name)}); err != nil {
 				log.Error(err, "")
@@ -551,11 +713,45 @@ func ParseBaseFromDescription(desc string) string {
 
 // +kubebuilder:rbac:groups="",resources=events,verbs=get;list;watch;create;update;patch;delete
 
-func (p *pipelineManager) emitEvents(job *cicdv1.IntegrationJob, oldState cicdv1.IntegrationJobState, oldMessage string) error {
-	if oldState == job.Status.State && oldMessage == job.Status.Message {
-		return nil
+// emitEvents emits events for the changes from the old status.
+// Cloud events are emitted only if the state is changed, and also for each job started/finished
+func (p *pipelineManager) emitEvents(job *cicdv1.IntegrationJob, oldStatus *cicdv1.IntegrationJobStatus, reason string) error {
+	// Jobs are finished before the IntegrationJob is completed, but started after the IntegrationJob is started
+	if job.Status.CompletionTime != nil {
+		emitJobEvents(job, oldStatus.Jobs)
+	}
+
+	if oldStatus.State != job.Status.State {
+		if err := events.Emit(p.Client, job, corev1.EventTypeNormal, reason, job.Status.Message); err != nil {
+			return err
+		}
+	} else if oldStatus.Message != job.Status.Message {
+		if err := events.EmitK8sEvent(p.Client, job, corev1.EventTypeNormal, reason, job.Status.Message); err != nil {
+			return err
+		}
+	}
+
+	if job.Status.CompletionTime == nil {
+		emitJobEvents(job, oldStatus.Jobs)
+	}
+	return nil
+}
+
+// emitJobEvents emits cloud events for the jobs, which are just started or finished
+func emitJobEvents(job *cicdv1.IntegrationJob, oldJobs []cicdv1.JobStatus) {
+	for i := range job.Status.Jobs {
+		j := &job.Status.Jobs[i]
+		old := cicdv1.JobStatus{}
+		if i < len(oldJobs) && oldJobs[i].Name == j.Name {
+			old = oldJobs[i]
+		}
+		if old.StartTime == nil && j.StartTime != nil {
+			events.EmitCloudEvent(events.NewIntegrationJobCloudEvent(events.CloudEventTypeIntegrationJobJobStarted, job, j))
+		}
+		if old.CompletionTime == nil && j.CompletionTime != nil {
+			events.EmitCloudEvent(events.NewIntegrationJobCloudEvent(events.CloudEventTypeIntegrationJobJobFinished, job, j))
+		}
 	}
-	return events.Emit(p.Client, job, corev1.EventTypeNormal, string(job.Status.State), job.Status.Message)
 }
 
 // Name is a PipelineRun's name for the IntegrationJob j
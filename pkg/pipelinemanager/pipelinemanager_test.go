@@ -17,17 +17,28 @@
 package pipelinemanager
 
 import (
+	"context"
 	"github.com/bmizerany/assert"
 	"github.com/stretchr/testify/require"
 	tektonv1alpha1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
 	tektonv1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
 	"github.com/tektoncd/pipeline/pkg/apis/run/v1alpha1"
 	cicdv1 "github.com/tmax-cloud/cicd-operator/api/v1"
+	"github.com/tmax-cloud/cicd-operator/internal/configs"
+	"github.com/tmax-cloud/cicd-operator/pkg/events"
 	"github.com/tmax-cloud/cicd-operator/pkg/git"
+	gitfake "github.com/tmax-cloud/cicd-operator/pkg/git/fake"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"knative.dev/pkg/apis"
 	"knative.dev/pkg/apis/duck/v1"
 	"knative.dev/pkg/apis/duck/v1beta1"
+	"net/http"
+	"net/http/httptest"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 	"testing"
 	"time"
 )
@@ -274,6 +285,77 @@ func TestGetJobRunStatus(t *testing.T) {
 	}
 }
 
+func TestGetJobRunStatus_retried(t *testing.T) {
+	prStatus := tektonv1beta1.PipelineRunStatus{
+		PipelineRunStatusFields: tektonv1beta1.PipelineRunStatusFields{
+			TaskRuns: map[string]*tektonv1beta1.PipelineRunTaskRunStatus{
+				"matchName": {
+					PipelineTaskName: "matchTask",
+					Status: &tektonv1beta1.TaskRunStatus{
+						TaskRunStatusFields: tektonv1beta1.TaskRunStatusFields{
+							PodName:   "match-3",
+							StartTime: &metav1.Time{Time: time.Now()},
+							RetriesStatus: []tektonv1beta1.TaskRunStatus{
+								{TaskRunStatusFields: tektonv1beta1.TaskRunStatusFields{PodName: "match-1"}},
+								{TaskRunStatusFields: tektonv1beta1.TaskRunStatusFields{PodName: "match-2"}},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	status := getJobRunStatus(prStatus, &cicdv1.Job{Container: corev1.Container{Name: "matchTask"}})
+	require.Equal(t, cicdv1.CommitStatusStatePending, status.State)
+	require.Equal(t, "match-3", status.PodName)
+	require.Equal(t, 3, status.Attempt)
+	require.Equal(t, []string{"match-1", "match-2", "match-3"}, status.AttemptPodNames)
+}
+
+func TestGetJobRunStatus_notRetried(t *testing.T) {
+	failedAttempt := func(podName string, exitCode int32) tektonv1beta1.TaskRunStatus {
+		return tektonv1beta1.TaskRunStatus{TaskRunStatusFields: tektonv1beta1.TaskRunStatusFields{
+			PodName: podName,
+			Steps:   []tektonv1beta1.StepState{{ContainerState: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{ExitCode: exitCode}}}},
+		}}
+	}
+	prStatus := tektonv1beta1.PipelineRunStatus{
+		PipelineRunStatusFields: tektonv1beta1.PipelineRunStatusFields{
+			TaskRuns: map[string]*tektonv1beta1.PipelineRunTaskRunStatus{
+				"matchName": {
+					PipelineTaskName: "matchTask",
+					Status: &tektonv1beta1.TaskRunStatus{
+						Status: v1beta1.Status{Conditions: v1beta1.Conditions{{
+							Type:    apis.ConditionSucceeded,
+							Status:  corev1.ConditionFalse,
+							Reason:  tektonv1beta1.TaskRunReasonCancelled.String(),
+							Message: "TaskRun \"matchName\" was cancelled",
+						}}},
+						TaskRunStatusFields: tektonv1beta1.TaskRunStatusFields{
+							PodName:        "match-3",
+							StartTime:      &metav1.Time{Time: time.Now()},
+							CompletionTime: &metav1.Time{Time: time.Now()},
+							RetriesStatus:  []tektonv1beta1.TaskRunStatus{failedAttempt("match-1", 137), failedAttempt("match-2", 1)},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	retried := &cicdv1.Job{Container: corev1.Container{Name: "matchTask"}, Retries: &cicdv1.JobRetries{Count: 3, OnExitCodes: []int32{137}}}
+	status := getJobRunStatus(prStatus, retried)
+	require.Equal(t, cicdv1.CommitStatusStateFailure, status.State)
+	require.Equal(t, "Failed with exit code 1, which is not retried", status.Message)
+	require.Equal(t, 3, status.Attempt)
+
+	// Canceled for other reasons (e.g., the IntegrationJob is canceled)
+	retried.Retries.OnExitCodes = []int32{1}
+	status = getJobRunStatus(prStatus, retried)
+	require.Equal(t, "TaskRun \"matchName\" was cancelled", status.Message)
+}
+
 func TestGetParams(t *testing.T) {
 	tc := map[string]struct {
 		job *cicdv1.IntegrationJob
@@ -496,3 +578,354 @@ func TestGetParams(t *testing.T) {
 //		})
 //	}
 //}
+
+func TestPipelineManager_Generate(t *testing.T) {
+	job := &cicdv1.IntegrationJob{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-job", Namespace: "default"},
+		Spec: cicdv1.IntegrationJobSpec{
+			ConfigRef: cicdv1.IntegrationJobConfigRef{Name: "test-config", Type: cicdv1.JobTypePreSubmit},
+			Jobs: cicdv1.Jobs{
+				{
+					Container:    corev1.Container{Name: "test", Image: "golang"},
+					Script:       "go test ./...",
+					SkipCheckout: true,
+					Matrix:       &cicdv1.JobMatrix{Axes: map[string][]string{"go": {"1.16", "1.17"}}},
+					Retries:      &cicdv1.JobRetries{Count: 2},
+				},
+				{Container: corev1.Container{Name: "report", Image: "alpine"}, Script: "echo done", SkipCheckout: true, After: []string{"test"}, Timeout: &metav1.Duration{Duration: time.Minute}},
+			},
+			Refs:       cicdv1.IntegrationJobRefs{Repository: "tmax-cloud/cicd-test", Link: "https://github.com/tmax-cloud/cicd-test"},
+			Timeout:    &metav1.Duration{Duration: time.Hour},
+			JobTimeout: &metav1.Duration{Duration: 30 * time.Minute},
+		},
+	}
+
+	pm := &pipelineManager{}
+	pl, pr, err := pm.Generate(job)
+	require.NoError(t, err)
+	require.Equal(t, 30*time.Minute, pr.Spec.Timeout.Duration)
+
+	tasks := pl.Spec.Tasks
+	require.Len(t, tasks, 3)
+	require.Equal(t, "test-1-16", tasks[0].Name)
+	require.Equal(t, "test-1-17", tasks[1].Name)
+	require.Contains(t, tasks[1].TaskSpec.Steps[0].Env, corev1.EnvVar{Name: "MATRIX_GO", Value: "1.17"})
+	require.Equal(t, 2, tasks[1].Retries)
+	require.Equal(t, "report", tasks[2].Name)
+	require.Equal(t, []string{"test-1-16", "test-1-17"}, tasks[2].RunAfter)
+	require.Nil(t, tasks[1].Timeout)
+	require.Equal(t, time.Minute, tasks[2].Timeout.Duration)
+
+	// Each expanded job has its own status
+	initState(job)
+	require.Len(t, job.Status.Jobs, 3)
+	require.Equal(t, "test-1-16", job.Status.Jobs[0].Name)
+	require.Equal(t, "test-1-17", job.Status.Jobs[1].Name)
+}
+
+func TestReflectStatus_canceled(t *testing.T) {
+	s := runtime.NewScheme()
+	utilruntime.Must(corev1.AddToScheme(s))
+	utilruntime.Must(cicdv1.AddToScheme(s))
+	utilruntime.Must(tektonv1beta1.AddToScheme(s))
+
+	sha := "4c2d7b4e5fcf7b5d0c3f2e6a6c3b7e8d9f0a1b2c"
+	now := metav1.Now()
+	job := &cicdv1.IntegrationJob{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-job", Namespace: "default"},
+		Spec: cicdv1.IntegrationJobSpec{
+			ConfigRef: cicdv1.IntegrationJobConfigRef{Name: "test-config", Type: cicdv1.JobTypePreSubmit},
+			Jobs: cicdv1.Jobs{
+				{Container: corev1.Container{Name: "lint"}},
+				{Container: corev1.Container{Name: "test"}, After: []string{"lint"}},
+			},
+			Refs: cicdv1.IntegrationJobRefs{
+				Pulls: []cicdv1.IntegrationJobRefsPull{{ID: 1, Sha: sha}},
+			},
+		},
+		Status: cicdv1.IntegrationJobStatus{
+			State:   cicdv1.IntegrationJobStateCanceled,
+			Message: "Superseded by 3196ccc37bcae94852079b04fcbfaf928341d6e9",
+		},
+	}
+	pr := &tektonv1beta1.PipelineRun{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-job", Namespace: "default", CreationTimestamp: now},
+		Status: tektonv1beta1.PipelineRunStatus{
+			PipelineRunStatusFields: tektonv1beta1.PipelineRunStatusFields{
+				TaskRuns: map[string]*tektonv1beta1.PipelineRunTaskRunStatus{
+					"test-job-lint": {
+						PipelineTaskName: "lint",
+						Status: &tektonv1beta1.TaskRunStatus{
+							Status: v1beta1.Status{Conditions: []apis.Condition{{Type: apis.ConditionSucceeded, Status: corev1.ConditionTrue}}},
+							TaskRunStatusFields: tektonv1beta1.TaskRunStatusFields{
+								StartTime:      &now,
+								CompletionTime: &now,
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	cfg := &cicdv1.IntegrationConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-config", Namespace: "default"},
+		Spec: cicdv1.IntegrationConfigSpec{
+			Git: cicdv1.GitConfig{
+				Type:       cicdv1.GitTypeFake,
+				Repository: "tmax-cloud/cicd-test",
+				Token:      &cicdv1.GitToken{Value: "test-token"},
+			},
+		},
+	}
+	gitfake.Repos = map[string]*gitfake.Repo{
+		"tmax-cloud/cicd-test": {CommitStatuses: map[string][]git.CommitStatus{}},
+	}
+
+	fakeCli := fake.NewClientBuilder().WithScheme(s).WithObjects(pr).Build()
+	pm := &pipelineManager{Client: fakeCli, Scheme: s}
+
+	require.NoError(t, pm.ReflectStatus(pr, job, cfg))
+
+	// PipelineRun should be canceled
+	resultPr := &tektonv1beta1.PipelineRun{}
+	require.NoError(t, fakeCli.Get(context.Background(), types.NamespacedName{Name: "test-job", Namespace: "default"}, resultPr))
+	require.Equal(t, tektonv1beta1.PipelineRunSpecStatus(tektonv1beta1.PipelineRunSpecStatusCancelled), resultPr.Spec.Status)
+
+	// Completed job keeps its state, while the others are canceled
+	require.Equal(t, cicdv1.IntegrationJobStateCanceled, job.Status.State)
+	require.NotNil(t, job.Status.CompletionTime)
+	require.Len(t, job.Status.Jobs, 2)
+	require.Equal(t, cicdv1.CommitStatusStateSuccess, job.Status.Jobs[0].State)
+	require.Equal(t, cicdv1.CommitStatusStateError, job.Status.Jobs[1].State)
+	require.Equal(t, JobMessageCanceled, job.Status.Jobs[1].Message)
+
+	statuses := gitfake.Repos["tmax-cloud/cicd-test"].CommitStatuses[sha]
+	require.Len(t, statuses, 2)
+	require.Equal(t, "test", statuses[1].Context)
+	require.Equal(t, git.CommitStatusStateError, statuses[1].State)
+	require.Contains(t, statuses[1].Description, JobMessageCanceled)
+}
+
+func TestReflectStatus_timedOut(t *testing.T) {
+	s := runtime.NewScheme()
+	utilruntime.Must(corev1.AddToScheme(s))
+	utilruntime.Must(cicdv1.AddToScheme(s))
+	utilruntime.Must(tektonv1beta1.AddToScheme(s))
+
+	sha := "4c2d7b4e5fcf7b5d0c3f2e6a6c3b7e8d9f0a1b2c"
+	now := metav1.Now()
+	succeeded := &tektonv1beta1.TaskRunStatus{
+		Status: v1beta1.Status{Conditions: []apis.Condition{{Type: apis.ConditionSucceeded, Status: corev1.ConditionTrue}}},
+		TaskRunStatusFields: tektonv1beta1.TaskRunStatusFields{
+			StartTime:      &now,
+			CompletionTime: &now,
+		},
+	}
+
+	tc := map[string]struct {
+		prReason    tektonv1beta1.PipelineRunReason
+		testTaskRun *tektonv1beta1.TaskRunStatus
+
+		expectedState   cicdv1.IntegrationJobState
+		expectedEvents  []string
+		expectedMessage string
+	}{
+		"jobTimeout": {
+			prReason: tektonv1beta1.PipelineRunReasonFailed,
+			testTaskRun: &tektonv1beta1.TaskRunStatus{
+				Status: v1beta1.Status{Conditions: []apis.Condition{{
+					Type:    apis.ConditionSucceeded,
+					Status:  corev1.ConditionFalse,
+					Reason:  tektonv1beta1.TaskRunReasonTimedOut.String(),
+					Message: "TaskRun \"test-job-test\" failed to finish within \"1m0s\"",
+				}}},
+				TaskRunStatusFields: tektonv1beta1.TaskRunStatusFields{
+					StartTime:      &now,
+					CompletionTime: &now,
+				},
+			},
+			expectedState:   cicdv1.IntegrationJobStateFailed,
+			expectedEvents:  []string{"Job test timed out"},
+			expectedMessage: "TaskRun \"test-job-test\" failed to finish within \"1m0s\"",
+		},
+		"integrationJobTimeout": {
+			prReason: tektonv1beta1.PipelineRunReasonTimedOut,
+			testTaskRun: &tektonv1beta1.TaskRunStatus{
+				TaskRunStatusFields: tektonv1beta1.TaskRunStatusFields{
+					StartTime: &now,
+				},
+			},
+			expectedState:   cicdv1.IntegrationJobStateTimedOut,
+			expectedEvents:  []string{""},
+			expectedMessage: JobMessageTimedOut,
+		},
+	}
+
+	for name, c := range tc {
+		t.Run(name, func(t *testing.T) {
+			job := &cicdv1.IntegrationJob{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-job", Namespace: "default"},
+				Spec: cicdv1.IntegrationJobSpec{
+					ConfigRef: cicdv1.IntegrationJobConfigRef{Name: "test-config", Type: cicdv1.JobTypePreSubmit},
+					Jobs: cicdv1.Jobs{
+						{Container: corev1.Container{Name: "lint"}},
+						{Container: corev1.Container{Name: "test"}, Timeout: &metav1.Duration{Duration: time.Minute}},
+					},
+					Refs: cicdv1.IntegrationJobRefs{
+						Pulls: []cicdv1.IntegrationJobRefsPull{{ID: 1, Sha: sha}},
+					},
+				},
+				Status: cicdv1.IntegrationJobStatus{State: cicdv1.IntegrationJobStateRunning},
+			}
+			pr := &tektonv1beta1.PipelineRun{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-job", Namespace: "default", CreationTimestamp: now},
+				Status: tektonv1beta1.PipelineRunStatus{
+					Status: v1beta1.Status{Conditions: []apis.Condition{{Type: apis.ConditionSucceeded, Status: corev1.ConditionFalse, Reason: c.prReason.String()}}},
+					PipelineRunStatusFields: tektonv1beta1.PipelineRunStatusFields{
+						CompletionTime: &now,
+						TaskRuns: map[string]*tektonv1beta1.PipelineRunTaskRunStatus{
+							"test-job-lint": {PipelineTaskName: "lint", Status: succeeded},
+							"test-job-test": {PipelineTaskName: "test", Status: c.testTaskRun},
+						},
+					},
+				},
+			}
+			cfg := &cicdv1.IntegrationConfig{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-config", Namespace: "default"},
+				Spec: cicdv1.IntegrationConfigSpec{
+					Git: cicdv1.GitConfig{
+						Type:       cicdv1.GitTypeFake,
+						Repository: "tmax-cloud/cicd-test",
+						Token:      &cicdv1.GitToken{Value: "test-token"},
+					},
+				},
+			}
+			gitfake.Repos = map[string]*gitfake.Repo{
+				"tmax-cloud/cicd-test": {CommitStatuses: map[string][]git.CommitStatus{}},
+			}
+
+			fakeCli := fake.NewClientBuilder().WithScheme(s).WithObjects(pr).Build()
+			pm := &pipelineManager{Client: fakeCli, Scheme: s}
+
+			require.NoError(t, pm.ReflectStatus(pr, job, cfg))
+
+			require.Equal(t, c.expectedState, job.Status.State)
+			require.NotNil(t, job.Status.CompletionTime)
+			require.Len(t, job.Status.Jobs, 2)
+			require.Equal(t, cicdv1.CommitStatusStateSuccess, job.Status.Jobs[0].State)
+			require.Equal(t, "", job.Status.Jobs[0].Reason)
+			require.Equal(t, cicdv1.CommitStatusStateFailure, job.Status.Jobs[1].State)
+			require.Equal(t, cicdv1.JobReasonTimedOut, job.Status.Jobs[1].Reason)
+			require.Equal(t, c.expectedMessage, job.Status.Jobs[1].Message)
+			require.NotNil(t, job.Status.Jobs[1].CompletionTime)
+
+			statuses := gitfake.Repos["tmax-cloud/cicd-test"].CommitStatuses[sha]
+			require.Len(t, statuses, 2)
+			require.Equal(t, "test", statuses[1].Context)
+			require.Equal(t, git.CommitStatusStateFailure, statuses[1].State)
+			require.Contains(t, statuses[1].Description, JobMessageTimedOut)
+
+			evList := &corev1.EventList{}
+			require.NoError(t, fakeCli.List(context.Background(), evList))
+			var messages []string
+			for _, ev := range evList.Items {
+				if ev.Reason == events.ReasonTimedOut {
+					messages = append(messages, ev.Message)
+				}
+			}
+			require.ElementsMatch(t, c.expectedEvents, messages)
+		})
+	}
+}
+
+func TestPipelineManager_emitEvents(t *testing.T) {
+	s := runtime.NewScheme()
+	utilruntime.Must(corev1.AddToScheme(s))
+	utilruntime.Must(cicdv1.AddToScheme(s))
+
+	received := make(chan string, 10)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received <- r.Header.Get("ce-type")
+	}))
+	defer srv.Close()
+	configs.CloudEventsSink = srv.URL
+	defer func() {
+		configs.CloudEventsSink = ""
+	}()
+
+	now := metav1.Now()
+	newJob := func(state cicdv1.IntegrationJobState, jobs ...cicdv1.JobStatus) *cicdv1.IntegrationJob {
+		job := &cicdv1.IntegrationJob{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-job", Namespace: "default"},
+			Spec: cicdv1.IntegrationJobSpec{
+				ConfigRef: cicdv1.IntegrationJobConfigRef{Name: "test-config"},
+				Jobs:      cicdv1.Jobs{{Container: corev1.Container{Name: "lint"}}},
+			},
+			Status: cicdv1.IntegrationJobStatus{State: state, Jobs: jobs},
+		}
+		if state == cicdv1.IntegrationJobStateCompleted {
+			job.Status.CompletionTime = &now
+		}
+		return job
+	}
+
+	tc := map[string]struct {
+		oldStatus *cicdv1.IntegrationJobStatus
+		job       *cicdv1.IntegrationJob
+		reason    string
+
+		expectedEvents []events.CloudEventType
+	}{
+		"queued": {
+			oldStatus:      &cicdv1.IntegrationJobStatus{},
+			job:            newJob(cicdv1.IntegrationJobStatePending, cicdv1.JobStatus{Name: "lint"}),
+			reason:         string(cicdv1.IntegrationJobStatePending),
+			expectedEvents: []events.CloudEventType{events.CloudEventTypeIntegrationJobQueued},
+		},
+		"messageOnly": {
+			oldStatus: &cicdv1.IntegrationJobStatus{State: cicdv1.IntegrationJobStatePending, Jobs: []cicdv1.JobStatus{{Name: "lint"}}},
+			job: func() *cicdv1.IntegrationJob {
+				job := newJob(cicdv1.IntegrationJobStatePending, cicdv1.JobStatus{Name: "lint"})
+				job.Status.Message = "waiting: global quota 5/5"
+				return job
+			}(),
+			reason: string(cicdv1.IntegrationJobStatePending),
+		},
+		"started": {
+			oldStatus:      &cicdv1.IntegrationJobStatus{State: cicdv1.IntegrationJobStatePending, Jobs: []cicdv1.JobStatus{{Name: "lint"}}},
+			job:            newJob(cicdv1.IntegrationJobStateRunning, cicdv1.JobStatus{Name: "lint", StartTime: &now}),
+			reason:         string(cicdv1.IntegrationJobStateRunning),
+			expectedEvents: []events.CloudEventType{events.CloudEventTypeIntegrationJobStarted, events.CloudEventTypeIntegrationJobJobStarted},
+		},
+		"completed": {
+			oldStatus:      &cicdv1.IntegrationJobStatus{State: cicdv1.IntegrationJobStateRunning, Jobs: []cicdv1.JobStatus{{Name: "lint", StartTime: &now}}},
+			job:            newJob(cicdv1.IntegrationJobStateCompleted, cicdv1.JobStatus{Name: "lint", StartTime: &now, CompletionTime: &now}),
+			reason:         string(cicdv1.IntegrationJobStateCompleted),
+			expectedEvents: []events.CloudEventType{events.CloudEventTypeIntegrationJobJobFinished, events.CloudEventTypeIntegrationJobSucceeded},
+		},
+	}
+
+	for name, c := range tc {
+		t.Run(name, func(t *testing.T) {
+			pm := &pipelineManager{Client: fake.NewClientBuilder().WithScheme(s).Build(), Scheme: s}
+			require.NoError(t, pm.emitEvents(c.job, c.oldStatus, c.reason))
+
+			var result []events.CloudEventType
+			for range c.expectedEvents {
+				select {
+				case ev := <-received:
+					result = append(result, events.CloudEventType(ev))
+				case <-time.After(5 * time.Second):
+					t.Fatal("cloud event is not received")
+				}
+			}
+			require.Equal(t, c.expectedEvents, result)
+
+			select {
+			case ev := <-received:
+				t.Fatalf("unexpected cloud event %s is received", ev)
+			case <-time.After(100 * time.Millisecond):
+			}
+		})
+	}
+}
@@ -0,0 +1,92 @@
+/*
+ Copyright 2021 The CI/CD Operator Authors
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package pipelinemanager
+
+import (
+	"context"
+	"fmt"
+
+	tektonv1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	cicdv1 "github.com/tmax-cloud/cicd-operator/api/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// +kubebuilder:rbac:groups=tekton.dev,resources=taskruns,verbs=get;list;watch;patch
+
+// stopUnretriableJob cancels the TaskRun of the job being retried, if its last attempt failed with an exit code
+// not in the job's retries.onExitCodes. Tekton retries the failed task regardless of the exit code, so the
+// retry is stopped by canceling it. The next attempt is already started when the failed attempt is seen, so it is
+// canceled as well
+func (p *pipelineManager) stopUnretriableJob(pr *tektonv1beta1.PipelineRun, j *cicdv1.Job) error {
+	if j.Retries == nil || len(j.Retries.OnExitCodes) == 0 {
+		return nil
+	}
+
+	for taskRunName, runStatus := range pr.Status.TaskRuns {
+		if runStatus.Status == nil || runStatus.PipelineTaskName != j.Name {
+			continue
+		}
+		rStatus := runStatus.Status
+		if rStatus.CompletionTime != nil || len(rStatus.RetriesStatus) == 0 {
+			return nil
+		}
+		exitCode, failed := failedExitCode(&rStatus.RetriesStatus[len(rStatus.RetriesStatus)-1])
+		if !failed || j.Retries.Retriable(exitCode) {
+			return nil
+		}
+
+		tr := &tektonv1beta1.TaskRun{}
+		if err := p.Client.Get(context.Background(), types.NamespacedName{Name: taskRunName, Namespace: pr.Namespace}, tr); err != nil {
+			return err
+		}
+		if tr.IsCancelled() {
+			return nil
+		}
+		log.Info(fmt.Sprintf("job %s failed with exit code %d, which is not retried", j.Name, exitCode))
+		original := tr.DeepCopy()
+		tr.Spec.Status = tektonv1beta1.TaskRunSpecStatusCancelled
+		return p.Client.Patch(context.Background(), tr, client.MergeFrom(original))
+	}
+	return nil
+}
+
+// notRetriedMessage returns the message of the job, whose retry is stopped by stopUnretriableJob.
+// It returns false if the TaskRun is not canceled, or its last attempt failed with an exit code to be retried
+func notRetriedMessage(rStatus *tektonv1beta1.TaskRunStatus, j *cicdv1.Job) (string, bool) {
+	if j.Retries == nil || len(j.Retries.OnExitCodes) == 0 || rStatus.CompletionTime == nil || len(rStatus.RetriesStatus) == 0 {
+		return "", false
+	}
+	if len(rStatus.Conditions) == 0 || rStatus.Conditions[0].Reason != tektonv1beta1.TaskRunReasonCancelled.String() {
+		return "", false
+	}
+	exitCode, failed := failedExitCode(&rStatus.RetriesStatus[len(rStatus.RetriesStatus)-1])
+	if !failed || j.Retries.Retriable(exitCode) {
+		return "", false
+	}
+	return fmt.Sprintf("Failed with exit code %d, which is not retried", exitCode), true
+}
+
+// failedExitCode returns the exit code of the first failed step of the attempt
+func failedExitCode(attempt *tektonv1beta1.TaskRunStatus) (int32, bool) {
+	for _, step := range attempt.Steps {
+		if step.Terminated != nil && step.Terminated.ExitCode != 0 {
+			return step.Terminated.ExitCode, true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,105 @@
+/*
+ Copyright 2021 The CI/CD Operator Authors
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package pipelinemanager
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	tektonv1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	cicdv1 "github.com/tmax-cloud/cicd-operator/api/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestPipelineManager_stopUnretriableJob(t *testing.T) {
+	s := runtime.NewScheme()
+	utilruntime.Must(tektonv1beta1.AddToScheme(s))
+
+	failedAttempt := func(exitCode int32) tektonv1beta1.TaskRunStatus {
+		return tektonv1beta1.TaskRunStatus{
+			TaskRunStatusFields: tektonv1beta1.TaskRunStatusFields{
+				PodName: "test-job-e2e-pod-1",
+				Steps: []tektonv1beta1.StepState{
+					{ContainerState: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{ExitCode: 0}}},
+					{ContainerState: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{ExitCode: exitCode}}},
+				},
+			},
+		}
+	}
+
+	tc := map[string]struct {
+		retries       *cicdv1.JobRetries
+		retriesStatus []tektonv1beta1.TaskRunStatus
+
+		expectedCanceled bool
+	}{
+		"retriable": {
+			retries:       &cicdv1.JobRetries{Count: 2, OnExitCodes: []int32{137}},
+			retriesStatus: []tektonv1beta1.TaskRunStatus{failedAttempt(137)},
+		},
+		"unretriable": {
+			retries:          &cicdv1.JobRetries{Count: 2, OnExitCodes: []int32{137}},
+			retriesStatus:    []tektonv1beta1.TaskRunStatus{failedAttempt(1)},
+			expectedCanceled: true,
+		},
+		"anyExitCode": {
+			retries:       &cicdv1.JobRetries{Count: 2},
+			retriesStatus: []tektonv1beta1.TaskRunStatus{failedAttempt(1)},
+		},
+		"notRetried": {
+			retries: &cicdv1.JobRetries{Count: 2, OnExitCodes: []int32{137}},
+		},
+	}
+
+	for name, c := range tc {
+		t.Run(name, func(t *testing.T) {
+			tr := &tektonv1beta1.TaskRun{ObjectMeta: metav1.ObjectMeta{Name: "test-job-e2e", Namespace: "default"}}
+			pr := &tektonv1beta1.PipelineRun{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-job", Namespace: "default"},
+				Status: tektonv1beta1.PipelineRunStatus{
+					PipelineRunStatusFields: tektonv1beta1.PipelineRunStatusFields{
+						TaskRuns: map[string]*tektonv1beta1.PipelineRunTaskRunStatus{
+							"test-job-e2e": {
+								PipelineTaskName: "e2e",
+								Status: &tektonv1beta1.TaskRunStatus{
+									TaskRunStatusFields: tektonv1beta1.TaskRunStatusFields{
+										PodName:       "test-job-e2e-pod-2",
+										RetriesStatus: c.retriesStatus,
+									},
+								},
+							},
+						},
+					},
+				},
+			}
+			fakeCli := fake.NewClientBuilder().WithScheme(s).WithObjects(tr).Build()
+			pm := &pipelineManager{Client: fakeCli, Scheme: s}
+
+			require.NoError(t, pm.stopUnretriableJob(pr, &cicdv1.Job{Container: corev1.Container{Name: "e2e"}, Retries: c.retries}))
+
+			result := &tektonv1beta1.TaskRun{}
+			require.NoError(t, fakeCli.Get(context.Background(), types.NamespacedName{Name: "test-job-e2e", Namespace: "default"}, result))
+			require.Equal(t, c.expectedCanceled, result.IsCancelled())
+		})
+	}
+}
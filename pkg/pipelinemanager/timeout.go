@@ -0,0 +1,64 @@
+/*
+ Copyright 2021 The CI/CD Operator Authors
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package pipelinemanager
+
+import (
+	"fmt"
+	"time"
+
+	tektonv1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	cicdv1 "github.com/tmax-cloud/cicd-operator/api/v1"
+	"github.com/tmax-cloud/cicd-operator/pkg/events"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// reflectTimedOut marks the unfinished jobs of the timed-out IntegrationJob as timed out.
+// The TaskRuns may keep running after the PipelineRun is timed out, so they are not waited for
+func reflectTimedOut(job *cicdv1.IntegrationJob, pr *tektonv1beta1.PipelineRun, stateChanged []bool) {
+	completionTime := pr.Status.CompletionTime.DeepCopy()
+	if completionTime == nil {
+		completionTime = &metav1.Time{Time: time.Now()}
+	}
+	for i := range job.Status.Jobs {
+		j := &job.Status.Jobs[i]
+		if j.CompletionTime != nil {
+			continue
+		}
+		j.State = cicdv1.CommitStatusStateFailure
+		j.Reason = cicdv1.JobReasonTimedOut
+		j.Message = JobMessageTimedOut
+		j.CompletionTime = completionTime
+		stateChanged[i] = true
+	}
+}
+
+// emitJobTimedOutEvents emits k8s events for the jobs, which are just timed out by their own timeouts
+func (p *pipelineManager) emitJobTimedOutEvents(job *cicdv1.IntegrationJob, oldJobs []cicdv1.JobStatus, stateChanged []bool) error {
+	for i, j := range job.Status.Jobs {
+		if !stateChanged[i] || j.Reason != cicdv1.JobReasonTimedOut {
+			continue
+		}
+		if i < len(oldJobs) && oldJobs[i].Name == j.Name && oldJobs[i].Reason == cicdv1.JobReasonTimedOut {
+			continue
+		}
+		if err := events.EmitK8sEvent(p.Client, job, corev1.EventTypeWarning, events.ReasonTimedOut, fmt.Sprintf("Job %s timed out", j.Name)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+/*
+ Copyright 2021 The CI/CD Operator Authors
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+// Package plugins registers the webhook plugins to the server
+package plugins
+
+import (
+	"github.com/tmax-cloud/cicd-operator/pkg/chatops"
+	"github.com/tmax-cloud/cicd-operator/pkg/chatops/plugins/approve"
+	"github.com/tmax-cloud/cicd-operator/pkg/chatops/plugins/hold"
+	"github.com/tmax-cloud/cicd-operator/pkg/chatops/plugins/trigger"
+	"github.com/tmax-cloud/cicd-operator/pkg/dispatcher"
+	"github.com/tmax-cloud/cicd-operator/pkg/git"
+	"github.com/tmax-cloud/cicd-operator/pkg/plugins/size"
+	"github.com/tmax-cloud/cicd-operator/pkg/server"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Register registers every webhook plugin (dispatcher, chat-ops, approve and size) to the server,
+// so a webhook is handled the same way whether it is received by the webhook server or replayed by the api server
+func Register(c client.Client) {
+	// Init chat-ops
+	co := chatops.New(c)
+
+	// Init chat-ops plugins
+	approveHandler := &approve.Handler{Client: c}
+	triggerHandler := &trigger.Handler{Client: c}
+	holdHandler := &hold.Handler{Client: c}
+
+	co.RegisterCommandHandler(approve.CommandTypeApprove, approveHandler.HandleChatOps)
+	co.RegisterCommandHandler(approve.CommandTypeGitLabApprove, approveHandler.HandleChatOps)
+	co.RegisterCommandHandler(trigger.CommandTypeTest, triggerHandler.HandleChatOps)
+	co.RegisterCommandHandler(trigger.CommandTypeRetest, triggerHandler.HandleChatOps)
+	co.RegisterCommandHandler(hold.CommandTypeHold, holdHandler.HandleChatOps)
+
+	// Add plugins for webhook
+	server.AddPlugin([]git.EventType{git.EventTypePullRequest, git.EventTypePush, git.EventTypeTagCreate, git.EventTypeRelease}, &dispatcher.Dispatcher{Client: c})
+	server.AddPlugin([]git.EventType{git.EventTypeIssueComment, git.EventTypePullRequestReview, git.EventTypePullRequestReviewComment, git.EventTypeCommitComment}, co)
+	server.AddPlugin([]git.EventType{git.EventTypePullRequest, git.EventTypePullRequestReview}, approveHandler)
+	server.AddPlugin([]git.EventType{git.EventTypePullRequest}, &size.Size{Client: c})
+}
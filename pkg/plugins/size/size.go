@@ -17,6 +17,7 @@
 package size
 
 import (
+	"context"
 	"fmt"
 	"strings"
 
@@ -58,7 +59,7 @@ func (s *Size) Name() string {
 }
 
 // Handle handles a pull request event and set size label to the pull request
-func (s *Size) Handle(wh *git.Webhook, config *cicdv1.IntegrationConfig) error {
+func (s *Size) Handle(_ context.Context, wh *git.Webhook, config *cicdv1.IntegrationConfig) error {
 	// Filter only PullRequest event's open/synchronize action
 	pr := wh.PullRequest
 	if wh.EventType != git.EventTypePullRequest || pr == nil || (pr.Action != git.PullRequestActionOpen && pr.Action != git.PullRequestActionReOpen && pr.Action != git.PullRequestActionSynchronize) {
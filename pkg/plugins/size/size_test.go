@@ -17,6 +17,7 @@
 package size
 
 import (
+	"context"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -123,7 +124,7 @@ func TestSize_Handle(t *testing.T) {
 					Labels: c.labels,
 				},
 			}
-			err := size.Handle(wh, ic)
+			err := size.Handle(context.Background(), wh, ic)
 			if c.errorOccurs {
 				require.Error(t, err)
 				require.Equal(t, c.errorMessage, err.Error())
@@ -0,0 +1,211 @@
+/*
+ Copyright 2021 The CI/CD Operator Authors
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package poller
+
+// Poller polls refs of the generic git repositories, which cannot send webhooks, and generates push events for them
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	cicdv1 "github.com/tmax-cloud/cicd-operator/api/v1"
+	"github.com/tmax-cloud/cicd-operator/internal/configs"
+	"github.com/tmax-cloud/cicd-operator/pkg/git"
+	"github.com/tmax-cloud/cicd-operator/pkg/git/generic"
+	"github.com/tmax-cloud/cicd-operator/pkg/server"
+	"github.com/tmax-cloud/cicd-operator/pkg/tracing"
+	"gopkg.in/robfig/cron.v2"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+var log = logf.Log.WithName("git-poller")
+
+// Poller is an interface of poller
+type Poller interface {
+	Start()
+}
+
+// poller polls refs of the generic git repositories
+type poller struct {
+	client client.Client
+
+	cron     *cron.Cron
+	cronSpec string
+	cronID   cron.EntryID
+
+	runPoll chan struct{}
+	lock    sync.Mutex
+
+	handleEvent func(context.Context, *git.Webhook, *cicdv1.IntegrationConfig, ...string) error
+}
+
+// New is a constructor of poller
+func New(c client.Client) (*poller, error) {
+	p := &poller{
+		client:      c,
+		cron:        cron.New(),
+		cronSpec:    parsePollingPeriod(),
+		runPoll:     make(chan struct{}, 1),
+		handleEvent: server.HandleEvent,
+	}
+	configs.RegisterControllerConfigUpdateChan(p.runPoll)
+	id, err := p.cron.AddFunc(p.cronSpec, p.poll)
+	if err != nil {
+		return nil, err
+	}
+	p.cronID = id
+	return p, nil
+}
+
+// Start starts the poller
+func (p *poller) Start() {
+	log.Info("Starting git poller")
+	p.cron.Start()
+
+	for range p.runPoll {
+		if err := p.reconfigure(); err != nil {
+			log.Error(err, "")
+		}
+	}
+}
+
+func (p *poller) reconfigure() error {
+	period := parsePollingPeriod()
+	if p.cronSpec == period {
+		return nil
+	}
+	p.cron.Stop()
+	p.cronSpec = period
+	p.cron.Remove(p.cronID)
+	id, err := p.cron.AddFunc(p.cronSpec, p.poll)
+	if err != nil {
+		return err
+	}
+	p.cronID = id
+
+	log.Info(fmt.Sprintf("Git poller runs %s", p.cronSpec))
+	p.cron.Start()
+	return nil
+}
+
+func (p *poller) poll() {
+	// Polls should not overlap, otherwise same events may be generated twice
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	icList := &cicdv1.IntegrationConfigList{}
+	if err := p.client.List(context.Background(), icList); err != nil {
+		if _, ok := err.(*cache.ErrCacheNotStarted); !ok {
+			log.Error(err, "")
+		}
+		return
+	}
+
+	for i := range icList.Items {
+		ic := &icList.Items[i]
+		if ic.Spec.Git.Type != cicdv1.GitTypeGeneric || ic.DeletionTimestamp != nil {
+			continue
+		}
+		if err := p.pollIntegrationConfig(ic); err != nil {
+			log.Error(err, fmt.Sprintf("cannot poll refs for IntegrationConfig %s/%s", ic.Namespace, ic.Name))
+		}
+	}
+}
+
+// pollIntegrationConfig compares the remote refs with the last seen refs, generates push events for the changed refs
+// and stores the refs to the IntegrationConfig's status.
+// The refs whose events failed are stored with the previous shas, so the events are generated again by the next poll,
+// unless the error is a PermanentError
+func (p *poller) pollIntegrationConfig(ic *cicdv1.IntegrationConfig) error {
+	gitCli := &generic.Client{IntegrationConfig: ic, K8sClient: p.client}
+	if err := gitCli.Init(); err != nil {
+		return err
+	}
+
+	refs, err := gitCli.ListRemoteRefs()
+	if err != nil {
+		return err
+	}
+
+	original := ic.DeepCopy()
+	seen := map[string]string{}
+	for ref, sha := range refs {
+		seen[ref] = sha
+	}
+
+	// Do not generate events for the first poll, not to trigger jobs for all the existing refs
+	if ic.Status.LastSeenRefs != nil {
+		var changed []string
+		for ref, sha := range refs {
+			if ic.Status.LastSeenRefs[ref] != sha {
+				changed = append(changed, ref)
+			}
+		}
+		sort.Strings(changed)
+
+		repo := git.Repository{Name: ic.Spec.Git.Repository, URL: gitCli.RepositoryURL()}
+		for _, ref := range changed {
+			log.Info(fmt.Sprintf("Ref %s of IntegrationConfig %s/%s is updated to %s", ref, ic.Namespace, ic.Name, refs[ref]))
+			wh := &git.Webhook{EventType: git.EventTypePush, Repo: repo, Push: &git.Push{Ref: ref, Sha: refs[ref], Before: ic.Status.LastSeenRefs[ref]}}
+			ctx, span := tracing.Start(context.Background(), "poller.push", tracing.AttributeNamespace.String(ic.Namespace),
+				tracing.AttributeIntegrationConfig.String(ic.Name), tracing.AttributeGitEvent.String(string(wh.EventType)))
+			err := p.handleEvent(ctx, wh, ic)
+			tracing.End(span, err)
+			if err != nil {
+				log.Error(err, "")
+				if !server.IsPermanent(err) {
+					keepLastSeenRef(seen, ic.Status.LastSeenRefs, ref)
+				}
+			}
+		}
+	}
+
+	if equalRefs(ic.Status.LastSeenRefs, seen) {
+		return nil
+	}
+	ic.Status.LastSeenRefs = seen
+	return p.client.Status().Patch(context.Background(), ic, client.MergeFrom(original))
+}
+
+// keepLastSeenRef sets the ref back to the last seen sha, or removes it if it was not seen before
+func keepLastSeenRef(seen, lastSeen map[string]string, ref string) {
+	if sha, exist := lastSeen[ref]; exist {
+		seen[ref] = sha
+	} else {
+		delete(seen, ref)
+	}
+}
+
+func equalRefs(a, b map[string]string) bool {
+	if a == nil || len(a) != len(b) {
+		return false
+	}
+	for ref, sha := range a {
+		if b[ref] != sha {
+			return false
+		}
+	}
+	return true
+}
+
+func parsePollingPeriod() string {
+	return fmt.Sprintf("@every %ds", configs.GitPollingPeriod)
+}
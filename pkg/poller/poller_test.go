@@ -0,0 +1,232 @@
+/*
+ Copyright 2021 The CI/CD Operator Authors
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package poller
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	cicdv1 "github.com/tmax-cloud/cicd-operator/api/v1"
+	"github.com/tmax-cloud/cicd-operator/internal/configs"
+	"github.com/tmax-cloud/cicd-operator/pkg/git"
+	"github.com/tmax-cloud/cicd-operator/pkg/server"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+const (
+	testMasterSha = "a2c8b1e7e4a1d0e6f4c0a9c6c9d3f0e1b2c3d4e5"
+	testDevSha    = "b3d9c2f8f5b2e1f7a5d1b0d7d0e4a1f2c3d4e5f6"
+	testOldSha    = "c4e0d3a9a6c3f2a8b6e2c1e8e1f5b2a3d4e5f6a7"
+)
+
+func TestNew(t *testing.T) {
+	configs.GitPollingPeriod = 30
+	p, err := New(fake.NewClientBuilder().Build())
+	require.NoError(t, err)
+	require.Equal(t, "@every 30s", p.cronSpec)
+}
+
+func Test_poller_reconfigure(t *testing.T) {
+	configs.GitPollingPeriod = 30
+	p, err := New(fake.NewClientBuilder().Build())
+	require.NoError(t, err)
+	p.cron.Start()
+
+	configs.GitPollingPeriod = 10
+	require.NoError(t, p.reconfigure())
+	require.Equal(t, "@every 10s", p.cronSpec)
+	p.cron.Stop()
+}
+
+func Test_poller_poll(t *testing.T) {
+	s := runtime.NewScheme()
+	utilruntime.Must(cicdv1.AddToScheme(s))
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(testMasterSha + "\trefs/heads/master\n" + testDevSha + "\trefs/heads/dev\n"))
+	}))
+	defer srv.Close()
+
+	tc := map[string]struct {
+		gitType      cicdv1.GitType
+		lastSeenRefs map[string]string
+
+		expectedPushes       []git.Push
+		expectedLastSeenRefs map[string]string
+	}{
+		"firstPoll": {
+			gitType:              cicdv1.GitTypeGeneric,
+			expectedLastSeenRefs: map[string]string{"refs/heads/master": testMasterSha, "refs/heads/dev": testDevSha},
+		},
+		"changed": {
+			gitType:              cicdv1.GitTypeGeneric,
+			lastSeenRefs:         map[string]string{"refs/heads/master": testOldSha, "refs/heads/deleted": testOldSha},
+			expectedPushes:       []git.Push{{Ref: "refs/heads/dev", Sha: testDevSha}, {Ref: "refs/heads/master", Sha: testMasterSha, Before: testOldSha}},
+			expectedLastSeenRefs: map[string]string{"refs/heads/master": testMasterSha, "refs/heads/dev": testDevSha},
+		},
+		"notChanged": {
+			gitType:              cicdv1.GitTypeGeneric,
+			lastSeenRefs:         map[string]string{"refs/heads/master": testMasterSha, "refs/heads/dev": testDevSha},
+			expectedLastSeenRefs: map[string]string{"refs/heads/master": testMasterSha, "refs/heads/dev": testDevSha},
+		},
+		"notGeneric": {
+			gitType: cicdv1.GitTypeGitHub,
+		},
+	}
+
+	for name, c := range tc {
+		t.Run(name, func(t *testing.T) {
+			ic := &cicdv1.IntegrationConfig{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-ic", Namespace: "default"},
+				Spec: cicdv1.IntegrationConfigSpec{
+					Git: cicdv1.GitConfig{
+						Type:       c.gitType,
+						Repository: "mirrors/test-repo",
+						APIUrl:     srv.URL,
+					},
+				},
+				Status: cicdv1.IntegrationConfigStatus{LastSeenRefs: c.lastSeenRefs},
+			}
+			fakeCli := fake.NewClientBuilder().WithScheme(s).WithObjects(ic).Build()
+
+			var pushes []git.Push
+			p := &poller{client: fakeCli, handleEvent: func(_ context.Context, wh *git.Webhook, _ *cicdv1.IntegrationConfig, _ ...string) error {
+				require.Equal(t, git.EventTypePush, wh.EventType)
+				require.Equal(t, "mirrors/test-repo", wh.Repo.Name)
+				require.Equal(t, srv.URL+"/mirrors/test-repo", wh.Repo.URL)
+				pushes = append(pushes, *wh.Push)
+				return nil
+			}}
+			p.poll()
+
+			require.Equal(t, c.expectedPushes, pushes)
+
+			resultIC := &cicdv1.IntegrationConfig{}
+			require.NoError(t, fakeCli.Get(context.Background(), types.NamespacedName{Name: "test-ic", Namespace: "default"}, resultIC))
+			require.Equal(t, c.expectedLastSeenRefs, resultIC.Status.LastSeenRefs)
+		})
+	}
+}
+
+func Test_poller_poll_emptyRepository(t *testing.T) {
+	s := runtime.NewScheme()
+	utilruntime.Must(cicdv1.AddToScheme(s))
+
+	refs := ""
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(refs))
+	}))
+	defer srv.Close()
+
+	ic := &cicdv1.IntegrationConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-ic", Namespace: "default"},
+		Spec: cicdv1.IntegrationConfigSpec{
+			Git: cicdv1.GitConfig{
+				Type:       cicdv1.GitTypeGeneric,
+				Repository: "mirrors/test-repo",
+				APIUrl:     srv.URL,
+			},
+		},
+	}
+	fakeCli := fake.NewClientBuilder().WithScheme(s).WithObjects(ic).Build()
+
+	var pushes []git.Push
+	p := &poller{client: fakeCli, handleEvent: func(_ context.Context, wh *git.Webhook, _ *cicdv1.IntegrationConfig, _ ...string) error {
+		pushes = append(pushes, *wh.Push)
+		return nil
+	}}
+
+	// The first poll of the empty repository is recorded
+	p.poll()
+	require.Empty(t, pushes)
+	resultIC := &cicdv1.IntegrationConfig{}
+	require.NoError(t, fakeCli.Get(context.Background(), types.NamespacedName{Name: "test-ic", Namespace: "default"}, resultIC))
+	require.NotNil(t, resultIC.Status.LastSeenRefs)
+	require.Empty(t, resultIC.Status.LastSeenRefs)
+
+	// The first push to the repository triggers the jobs
+	refs = testMasterSha + "\trefs/heads/master\n"
+	p.poll()
+	require.Equal(t, []git.Push{{Ref: "refs/heads/master", Sha: testMasterSha}}, pushes)
+}
+
+func Test_poller_poll_handleEventError(t *testing.T) {
+	s := runtime.NewScheme()
+	utilruntime.Must(cicdv1.AddToScheme(s))
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(testMasterSha + "\trefs/heads/master\n" + testDevSha + "\trefs/heads/dev\n" + testOldSha + "\trefs/heads/invalid\n"))
+	}))
+	defer srv.Close()
+
+	ic := &cicdv1.IntegrationConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-ic", Namespace: "default"},
+		Spec: cicdv1.IntegrationConfigSpec{
+			Git: cicdv1.GitConfig{
+				Type:       cicdv1.GitTypeGeneric,
+				Repository: "mirrors/test-repo",
+				APIUrl:     srv.URL,
+			},
+		},
+		Status: cicdv1.IntegrationConfigStatus{LastSeenRefs: map[string]string{"refs/heads/master": testOldSha}},
+	}
+	fakeCli := fake.NewClientBuilder().WithScheme(s).WithObjects(ic).Build()
+
+	// Events fail at the first poll. The error of refs/heads/invalid is permanent
+	var pushes []git.Push
+	fail := true
+	p := &poller{client: fakeCli, handleEvent: func(_ context.Context, wh *git.Webhook, _ *cicdv1.IntegrationConfig, _ ...string) error {
+		pushes = append(pushes, *wh.Push)
+		if wh.Push.Ref == "refs/heads/invalid" {
+			return server.NewPermanentError(fmt.Errorf("invalid jobs"))
+		}
+		if fail {
+			return fmt.Errorf("temporary error")
+		}
+		return nil
+	}}
+	p.poll()
+	require.Equal(t, []git.Push{
+		{Ref: "refs/heads/dev", Sha: testDevSha},
+		{Ref: "refs/heads/invalid", Sha: testOldSha},
+		{Ref: "refs/heads/master", Sha: testMasterSha, Before: testOldSha},
+	}, pushes)
+
+	resultIC := &cicdv1.IntegrationConfig{}
+	require.NoError(t, fakeCli.Get(context.Background(), types.NamespacedName{Name: "test-ic", Namespace: "default"}, resultIC))
+	require.Equal(t, map[string]string{"refs/heads/master": testOldSha, "refs/heads/invalid": testOldSha}, resultIC.Status.LastSeenRefs)
+
+	// The failed events are generated again by the next poll
+	pushes = nil
+	fail = false
+	p.poll()
+	require.Equal(t, []git.Push{
+		{Ref: "refs/heads/dev", Sha: testDevSha},
+		{Ref: "refs/heads/master", Sha: testMasterSha, Before: testOldSha},
+	}, pushes)
+
+	require.NoError(t, fakeCli.Get(context.Background(), types.NamespacedName{Name: "test-ic", Namespace: "default"}, resultIC))
+	require.Equal(t, map[string]string{"refs/heads/master": testMasterSha, "refs/heads/dev": testDevSha, "refs/heads/invalid": testOldSha}, resultIC.Status.LastSeenRefs)
+}
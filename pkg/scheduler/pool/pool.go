@@ -30,8 +30,13 @@ import (
 type jobPool struct {
 	jobMap jobMap
 
-	pending structs.SortedUniqueList
-	running structs.SortedUniqueList
+	pending     structs.SortedUniqueList
+	running     structs.SortedUniqueList
+	compareFunc structs.CompareFunc
+
+	// timeouts of the pending jobs are fired by a single timer, which is armed for the earliest one
+	timeouts     timeoutHeap
+	timeoutTimer *time.Timer
 
 	scheduleChan chan struct{}
 	lock         sync.Mutex
@@ -42,19 +47,25 @@ type JobPool interface {
 	Lock()
 	Unlock()
 	SyncJob(job *v1.IntegrationJob)
+	Rebuild(jobs []v1.IntegrationJob)
 	Running() structs.SortedUniqueList
 	Pending() structs.SortedUniqueList
+	Ordered(strategy Strategy) []*JobNode
 }
 
 // New is a constructor for a jobPool
 func New(ch chan struct{}, compareFunc structs.CompareFunc) *jobPool {
-	return &jobPool{
+	p := &jobPool{
 		jobMap:       jobMap{},
 		pending:      structs.NewSortedUniqueQueue(compareFunc),
 		running:      structs.NewSortedUniqueQueue(nil),
+		compareFunc:  compareFunc,
 		scheduleChan: ch,
 		lock:         sync.Mutex{},
 	}
+	p.timeoutTimer = time.AfterFunc(time.Hour, p.fireTimeouts)
+	p.timeoutTimer.Stop()
+	return p
 }
 
 func (j *jobPool) Running() structs.SortedUniqueList {
@@ -65,6 +76,11 @@ func (j *jobPool) Pending() structs.SortedUniqueList {
 	return j.pending
 }
 
+// Ordered returns the pending jobs in the order to be scheduled, by the strategy
+func (j *jobPool) Ordered(strategy Strategy) []*JobNode {
+	return strategy.Order(j.pending, j.running)
+}
+
 // Lock locks jobPool
 func (j *jobPool) Lock() {
 	j.lock.Lock()
@@ -120,20 +136,24 @@ func (j *jobPool) SyncJob(job *v1.IntegrationJob) {
 		switch newStatus {
 		case v1.IntegrationJobStatePending:
 			j.pending.Add(node)
-			timeout := job.Spec.Timeout.Duration - time.Since(job.CreationTimestamp.Time)
-			go j.manageTimeout(timeout, job)
+			j.addTimeout(nodeID, job.CreationTimestamp.Time.Add(job.Spec.Timeout.Duration))
 		case v1.IntegrationJobStateRunning:
 			j.running.Add(node)
+		default:
+			// Completed, failed or canceled jobs are not managed by the pool
+			delete(j.jobMap, nodeID)
 		}
 		j.sendSchedule()
 		return
 	}
 
-	// Pending -> Running / Failed
+	// Pending -> Running / Failed / Canceled
 	if oldStatus == v1.IntegrationJobStatePending {
 		j.pending.Delete(node)
 		if newStatus == v1.IntegrationJobStateRunning {
 			j.running.Add(node)
+		} else {
+			delete(j.jobMap, nodeID)
 		}
 		return
 	}
@@ -152,8 +172,21 @@ func (j *jobPool) SyncJob(job *v1.IntegrationJob) {
 	}
 }
 
-func (j *jobPool) manageTimeout(timeout time.Duration, job *v1.IntegrationJob) {
-	time.Sleep(timeout)
+// Rebuild resets the pool and fills it with the jobs (usually a full list of IntegrationJobs), so that the pool does
+// not depend on the reconciles which arrived before, e.g., when the operator is restarted or a new leader is elected
+func (j *jobPool) Rebuild(jobs []v1.IntegrationJob) {
+	j.jobMap = jobMap{}
+	j.pending = structs.NewSortedUniqueQueue(j.compareFunc)
+	j.running = structs.NewSortedUniqueQueue(nil)
+	j.timeouts = timeoutHeap{}
+	j.timeoutTimer.Stop()
+
+	for i := range jobs {
+		if jobs[i].Status.CompletionTime != nil {
+			continue
+		}
+		j.SyncJob(&jobs[i])
+	}
 	j.sendSchedule()
 }
 
@@ -30,6 +30,9 @@ import (
 func TestJobPool_SyncJob(t *testing.T) {
 	ch := make(chan struct{}, 1)
 	p := New(ch, testCompare)
+	// Timeouts are fired in another goroutine
+	p.Lock()
+	defer p.Unlock()
 
 	now := time.Now()
 	testJob1 := jobForTest("1", "default", now)
@@ -63,6 +66,31 @@ func TestJobPool_SyncJob(t *testing.T) {
 	p.SyncJob(testJob3)
 	assert.Equal(t, 6, p.pending.Len(), "state transition isn't done properly")
 	assert.Equal(t, 0, p.running.Len(), "state transition isn't done properly")
+
+	// 1 Canceled while pending
+	testJob1.Status.State = cicdv1.IntegrationJobStateCanceled
+	p.SyncJob(testJob1)
+	assert.Equal(t, 5, p.pending.Len(), "state transition isn't done properly")
+	assert.Equal(t, 0, p.running.Len(), "state transition isn't done properly")
+	_, exist := p.jobMap[getNodeID(testJob1)]
+	assert.Equal(t, false, exist, "canceled job should be removed")
+
+	// 2 Canceled while running
+	testJob2.Status.State = cicdv1.IntegrationJobStateRunning
+	p.SyncJob(testJob2)
+	testJob2.Status.State = cicdv1.IntegrationJobStateCanceled
+	p.SyncJob(testJob2)
+	assert.Equal(t, 4, p.pending.Len(), "state transition isn't done properly")
+	assert.Equal(t, 0, p.running.Len(), "state transition isn't done properly")
+
+	// Newly synced canceled job is not queued
+	testJob8 := jobForTest("8", "default", now)
+	testJob8.Status.State = cicdv1.IntegrationJobStateCanceled
+	p.SyncJob(testJob8)
+	assert.Equal(t, 4, p.pending.Len(), "state transition isn't done properly")
+	assert.Equal(t, 0, p.running.Len(), "state transition isn't done properly")
+	_, exist = p.jobMap[getNodeID(testJob8)]
+	assert.Equal(t, false, exist, "canceled job should not be stored")
 }
 
 func testCompare(_a, _b structs.Item) bool {
@@ -95,3 +123,84 @@ func jobForTest(name, namespace string, created time.Time) *cicdv1.IntegrationJo
 		},
 	}
 }
+
+func TestJobPool_Rebuild(t *testing.T) {
+	now := time.Now()
+	completed := metav1.NewTime(now)
+
+	pending1 := jobForTest("pending-1", "default", now)
+	pending2 := jobForTest("pending-2", "l2c-system", now)
+	running := jobForTest("running", "default", now)
+	running.Status.State = cicdv1.IntegrationJobStateRunning
+	finished := jobForTest("finished", "default", now)
+	finished.Status.State = cicdv1.IntegrationJobStateCompleted
+	finished.Status.CompletionTime = &completed
+	notDefaulted := jobForTest("not-defaulted", "default", now)
+	notDefaulted.Status.State = ""
+
+	jobs := []cicdv1.IntegrationJob{*pending1, *pending2, *running, *finished, *notDefaulted}
+
+	// Before restart
+	p := New(make(chan struct{}, 1), testCompare)
+	p.Lock()
+	defer p.Unlock()
+	for i := range jobs {
+		p.SyncJob(&jobs[i])
+	}
+	assert.Equal(t, 2, p.pending.Len(), "jobs are not synced properly")
+	assert.Equal(t, 1, p.running.Len(), "jobs are not synced properly")
+
+	// Restarted pool is rebuilt only from the list
+	ch := make(chan struct{}, 1)
+	restarted := New(ch, testCompare)
+	restarted.Lock()
+	defer restarted.Unlock()
+	restarted.Rebuild(jobs)
+	assert.Equal(t, 2, restarted.pending.Len(), "pool is not rebuilt properly")
+	assert.Equal(t, 1, restarted.running.Len(), "pool is not rebuilt properly")
+	assert.Equal(t, 3, len(restarted.jobMap), "pool is not rebuilt properly")
+	assert.Equal(t, 1, len(ch), "scheduling should be triggered")
+
+	// Rebuilding again drops the jobs which are not in the list anymore
+	restarted.Rebuild([]cicdv1.IntegrationJob{*pending1})
+	assert.Equal(t, 1, restarted.pending.Len(), "pool is not rebuilt properly")
+	assert.Equal(t, 0, restarted.running.Len(), "pool is not rebuilt properly")
+	assert.Equal(t, 1, len(restarted.jobMap), "pool is not rebuilt properly")
+	assert.Equal(t, 1, restarted.timeouts.Len(), "timeouts are not rebuilt properly")
+}
+
+func TestJobPool_timeouts(t *testing.T) {
+	ch := make(chan struct{}, 1)
+	p := New(ch, testCompare)
+
+	now := time.Now()
+	expiring := jobForTest("expiring", "default", now)
+	expiring.Spec.Timeout.Duration = 100 * time.Millisecond
+	scheduled := jobForTest("scheduled", "default", now)
+	scheduled.Spec.Timeout.Duration = 50 * time.Millisecond
+	later := jobForTest("later", "default", now)
+	later.Spec.Timeout.Duration = time.Hour
+
+	p.Lock()
+	p.SyncJob(later)
+	p.SyncJob(scheduled)
+	p.SyncJob(expiring)
+	scheduled.Status.State = cicdv1.IntegrationJobStateRunning
+	p.SyncJob(scheduled)
+	assert.Equal(t, 3, p.timeouts.Len(), "timeouts are not added")
+	assert.Equal(t, getNodeID(scheduled), p.timeouts[0].nodeID, "earliest timeout should be at the top")
+	p.Unlock()
+	<-ch
+
+	// Only the pending job's timeout calls the scheduler
+	select {
+	case <-ch:
+	case <-time.After(5 * time.Second):
+		t.Fatal("scheduling is not triggered by the timeout")
+	}
+
+	p.Lock()
+	assert.Equal(t, 1, p.timeouts.Len(), "expired timeouts are not popped")
+	assert.Equal(t, getNodeID(later), p.timeouts[0].nodeID, "remaining timeout is wrong")
+	p.Unlock()
+}
@@ -0,0 +1,126 @@
+/*
+ Copyright 2021 The CI/CD Operator Authors
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package pool
+
+import (
+	"github.com/tmax-cloud/cicd-operator/pkg/structs"
+)
+
+// Strategy names
+const (
+	StrategyPriority  = "priority"
+	StrategyFairShare = "fairShare"
+)
+
+// Strategy orders the pending jobs of a pool, to be scheduled
+type Strategy interface {
+	Order(pending, running structs.SortedUniqueList) []*JobNode
+}
+
+// WeightFunc returns a weight of a namespace for the fair-share strategy
+type WeightFunc func(namespace string) int
+
+// NewStrategy returns a strategy of the name. Priority strategy is returned if the name is unknown
+func NewStrategy(name string, weightFunc WeightFunc) Strategy {
+	if name == StrategyFairShare {
+		return &fairShareStrategy{weightFunc: weightFunc}
+	}
+	return &priorityStrategy{}
+}
+
+// priorityStrategy keeps the order of the pending list
+type priorityStrategy struct{}
+
+// Order implements Strategy's method
+func (p *priorityStrategy) Order(pending, _ structs.SortedUniqueList) []*JobNode {
+	var ordered []*JobNode
+	pending.ForEach(func(item structs.Item) {
+		if j, ok := item.(*JobNode); ok {
+			ordered = append(ordered, j)
+		}
+	})
+	return ordered
+}
+
+// fairShareStrategy divides the slots among the namespaces having pending jobs, in proportion to their weights.
+// The next job is always taken from the namespace with the least (running + ordered) jobs per weight, and the jobs of
+// each namespace keep the order of the pending list
+type fairShareStrategy struct {
+	weightFunc WeightFunc
+}
+
+// Order implements Strategy's method
+func (f *fairShareStrategy) Order(pending, running structs.SortedUniqueList) []*JobNode {
+	// Pending jobs of each namespace, in the order of the pending list
+	queues := map[string][]*JobNode{}
+	var namespaces []string
+	pending.ForEach(func(item structs.Item) {
+		j, ok := item.(*JobNode)
+		if !ok {
+			return
+		}
+		if _, exist := queues[j.Namespace]; !exist {
+			namespaces = append(namespaces, j.Namespace)
+		}
+		queues[j.Namespace] = append(queues[j.Namespace], j)
+	})
+
+	usage := map[string]int{}
+	running.ForEach(func(item structs.Item) {
+		if j, ok := item.(*JobNode); ok {
+			usage[j.Namespace]++
+		}
+	})
+
+	weights := map[string]int{}
+	for _, ns := range namespaces {
+		weight := 1
+		if f.weightFunc != nil {
+			weight = f.weightFunc(ns)
+		}
+		if weight <= 0 {
+			weight = 1
+		}
+		weights[ns] = weight
+	}
+
+	var ordered []*JobNode
+	for {
+		next := ""
+		for _, ns := range namespaces {
+			if len(queues[ns]) == 0 {
+				continue
+			}
+			// usage[ns]/weights[ns] < usage[next]/weights[next], tie broken by the namespace name
+			if next == "" {
+				next = ns
+				continue
+			}
+			lhs, rhs := usage[ns]*weights[next], usage[next]*weights[ns]
+			if lhs < rhs || (lhs == rhs && ns < next) {
+				next = ns
+			}
+		}
+		if next == "" {
+			return ordered
+		}
+
+		ordered = append(ordered, queues[next][0])
+		queues[next] = queues[next][1:]
+		usage[next]++
+	}
+}
@@ -0,0 +1,125 @@
+/*
+ Copyright 2021 The CI/CD Operator Authors
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package pool
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/bmizerany/assert"
+	cicdv1 "github.com/tmax-cloud/cicd-operator/api/v1"
+)
+
+func TestJobPool_Ordered(t *testing.T) {
+	base := time.Date(2021, 10, 1, 0, 0, 0, 0, time.UTC)
+
+	tc := map[string]struct {
+		strategy string
+		weights  map[string]int
+		pending  []*cicdv1.IntegrationJob
+		running  []*cicdv1.IntegrationJob
+
+		expected []string
+	}{
+		"priority": {
+			strategy: StrategyPriority,
+			pending: []*cicdv1.IntegrationJob{
+				jobForTest("a1", "team-a", base),
+				jobForTest("a2", "team-a", base.Add(1*time.Second)),
+				jobForTest("a3", "team-a", base.Add(2*time.Second)),
+				jobForTest("b1", "team-b", base.Add(3*time.Second)),
+			},
+			expected: []string{"team-a/a1", "team-a/a2", "team-a/a3", "team-b/b1"},
+		},
+		"unknownStrategy": {
+			strategy: "unknown",
+			pending: []*cicdv1.IntegrationJob{
+				jobForTest("a1", "team-a", base),
+				jobForTest("b1", "team-b", base.Add(1*time.Second)),
+			},
+			expected: []string{"team-a/a1", "team-b/b1"},
+		},
+		"fairShare": {
+			strategy: StrategyFairShare,
+			pending: []*cicdv1.IntegrationJob{
+				jobForTest("a1", "team-a", base),
+				jobForTest("a2", "team-a", base.Add(1*time.Second)),
+				jobForTest("a3", "team-a", base.Add(2*time.Second)),
+				jobForTest("b1", "team-b", base.Add(3*time.Second)),
+				jobForTest("c1", "team-c", base.Add(4*time.Second)),
+				jobForTest("b2", "team-b", base.Add(5*time.Second)),
+			},
+			expected: []string{"team-a/a1", "team-b/b1", "team-c/c1", "team-a/a2", "team-b/b2", "team-a/a3"},
+		},
+		"fairShareRunning": {
+			strategy: StrategyFairShare,
+			pending: []*cicdv1.IntegrationJob{
+				jobForTest("a1", "team-a", base),
+				jobForTest("a2", "team-a", base.Add(1*time.Second)),
+				jobForTest("b1", "team-b", base.Add(2*time.Second)),
+				jobForTest("b2", "team-b", base.Add(3*time.Second)),
+			},
+			running: []*cicdv1.IntegrationJob{
+				jobForTest("a0", "team-a", base.Add(-2*time.Second)),
+				jobForTest("a-1", "team-a", base.Add(-1*time.Second)),
+			},
+			expected: []string{"team-b/b1", "team-b/b2", "team-a/a1", "team-a/a2"},
+		},
+		"fairShareWeighted": {
+			strategy: StrategyFairShare,
+			weights:  map[string]int{"team-a": 2, "team-b": 0},
+			pending: []*cicdv1.IntegrationJob{
+				jobForTest("a1", "team-a", base),
+				jobForTest("a2", "team-a", base.Add(1*time.Second)),
+				jobForTest("a3", "team-a", base.Add(2*time.Second)),
+				jobForTest("a4", "team-a", base.Add(3*time.Second)),
+				jobForTest("b1", "team-b", base.Add(4*time.Second)),
+				jobForTest("b2", "team-b", base.Add(5*time.Second)),
+			},
+			expected: []string{"team-a/a1", "team-b/b1", "team-a/a2", "team-a/a3", "team-b/b2", "team-a/a4"},
+		},
+	}
+
+	for name, c := range tc {
+		t.Run(name, func(t *testing.T) {
+			p := New(make(chan struct{}, 1), testCompare)
+			p.Lock()
+			defer p.Unlock()
+			for _, j := range c.pending {
+				p.SyncJob(j)
+			}
+			for _, j := range c.running {
+				j.Status.State = cicdv1.IntegrationJobStateRunning
+				p.SyncJob(j)
+			}
+
+			strategy := NewStrategy(c.strategy, func(namespace string) int {
+				if weight, exist := c.weights[namespace]; exist {
+					return weight
+				}
+				return 1
+			})
+
+			var result []string
+			for _, j := range p.Ordered(strategy) {
+				result = append(result, fmt.Sprintf("%s/%s", j.Namespace, j.Name))
+			}
+			assert.Equal(t, c.expected, result)
+		})
+	}
+}
@@ -0,0 +1,88 @@
+/*
+ Copyright 2021 The CI/CD Operator Authors
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package pool
+
+import (
+	"container/heap"
+	"time"
+
+	v1 "github.com/tmax-cloud/cicd-operator/api/v1"
+)
+
+// timeoutItem is a timeout of a pending job
+type timeoutItem struct {
+	deadline time.Time
+	nodeID   string
+}
+
+// timeoutHeap is a min-heap of timeouts, ordered by their deadlines
+type timeoutHeap []timeoutItem
+
+// Len implements heap.Interface's method
+func (h timeoutHeap) Len() int { return len(h) }
+
+// Less implements heap.Interface's method
+func (h timeoutHeap) Less(i, j int) bool { return h[i].deadline.Before(h[j].deadline) }
+
+// Swap implements heap.Interface's method
+func (h timeoutHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+
+// Push implements heap.Interface's method
+func (h *timeoutHeap) Push(x interface{}) {
+	*h = append(*h, x.(timeoutItem))
+}
+
+// Pop implements heap.Interface's method
+func (h *timeoutHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	item := old[n-1]
+	*h = old[:n-1]
+	return item
+}
+
+// addTimeout pushes the timeout of a pending job, and re-arms the timer if it is the earliest one.
+// It should be called while the pool is locked
+func (j *jobPool) addTimeout(nodeID string, deadline time.Time) {
+	heap.Push(&j.timeouts, timeoutItem{deadline: deadline, nodeID: nodeID})
+	if j.timeouts[0].nodeID == nodeID && j.timeouts[0].deadline.Equal(deadline) {
+		j.timeoutTimer.Reset(time.Until(deadline))
+	}
+}
+
+// fireTimeouts pops the expired timeouts and calls the scheduler if any of them is still pending.
+// Timeouts of the jobs which are not pending anymore are just dropped
+func (j *jobPool) fireTimeouts() {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+
+	now := time.Now()
+	expired := false
+	for j.timeouts.Len() > 0 && !j.timeouts[0].deadline.After(now) {
+		item := heap.Pop(&j.timeouts).(timeoutItem)
+		if node, exist := j.jobMap[item.nodeID]; exist && node.Status.State == v1.IntegrationJobStatePending {
+			expired = true
+		}
+	}
+	if expired {
+		j.sendSchedule()
+	}
+
+	if j.timeouts.Len() > 0 {
+		j.timeoutTimer.Reset(time.Until(j.timeouts[0].deadline))
+	}
+}
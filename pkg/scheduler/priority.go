@@ -0,0 +1,55 @@
+/*
+ Copyright 2021 The CI/CD Operator Authors
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package scheduler
+
+import (
+	"time"
+
+	"github.com/tmax-cloud/cicd-operator/internal/configs"
+	"github.com/tmax-cloud/cicd-operator/pkg/scheduler/pool"
+	"github.com/tmax-cloud/cicd-operator/pkg/structs"
+)
+
+// priorityCompare orders the jobs by their effective priorities (higher first), and then by their creation time
+func priorityCompare(_a, _b structs.Item) bool {
+	if _a == nil || _b == nil {
+		return false
+	}
+	a, aOk := _a.(*pool.JobNode)
+	b, bOk := _b.(*pool.JobNode)
+	if !aOk || !bOk {
+		return false
+	}
+
+	now := time.Now()
+	aPriority, bPriority := effectivePriority(a, now), effectivePriority(b, now)
+	if aPriority != bPriority {
+		return aPriority > bPriority
+	}
+
+	return fifoCompare(_a, _b)
+}
+
+// effectivePriority is the job's priority, increased by one for every aging period it has waited.
+// It prevents the jobs with low priorities from starving
+func effectivePriority(j *pool.JobNode, now time.Time) int {
+	if configs.PriorityAgingPeriod <= 0 {
+		return j.Spec.Priority
+	}
+	waited := now.Sub(j.CreationTimestamp.Time)
+	return j.Spec.Priority + int(waited/(time.Duration(configs.PriorityAgingPeriod)*time.Minute))
+}
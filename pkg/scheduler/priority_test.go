@@ -0,0 +1,123 @@
+/*
+ Copyright 2021 The CI/CD Operator Authors
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	cicdv1 "github.com/tmax-cloud/cicd-operator/api/v1"
+	"github.com/tmax-cloud/cicd-operator/internal/configs"
+	"github.com/tmax-cloud/cicd-operator/pkg/scheduler/pool"
+	"github.com/tmax-cloud/cicd-operator/pkg/structs"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestPriorityCompare(t *testing.T) {
+	configs.PriorityAgingPeriod = 10
+	now := time.Now()
+
+	tc := map[string]struct {
+		a *pool.JobNode
+		b *pool.JobNode
+
+		expected bool
+	}{
+		"higherPriority": {
+			a:        jobNodeForTest("a", 20, now),
+			b:        jobNodeForTest("b", 10, now.Add(-time.Minute)),
+			expected: true,
+		},
+		"lowerPriority": {
+			a:        jobNodeForTest("a", 0, now.Add(-time.Minute)),
+			b:        jobNodeForTest("b", 10, now),
+			expected: false,
+		},
+		"samePriorityOlder": {
+			a:        jobNodeForTest("b", 10, now.Add(-time.Minute)),
+			b:        jobNodeForTest("a", 10, now),
+			expected: true,
+		},
+		"samePriorityNewer": {
+			a:        jobNodeForTest("a", 10, now),
+			b:        jobNodeForTest("b", 10, now.Add(-time.Minute)),
+			expected: false,
+		},
+		"samePriorityAndTime": {
+			a:        jobNodeForTest("a", 10, now),
+			b:        jobNodeForTest("b", 10, now),
+			expected: true,
+		},
+		"aged": {
+			a:        jobNodeForTest("a", 0, now.Add(-105*time.Minute)),
+			b:        jobNodeForTest("b", 10, now),
+			expected: true,
+		},
+		"notAgedEnough": {
+			a:        jobNodeForTest("a", 0, now.Add(-95*time.Minute)),
+			b:        jobNodeForTest("b", 10, now),
+			expected: false,
+		},
+	}
+
+	for name, c := range tc {
+		t.Run(name, func(t *testing.T) {
+			require.Equal(t, c.expected, priorityCompare(c.a, c.b))
+		})
+	}
+}
+
+func TestPriorityCompare_agingDisabled(t *testing.T) {
+	configs.PriorityAgingPeriod = 0
+	now := time.Now()
+
+	require.False(t, priorityCompare(jobNodeForTest("a", 0, now.Add(-24*time.Hour)), jobNodeForTest("b", 10, now)))
+}
+
+func TestPriorityCompare_sort(t *testing.T) {
+	configs.PriorityAgingPeriod = 10
+	now := time.Now()
+
+	q := structs.NewSortedUniqueQueue(priorityCompare)
+	q.Add(jobNodeForTest("periodic", cicdv1.DefaultPriorityPeriodic, now.Add(-5*time.Minute)))
+	q.Add(jobNodeForTest("pre-submit", cicdv1.DefaultPriorityPreSubmit, now.Add(-time.Minute)))
+	q.Add(jobNodeForTest("post-submit", cicdv1.DefaultPriorityPostSubmit, now))
+	q.Add(jobNodeForTest("old-periodic", cicdv1.DefaultPriorityPeriodic, now.Add(-150*time.Minute)))
+	q.Sort()
+
+	var names []string
+	q.ForEach(func(item structs.Item) {
+		names = append(names, item.(*pool.JobNode).Name)
+	})
+	require.Equal(t, []string{"post-submit", "old-periodic", "pre-submit", "periodic"}, names)
+}
+
+func jobNodeForTest(name string, priority int, created time.Time) *pool.JobNode {
+	return &pool.JobNode{
+		IntegrationJob: &cicdv1.IntegrationJob{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              name,
+				Namespace:         "default",
+				CreationTimestamp: metav1.Time{Time: created},
+			},
+			Spec: cicdv1.IntegrationJobSpec{
+				Priority: priority,
+			},
+		},
+	}
+}
@@ -0,0 +1,171 @@
+/*
+ Copyright 2021 The CI/CD Operator Authors
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+
+	cicdv1 "github.com/tmax-cloud/cicd-operator/api/v1"
+	"github.com/tmax-cloud/cicd-operator/internal/configs"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// quota counts running jobs per namespace and per IntegrationConfig, and checks if a pending job can be scheduled
+// without exceeding the namespace/IntegrationConfig limits. It also tracks the concurrency groups held by the running
+// jobs. It is made for every scheduling cycle
+type quota struct {
+	k8sClient client.Client
+
+	namespaceRunning map[string]int
+	configRunning    map[string]int
+
+	// groupHolders are the running jobs holding the concurrency groups (keyed by namespace_group)
+	groupHolders map[string]*cicdv1.IntegrationJob
+
+	// Namespaces and limits are cached during a scheduling cycle
+	namespaces   map[string]*corev1.Namespace
+	configLimits map[string]int
+}
+
+func newQuota(c client.Client) *quota {
+	return &quota{
+		k8sClient:        c,
+		namespaceRunning: map[string]int{},
+		configRunning:    map[string]int{},
+		groupHolders:     map[string]*cicdv1.IntegrationJob{},
+		namespaces:       map[string]*corev1.Namespace{},
+		configLimits:     map[string]int{},
+	}
+}
+
+// add counts the job as a running one
+func (q *quota) add(job *cicdv1.IntegrationJob) {
+	q.namespaceRunning[job.Namespace]++
+	q.configRunning[configKey(job)]++
+
+	groups, err := job.GetConcurrencyGroups()
+	if err != nil {
+		log.Error(err, "")
+		return
+	}
+	for group := range groups {
+		q.groupHolders[groupKey(job.Namespace, group)] = job
+	}
+}
+
+// groupHolder returns a running job holding any of the concurrency groups, in the same namespace
+func (q *quota) groupHolder(namespace string, groups map[string]cicdv1.ConcurrencyPolicy) (string, *cicdv1.IntegrationJob) {
+	var names []string
+	for group := range groups {
+		names = append(names, group)
+	}
+	sort.Strings(names)
+
+	for _, group := range names {
+		if holder, exist := q.groupHolders[groupKey(namespace, group)]; exist {
+			return group, holder
+		}
+	}
+	return "", nil
+}
+
+// check returns the reason why the job cannot be scheduled, or an empty string if the job can be scheduled
+func (q *quota) check(job *cicdv1.IntegrationJob) string {
+	if limit := q.namespaceLimit(job.Namespace); limit > 0 && q.namespaceRunning[job.Namespace] >= limit {
+		return fmt.Sprintf("waiting: namespace quota %d/%d", q.namespaceRunning[job.Namespace], limit)
+	}
+	key := configKey(job)
+	if limit := q.configLimit(job); limit > 0 && q.configRunning[key] >= limit {
+		return fmt.Sprintf("waiting: integration config quota %d/%d", q.configRunning[key], limit)
+	}
+	return ""
+}
+
+// +kubebuilder:rbac:groups="",resources=namespaces,verbs=get;list;watch
+
+// namespaceLimit returns the namespace's annotation value if it exists, or the operator's default value
+func (q *quota) namespaceLimit(namespace string) int {
+	return q.namespaceAnnotation(namespace, cicdv1.NamespaceAnnotationMaxPipelineRun, configs.MaxNamespacePipelineRun)
+}
+
+// namespaceWeight returns the namespace's weight for the fair-share strategy (default is 1)
+func (q *quota) namespaceWeight(namespace string) int {
+	return q.namespaceAnnotation(namespace, cicdv1.NamespaceAnnotationSchedulingWeight, 1)
+}
+
+// namespaceAnnotation returns the integer value of the namespace's annotation, or the default value if it does not
+// exist or is invalid
+func (q *quota) namespaceAnnotation(namespace, key string, defaultVal int) int {
+	ns, cached := q.namespaces[namespace]
+	if !cached {
+		ns = &corev1.Namespace{}
+		if err := q.k8sClient.Get(context.Background(), types.NamespacedName{Name: namespace}, ns); err != nil {
+			log.Error(err, "")
+			ns = nil
+		}
+		q.namespaces[namespace] = ns
+	}
+	if ns == nil {
+		return defaultVal
+	}
+
+	val, exist := ns.Annotations[key]
+	if !exist {
+		return defaultVal
+	}
+	parsed, err := strconv.Atoi(val)
+	if err != nil {
+		log.Error(err, fmt.Sprintf("invalid annotation %s of namespace %s", key, namespace))
+		return defaultVal
+	}
+	return parsed
+}
+
+// configLimit returns the maxConcurrentJobs of the job's IntegrationConfig
+func (q *quota) configLimit(job *cicdv1.IntegrationJob) int {
+	key := configKey(job)
+	if limit, exist := q.configLimits[key]; exist {
+		return limit
+	}
+
+	limit := 0
+	ic := &cicdv1.IntegrationConfig{}
+	if err := q.k8sClient.Get(context.Background(), types.NamespacedName{Name: job.Spec.ConfigRef.Name, Namespace: job.Namespace}, ic); err != nil {
+		if !errors.IsNotFound(err) {
+			log.Error(err, "")
+		}
+	} else {
+		limit = ic.Spec.MaxConcurrentJobs
+	}
+
+	q.configLimits[key] = limit
+	return limit
+}
+
+func configKey(job *cicdv1.IntegrationJob) string {
+	return fmt.Sprintf("%s_%s", job.Namespace, job.Spec.ConfigRef.Name)
+}
+
+func groupKey(namespace, group string) string {
+	return fmt.Sprintf("%s_%s", namespace, group)
+}
@@ -0,0 +1,180 @@
+/*
+ Copyright 2021 The CI/CD Operator Authors
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package scheduler
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	cicdv1 "github.com/tmax-cloud/cicd-operator/api/v1"
+	"github.com/tmax-cloud/cicd-operator/internal/configs"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestQuota_check(t *testing.T) {
+	s := runtime.NewScheme()
+	utilruntime.Must(cicdv1.AddToScheme(s))
+	utilruntime.Must(corev1.AddToScheme(s))
+
+	newJob := func(namespace, config string) *cicdv1.IntegrationJob {
+		return &cicdv1.IntegrationJob{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-job", Namespace: namespace},
+			Spec:       cicdv1.IntegrationJobSpec{ConfigRef: cicdv1.IntegrationJobConfigRef{Name: config}},
+		}
+	}
+
+	objs := []client.Object{
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "default"}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "annotated", Annotations: map[string]string{cicdv1.NamespaceAnnotationMaxPipelineRun: "1"}}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "invalid", Annotations: map[string]string{cicdv1.NamespaceAnnotationMaxPipelineRun: "one"}}},
+		&cicdv1.IntegrationConfig{ObjectMeta: metav1.ObjectMeta{Name: "limited", Namespace: "default"}, Spec: cicdv1.IntegrationConfigSpec{MaxConcurrentJobs: 1}},
+		&cicdv1.IntegrationConfig{ObjectMeta: metav1.ObjectMeta{Name: "unlimited", Namespace: "default"}},
+	}
+
+	tc := map[string]struct {
+		namespaceDefault int
+		running          []*cicdv1.IntegrationJob
+		job              *cicdv1.IntegrationJob
+
+		expectedReason string
+	}{
+		"noLimit": {
+			running: []*cicdv1.IntegrationJob{newJob("default", "unlimited"), newJob("default", "unlimited")},
+			job:     newJob("default", "unlimited"),
+		},
+		"namespaceDefault": {
+			namespaceDefault: 2,
+			running:          []*cicdv1.IntegrationJob{newJob("default", "unlimited"), newJob("default", "unlimited")},
+			job:              newJob("default", "unlimited"),
+			expectedReason:   "waiting: namespace quota 2/2",
+		},
+		"namespaceDefaultOtherNamespace": {
+			namespaceDefault: 2,
+			running:          []*cicdv1.IntegrationJob{newJob("default", "unlimited"), newJob("default", "unlimited")},
+			job:              newJob("annotated", "unlimited"),
+		},
+		"namespaceAnnotation": {
+			namespaceDefault: 3,
+			running:          []*cicdv1.IntegrationJob{newJob("annotated", "test")},
+			job:              newJob("annotated", "test"),
+			expectedReason:   "waiting: namespace quota 1/1",
+		},
+		"namespaceAnnotationInvalid": {
+			namespaceDefault: 2,
+			running:          []*cicdv1.IntegrationJob{newJob("invalid", "test")},
+			job:              newJob("invalid", "test"),
+		},
+		"namespaceNotFound": {
+			running: []*cicdv1.IntegrationJob{newJob("not-exist", "test")},
+			job:     newJob("not-exist", "test"),
+		},
+		"config": {
+			running:        []*cicdv1.IntegrationJob{newJob("default", "limited")},
+			job:            newJob("default", "limited"),
+			expectedReason: "waiting: integration config quota 1/1",
+		},
+		"configOtherJobs": {
+			running: []*cicdv1.IntegrationJob{newJob("default", "unlimited")},
+			job:     newJob("default", "limited"),
+		},
+	}
+
+	for name, c := range tc {
+		t.Run(name, func(t *testing.T) {
+			configs.MaxNamespacePipelineRun = c.namespaceDefault
+			q := newQuota(fake.NewClientBuilder().WithScheme(s).WithObjects(objs...).Build())
+			for _, j := range c.running {
+				q.add(j)
+			}
+			require.Equal(t, c.expectedReason, q.check(c.job))
+		})
+	}
+}
+
+func TestQuota_namespaceWeight(t *testing.T) {
+	s := runtime.NewScheme()
+	utilruntime.Must(corev1.AddToScheme(s))
+
+	fakeCli := fake.NewClientBuilder().WithScheme(s).WithObjects(
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "default"}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "weighted", Annotations: map[string]string{cicdv1.NamespaceAnnotationSchedulingWeight: "3"}}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "invalid", Annotations: map[string]string{cicdv1.NamespaceAnnotationSchedulingWeight: "three"}}},
+	).Build()
+	q := newQuota(fakeCli)
+
+	require.Equal(t, 1, q.namespaceWeight("default"))
+	require.Equal(t, 3, q.namespaceWeight("weighted"))
+	require.Equal(t, 1, q.namespaceWeight("invalid"))
+	require.Equal(t, 1, q.namespaceWeight("not-exist"))
+}
+
+func TestQuota_groupHolder(t *testing.T) {
+	newJob := func(name, namespace, group string) *cicdv1.IntegrationJob {
+		return &cicdv1.IntegrationJob{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+			Spec: cicdv1.IntegrationJobSpec{
+				Jobs: cicdv1.Jobs{{Container: corev1.Container{Name: "deploy"}, ConcurrencyGroup: group}},
+				Refs: cicdv1.IntegrationJobRefs{Base: cicdv1.IntegrationJobRefsBase{Ref: "refs/heads/master"}},
+			},
+		}
+	}
+
+	q := newQuota(fake.NewClientBuilder().Build())
+	q.add(newJob("running-1", "default", "deploy-{{.Refs.Base.Ref.GetBranch}}"))
+	q.add(newJob("running-2", "other", "deploy-staging"))
+	q.add(newJob("running-3", "default", "{{.Unknown}}"))
+
+	tc := map[string]struct {
+		namespace string
+		groups    map[string]cicdv1.ConcurrencyPolicy
+
+		expectedGroup  string
+		expectedHolder string
+	}{
+		"noGroup": {
+			namespace: "default",
+			groups:    map[string]cicdv1.ConcurrencyPolicy{},
+		},
+		"held": {
+			namespace:      "default",
+			groups:         map[string]cicdv1.ConcurrencyPolicy{"deploy-master": cicdv1.ConcurrencyPolicyQueue, "lint": cicdv1.ConcurrencyPolicyQueue},
+			expectedGroup:  "deploy-master",
+			expectedHolder: "running-1",
+		},
+		"otherNamespace": {
+			namespace: "default",
+			groups:    map[string]cicdv1.ConcurrencyPolicy{"deploy-staging": cicdv1.ConcurrencyPolicyQueue},
+		},
+	}
+
+	for name, c := range tc {
+		t.Run(name, func(t *testing.T) {
+			group, holder := q.groupHolder(c.namespace, c.groups)
+			require.Equal(t, c.expectedGroup, group)
+			if c.expectedHolder == "" {
+				require.Nil(t, holder)
+			} else {
+				require.Equal(t, c.expectedHolder, holder.Name)
+			}
+		})
+	}
+}
@@ -24,9 +24,11 @@ import (
 	tektonv1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
 	cicdv1 "github.com/tmax-cloud/cicd-operator/api/v1"
 	"github.com/tmax-cloud/cicd-operator/internal/configs"
+	"github.com/tmax-cloud/cicd-operator/pkg/metrics"
 	"github.com/tmax-cloud/cicd-operator/pkg/pipelinemanager"
 	"github.com/tmax-cloud/cicd-operator/pkg/scheduler/pool"
 	"github.com/tmax-cloud/cicd-operator/pkg/structs"
+	"github.com/tmax-cloud/cicd-operator/pkg/tracing"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -49,14 +51,17 @@ func New(c client.Client, s *runtime.Scheme, pm pipelinemanager.PipelineManager)
 		caller:    make(chan struct{}, 1),
 		pm:        pm,
 	}
-	sch.jobPool = pool.New(sch.caller, fifoCompare)
+	sch.jobPool = pool.New(sch.caller, priorityCompare)
 	go sch.start()
 	return sch
 }
 
 // Scheduler is an interface of scheduler
+// It is also a leader election runnable of the manager, which rebuilds the job pool when it is started
 type Scheduler interface {
 	Notify(job *cicdv1.IntegrationJob)
+	Start(ctx context.Context) error
+	NeedLeaderElection() bool
 }
 
 // scheduler watches IntegrationJobs and creates corresponding PipelineRuns, considering how many pipeline runs are
@@ -82,6 +87,34 @@ func (s scheduler) Notify(job *cicdv1.IntegrationJob) {
 	s.jobPool.Unlock()
 }
 
+// Start rebuilds the job pool from the full list of IntegrationJobs, as the pool is only in memory.
+// It is called by the manager at startup, or when the manager is elected as a leader
+func (s scheduler) Start(ctx context.Context) error {
+	if err := s.rebuild(ctx); err != nil {
+		return err
+	}
+	<-ctx.Done()
+	return nil
+}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable's method
+func (s scheduler) NeedLeaderElection() bool {
+	return true
+}
+
+func (s scheduler) rebuild(ctx context.Context) error {
+	jobs := &cicdv1.IntegrationJobList{}
+	if err := s.k8sClient.List(ctx, jobs); err != nil {
+		return err
+	}
+
+	s.jobPool.Lock()
+	defer s.jobPool.Unlock()
+	s.jobPool.Rebuild(jobs.Items)
+	log.Info(fmt.Sprintf("Rebuilt job pool (%d pending / %d running)", s.jobPool.Pending().Len(), s.jobPool.Running().Len()))
+	return nil
+}
+
 func (s scheduler) start() {
 	for range s.caller {
 		s.run()
@@ -94,10 +127,15 @@ func (s scheduler) run() {
 	s.jobPool.Lock()
 	defer s.jobPool.Unlock()
 	log.Info("scheduling...")
+
+	// Re-sort pending jobs, as their priorities increase while they are waiting
+	s.jobPool.Pending().Sort()
+
 	availableCnt := configs.MaxPipelineRun - s.jobPool.Running().Len()
+	q := newQuota(s.k8sClient)
 
 	// Check if running jobs are actually running (has pipelineRun, pipelineRun is running)
-	s.jobPool.Running().ForEach(s.filterOutRunning(&availableCnt))
+	s.jobPool.Running().ForEach(s.filterOutRunning(&availableCnt, q))
 
 	// Check if pending jobs are timeouted
 	s.jobPool.Pending().ForEach(s.filterOutPending())
@@ -105,14 +143,19 @@ func (s scheduler) run() {
 	// If the number of running jobs is greater or equals to the max pipeline run, no scheduling is allowed
 	if availableCnt <= 0 {
 		log.Info("Max number of PipelineRuns already exist")
-		return
 	}
 
-	// Schedule if available
-	s.jobPool.Pending().ForEach(s.schedulePending(&availableCnt))
+	// Schedule if available, in the order of the strategy
+	schedule := s.schedulePending(&availableCnt, q)
+	for _, j := range s.jobPool.Ordered(pool.NewStrategy(configs.SchedulingStrategy, q.namespaceWeight)) {
+		schedule(j)
+	}
+
+	metrics.SchedulerPendingJobs.Set(float64(s.jobPool.Pending().Len()))
+	metrics.SchedulerRunningJobs.Set(float64(s.jobPool.Running().Len()))
 }
 
-func (s *scheduler) filterOutRunning(availableCnt *int) func(structs.Item) {
+func (s *scheduler) filterOutRunning(availableCnt *int, q *quota) func(structs.Item) {
 	return func(item structs.Item) {
 		j, ok := item.(*pool.JobNode)
 		if !ok {
@@ -123,7 +166,9 @@ func (s *scheduler) filterOutRunning(availableCnt *int) func(structs.Item) {
 		// If PipelineRun is not found or is already completed, is not actually running
 		if (err != nil && errors.IsNotFound(err)) || (err == nil && pr.Status.CompletionTime != nil) {
 			*availableCnt = *availableCnt + 1
+			return
 		}
+		q.add(j.IntegrationJob)
 	}
 }
 
@@ -143,16 +188,19 @@ func (s *scheduler) filterOutPending() func(structs.Item) {
 	}
 }
 
-func (s *scheduler) schedulePending(availableCnt *int) func(structs.Item) {
+func (s *scheduler) schedulePending(availableCnt *int, q *quota) func(structs.Item) {
 	return func(item structs.Item) {
-		if *availableCnt <= 0 {
-			return
-		}
 		jobNode, ok := item.(*pool.JobNode)
 		if !ok {
 			return
 		}
 
+		// Pending jobs are left with the reason why they are not scheduled
+		if *availableCnt <= 0 {
+			s.patchJobWaiting(jobNode.IntegrationJob, fmt.Sprintf("waiting: global quota %d/%d", configs.MaxPipelineRun-*availableCnt, configs.MaxPipelineRun))
+			return
+		}
+
 		// Check if PipelineRun already exists
 		testPr := &tektonv1beta1.PipelineRun{}
 		if err := s.k8sClient.Get(context.Background(), types.NamespacedName{Name: pipelinemanager.Name(jobNode.IntegrationJob), Namespace: jobNode.Namespace}, testPr); err != nil {
@@ -164,9 +212,39 @@ func (s *scheduler) schedulePending(availableCnt *int) func(structs.Item) {
 		} else {
 			// PipelineRun already exists...
 			*availableCnt = *availableCnt - 1
+			q.add(jobNode.IntegrationJob)
+			return
+		}
+
+		// Check namespace/IntegrationConfig quotas
+		if reason := q.check(jobNode.IntegrationJob); reason != "" {
+			s.patchJobWaiting(jobNode.IntegrationJob, reason)
+			return
+		}
+
+		// Check concurrency groups
+		groups, err := jobNode.GetConcurrencyGroups()
+		if err != nil {
+			if err := s.patchJobScheduleFailed(jobNode.IntegrationJob, err.Error()); err != nil {
+				log.Error(err, "")
+			}
+			log.Error(err, "")
+			return
+		}
+		if group, holder := q.groupHolder(jobNode.Namespace, groups); holder != nil {
+			if groups[group] == cicdv1.ConcurrencyPolicyCancelInProgress && holder.CreationTimestamp.Before(&jobNode.CreationTimestamp) {
+				s.cancelJob(holder, fmt.Sprintf("Canceled by %s in concurrency group %s", jobNode.Name, group))
+			}
+			s.patchJobWaiting(jobNode.IntegrationJob, fmt.Sprintf("waiting: concurrency group %s is held by %s", group, holder.Name))
 			return
 		}
 
+		_, span := tracing.StartForJob(jobNode.IntegrationJob, jobNode.Spec.ConfigRef.Name, "scheduler.schedule")
+		var scheduleErr error
+		defer func() {
+			tracing.End(span, scheduleErr)
+		}()
+
 		// Generate PipeLine and PipeLineRun
 		pl, pr, err := s.pm.Generate(jobNode.IntegrationJob)
 
@@ -175,6 +253,7 @@ func (s *scheduler) schedulePending(availableCnt *int) func(structs.Item) {
 		if err := s.k8sClient.Get(context.Background(), types.NamespacedName{Name: pipelinemanager.Name(jobNode.IntegrationJob), Namespace: jobNode.Namespace}, testPl); err != nil {
 			//
 			if err := s.k8sClient.Create(context.Background(), pl); err != nil {
+				scheduleErr = err
 				if err := s.patchJobScheduleFailed(jobNode.IntegrationJob, err.Error()); err != nil {
 					log.Error(err, "")
 				}
@@ -184,6 +263,7 @@ func (s *scheduler) schedulePending(availableCnt *int) func(structs.Item) {
 		}
 
 		if err != nil {
+			scheduleErr = err
 			if err := s.patchJobScheduleFailed(jobNode.IntegrationJob, err.Error()); err != nil {
 				log.Error(err, "")
 			}
@@ -191,6 +271,7 @@ func (s *scheduler) schedulePending(availableCnt *int) func(structs.Item) {
 			return
 		}
 		if err := controllerutil.SetControllerReference(jobNode.IntegrationJob, pr, s.scheme); err != nil {
+			scheduleErr = err
 			if err := s.patchJobScheduleFailed(jobNode.IntegrationJob, err.Error()); err != nil {
 				log.Error(err, "")
 			}
@@ -198,9 +279,22 @@ func (s *scheduler) schedulePending(availableCnt *int) func(structs.Item) {
 			return
 		}
 
+		// Do not run the job canceled while it's being scheduled
+		pending, err := s.stillPending(jobNode.IntegrationJob)
+		if err != nil {
+			scheduleErr = err
+			log.Error(err, "")
+			return
+		}
+		if !pending {
+			log.Info(fmt.Sprintf("Skipped %s / %s, as it's no longer pending", jobNode.Name, jobNode.Namespace))
+			return
+		}
+
 		log.Info(fmt.Sprintf("Scheduled %s / %s / %s", jobNode.Name, jobNode.Namespace, jobNode.CreationTimestamp))
 		// Create PipelineRun only when there is no Pipeline exists
 		if err := s.k8sClient.Create(context.Background(), pr); err != nil {
+			scheduleErr = err
 			if err := s.patchJobScheduleFailed(jobNode.IntegrationJob, err.Error()); err != nil {
 				log.Error(err, "")
 			}
@@ -209,6 +303,58 @@ func (s *scheduler) schedulePending(availableCnt *int) func(structs.Item) {
 		}
 
 		*availableCnt = *availableCnt - 1
+		q.add(jobNode.IntegrationJob)
+		metrics.SchedulerPendingDuration.WithLabelValues(jobNode.Namespace).Observe(time.Since(jobNode.CreationTimestamp.Time).Seconds())
+	}
+}
+
+// stillPending re-reads the job, as the job pool may hold a stale copy of the job which is already canceled
+// (e.g., superseded by a new commit) or completed
+func (s *scheduler) stillPending(job *cicdv1.IntegrationJob) (bool, error) {
+	latest := &cicdv1.IntegrationJob{}
+	if err := s.k8sClient.Get(context.Background(), types.NamespacedName{Name: job.Name, Namespace: job.Namespace}, latest); err != nil {
+		if errors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return latest.Status.CompletionTime == nil && latest.Status.State != cicdv1.IntegrationJobStateCanceled, nil
+}
+
+// cancelJob marks the job as canceled. Its PipelineRun is canceled by the IntegrationJob controller
+func (s *scheduler) cancelJob(job *cicdv1.IntegrationJob, msg string) {
+	if job.Status.State == cicdv1.IntegrationJobStateCanceled {
+		return
+	}
+	original := job.DeepCopy()
+
+	job.Status.State = cicdv1.IntegrationJobStateCanceled
+	job.Status.Message = msg
+
+	p := client.MergeFrom(original)
+	if err := s.k8sClient.Status().Patch(context.Background(), job, p); err != nil {
+		log.Error(err, "")
+		return
+	}
+	log.Info(fmt.Sprintf("Canceled IntegrationJob %s/%s", job.Namespace, job.Name))
+}
+
+// patchJobWaiting sets the message of the pending job, if it is changed
+func (s *scheduler) patchJobWaiting(job *cicdv1.IntegrationJob, msg string) {
+	if job.Status.State != cicdv1.IntegrationJobStatePending || job.Status.Message == msg {
+		return
+	}
+	original := job.DeepCopy()
+
+	_, span := tracing.StartForJob(job, job.Spec.ConfigRef.Name, "scheduler.wait")
+	span.AddEvent(msg)
+	span.End()
+
+	job.Status.Message = msg
+
+	p := client.MergeFrom(original)
+	if err := s.k8sClient.Status().Patch(context.Background(), job, p); err != nil {
+		log.Error(err, "")
 	}
 }
 
@@ -0,0 +1,126 @@
+/*
+ Copyright 2021 The CI/CD Operator Authors
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package scheduler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	cicdv1 "github.com/tmax-cloud/cicd-operator/api/v1"
+	"github.com/tmax-cloud/cicd-operator/pkg/scheduler/pool"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestScheduler_Start(t *testing.T) {
+	s := runtime.NewScheme()
+	utilruntime.Must(cicdv1.AddToScheme(s))
+
+	completed := metav1.Now()
+	newJob := func(name string, state cicdv1.IntegrationJobState) *cicdv1.IntegrationJob {
+		job := &cicdv1.IntegrationJob{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", CreationTimestamp: metav1.Now()},
+			Spec:       cicdv1.IntegrationJobSpec{Timeout: &metav1.Duration{Duration: time.Hour}},
+			Status:     cicdv1.IntegrationJobStatus{State: state},
+		}
+		if state == cicdv1.IntegrationJobStateCompleted {
+			job.Status.CompletionTime = &completed
+		}
+		return job
+	}
+	fakeCli := fake.NewClientBuilder().WithScheme(s).WithObjects([]client.Object{
+		newJob("pending-1", cicdv1.IntegrationJobStatePending),
+		newJob("pending-2", cicdv1.IntegrationJobStatePending),
+		newJob("running", cicdv1.IntegrationJobStateRunning),
+		newJob("completed", cicdv1.IntegrationJobStateCompleted),
+	}...).Build()
+
+	// The scheduler is restarted with an empty pool, and no reconcile has arrived yet
+	sch := &scheduler{k8sClient: fakeCli, scheme: s, caller: make(chan struct{}, 1)}
+	sch.jobPool = pool.New(sch.caller, priorityCompare)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errCh := make(chan error)
+	go func() {
+		errCh <- sch.Start(ctx)
+	}()
+
+	select {
+	case <-sch.caller:
+	case <-time.After(5 * time.Second):
+		t.Fatal("scheduling is not triggered after the pool is rebuilt")
+	}
+
+	sch.jobPool.Lock()
+	require.Equal(t, 2, sch.jobPool.Pending().Len())
+	require.Equal(t, 1, sch.jobPool.Running().Len())
+	sch.jobPool.Unlock()
+
+	require.True(t, sch.NeedLeaderElection())
+	cancel()
+	require.NoError(t, <-errCh)
+}
+
+func TestScheduler_stillPending(t *testing.T) {
+	s := runtime.NewScheme()
+	utilruntime.Must(cicdv1.AddToScheme(s))
+
+	completed := metav1.Now()
+	tc := map[string]struct {
+		status *cicdv1.IntegrationJobStatus
+
+		expectedPending bool
+	}{
+		"pending": {
+			status:          &cicdv1.IntegrationJobStatus{State: cicdv1.IntegrationJobStatePending},
+			expectedPending: true,
+		},
+		"canceled": {
+			status: &cicdv1.IntegrationJobStatus{State: cicdv1.IntegrationJobStateCanceled},
+		},
+		"completed": {
+			status: &cicdv1.IntegrationJobStatus{State: cicdv1.IntegrationJobStateFailed, CompletionTime: &completed},
+		},
+		"deleted": {},
+	}
+
+	for name, c := range tc {
+		t.Run(name, func(t *testing.T) {
+			// The job pool holds a stale, pending copy of the job
+			stale := &cicdv1.IntegrationJob{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-job", Namespace: "default"},
+				Status:     cicdv1.IntegrationJobStatus{State: cicdv1.IntegrationJobStatePending},
+			}
+			builder := fake.NewClientBuilder().WithScheme(s)
+			if c.status != nil {
+				latest := stale.DeepCopy()
+				latest.Status = *c.status
+				builder = builder.WithObjects(latest)
+			}
+			sch := &scheduler{k8sClient: builder.Build(), scheme: s}
+
+			pending, err := sch.stillPending(stale)
+			require.NoError(t, err)
+			require.Equal(t, c.expectedPending, pending)
+		})
+	}
+}
@@ -0,0 +1,70 @@
+/*
+ Copyright 2021 The CI/CD Operator Authors
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package server
+
+import (
+	"sync"
+	"time"
+
+	"github.com/tmax-cloud/cicd-operator/internal/configs"
+)
+
+// deliveryIDs remembers the delivery IDs of the received webhooks for the dedup window (webhookDedupWindow), so the
+// webhooks redelivered by the git servers are ignored
+type deliveryIDs struct {
+	lock     sync.Mutex
+	received map[string]time.Time
+}
+
+func newDeliveryIDs() *deliveryIDs {
+	return &deliveryIDs{received: map[string]time.Time{}}
+}
+
+// add adds the delivery ID of a webhook of the IntegrationConfig, received at t. It returns false if the ID is already
+// added within the window, i.e., the webhook is a duplicate. Webhooks without delivery IDs are never duplicates
+func (d *deliveryIDs) add(namespace, config, id string, t time.Time) bool {
+	window := time.Duration(configs.WebhookDedupWindow) * time.Minute
+	if window <= 0 || id == "" {
+		return true
+	}
+
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	now := time.Now()
+	for key, received := range d.received {
+		if now.Sub(received) > window {
+			delete(d.received, key)
+		}
+	}
+
+	key := namespace + "/" + config + "/" + id
+	if _, exist := d.received[key]; exist {
+		return false
+	}
+	if now.Sub(t) <= window {
+		d.received[key] = t
+	}
+	return true
+}
+
+// remove removes the delivery ID, so its redelivery can be handled
+func (d *deliveryIDs) remove(namespace, config, id string) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	delete(d.received, namespace+"/"+config+"/"+id)
+}
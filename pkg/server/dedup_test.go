@@ -0,0 +1,58 @@
+/*
+ Copyright 2021 The CI/CD Operator Authors
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package server
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tmax-cloud/cicd-operator/internal/configs"
+)
+
+func TestDeliveryIDs(t *testing.T) {
+	configs.WebhookDedupWindow = 60
+	defer func() {
+		configs.WebhookDedupWindow = 0
+	}()
+
+	d := newDeliveryIDs()
+	now := time.Now()
+
+	// Redelivery is a duplicate
+	require.True(t, d.add("default", "test-ic", "id-1", now))
+	require.False(t, d.add("default", "test-ic", "id-1", now))
+
+	// Same ID for another config is not a duplicate
+	require.True(t, d.add("default", "other-ic", "id-1", now))
+
+	// Webhooks without delivery IDs are never duplicates
+	require.True(t, d.add("default", "test-ic", "", now))
+	require.True(t, d.add("default", "test-ic", "", now))
+
+	// Removed ID can be added again
+	d.remove("default", "test-ic", "id-1")
+	require.True(t, d.add("default", "test-ic", "id-1", now))
+
+	// IDs out of the window are forgotten
+	require.True(t, d.add("default", "test-ic", "id-old", now.Add(-2*time.Hour)))
+	require.True(t, d.add("default", "test-ic", "id-old", now))
+
+	// Disabled
+	configs.WebhookDedupWindow = 0
+	require.True(t, d.add("default", "test-ic", "id-1", now))
+}
@@ -0,0 +1,197 @@
+/*
+ Copyright 2021 The CI/CD Operator Authors
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	cicdv1 "github.com/tmax-cloud/cicd-operator/api/v1"
+	"github.com/tmax-cloud/cicd-operator/internal/configs"
+	"github.com/tmax-cloud/cicd-operator/internal/utils"
+	"github.com/tmax-cloud/cicd-operator/pkg/git"
+	"github.com/tmax-cloud/cicd-operator/pkg/tracing"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// +kubebuilder:rbac:groups=cicd.tmax.io,resources=webhookdeliveries,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=cicd.tmax.io,resources=webhookdeliveries/status,verbs=get;update;patch
+
+// deliveryPruneInterval is the interval of pruning the WebhookDeliveries exceeding the limit or the TTL
+const deliveryPruneInterval = 1 * time.Minute
+
+// storeDelivery stores the webhook request as a WebhookDelivery, so it can be replayed later.
+// It returns nil if the store is disabled or the delivery cannot be stored
+func storeDelivery(cli client.Client, config *cicdv1.IntegrationConfig, id string, header http.Header, body []byte) *cicdv1.WebhookDelivery {
+	if configs.WebhookDeliveryLimit <= 0 {
+		return nil
+	}
+
+	isController := true
+	delivery := &cicdv1.WebhookDelivery{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      deliveryName(config, id),
+			Namespace: config.Namespace,
+			Labels: map[string]string{
+				cicdv1.WebhookDeliveryLabelConfig: config.Name,
+			},
+			OwnerReferences: []metav1.OwnerReference{{
+				APIVersion: cicdv1.GroupVersion.String(),
+				Kind:       "IntegrationConfig",
+				Name:       config.Name,
+				UID:        config.UID,
+				Controller: &isController,
+			}},
+		},
+		Spec: cicdv1.WebhookDeliverySpec{
+			IntegrationConfig: config.Name,
+			Header:            header.Clone(),
+			Body:              string(body),
+		},
+	}
+	if err := cli.Create(context.Background(), delivery); err != nil {
+		logger.Error(err, fmt.Sprintf("cannot store webhook delivery %s/%s", delivery.Namespace, delivery.Name))
+		return nil
+	}
+	return delivery
+}
+
+// deliveryName is the name of the WebhookDelivery for the webhook request
+func deliveryName(config *cicdv1.IntegrationConfig, id string) string {
+	return fmt.Sprintf("%s-%s", config.Name, id)
+}
+
+// recordDelivery records the state of the delivery to its status. It is a no-op if the delivery is nil.
+// The plugins' results should be set to the status before calling it
+func recordDelivery(cli client.Client, delivery *cicdv1.WebhookDelivery, state cicdv1.WebhookDeliveryState, wh *git.Webhook, err error) {
+	if delivery == nil {
+		return
+	}
+
+	delivery.Status.State = state
+	if wh != nil {
+		delivery.Status.EventType = string(wh.EventType)
+		delivery.Status.DeliveryID = wh.DeliveryID
+	}
+	delivery.Status.Error = ""
+	if err != nil {
+		delivery.Status.Error = err.Error()
+	}
+	delivery.Status.HandledTime = &metav1.Time{Time: time.Now()}
+
+	if err := cli.Status().Update(context.Background(), delivery); err != nil {
+		logger.Error(err, fmt.Sprintf("cannot update webhook delivery %s/%s", delivery.Namespace, delivery.Name))
+	}
+}
+
+// parseDelivery parses the stored webhook delivery
+func parseDelivery(ctx context.Context, cli client.Client, delivery *cicdv1.WebhookDelivery, config *cicdv1.IntegrationConfig) (*git.Webhook, error) {
+	gitCli, err := utils.GetGitCliWithContext(ctx, config, cli)
+	if err != nil {
+		return nil, err
+	}
+	return gitCli.ParseWebhook(delivery.Spec.Header, []byte(delivery.Spec.Body))
+}
+
+// startDeliveryPruner prunes the deliveries every deliveryPruneInterval, in the background
+func startDeliveryPruner(cli client.Client) {
+	go wait.Forever(func() {
+		if err := pruneDeliveries(cli); err != nil {
+			if _, ok := err.(*cache.ErrCacheNotStarted); ok {
+				return
+			}
+			logger.Error(err, "cannot prune webhook deliveries")
+		}
+	}, deliveryPruneInterval)
+}
+
+// pruneDeliveries deletes the handled deliveries of each config, exceeding the limit or the TTL
+func pruneDeliveries(cli client.Client) error {
+	if configs.WebhookDeliveryLimit <= 0 {
+		return nil
+	}
+
+	deliveries := &cicdv1.WebhookDeliveryList{}
+	if err := cli.List(context.Background(), deliveries); err != nil {
+		return err
+	}
+
+	// Newest first
+	sort.Slice(deliveries.Items, func(i, j int) bool {
+		return deliveries.Items[j].CreationTimestamp.Before(&deliveries.Items[i].CreationTimestamp)
+	})
+
+	now := time.Now()
+	kept := map[string]int{}
+	for i := range deliveries.Items {
+		d := &deliveries.Items[i]
+		// Queued deliveries are not pruned by the limit, not to lose the webhooks before they are handled
+		if !d.Status.Handled() && !d.Expired(now) {
+			continue
+		}
+		key := d.Namespace + "/" + d.Spec.IntegrationConfig
+		if kept[key] < configs.WebhookDeliveryLimit && !d.Expired(now) {
+			kept[key]++
+			continue
+		}
+		if err := cli.Delete(context.Background(), d); err != nil && client.IgnoreNotFound(err) != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ReplayDelivery re-runs the stored webhook delivery through the plugins synchronously, and records the result to
+// the delivery
+func ReplayDelivery(ctx context.Context, cli client.Client, delivery *cicdv1.WebhookDelivery, config *cicdv1.IntegrationConfig) (err error) {
+	ctx, span := tracing.Start(ctx, "webhook.replay",
+		tracing.AttributeNamespace.String(config.Namespace), tracing.AttributeIntegrationConfig.String(config.Name))
+	defer func() {
+		tracing.End(span, err)
+	}()
+
+	delivery.Status.Replays++
+
+	wh, err := parseDelivery(ctx, cli, delivery, config)
+	if err != nil {
+		delivery.Status.Plugins = nil
+		recordDelivery(cli, delivery, cicdv1.WebhookDeliveryStateFailed, nil, err)
+		return err
+	}
+	if wh == nil {
+		delivery.Status.Plugins = nil
+		recordDelivery(cli, delivery, cicdv1.WebhookDeliveryStateSucceeded, nil, nil)
+		return nil
+	}
+	span.SetAttributes(tracing.AttributeGitEvent.String(string(wh.EventType)))
+
+	results, err := HandleEventWithResults(ctx, wh, config)
+	delivery.Status.Plugins = results
+	delivery.Status.Attempts++
+	if err != nil {
+		recordDelivery(cli, delivery, cicdv1.WebhookDeliveryStateFailed, wh, nil)
+		return err
+	}
+	recordDelivery(cli, delivery, cicdv1.WebhookDeliveryStateSucceeded, wh, nil)
+	return nil
+}
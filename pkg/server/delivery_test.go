@@ -0,0 +1,288 @@
+/*
+ Copyright 2021 The CI/CD Operator Authors
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	cicdv1 "github.com/tmax-cloud/cicd-operator/api/v1"
+	"github.com/tmax-cloud/cicd-operator/internal/configs"
+	"github.com/tmax-cloud/cicd-operator/pkg/git"
+	"github.com/tmax-cloud/cicd-operator/pkg/git/github"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func testDelivery(name string, age time.Duration) *cicdv1.WebhookDelivery {
+	return &cicdv1.WebhookDelivery{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              name,
+			Namespace:         "default",
+			Labels:            map[string]string{cicdv1.WebhookDeliveryLabelConfig: "test-ic"},
+			CreationTimestamp: metav1.Time{Time: time.Now().Add(-age)},
+		},
+		Spec:   cicdv1.WebhookDeliverySpec{IntegrationConfig: "test-ic"},
+		Status: cicdv1.WebhookDeliveryStatus{State: cicdv1.WebhookDeliveryStateSucceeded},
+	}
+}
+
+func Test_storeDelivery(t *testing.T) {
+	s := runtime.NewScheme()
+	require.NoError(t, cicdv1.AddToScheme(s))
+
+	ic := &cicdv1.IntegrationConfig{ObjectMeta: metav1.ObjectMeta{Name: "test-ic", Namespace: "default"}}
+
+	tc := map[string]struct {
+		limit int
+
+		expectedStored bool
+	}{
+		"disabled": {
+			limit: 0,
+		},
+		"normal": {
+			limit:          3,
+			expectedStored: true,
+		},
+	}
+
+	for name, c := range tc {
+		t.Run(name, func(t *testing.T) {
+			configs.WebhookDeliveryLimit = c.limit
+			cli := fake.NewClientBuilder().WithScheme(s).Build()
+
+			header := http.Header{"X-Github-Event": []string{"push"}}
+			delivery := storeDelivery(cli, ic, "new", header, []byte("{}"))
+			list := &cicdv1.WebhookDeliveryList{}
+			require.NoError(t, cli.List(context.Background(), list))
+			if !c.expectedStored {
+				require.Nil(t, delivery)
+				require.Empty(t, list.Items)
+				return
+			}
+			require.NotNil(t, delivery)
+			require.Equal(t, "test-ic-new", delivery.Name)
+			require.Equal(t, "test-ic", delivery.Spec.IntegrationConfig)
+			require.Equal(t, "{}", delivery.Spec.Body)
+			require.Equal(t, []string{"push"}, delivery.Spec.Header["X-Github-Event"])
+			require.Equal(t, "test-ic", delivery.OwnerReferences[0].Name)
+			require.Len(t, list.Items, 1)
+		})
+	}
+}
+
+func Test_pruneDeliveries(t *testing.T) {
+	s := runtime.NewScheme()
+	require.NoError(t, cicdv1.AddToScheme(s))
+
+	configs.WebhookDeliveryTTL = 24
+
+	otherConfigDelivery := func(name string, age time.Duration) *cicdv1.WebhookDelivery {
+		d := testDelivery(name, age)
+		d.Labels[cicdv1.WebhookDeliveryLabelConfig] = "other-ic"
+		d.Spec.IntegrationConfig = "other-ic"
+		return d
+	}
+
+	tc := map[string]struct {
+		limit    int
+		existing []client.Object
+
+		expectedRemaining []string
+	}{
+		"disabled": {
+			limit: 0,
+			existing: []client.Object{
+				testDelivery("test-ic-old1", time.Hour),
+				testDelivery("test-ic-old2", 25*time.Hour),
+			},
+			expectedRemaining: []string{"test-ic-old1", "test-ic-old2"},
+		},
+		"limitExceeded": {
+			limit: 2,
+			existing: []client.Object{
+				testDelivery("test-ic-old1", time.Hour),
+				testDelivery("test-ic-old2", 2*time.Hour),
+				testDelivery("test-ic-old3", 3*time.Hour),
+				otherConfigDelivery("other-ic-old1", 4*time.Hour),
+				otherConfigDelivery("other-ic-old2", 5*time.Hour),
+			},
+			expectedRemaining: []string{"test-ic-old1", "test-ic-old2", "other-ic-old1", "other-ic-old2"},
+		},
+		"queuedNotPruned": {
+			limit: 1,
+			existing: []client.Object{
+				testDelivery("test-ic-old1", time.Hour),
+				&cicdv1.WebhookDelivery{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:              "test-ic-old2",
+						Namespace:         "default",
+						Labels:            map[string]string{cicdv1.WebhookDeliveryLabelConfig: "test-ic"},
+						CreationTimestamp: metav1.Time{Time: time.Now().Add(-2 * time.Hour)},
+					},
+					Spec: cicdv1.WebhookDeliverySpec{IntegrationConfig: "test-ic"},
+				},
+				testDelivery("test-ic-old3", 3*time.Hour),
+			},
+			expectedRemaining: []string{"test-ic-old1", "test-ic-old2"},
+		},
+		"expired": {
+			limit: 3,
+			existing: []client.Object{
+				testDelivery("test-ic-old1", time.Hour),
+				testDelivery("test-ic-old2", 25*time.Hour),
+			},
+			expectedRemaining: []string{"test-ic-old1"},
+		},
+	}
+
+	for name, c := range tc {
+		t.Run(name, func(t *testing.T) {
+			configs.WebhookDeliveryLimit = c.limit
+			cli := fake.NewClientBuilder().WithScheme(s).WithObjects(c.existing...).Build()
+
+			require.NoError(t, pruneDeliveries(cli))
+
+			list := &cicdv1.WebhookDeliveryList{}
+			require.NoError(t, cli.List(context.Background(), list))
+			var remaining []string
+			for _, d := range list.Items {
+				remaining = append(remaining, d.Name)
+			}
+			require.ElementsMatch(t, c.expectedRemaining, remaining)
+		})
+	}
+}
+
+type testPlugin struct {
+	handled []string
+}
+
+func (p *testPlugin) Name() string {
+	return "test-plugin"
+}
+
+func (p *testPlugin) Handle(_ context.Context, wh *git.Webhook, _ *cicdv1.IntegrationConfig) error {
+	p.handled = append(p.handled, wh.Push.Sha)
+	if wh.Push.Sha == "error-sha" {
+		return fmt.Errorf("test error")
+	}
+	return nil
+}
+
+func TestReplayDelivery(t *testing.T) {
+	s := runtime.NewScheme()
+	require.NoError(t, cicdv1.AddToScheme(s))
+
+	plugin := &testPlugin{}
+	AddPlugin([]git.EventType{git.EventTypePush}, plugin)
+	defer func() {
+		plugins = map[git.EventType][]Plugin{}
+	}()
+
+	ic := &cicdv1.IntegrationConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-ic", Namespace: "default"},
+		Spec: cicdv1.IntegrationConfigSpec{
+			Git: cicdv1.GitConfig{Type: cicdv1.GitTypeGitHub, APIUrl: "http://127.0.0.1:1", Repository: "test/test"},
+		},
+		Status: cicdv1.IntegrationConfigStatus{Secrets: "test-secret"},
+	}
+
+	tc := map[string]struct {
+		sha       string
+		signature string
+
+		errorOccurs        bool
+		errorMessage       string
+		expectedState      cicdv1.WebhookDeliveryState
+		expectedEventType  string
+		expectedError      string
+		expectedPluginErrs []string
+	}{
+		"normal": {
+			sha:                "test-sha",
+			expectedState:      cicdv1.WebhookDeliveryStateSucceeded,
+			expectedEventType:  "push",
+			expectedPluginErrs: []string{""},
+		},
+		"pluginErr": {
+			sha:                "error-sha",
+			errorOccurs:        true,
+			errorMessage:       "test error",
+			expectedState:      cicdv1.WebhookDeliveryStateFailed,
+			expectedEventType:  "push",
+			expectedPluginErrs: []string{"test error"},
+		},
+		"parseErr": {
+			sha:           "test-sha",
+			signature:     "wrong-signature",
+			errorOccurs:   true,
+			errorMessage:  "invalid request : X-Hub-Signature does not match secret",
+			expectedState: cicdv1.WebhookDeliveryStateFailed,
+			expectedError: "invalid request : X-Hub-Signature does not match secret",
+		},
+	}
+
+	for name, c := range tc {
+		t.Run(name, func(t *testing.T) {
+			body := fmt.Sprintf(`{"ref": "refs/heads/master", "after": "%s", "repository": {"full_name": "test/test"}, "sender": {"login": "test"}}`, c.sha)
+			signature := c.signature
+			if signature == "" {
+				signature = github.HashPayload("test-secret", []byte(body))
+			}
+			delivery := testDelivery("test-ic-delivery", time.Hour)
+			delivery.Spec.Header = map[string][]string{
+				"X-Github-Event":  {"push"},
+				"X-Hub-Signature": {"sha1=" + signature},
+			}
+			delivery.Spec.Body = body
+			cli := fake.NewClientBuilder().WithScheme(s).WithObjects(ic, delivery).Build()
+			plugin.handled = nil
+
+			err := ReplayDelivery(context.Background(), cli, delivery, ic)
+			if c.errorOccurs {
+				require.Error(t, err)
+				require.Equal(t, c.errorMessage, err.Error())
+			} else {
+				require.NoError(t, err)
+			}
+
+			stored := &cicdv1.WebhookDelivery{}
+			require.NoError(t, cli.Get(context.Background(), types.NamespacedName{Name: delivery.Name, Namespace: delivery.Namespace}, stored))
+			require.Equal(t, 1, stored.Status.Replays)
+			require.Equal(t, c.expectedState, stored.Status.State)
+			require.NotNil(t, stored.Status.HandledTime)
+			require.Equal(t, c.expectedEventType, stored.Status.EventType)
+			require.Equal(t, c.expectedError, stored.Status.Error)
+			var pluginErrs []string
+			for _, p := range stored.Status.Plugins {
+				require.Equal(t, "test-plugin", p.Name)
+				pluginErrs = append(pluginErrs, p.Error)
+			}
+			require.Equal(t, c.expectedPluginErrs, pluginErrs)
+			require.Equal(t, len(c.expectedPluginErrs), len(plugin.handled))
+		})
+	}
+}
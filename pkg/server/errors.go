@@ -0,0 +1,45 @@
+/*
+ Copyright 2021 The CI/CD Operator Authors
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package server
+
+import "errors"
+
+// PermanentError is an error of a plugin, which is not resolved by retrying the plugin
+type PermanentError struct {
+	Err error
+}
+
+// NewPermanentError wraps the error as a PermanentError
+func NewPermanentError(err error) *PermanentError {
+	return &PermanentError{Err: err}
+}
+
+// Error returns error string
+func (e *PermanentError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the wrapped error
+func (e *PermanentError) Unwrap() error {
+	return e.Err
+}
+
+// IsPermanent returns if the error is a PermanentError or wraps one
+func IsPermanent(err error) bool {
+	var permanentErr *PermanentError
+	return errors.As(err, &permanentErr)
+}
@@ -53,6 +53,14 @@ type report struct {
 	JobJobName string
 	JobStatus  *cicdv1.JobStatus
 	Log        string
+	// Attempts are the logs of each attempt, if the job is retried
+	Attempts []reportAttempt
+}
+
+type reportAttempt struct {
+	Attempt int
+	PodName string
+	Log     string
 }
 
 type reportHandler struct {
@@ -119,6 +127,16 @@ func (h *reportHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		podLog = errorLogNotExist
 	}
 
+	// Get logs of each attempt
+	var attempts []reportAttempt
+	for i, podName := range jobStatus.AttemptPodNames {
+		attemptLog, err := h.getPodLogs(podName, ns, log)
+		if err != nil {
+			attemptLog = errorLogNotExist
+		}
+		attempts = append(attempts, reportAttempt{Attempt: i + 1, PodName: podName, Log: attemptLog})
+	}
+
 	// Get template
 	tmpl, err := h.getTemplate()
 	if err != nil {
@@ -130,7 +148,7 @@ func (h *reportHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	// Publish report
 	// Maybe template.Execute writes header before returning 400...? so we do NOT call tmpl.Execute(w, ...) directly
 	var buf bytes.Buffer
-	if err := tmpl.Execute(&buf, report{JobName: ijName, JobJobName: job, JobStatus: jobStatus, Log: podLog}); err != nil {
+	if err := tmpl.Execute(&buf, report{JobName: ijName, JobJobName: job, JobStatus: jobStatus, Log: podLog, Attempts: attempts}); err != nil {
 		logAndRespond(w, log, http.StatusBadRequest, fmt.Sprintf("req: %s, cannot execute report template", reqID),
 			"Cannot execute report template, err: "+err.Error())
 		return
@@ -96,6 +96,26 @@ func Test_reportHandler_ServeHTTP(t *testing.T) {
 			expectedCode:    http.StatusOK,
 			expectedMessage: "TEST: test # Step : step-step-0\nThis is the log of the pod1 - step-step-0\n\n# Step : step-step-1\nThis is the log of the pod1 - step-step-1\n\n",
 		},
+		"retried": {
+			ns:   "default",
+			name: "test",
+			job:  "job1",
+			ij: &cicdv1.IntegrationJob{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "default",
+					Name:      "test",
+				},
+				Status: cicdv1.IntegrationJobStatus{
+					Jobs: []cicdv1.JobStatus{
+						{Name: "job1", PodName: "pod1", Attempt: 2, AttemptPodNames: []string{"pod0", "pod1"}},
+					},
+				},
+			},
+			template: []byte("TEST: {{range .Attempts}}#{{.Attempt}} {{.PodName}} {{.Log}}{{end}}"),
+
+			expectedCode:    http.StatusOK,
+			expectedMessage: "TEST: #1 pod0 log does not exist... maybe the pod does not exist#2 pod1 # Step : step-step-0\nThis is the log of the pod1 - step-step-0\n\n# Step : step-step-1\nThis is the log of the pod1 - step-step-1\n\n",
+		},
 		"noJobJobErr": {
 			ns:   "default",
 			name: "test",
@@ -21,9 +21,13 @@ import (
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"time"
 
 	"github.com/gorilla/mux"
 	"github.com/tmax-cloud/cicd-operator/internal/utils"
+	"github.com/tmax-cloud/cicd-operator/pkg/metrics"
+	"github.com/tmax-cloud/cicd-operator/pkg/tracing"
+	"go.opentelemetry.io/otel/trace"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
@@ -34,6 +38,7 @@ var webhookPath = fmt.Sprintf("/webhook/{%s}/{%s}", paramKeyNamespace, paramKeyC
 
 type webhookHandler struct {
 	k8sClient client.Client
+	queue     *webhookQueue
 }
 
 func (h *webhookHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
@@ -51,8 +56,13 @@ func (h *webhookHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	ctx, span := tracing.Start(tracing.ExtractHTTP(r.Context(), r.Header), "webhook.receive",
+		tracing.AttributeNamespace.String(ns), tracing.AttributeIntegrationConfig.String(configName))
+	defer span.End()
+
 	body, err := ioutil.ReadAll(r.Body)
 	if err != nil {
+		tracing.SetError(span, err)
 		_ = utils.RespondError(w, http.StatusInternalServerError, fmt.Sprintf("req: %s, cannot read webhook body", reqID))
 		log.Info("cannot read webhook body", "error", err.Error())
 		return
@@ -60,21 +70,30 @@ func (h *webhookHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 
 	config := &cicdv1.IntegrationConfig{}
 	if err := h.k8sClient.Get(context.Background(), types.NamespacedName{Name: configName, Namespace: ns}, config); err != nil {
+		tracing.SetError(span, err)
 		_ = utils.RespondError(w, http.StatusBadRequest, fmt.Sprintf("req: %s, cannot get IntegrationConfig %s/%s", reqID, ns, configName))
 		log.Info("Bad request for path", "path", r.RequestURI, "error", err.Error())
 		return
 	}
 
-	gitCli, err := utils.GetGitCli(config, h.k8sClient)
+	provider := string(config.Spec.Git.Type)
+	metrics.WebhookEventsReceived.WithLabelValues(provider).Inc()
+	span.SetAttributes(tracing.AttributeGitProvider.String(provider))
+
+	gitCli, err := utils.GetGitCliWithContext(ctx, config, h.k8sClient)
 	if err != nil {
+		tracing.SetError(span, err)
+		metrics.WebhookEventsFailed.WithLabelValues(provider, "gitClient").Inc()
 		log.Info("Cannot initialize git cli", "error", err.Error())
 		_ = utils.RespondError(w, http.StatusInternalServerError, fmt.Sprintf("req: %s, err: %s", reqID, err.Error()))
 		return
 	}
 
-	// Convert webhook
+	// Convert webhook. The signature is validated here, so the rejected requests are not stored
 	wh, err := gitCli.ParseWebhook(r.Header, body)
 	if err != nil {
+		tracing.SetError(span, err)
+		metrics.WebhookEventsFailed.WithLabelValues(provider, "parse").Inc()
 		_ = utils.RespondError(w, http.StatusInternalServerError, fmt.Sprintf("req: %s, cannot parse webhook body", reqID))
 		log.Info("Cannot parse webhook", "error", err.Error())
 		return
@@ -83,13 +102,42 @@ func (h *webhookHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	if wh == nil {
 		return
 	}
+	metrics.WebhookEventsParsed.WithLabelValues(provider, string(wh.EventType)).Inc()
+	span.SetAttributes(tracing.AttributeGitEvent.String(string(wh.EventType)))
 
 	if config.Spec.RequestBodyLogging {
 		log.Info(string(body))
 	}
 
-	// Call plugin functions
-	if err := HandleEvent(wh, config); err != nil {
-		log.Error(err, "")
+	// Store the delivery before it's handled, so it can be replayed or recovered even if it fails halfway
+	h.queue.markReceived(config.Namespace, deliveryName(config, reqID))
+	delivery := storeDelivery(h.k8sClient, config, reqID, r.Header, body)
+
+	// Ignore the webhook redelivered by the git server
+	if !h.queue.deliveryIDs.add(config.Namespace, config.Name, wh.DeliveryID, time.Now()) {
+		span.AddEvent("Duplicated webhook is ignored")
+		recordDelivery(h.k8sClient, delivery, cicdv1.WebhookDeliveryStateDuplicated, wh, nil)
+		metrics.WebhookEventsDuplicated.WithLabelValues(provider).Inc()
+		log.Info(fmt.Sprintf("webhook is ignored, as delivery %s is already received", wh.DeliveryID))
+		return
 	}
+
+	// Queue the webhook to be handled by the plugins. The trace is continued by the worker, after the request is done
+	item := &webhookItem{
+		ctx:      trace.ContextWithSpanContext(context.Background(), span.SpanContext()),
+		config:   config,
+		wh:       wh,
+		delivery: delivery,
+	}
+	if !h.queue.add(item) {
+		h.queue.deliveryIDs.remove(config.Namespace, config.Name, wh.DeliveryID)
+		tracing.SetError(span, errQueueFull)
+		recordDelivery(h.k8sClient, delivery, cicdv1.WebhookDeliveryStateFailed, wh, errQueueFull)
+		metrics.WebhookEventsFailed.WithLabelValues(provider, "queueFull").Inc()
+		_ = utils.RespondError(w, http.StatusServiceUnavailable, fmt.Sprintf("req: %s, %s", reqID, errQueueFull.Error()))
+		log.Info(errQueueFull.Error())
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
 }
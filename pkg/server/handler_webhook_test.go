@@ -0,0 +1,85 @@
+/*
+ Copyright 2021 The CI/CD Operator Authors
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package server
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/require"
+	cicdv1 "github.com/tmax-cloud/cicd-operator/api/v1"
+	"github.com/tmax-cloud/cicd-operator/internal/configs"
+	"github.com/tmax-cloud/cicd-operator/pkg/git/github"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestWebhookHandler_ServeHTTP(t *testing.T) {
+	configs.WebhookDeliveryLimit = 20
+	body := []byte(`{"ref": "refs/heads/master", "after": "sha-1", "repository": {"full_name": "test/test"}, "sender": {"login": "test"}}`)
+
+	tc := map[string]struct {
+		secret string
+
+		expectedCode       int
+		expectedDeliveries int
+	}{
+		"validSignature": {
+			secret:             "test-secret",
+			expectedCode:       http.StatusAccepted,
+			expectedDeliveries: 1,
+		},
+		"invalidSignature": {
+			secret:             "wrong-secret",
+			expectedCode:       http.StatusInternalServerError,
+			expectedDeliveries: 0,
+		},
+	}
+
+	for name, c := range tc {
+		t.Run(name, func(t *testing.T) {
+			ic := &cicdv1.IntegrationConfig{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-ic", Namespace: "default"},
+				Spec: cicdv1.IntegrationConfigSpec{
+					Git: cicdv1.GitConfig{Type: cicdv1.GitTypeGitHub, APIUrl: "http://127.0.0.1:1", Repository: "test/test"},
+				},
+				Status: cicdv1.IntegrationConfigStatus{Secrets: "test-secret"},
+			}
+			cli := fake.NewClientBuilder().WithScheme(testQueueScheme(t)).WithObjects(ic).Build()
+			q := newWebhookQueue(cli, 1)
+			defer q.queue.ShutDown()
+			handler := &webhookHandler{k8sClient: cli, queue: q}
+
+			req := httptest.NewRequest(http.MethodPost, "/webhook/default/test-ic", bytes.NewReader(body))
+			req.Header.Set("X-Github-Event", "push")
+			req.Header.Set("X-Hub-Signature", "sha1="+github.HashPayload(c.secret, body))
+			req = mux.SetURLVars(req, map[string]string{paramKeyNamespace: "default", paramKeyConfigName: "test-ic"})
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+			require.Equal(t, c.expectedCode, w.Code)
+
+			// Requests with invalid signatures are not stored
+			deliveries := &cicdv1.WebhookDeliveryList{}
+			require.NoError(t, cli.List(context.Background(), deliveries))
+			require.Len(t, deliveries.Items, c.expectedDeliveries)
+		})
+	}
+}
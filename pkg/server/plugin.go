@@ -17,30 +17,64 @@
 package server
 
 import (
+	"context"
+
 	cicdv1 "github.com/tmax-cloud/cicd-operator/api/v1"
 	"github.com/tmax-cloud/cicd-operator/pkg/git"
+	"github.com/tmax-cloud/cicd-operator/pkg/tracing"
 )
 
 // Plugin is a webhook plugin interface, which handles git webhook payloads
 type Plugin interface {
 	Name() string
-	Handle(*git.Webhook, *cicdv1.IntegrationConfig) error
+	Handle(context.Context, *git.Webhook, *cicdv1.IntegrationConfig) error
 }
 
 var plugins = map[git.EventType][]Plugin{}
 
-// HandleEvent passes webhook event to plugins
-func HandleEvent(wh *git.Webhook, ic *cicdv1.IntegrationConfig, wantedPlugins ...string) error {
-	var retErr error
+// HandleEvent passes webhook event to plugins. Each plugin is traced as a child span of ctx
+func HandleEvent(ctx context.Context, wh *git.Webhook, ic *cicdv1.IntegrationConfig, wantedPlugins ...string) error {
+	_, err := HandleEventWithResults(ctx, wh, ic, wantedPlugins...)
+	return err
+}
+
+// HandleEventWithResults passes webhook event to plugins, and returns the result of each plugin.
+// The returned error is the last error returned by the plugins. A PermanentError is returned only if every failed
+// plugin returned a PermanentError, so the returned error is retriable if any of the plugins can be retried
+func HandleEventWithResults(ctx context.Context, wh *git.Webhook, ic *cicdv1.IntegrationConfig, wantedPlugins ...string) ([]cicdv1.WebhookDeliveryPluginResult, error) {
+	results, _, err := handlePlugins(ctx, wh, ic, wantedPlugins...)
+	return results, err
+}
+
+// handlePlugins passes webhook event to plugins, and returns the result of each plugin and the names of the plugins
+// failed with retriable errors
+func handlePlugins(ctx context.Context, wh *git.Webhook, ic *cicdv1.IntegrationConfig, wantedPlugins ...string) ([]cicdv1.WebhookDeliveryPluginResult, []string, error) {
+	var retriableErr, permanentErr error
+	var results []cicdv1.WebhookDeliveryPluginResult
+	var retriable []string
 	plugins := getPlugins(wh.EventType)
 	for _, p := range plugins {
 		if len(wantedPlugins) == 0 || contains(wantedPlugins, p.Name()) {
-			if err := p.Handle(wh, ic); err != nil {
-				retErr = err
+			pluginCtx, span := tracing.Start(ctx, "plugin."+p.Name(), tracing.AttributePlugin.String(p.Name()))
+			err := p.Handle(pluginCtx, wh, ic)
+			tracing.End(span, err)
+			result := cicdv1.WebhookDeliveryPluginResult{Name: p.Name()}
+			if err != nil {
+				if IsPermanent(err) {
+					permanentErr = err
+				} else {
+					retriableErr = err
+					retriable = append(retriable, p.Name())
+				}
+				result.Error = err.Error()
 			}
+			results = append(results, result)
 		}
 	}
-	return retErr
+	if retriableErr != nil {
+		return results, retriable, retriableErr
+	}
+	return results, nil, permanentErr
 }
 
 func contains(list []string, needle string) bool {
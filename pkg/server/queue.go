@@ -0,0 +1,367 @@
+/*
+ Copyright 2021 The CI/CD Operator Authors
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package server
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	cicdv1 "github.com/tmax-cloud/cicd-operator/api/v1"
+	"github.com/tmax-cloud/cicd-operator/internal/configs"
+	"github.com/tmax-cloud/cicd-operator/pkg/git"
+	"github.com/tmax-cloud/cicd-operator/pkg/metrics"
+	"github.com/tmax-cloud/cicd-operator/pkg/tracing"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// webhookQueueSize is the max number of queued webhooks per worker
+const webhookQueueSize = 100
+
+var errQueueFull = fmt.Errorf("webhook queue is full")
+
+// webhookRetryBackoff is a backoff for retrying the failed plugins
+var webhookRetryBackoff = wait.Backoff{
+	Duration: 1 * time.Second,
+	Factor:   2,
+	Steps:    10,
+	Cap:      1 * time.Minute,
+}
+
+// webhookItem is a parsed webhook, queued to be handled by the plugins
+type webhookItem struct {
+	// ctx carries the trace context of the webhook request
+	ctx      context.Context
+	config   *cicdv1.IntegrationConfig
+	wh       *git.Webhook
+	delivery *cicdv1.WebhookDelivery
+
+	// plugins are the plugins to be run. Every plugin is run if it is empty
+	plugins  []string
+	results  []cicdv1.WebhookDeliveryPluginResult
+	attempts int
+
+	// backoff is the backoff of retrying the failed plugins, and retryAt is the time of the next retry
+	backoff wait.Backoff
+	retryAt time.Time
+}
+
+// webhookQueue is a work queue of webhooks, handled by the workers asynchronously.
+// Webhooks of an IntegrationConfig (i.e., a repository) are queued in a list, and the key of the IntegrationConfig is
+// queued to the work queue, which never hands the same key to two workers at once. So the webhooks of an
+// IntegrationConfig are handled in the received order, and a webhook being retried delays only the later webhooks of
+// the same IntegrationConfig. The WebhookDeliveries are the durable records of the queue, so the webhooks not handled
+// yet are queued again when the server restarts
+type webhookQueue struct {
+	k8sClient client.Client
+	workers   int
+	queue     workqueue.DelayingInterface
+
+	// items are the queued webhooks of each IntegrationConfig, in the received order
+	items     map[string][]*webhookItem
+	size      int
+	itemsLock sync.Mutex
+
+	// received are the deliveries received while recovering, which should not be recovered
+	received     map[string]struct{}
+	recovering   bool
+	recoveryLock sync.Mutex
+
+	// deliveryIDs are the delivery IDs of the webhooks received within the dedup window
+	deliveryIDs *deliveryIDs
+}
+
+func newWebhookQueue(c client.Client, workers int) *webhookQueue {
+	if workers < 1 {
+		workers = 1
+	}
+	return &webhookQueue{
+		k8sClient:   c,
+		workers:     workers,
+		queue:       workqueue.NewDelayingQueue(),
+		items:       map[string][]*webhookItem{},
+		received:    map[string]struct{}{},
+		recovering:  true,
+		deliveryIDs: newDeliveryIDs(),
+	}
+}
+
+// start starts the workers and queues the WebhookDeliveries not handled yet
+func (q *webhookQueue) start() {
+	for i := 0; i < q.workers; i++ {
+		go q.work()
+	}
+	go q.recover()
+}
+
+// markReceived marks that the delivery is received by the server, so it is not recovered.
+// It should be called before the delivery is stored
+func (q *webhookQueue) markReceived(namespace, name string) {
+	q.recoveryLock.Lock()
+	defer q.recoveryLock.Unlock()
+	if q.recovering {
+		q.received[namespace+"/"+name] = struct{}{}
+	}
+}
+
+// add queues the webhook. It returns false if the queue is full
+func (q *webhookQueue) add(item *webhookItem) bool {
+	q.itemsLock.Lock()
+	defer q.itemsLock.Unlock()
+	if q.size >= webhookQueueSize*q.workers {
+		return false
+	}
+
+	key := item.config.Namespace + "/" + item.config.Name
+	item.backoff = webhookRetryBackoff
+	q.items[key] = append(q.items[key], item)
+	q.size++
+	metrics.WebhookQueueDepth.Inc()
+	q.queue.Add(key)
+	return true
+}
+
+func (q *webhookQueue) work() {
+	for q.processNext() {
+	}
+}
+
+// processNext handles the first webhook of the next IntegrationConfig. If the webhook should be retried, it's kept at
+// the head of the list, and the IntegrationConfig is queued again after the backoff
+func (q *webhookQueue) processNext() bool {
+	obj, shutdown := q.queue.Get()
+	if shutdown {
+		return false
+	}
+	defer q.queue.Done(obj)
+	key := obj.(string)
+
+	item := q.head(key)
+	if item == nil {
+		return true
+	}
+	if delay := time.Until(item.retryAt); delay > 0 {
+		q.queue.AddAfter(key, delay)
+		return true
+	}
+
+	if !q.handle(item) {
+		q.queue.AddAfter(key, time.Until(item.retryAt))
+		return true
+	}
+	if q.pop(key) {
+		q.queue.Add(key)
+	}
+	return true
+}
+
+// head returns the first queued webhook of the IntegrationConfig
+func (q *webhookQueue) head(key string) *webhookItem {
+	q.itemsLock.Lock()
+	defer q.itemsLock.Unlock()
+	if len(q.items[key]) == 0 {
+		return nil
+	}
+	return q.items[key][0]
+}
+
+// pop removes the first queued webhook of the IntegrationConfig, and returns if there are more webhooks
+func (q *webhookQueue) pop(key string) bool {
+	q.itemsLock.Lock()
+	defer q.itemsLock.Unlock()
+	q.items[key] = q.items[key][1:]
+	q.size--
+	metrics.WebhookQueueDepth.Dec()
+	if len(q.items[key]) == 0 {
+		delete(q.items, key)
+		return false
+	}
+	return true
+}
+
+// handle runs the plugins for the webhook, and returns if the webhook is done. If any plugin fails, the failed plugins
+// are retried at retryAt, with a backoff. Plugins failed with a PermanentError are not retried.
+// The webhook is dead-lettered (i.e., the delivery is recorded as failed) if the plugins still fail after the retries,
+// or if every failed plugin returned a PermanentError
+func (q *webhookQueue) handle(item *webhookItem) bool {
+	provider := string(item.config.Spec.Git.Type)
+	ctx, span := tracing.Start(item.ctx, "webhook.handle",
+		tracing.AttributeNamespace.String(item.config.Namespace), tracing.AttributeIntegrationConfig.String(item.config.Name),
+		tracing.AttributeGitProvider.String(provider), tracing.AttributeGitEvent.String(string(item.wh.EventType)))
+	defer span.End()
+
+	log := logger.WithValues("namespace", item.config.Namespace, "config", item.config.Name)
+	results, retriable, err := handlePlugins(ctx, item.wh, item.config, item.plugins...)
+	item.attempts++
+	item.results = mergePluginResults(item.results, results)
+	if err == nil {
+		q.record(item, cicdv1.WebhookDeliveryStateSucceeded)
+		return true
+	}
+
+	if len(retriable) == 0 || item.attempts > configs.WebhookRetries {
+		tracing.SetError(span, err)
+		metrics.WebhookEventsFailed.WithLabelValues(provider, "handle").Inc()
+		if len(retriable) == 0 {
+			log.Error(err, "webhook is dead-lettered, as the error is not retriable")
+		} else {
+			log.Error(err, fmt.Sprintf("webhook is dead-lettered after %d attempts", item.attempts))
+		}
+		// Dead-lettered webhook can be handled again if it is redelivered
+		q.deliveryIDs.remove(item.config.Namespace, item.config.Name, item.wh.DeliveryID)
+		q.record(item, cicdv1.WebhookDeliveryStateFailed)
+		return true
+	}
+
+	metrics.WebhookEventRetries.WithLabelValues(provider).Inc()
+	log.Info(fmt.Sprintf("retrying failed plugins, attempts: %d, error: %s", item.attempts, err.Error()))
+	q.record(item, cicdv1.WebhookDeliveryStateRetrying)
+	item.plugins = retriable
+	item.retryAt = time.Now().Add(item.backoff.Step())
+	return false
+}
+
+// record records the plugins' results to the delivery
+func (q *webhookQueue) record(item *webhookItem, state cicdv1.WebhookDeliveryState) {
+	if item.delivery == nil {
+		return
+	}
+	item.delivery.Status.Plugins = item.results
+	item.delivery.Status.Attempts = item.attempts
+	recordDelivery(q.k8sClient, item.delivery, state, item.wh, nil)
+}
+
+// recover queues the WebhookDeliveries which are not handled yet, i.e., received or being retried when the server
+// stopped. The delivery IDs of the stored deliveries are remembered, so the webhooks redelivered after the server
+// restarts are also deduplicated
+func (q *webhookQueue) recover() {
+	deliveries := &cicdv1.WebhookDeliveryList{}
+	if err := wait.PollImmediateInfinite(time.Second, func() (bool, error) {
+		if err := q.k8sClient.List(context.Background(), deliveries); err != nil {
+			if _, ok := err.(*cache.ErrCacheNotStarted); ok {
+				return false, nil
+			}
+			return false, err
+		}
+		return true, nil
+	}); err != nil {
+		logger.Error(err, "cannot list webhook deliveries to be recovered")
+		return
+	}
+
+	defer func() {
+		q.recoveryLock.Lock()
+		q.recovering = false
+		q.received = nil
+		q.recoveryLock.Unlock()
+	}()
+
+	for i := range deliveries.Items {
+		delivery := &deliveries.Items[i]
+		if q.isReceived(delivery) {
+			continue
+		}
+		if delivery.Status.Handled() || delivery.Expired(time.Now()) {
+			if delivery.Status.State == cicdv1.WebhookDeliveryStateSucceeded {
+				q.deliveryIDs.add(delivery.Namespace, delivery.Spec.IntegrationConfig, delivery.Status.DeliveryID, delivery.CreationTimestamp.Time)
+			}
+			continue
+		}
+
+		config := &cicdv1.IntegrationConfig{}
+		if err := q.k8sClient.Get(context.Background(), types.NamespacedName{Name: delivery.Spec.IntegrationConfig, Namespace: delivery.Namespace}, config); err != nil {
+			logger.Error(err, fmt.Sprintf("cannot get IntegrationConfig of webhook delivery %s/%s", delivery.Namespace, delivery.Name))
+			continue
+		}
+
+		wh, err := parseDelivery(context.Background(), q.k8sClient, delivery, config)
+		if err != nil || wh == nil {
+			state := cicdv1.WebhookDeliveryStateFailed
+			if err == nil {
+				state = cicdv1.WebhookDeliveryStateSucceeded
+			}
+			recordDelivery(q.k8sClient, delivery, state, nil, err)
+			continue
+		}
+
+		if !q.deliveryIDs.add(delivery.Namespace, delivery.Spec.IntegrationConfig, wh.DeliveryID, delivery.CreationTimestamp.Time) {
+			metrics.WebhookEventsDuplicated.WithLabelValues(string(config.Spec.Git.Type)).Inc()
+			recordDelivery(q.k8sClient, delivery, cicdv1.WebhookDeliveryStateDuplicated, wh, nil)
+			continue
+		}
+
+		item := &webhookItem{
+			ctx:      context.Background(),
+			config:   config,
+			wh:       wh,
+			delivery: delivery,
+			results:  delivery.Status.Plugins,
+			attempts: delivery.Status.Attempts,
+		}
+		if delivery.Status.State == cicdv1.WebhookDeliveryStateRetrying {
+			item.plugins = failedPlugins(delivery.Status.Plugins)
+		}
+		logger.Info(fmt.Sprintf("recovering webhook delivery %s/%s", delivery.Namespace, delivery.Name))
+		if !q.add(item) {
+			metrics.WebhookEventsFailed.WithLabelValues(string(config.Spec.Git.Type), "queueFull").Inc()
+			q.deliveryIDs.remove(delivery.Namespace, delivery.Spec.IntegrationConfig, wh.DeliveryID)
+			recordDelivery(q.k8sClient, delivery, cicdv1.WebhookDeliveryStateFailed, wh, errQueueFull)
+		}
+	}
+}
+
+func (q *webhookQueue) isReceived(delivery *cicdv1.WebhookDelivery) bool {
+	q.recoveryLock.Lock()
+	defer q.recoveryLock.Unlock()
+	_, received := q.received[delivery.Namespace+"/"+delivery.Name]
+	return received
+}
+
+// mergePluginResults overwrites the previous results with the new results, by the plugin names
+func mergePluginResults(prev, results []cicdv1.WebhookDeliveryPluginResult) []cicdv1.WebhookDeliveryPluginResult {
+	merged := append([]cicdv1.WebhookDeliveryPluginResult{}, prev...)
+	for _, r := range results {
+		found := false
+		for i := range merged {
+			if merged[i].Name == r.Name {
+				merged[i] = r
+				found = true
+				break
+			}
+		}
+		if !found {
+			merged = append(merged, r)
+		}
+	}
+	return merged
+}
+
+// failedPlugins returns the names of the failed plugins
+func failedPlugins(results []cicdv1.WebhookDeliveryPluginResult) []string {
+	var failed []string
+	for _, r := range results {
+		if r.Error != "" {
+			failed = append(failed, r.Name)
+		}
+	}
+	return failed
+}
@@ -0,0 +1,309 @@
+/*
+ Copyright 2021 The CI/CD Operator Authors
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package server
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	cicdv1 "github.com/tmax-cloud/cicd-operator/api/v1"
+	"github.com/tmax-cloud/cicd-operator/internal/configs"
+	"github.com/tmax-cloud/cicd-operator/pkg/git"
+	"github.com/tmax-cloud/cicd-operator/pkg/git/github"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// flakyPlugin fails for the first failures calls, and records the handled shas.
+// It fails with a PermanentError if permanent is set
+type flakyPlugin struct {
+	name      string
+	failures  int
+	permanent bool
+
+	lock    sync.Mutex
+	handled []string
+}
+
+func (p *flakyPlugin) Name() string {
+	return p.name
+}
+
+func (p *flakyPlugin) Handle(_ context.Context, wh *git.Webhook, _ *cicdv1.IntegrationConfig) error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.handled = append(p.handled, wh.Push.Sha)
+	if len(p.handled) <= p.failures {
+		err := fmt.Errorf("%s error", p.name)
+		if p.permanent {
+			return NewPermanentError(err)
+		}
+		return err
+	}
+	return nil
+}
+
+func (p *flakyPlugin) getHandled() []string {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	return append([]string{}, p.handled...)
+}
+
+func testQueueScheme(t *testing.T) *runtime.Scheme {
+	s := runtime.NewScheme()
+	require.NoError(t, cicdv1.AddToScheme(s))
+	return s
+}
+
+func TestWebhookQueue_handle(t *testing.T) {
+	webhookRetryBackoff = wait.Backoff{Duration: time.Millisecond, Factor: 1, Steps: 10}
+	configs.WebhookRetries = 2
+
+	tc := map[string]struct {
+		failures  int
+		permanent bool
+
+		expectedState       cicdv1.WebhookDeliveryState
+		expectedAttempts    int
+		expectedFlakyCalls  int
+		expectedPluginError string
+	}{
+		"succeeded": {
+			failures:         0,
+			expectedState:    cicdv1.WebhookDeliveryStateSucceeded,
+			expectedAttempts: 1,
+		},
+		"retried": {
+			failures:         2,
+			expectedState:    cicdv1.WebhookDeliveryStateSucceeded,
+			expectedAttempts: 3,
+		},
+		"deadLettered": {
+			failures:            3,
+			expectedState:       cicdv1.WebhookDeliveryStateFailed,
+			expectedAttempts:    3,
+			expectedPluginError: "flaky error",
+		},
+		"permanent": {
+			failures:            3,
+			permanent:           true,
+			expectedState:       cicdv1.WebhookDeliveryStateFailed,
+			expectedAttempts:    1,
+			expectedPluginError: "flaky error",
+		},
+	}
+
+	for name, c := range tc {
+		t.Run(name, func(t *testing.T) {
+			stable := &flakyPlugin{name: "stable"}
+			flaky := &flakyPlugin{name: "flaky", failures: c.failures, permanent: c.permanent}
+			AddPlugin([]git.EventType{git.EventTypePush}, stable)
+			AddPlugin([]git.EventType{git.EventTypePush}, flaky)
+			defer func() {
+				plugins = map[git.EventType][]Plugin{}
+			}()
+
+			delivery := testDelivery("test-ic-delivery", time.Hour)
+			delivery.Status = cicdv1.WebhookDeliveryStatus{}
+			cli := fake.NewClientBuilder().WithScheme(testQueueScheme(t)).WithObjects(delivery).Build()
+
+			q := newWebhookQueue(cli, 1)
+			defer q.queue.ShutDown()
+			require.True(t, q.add(&webhookItem{
+				ctx:      context.Background(),
+				config:   &cicdv1.IntegrationConfig{ObjectMeta: metav1.ObjectMeta{Name: "test-ic", Namespace: "default"}},
+				wh:       &git.Webhook{EventType: git.EventTypePush, Push: &git.Push{Sha: "sha-1"}},
+				delivery: delivery,
+			}))
+			go q.work()
+
+			stored := &cicdv1.WebhookDelivery{}
+			require.Eventually(t, func() bool {
+				require.NoError(t, cli.Get(context.Background(), types.NamespacedName{Name: delivery.Name, Namespace: delivery.Namespace}, stored))
+				return stored.Status.State == c.expectedState
+			}, time.Second, 10*time.Millisecond)
+
+			// Only the failed plugin is retried
+			require.Len(t, stable.getHandled(), 1)
+			require.Len(t, flaky.getHandled(), c.expectedAttempts)
+			require.Equal(t, c.expectedAttempts, stored.Status.Attempts)
+			require.Equal(t, []cicdv1.WebhookDeliveryPluginResult{{Name: "stable"}, {Name: "flaky", Error: c.expectedPluginError}}, stored.Status.Plugins)
+		})
+	}
+}
+
+func TestWebhookQueue_add(t *testing.T) {
+	plugin := &flakyPlugin{name: "test"}
+	AddPlugin([]git.EventType{git.EventTypePush}, plugin)
+	defer func() {
+		plugins = map[git.EventType][]Plugin{}
+	}()
+
+	q := newWebhookQueue(nil, 3)
+	defer q.queue.ShutDown()
+	ic := &cicdv1.IntegrationConfig{ObjectMeta: metav1.ObjectMeta{Name: "test-ic", Namespace: "default"}}
+
+	// Webhooks of a config are queued to the same list
+	var shas []string
+	for i := 0; i < 5; i++ {
+		sha := fmt.Sprintf("sha-%d", i)
+		shas = append(shas, sha)
+		require.True(t, q.add(&webhookItem{ctx: context.Background(), config: ic, wh: &git.Webhook{EventType: git.EventTypePush, Push: &git.Push{Sha: sha}}}))
+	}
+	require.Len(t, q.items, 1)
+	require.Len(t, q.items["default/test-ic"], 5)
+
+	// Handled in order
+	for i := 0; i < q.workers; i++ {
+		go q.work()
+	}
+	require.Eventually(t, func() bool {
+		return len(plugin.getHandled()) == 5
+	}, time.Second, 10*time.Millisecond)
+	require.Equal(t, shas, plugin.getHandled())
+
+	// Queue is full
+	full := newWebhookQueue(nil, 1)
+	for i := 0; i < webhookQueueSize; i++ {
+		require.True(t, full.add(&webhookItem{config: ic}))
+	}
+	require.False(t, full.add(&webhookItem{config: ic}))
+}
+
+func TestWebhookQueue_retryNotBlocking(t *testing.T) {
+	webhookRetryBackoff = wait.Backoff{Duration: time.Hour, Factor: 1, Steps: 10}
+	configs.WebhookRetries = 2
+	defer func() {
+		webhookRetryBackoff = wait.Backoff{Duration: time.Millisecond, Factor: 1, Steps: 10}
+	}()
+
+	// The first call, for the retried config, fails
+	plugin := &flakyPlugin{name: "test", failures: 1}
+	AddPlugin([]git.EventType{git.EventTypePush}, plugin)
+	defer func() {
+		plugins = map[git.EventType][]Plugin{}
+	}()
+
+	q := newWebhookQueue(nil, 1)
+	defer q.queue.ShutDown()
+	retried := &cicdv1.IntegrationConfig{ObjectMeta: metav1.ObjectMeta{Name: "retried-ic", Namespace: "default"}}
+	other := &cicdv1.IntegrationConfig{ObjectMeta: metav1.ObjectMeta{Name: "other-ic", Namespace: "default"}}
+	require.True(t, q.add(&webhookItem{ctx: context.Background(), config: retried, wh: &git.Webhook{EventType: git.EventTypePush, Push: &git.Push{Sha: "sha-retried"}}}))
+	require.True(t, q.add(&webhookItem{ctx: context.Background(), config: retried, wh: &git.Webhook{EventType: git.EventTypePush, Push: &git.Push{Sha: "sha-retried-next"}}}))
+	require.True(t, q.add(&webhookItem{ctx: context.Background(), config: other, wh: &git.Webhook{EventType: git.EventTypePush, Push: &git.Push{Sha: "sha-other"}}}))
+	go q.work()
+
+	// The other config's webhook is handled while the failed webhook is waiting for the retry, but the later webhook
+	// of the retried config keeps waiting for it
+	require.Eventually(t, func() bool {
+		return len(plugin.getHandled()) == 2
+	}, time.Second, 10*time.Millisecond)
+	require.Equal(t, []string{"sha-retried", "sha-other"}, plugin.getHandled())
+	time.Sleep(100 * time.Millisecond)
+	require.Len(t, plugin.getHandled(), 2)
+	q.itemsLock.Lock()
+	require.Len(t, q.items["default/retried-ic"], 2)
+	q.itemsLock.Unlock()
+}
+
+func TestWebhookQueue_recover(t *testing.T) {
+	webhookRetryBackoff = wait.Backoff{Duration: time.Millisecond, Factor: 1, Steps: 10}
+	configs.WebhookRetries = 2
+	configs.WebhookDeliveryTTL = 24
+	configs.WebhookDedupWindow = 120
+	defer func() {
+		configs.WebhookDedupWindow = 0
+	}()
+
+	stable := &flakyPlugin{name: "stable"}
+	flaky := &flakyPlugin{name: "flaky"}
+	AddPlugin([]git.EventType{git.EventTypePush}, stable)
+	AddPlugin([]git.EventType{git.EventTypePush}, flaky)
+	defer func() {
+		plugins = map[git.EventType][]Plugin{}
+	}()
+
+	ic := &cicdv1.IntegrationConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-ic", Namespace: "default"},
+		Spec: cicdv1.IntegrationConfigSpec{
+			Git: cicdv1.GitConfig{Type: cicdv1.GitTypeGitHub, APIUrl: "http://127.0.0.1:1", Repository: "test/test"},
+		},
+		Status: cicdv1.IntegrationConfigStatus{Secrets: "test-secret"},
+	}
+
+	pushDelivery := func(name, sha string, status cicdv1.WebhookDeliveryStatus) *cicdv1.WebhookDelivery {
+		body := fmt.Sprintf(`{"ref": "refs/heads/master", "after": "%s", "repository": {"full_name": "test/test"}, "sender": {"login": "test"}}`, sha)
+		d := testDelivery(name, time.Hour)
+		d.Spec.Header = map[string][]string{
+			"X-Github-Event":  {"push"},
+			"X-Hub-Signature": {"sha1=" + github.HashPayload("test-secret", []byte(body))},
+		}
+		d.Spec.Body = body
+		d.Status = status
+		return d
+	}
+
+	objs := []client.Object{
+		ic,
+		pushDelivery("test-ic-queued", "sha-queued", cicdv1.WebhookDeliveryStatus{}),
+		pushDelivery("test-ic-retrying", "sha-retrying", cicdv1.WebhookDeliveryStatus{
+			State:    cicdv1.WebhookDeliveryStateRetrying,
+			Attempts: 1,
+			Plugins:  []cicdv1.WebhookDeliveryPluginResult{{Name: "stable"}, {Name: "flaky", Error: "flaky error"}},
+		}),
+		pushDelivery("test-ic-succeeded", "sha-succeeded", cicdv1.WebhookDeliveryStatus{State: cicdv1.WebhookDeliveryStateSucceeded}),
+		pushDelivery("test-ic-received", "sha-received", cicdv1.WebhookDeliveryStatus{}),
+		pushDelivery("test-ic-handled", "sha-handled", cicdv1.WebhookDeliveryStatus{State: cicdv1.WebhookDeliveryStateSucceeded, DeliveryID: "id-handled"}),
+	}
+	redelivered := pushDelivery("test-ic-redelivered", "sha-handled", cicdv1.WebhookDeliveryStatus{})
+	redelivered.Spec.Header["X-Github-Delivery"] = []string{"id-handled"}
+	objs = append(objs, redelivered)
+	cli := fake.NewClientBuilder().WithScheme(testQueueScheme(t)).WithObjects(objs...).Build()
+
+	q := newWebhookQueue(cli, 1)
+	q.markReceived("default", "test-ic-received")
+	q.recover()
+	require.False(t, q.recovering)
+	defer q.queue.ShutDown()
+	go q.work()
+
+	require.Eventually(t, func() bool {
+		return len(flaky.getHandled()) == 2
+	}, time.Second, 10*time.Millisecond)
+	require.Equal(t, []string{"sha-queued"}, stable.getHandled())
+	require.ElementsMatch(t, []string{"sha-queued", "sha-retrying"}, flaky.getHandled())
+
+	require.Eventually(t, func() bool {
+		stored := &cicdv1.WebhookDelivery{}
+		require.NoError(t, cli.Get(context.Background(), types.NamespacedName{Name: "test-ic-retrying", Namespace: "default"}, stored))
+		return stored.Status.State == cicdv1.WebhookDeliveryStateSucceeded && stored.Status.Attempts == 2
+	}, time.Second, 10*time.Millisecond)
+
+	// Redelivered webhook is not handled
+	stored := &cicdv1.WebhookDelivery{}
+	require.NoError(t, cli.Get(context.Background(), types.NamespacedName{Name: "test-ic-redelivered", Namespace: "default"}, stored))
+	require.Equal(t, cicdv1.WebhookDeliveryStateDuplicated, stored.Status.State)
+	require.Equal(t, "id-handled", stored.Status.DeliveryID)
+}
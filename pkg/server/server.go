@@ -25,6 +25,7 @@ import (
 
 	"github.com/go-logr/logr"
 	"github.com/gorilla/mux"
+	"github.com/tmax-cloud/cicd-operator/internal/configs"
 	"github.com/tmax-cloud/cicd-operator/internal/utils"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
@@ -52,6 +53,7 @@ type Server interface {
 type server struct {
 	k8sClient client.Client
 	router    *mux.Router
+	queue     *webhookQueue
 }
 
 // New is a constructor of a server
@@ -66,7 +68,8 @@ func New(c client.Client, cfg *rest.Config) *server {
 	}
 
 	// Add webhook handler
-	r.Methods(http.MethodPost).Subrouter().Handle(webhookPath, &webhookHandler{k8sClient: c})
+	queue := newWebhookQueue(c, configs.WebhookWorkers)
+	r.Methods(http.MethodPost).Subrouter().Handle(webhookPath, &webhookHandler{k8sClient: c, queue: queue})
 
 	// Add report handler
 	r.Methods(http.MethodGet).Subrouter().Handle(reportPath, &reportHandler{k8sClient: c, podsGetter: clientSet.CoreV1()})
@@ -74,6 +77,7 @@ func New(c client.Client, cfg *rest.Config) *server {
 	return &server{
 		k8sClient: c,
 		router:    r,
+		queue:     queue,
 	}
 }
 
@@ -81,6 +85,9 @@ func New(c client.Client, cfg *rest.Config) *server {
 func (s *server) Start() {
 	httpAddr := fmt.Sprintf("0.0.0.0:%d", port)
 
+	s.queue.start()
+	startDeliveryPruner(s.k8sClient)
+
 	logger.Info(fmt.Sprintf("Server is running on %s", httpAddr))
 	if err := http.ListenAndServe(httpAddr, s.router); err != nil {
 		logger.Error(err, "cannot launch http server")
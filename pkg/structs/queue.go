@@ -17,6 +17,7 @@
 package structs
 
 import (
+	"sort"
 	"sync"
 )
 
@@ -47,6 +48,7 @@ type SortedUniqueList interface {
 	ForEach(iteratorFunc IteratorFunc)
 	Delete(i Item)
 	Len() int
+	Sort()
 }
 
 // sortedUniqueList is a kind of priority queues, whose nodes are sorted
@@ -170,3 +172,35 @@ func (q *sortedUniqueList) Len() int {
 	})
 	return i
 }
+
+// Sort re-sorts the nodes using the compare function.
+// It is needed if the order of the nodes changes over time (e.g., the compare function depends on the current time)
+func (q *sortedUniqueList) Sort() {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	if q.compareFunc == nil {
+		return
+	}
+
+	var nodes []*node
+	for n := q.nodes; n != nil; n = n.next {
+		nodes = append(nodes, n)
+	}
+	sort.SliceStable(nodes, func(i, j int) bool {
+		return q.compareFunc(nodes[i].item, nodes[j].item)
+	})
+
+	q.nodes = nil
+	var prev *node
+	for _, n := range nodes {
+		n.prev = prev
+		n.next = nil
+		if prev != nil {
+			prev.next = n
+		} else {
+			q.nodes = n
+		}
+		prev = n
+	}
+}
@@ -142,3 +142,29 @@ func TestSortedUniqueList_ForEach(t *testing.T) {
 		i++
 	})
 }
+
+func TestSortedUniqueList_Sort(t *testing.T) {
+	q := initQueue()
+
+	// Reverse the order
+	q.compareFunc = func(a, b Item) bool {
+		return compare(b, a)
+	}
+	q.Sort()
+
+	i := 7
+	q.ForEach(func(item Item) {
+		it, ok := item.(*testType)
+		assert.Equal(t, true, ok, "item is not a testType")
+
+		assert.Equal(t, i, it.body, "item is not sorted")
+		i--
+	})
+	assert.Equal(t, 0, i, "items are lost")
+
+	// Links should be valid
+	q.Delete(&testType{body: 7})
+	assert.Equal(t, 6, q.First().(*testType).body, "item is not deleted")
+	q.Delete(&testType{body: 1})
+	assert.Equal(t, 5, q.Len(), "item is not deleted")
+}
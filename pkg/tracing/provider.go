@@ -0,0 +1,98 @@
+/*
+ Copyright 2021 The CI/CD Operator Authors
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package tracing
+
+import (
+	"context"
+	"sync"
+
+	"github.com/tmax-cloud/cicd-operator/internal/configs"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.7.0"
+	"go.opentelemetry.io/otel/trace"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+var log = logf.Log.WithName("tracing")
+
+var (
+	provider         *sdktrace.TracerProvider
+	providerEndpoint string
+	providerInsecure bool
+	providerLock     sync.Mutex
+)
+
+// Init sets up the tracer provider from the controller configs, and sets it up again whenever the configs are updated.
+// It should be called after the controller configs are initiated
+func Init(serviceName string) {
+	ch := make(chan struct{}, 1)
+	configs.RegisterControllerConfigUpdateChan(ch)
+
+	setupProvider(serviceName)
+	go func() {
+		for range ch {
+			setupProvider(serviceName)
+		}
+	}()
+}
+
+// setupProvider replaces the global tracer provider, if the endpoint configs are changed.
+// A no-op provider is used if the endpoint is not configured
+func setupProvider(serviceName string) {
+	providerLock.Lock()
+	defer providerLock.Unlock()
+
+	if provider != nil && providerEndpoint == configs.TracingEndpoint && providerInsecure == configs.TracingInsecure {
+		return
+	}
+
+	// Flush the spans of the previous provider
+	if provider != nil {
+		if err := provider.Shutdown(context.Background()); err != nil {
+			log.Error(err, "cannot shut down the tracer provider")
+		}
+		provider = nil
+	}
+	providerEndpoint = configs.TracingEndpoint
+	providerInsecure = configs.TracingInsecure
+
+	if providerEndpoint == "" {
+		otel.SetTracerProvider(trace.NewNoopTracerProvider())
+		return
+	}
+
+	opts := []otlptracehttp.Option{otlptracehttp.WithEndpoint(providerEndpoint)}
+	if providerInsecure {
+		opts = append(opts, otlptracehttp.WithInsecure())
+	}
+	exporter, err := otlptracehttp.New(context.Background(), opts...)
+	if err != nil {
+		log.Error(err, "cannot create the trace exporter")
+		otel.SetTracerProvider(trace.NewNoopTracerProvider())
+		return
+	}
+
+	provider = sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceNameKey.String(serviceName))),
+	)
+	otel.SetTracerProvider(provider)
+	log.Info("tracing is enabled", "endpoint", providerEndpoint)
+}
@@ -0,0 +1,136 @@
+/*
+ Copyright 2021 The CI/CD Operator Authors
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+// Package tracing traces the flow of the operator with OpenTelemetry, from receiving a webhook to setting the commit
+// statuses. The trace context is carried across the resources through the IntegrationJob's annotations
+package tracing
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const tracerName = "github.com/tmax-cloud/cicd-operator"
+
+// Annotations of IntegrationJobs, which carry the trace context
+const (
+	AnnotationTraceParent = "cicd.tmax.io/traceparent"
+	AnnotationTraceState  = "cicd.tmax.io/tracestate"
+)
+
+// Attribute keys
+const (
+	AttributeNamespace         = attribute.Key("cicd.namespace")
+	AttributeIntegrationConfig = attribute.Key("cicd.integration_config")
+	AttributeIntegrationJob    = attribute.Key("cicd.integration_job")
+	AttributeGitProvider       = attribute.Key("cicd.git.provider")
+	AttributeGitEvent          = attribute.Key("cicd.git.event")
+	AttributePlugin            = attribute.Key("cicd.plugin")
+)
+
+var propagator = propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{})
+
+// Start starts a new span, as a child of the span in ctx
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(tracerName).Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// End ends the span, recording the error if it is not nil
+func End(span trace.Span, err error) {
+	SetError(span, err)
+	span.End()
+}
+
+// SetError records the error to the span and marks the span as failed, if the error is not nil
+func SetError(span trace.Span, err error) {
+	if err == nil {
+		return
+	}
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
+
+// StartForJob starts a new span for the IntegrationJob, continuing the trace stored in the IntegrationJob's annotations
+func StartForJob(job metav1.Object, configName, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	ctx := Extract(context.Background(), job)
+	attrs = append(attrs, AttributeNamespace.String(job.GetNamespace()), AttributeIntegrationConfig.String(configName), AttributeIntegrationJob.String(job.GetName()))
+	return Start(ctx, name, attrs...)
+}
+
+// ExtractHTTP extracts the trace context from the http header, so the trace is continued from the caller
+func ExtractHTTP(ctx context.Context, header map[string][]string) context.Context {
+	return propagator.Extract(ctx, propagation.HeaderCarrier(header))
+}
+
+// Inject injects the trace context of ctx into the object's annotations
+func Inject(ctx context.Context, obj metav1.Object) {
+	annotations := obj.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	propagator.Inject(ctx, annotationCarrier(annotations))
+	if len(annotations) > 0 {
+		obj.SetAnnotations(annotations)
+	}
+}
+
+// Extract extracts the trace context from the object's annotations
+func Extract(ctx context.Context, obj metav1.Object) context.Context {
+	return propagator.Extract(ctx, annotationCarrier(obj.GetAnnotations()))
+}
+
+// annotationCarrier is a propagation.TextMapCarrier, which stores the trace context in the annotations
+type annotationCarrier map[string]string
+
+var annotationKeys = map[string]string{
+	"traceparent": AnnotationTraceParent,
+	"tracestate":  AnnotationTraceState,
+}
+
+// Get returns the value for the key
+func (a annotationCarrier) Get(key string) string {
+	annotation, exist := annotationKeys[key]
+	if !exist {
+		return ""
+	}
+	return a[annotation]
+}
+
+// Set sets the value for the key. Keys other than the trace context (e.g., baggage) are not stored
+func (a annotationCarrier) Set(key, value string) {
+	annotation, exist := annotationKeys[key]
+	if !exist || a == nil {
+		return
+	}
+	a[annotation] = value
+}
+
+// Keys lists the keys stored in the carrier
+func (a annotationCarrier) Keys() []string {
+	var keys []string
+	for key, annotation := range annotationKeys {
+		if _, exist := a[annotation]; exist {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
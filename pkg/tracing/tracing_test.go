@@ -0,0 +1,67 @@
+/*
+ Copyright 2021 The CI/CD Operator Authors
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestInjectExtract(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+
+	// Webhook is received with a trace context
+	header := http.Header{}
+	header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	ctx, span := Start(ExtractHTTP(context.Background(), header), "webhook.receive")
+	require.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", span.SpanContext().TraceID().String())
+
+	// IntegrationJob is created, carrying the trace context
+	job := &metav1.ObjectMeta{Name: "test-job", Namespace: "default", Annotations: map[string]string{"requestBody": "{}"}}
+	Inject(ctx, job)
+	End(span, nil)
+	require.Equal(t, fmt.Sprintf("00-%s-%s-01", span.SpanContext().TraceID(), span.SpanContext().SpanID()), job.Annotations[AnnotationTraceParent])
+	require.Equal(t, "{}", job.Annotations["requestBody"])
+
+	// Controller continues the trace
+	_, jobSpan := StartForJob(job, "test-config", "scheduler.schedule")
+	End(jobSpan, fmt.Errorf("test error"))
+	require.Equal(t, span.SpanContext().TraceID(), jobSpan.SpanContext().TraceID())
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+	require.Equal(t, "scheduler.schedule", spans[1].Name())
+	require.Equal(t, span.SpanContext().SpanID(), spans[1].Parent().SpanID())
+	require.Equal(t, codes.Error, spans[1].Status().Code)
+	require.Contains(t, spans[1].Attributes(), AttributeIntegrationJob.String("test-job"))
+	require.Contains(t, spans[1].Attributes(), AttributeIntegrationConfig.String("test-config"))
+
+	// A job without the trace context starts a new trace
+	_, newSpan := StartForJob(&metav1.ObjectMeta{}, "test-config", "scheduler.schedule")
+	require.False(t, newSpan.SpanContext().TraceID() == span.SpanContext().TraceID())
+	require.False(t, newSpan.(sdktrace.ReadOnlySpan).Parent().IsValid())
+}